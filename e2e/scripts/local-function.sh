@@ -0,0 +1,16 @@
+#!/usr/bin/env bash
+
+set -e
+
+# Serves a function for the local functions backend of dispatch-server, which runs it in the function source
+# directory with PORT, HANDLER and IMAGE set. The function image runs in docker with the source mounted at
+# FUNCTION_DIR.
+# Usage: dispatch-server --functions-backend local --local-command "${DISPATCH_ROOT}/e2e/scripts/local-function.sh"
+
+: ${FUNCTION_DIR:="/function"}
+
+exec docker run --rm --init \
+    -p "127.0.0.1:${PORT}:8080" \
+    -v "$(pwd):${FUNCTION_DIR}:ro" \
+    -e HANDLER -e SERVERS -e SECRETS -e TIMEOUT \
+    "${IMAGE}"
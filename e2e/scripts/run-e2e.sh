@@ -15,12 +15,52 @@ function quiet_run () {
 
 function bring_dispatch_up {
   OS=`uname`
+  if [[ "$FUNCTIONS_BACKEND" == "local" ]]; then
+    start_local_dispatch
+    return
+  fi
   echo "   Installing dispatch..."
   dispatch install --file ${DISPATCH_CONFIG} --charts-dir ${DISPATCH_ROOT}/charts --destination ${DISPATCH_ROOT} --debug
 }
 
+# start_local_dispatch runs dispatch-server on this machine, functions run on the local backend so no cluster is needed
+function start_local_dispatch {
+  echo "   Starting a local dispatch-server..."
+  LOCAL_DIR="${DISPATCH_ROOT}/.dispatch/local"
+  mkdir -p "${LOCAL_DIR}/sources" "$(dirname ${DISPATCH_CLI_CONFIG})"
+  local args=(--functions-backend local --local-work-dir "${LOCAL_DIR}/functions"
+    --storage file --file-sourceroot "${LOCAL_DIR}/sources" --db-address "${LOCAL_DIR}/db.bolt"
+    --namespace "${DISPATCH_NAMESPACE}" --port "${LOCAL_PORT}")
+  while read -r command; do
+    if [[ -n "$command" ]]; then
+      args+=(--local-command "$command")
+    fi
+  done <<< "${LOCAL_COMMANDS}"
+  ${DISPATCH_ROOT}/bin/dispatch-server-${OS,,} "${args[@]}" &> "${LOCAL_DIR}/dispatch-server.log" &
+  LOCAL_PID=$!
+  cat > "${DISPATCH_CLI_CONFIG}" <<EOC
+{
+  "current": "local",
+  "contexts": {
+    "local": {"host": "127.0.0.1", "port": ${LOCAL_PORT}, "scheme": "http", "organization": "${DISPATCH_ORGANIZATION:-dispatch}", "insecure": true}
+  }
+}
+EOC
+  for i in $(seq 1 30); do
+    if curl -s -o /dev/null "http://127.0.0.1:${LOCAL_PORT}/"; then
+      return
+    fi
+    sleep 1
+  done
+  echo "dispatch-server did not start, see ${LOCAL_DIR}/dispatch-server.log"
+  exit 1
+}
+
 function cleanup_dispatch {
   echo " - Cleaning up dispatch"
+  if [[ -n "$LOCAL_PID" ]]; then
+    kill "$LOCAL_PID"
+  fi
 }
 
 function dispatch() {
@@ -61,6 +101,12 @@ export DISPATCH_CONFIG="$2"
 : ${INSTALL_DISPATCH:=1}
 : ${DISPATCH_NAMESPACE:="dispatch"}
 export DISPATCH_NAMESPACE
+# FUNCTIONS_BACKEND=local runs dispatch-server on this machine instead of installing it on a cluster, LOCAL_COMMANDS
+# holds its --local-command values, one per line
+: ${FUNCTIONS_BACKEND:="knative"}
+: ${LOCAL_PORT:=8080}
+: ${LOCAL_COMMANDS:="${BASE_TEST_DIR:-$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )}/local-function.sh"}
+export FUNCTIONS_BACKEND
 
 # Check we're not running bash 3.x
 if [ "${BASH_VERSINFO[0]}" -lt 4 ]; then
@@ -84,12 +130,12 @@ if [[ ! -e "$BATS_FILE" ]]; then
     exit 1
 fi
 
-if [[ -z "$DISPATCH_CONFIG" && $INSTALL_DISPATCH == 1 ]]; then
+if [[ -z "$DISPATCH_CONFIG" && $INSTALL_DISPATCH == 1 && "$FUNCTIONS_BACKEND" != "local" ]]; then
     echo "You must specify a dispatch config file if installing dispatch (default)."
     exit 1
 fi
 
-if [[ ! -e "$DISPATCH_CONFIG" && $INSTALL_DISPATCH == 1 ]]; then
+if [[ ! -e "$DISPATCH_CONFIG" && $INSTALL_DISPATCH == 1 && "$FUNCTIONS_BACKEND" != "local" ]]; then
     echo "Requested dispatch config file not found: $DISPATCH_CONFIG"
     exit 1
 fi
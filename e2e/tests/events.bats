@@ -77,6 +77,7 @@ load variables
 
 
 @test "Create event driver and matching subscription" {
+    skip_without_cluster
     func_name=node-echo-back-${RANDOM}
     sub_name=testsub-${RANDOM}
     driver_name=testdriver-${RANDOM}
@@ -148,6 +149,7 @@ load variables
 }
 
 @test "Create event driver without available image" {
+    skip_without_cluster
     driver_name=testdriver-${RANDOM}
 
     run dispatch create eventdrivertype baddrivertype unavailable-image:latest
@@ -175,6 +177,7 @@ load variables
 }
 
 @test "Create eventdriver with invalid name (Upper case, underscores)" {
+    skip_without_cluster
 
     bad_driver_name1=testDriver-${RANDOM}
     bad_driver_name2=test_driver-${RANDOM}
@@ -2,6 +2,13 @@
 
 ### COMMON FUNCTIONS ###
 
+# skip_without_cluster skips tests of features the local dispatch-server does not support, such as event drivers
+skip_without_cluster () {
+  if [[ "${FUNCTIONS_BACKEND}" == "local" ]]; then
+    skip "needs a cluster, not supported with FUNCTIONS_BACKEND=local"
+  fi
+}
+
 # retry_simple takes 3 args: command [interval (secs)] [attempts (num)]
 retry_simple () {
   count=0
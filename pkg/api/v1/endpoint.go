@@ -6,6 +6,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"strconv"
 
 	strfmt "github.com/go-openapi/strfmt"
@@ -17,15 +18,34 @@ import (
 
 // NO TESTS
 
+const (
+	// EndpointMatchExact endpoints serve requests to their uris, which can be templates with {parameters}
+	EndpointMatchExact string = "exact"
+	// EndpointMatchPrefix endpoints serve requests to paths starting with their uris, which can be templates with {parameters}
+	EndpointMatchPrefix string = "prefix"
+	// EndpointMatchRegex endpoints serve requests to paths fully matching their uris as regular expressions
+	EndpointMatchRegex string = "regex"
+)
+
 // Endpoint Endpoint
 // swagger:model Endpoint
 type Endpoint struct {
 	// meta
 	Meta
 
+	// end-user authentication, public when not set
+	Auth *EndpointAuth `json:"auth,omitempty"`
+
+	// rate limit of the requests of each consumer (the authenticated subject, or the client address of public
+	// endpoints), no limit when not set
+	ConsumerRateLimit *EndpointRateLimit `json:"consumerRateLimit,omitempty"`
+
 	// enable Cross-Origin Resource Sharing (CORS)
 	Cors bool `json:"cors,omitempty"`
 
+	// Cross-Origin Resource Sharing (CORS) policy, allowing any origin when not set
+	CorsPolicy *EndpointCors `json:"corsPolicy,omitempty"`
+
 	// a easy way to disable an Endpoint without deleting it.
 	Enabled bool `json:"enabled,omitempty"`
 
@@ -36,12 +56,27 @@ type Endpoint struct {
 	// a list of domain names that point to the Endpoint
 	Hosts []string `json:"hosts"`
 
+	// how request paths are matched against the uris, exact when not set
+	// Enum: [exact prefix regex]
+	Match string `json:"match,omitempty"`
+
+	// maximum size, in bytes, of request bodies, larger requests are rejected, no limit when not set
+	// Minimum: 0
+	MaxBodySize int64 `json:"maxBodySize,omitempty"`
+
 	// a list of HTTP/S methods that point to the Endpoint
 	Methods []string `json:"methods"`
 
 	// a list of support protocols (i.e. http, https)
 	Protocols []string `json:"protocols"`
 
+	// rate limit of the requests of all consumers, no limit when not set
+	RateLimit *EndpointRateLimit `json:"rateLimit,omitempty"`
+
+	// path the matched uri (or uri prefix) is replaced with before the request reaches the function, the
+	// {parameters} of templated uris are substituted
+	Rewrite string `json:"rewrite,omitempty"`
+
 	// status
 	Status Status `json:"status,omitempty"`
 
@@ -62,6 +97,21 @@ func NewEndpoint() *Endpoint {
 func (m *Endpoint) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAuth(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateConsumerRateLimit(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateCorsPolicy(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateFunction(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -82,6 +132,16 @@ func (m *Endpoint) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateMatch(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateMaxBodySize(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateMethods(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -97,6 +157,11 @@ func (m *Endpoint) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateRateLimit(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateStatus(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -118,6 +183,66 @@ func (m *Endpoint) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Endpoint) validateAuth(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Auth) { // not required
+		return nil
+	}
+
+	if m.Auth != nil {
+
+		if err := m.Auth.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("auth")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Endpoint) validateConsumerRateLimit(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ConsumerRateLimit) { // not required
+		return nil
+	}
+
+	if m.ConsumerRateLimit != nil {
+
+		if err := m.ConsumerRateLimit.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("consumerRateLimit")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Endpoint) validateCorsPolicy(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.CorsPolicy) { // not required
+		return nil
+	}
+
+	if m.CorsPolicy != nil {
+
+		if err := m.CorsPolicy.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("corsPolicy")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Endpoint) validateFunction(formats strfmt.Registry) error {
 
 	if err := validate.Required("function", "body", m.Function); err != nil {
@@ -162,6 +287,53 @@ func (m *Endpoint) validateKind(formats strfmt.Registry) error {
 	return nil
 }
 
+var endpointTypeMatchPropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["exact","prefix","regex"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		endpointTypeMatchPropEnum = append(endpointTypeMatchPropEnum, v)
+	}
+}
+
+// prop value enum
+func (m *Endpoint) validateMatchEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, endpointTypeMatchPropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Endpoint) validateMatch(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Match) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateMatchEnum("match", "body", m.Match); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Endpoint) validateMaxBodySize(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MaxBodySize) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("maxBodySize", "body", int64(m.MaxBodySize), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Endpoint) validateMethods(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Methods) { // not required
@@ -193,6 +365,26 @@ func (m *Endpoint) validateProtocols(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Endpoint) validateRateLimit(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.RateLimit) { // not required
+		return nil
+	}
+
+	if m.RateLimit != nil {
+
+		if err := m.RateLimit.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("rateLimit")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Endpoint) validateStatus(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Status) { // not required
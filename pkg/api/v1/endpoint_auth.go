@@ -0,0 +1,142 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	"encoding/json"
+
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+const (
+	// EndpointAuthPublic endpoints are not authenticated
+	EndpointAuthPublic string = "public"
+	// EndpointAuthBasic endpoints authenticate users with the usernames and passwords of a secret
+	EndpointAuthBasic string = "basic"
+	// EndpointAuthAPIKey endpoints authenticate clients with the keys of a secret, in the X-API-Key header
+	EndpointAuthAPIKey string = "apikey"
+	// EndpointAuthOAuth2 endpoints authenticate clients with a JWT bearer token signed by an OAuth2/OIDC issuer
+	EndpointAuthOAuth2 string = "oauth2"
+)
+
+// EndpointAuth end-user authentication of an endpoint
+// swagger:model EndpointAuth
+type EndpointAuth struct {
+
+	// audiences accepted in the JWT, any when empty (oauth2)
+	Audiences []string `json:"audiences,omitempty"`
+
+	// issuer of the JWT, its keys are discovered from the OpenID configuration of the issuer unless jwksUri is set (oauth2)
+	Issuer string `json:"issuer,omitempty"`
+
+	// URI of the JSON Web Key Set validating the JWT (oauth2)
+	JwksURI string `json:"jwksUri,omitempty"`
+
+	// requests per hour allowed to each API key by name, overriding quota (apikey)
+	KeyQuotas map[string]int64 `json:"keyQuotas,omitempty"`
+
+	// requests per hour allowed to each API key, 0 for no limit (apikey)
+	// Minimum: 0
+	Quota int64 `json:"quota,omitempty"`
+
+	// name of the secret holding the passwords by username (basic) or the API keys by name (apikey)
+	Secret string `json:"secret,omitempty"`
+
+	// authentication method
+	// Enum: [public basic apikey oauth2]
+	Type string `json:"type,omitempty"`
+}
+
+// Validate validates this endpoint auth
+func (m *EndpointAuth) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateQuota(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateType(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *EndpointAuth) validateQuota(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Quota) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("quota", "body", int64(m.Quota), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var endpointAuthTypeTypePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["public","basic","apikey","oauth2"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		endpointAuthTypeTypePropEnum = append(endpointAuthTypeTypePropEnum, v)
+	}
+}
+
+// prop value enum
+func (m *EndpointAuth) validateTypeEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, endpointAuthTypeTypePropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *EndpointAuth) validateType(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Type) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateTypeEnum("type", "body", m.Type); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *EndpointAuth) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *EndpointAuth) UnmarshalBinary(b []byte) error {
+	var res EndpointAuth
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,86 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// EndpointCors Cross-Origin Resource Sharing (CORS) policy of an endpoint
+// swagger:model EndpointCors
+type EndpointCors struct {
+
+	// allow credentials (cookies, authorization headers) in cross-origin requests, only for the origins listed in allowOrigins
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+
+	// request headers allowed in cross-origin requests, the headers requested by the browser when empty
+	AllowHeaders []string `json:"allowHeaders"`
+
+	// methods allowed in cross-origin requests, the methods of the endpoint when empty
+	AllowMethods []string `json:"allowMethods"`
+
+	// origins allowed to make cross-origin requests, any when empty
+	AllowOrigins []string `json:"allowOrigins"`
+
+	// response headers exposed to the browser
+	ExposeHeaders []string `json:"exposeHeaders"`
+
+	// how long, in seconds, browsers cache the result of a preflight request
+	// Minimum: 0
+	MaxAge int64 `json:"maxAge,omitempty"`
+}
+
+// Validate validates this endpoint cors
+func (m *EndpointCors) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateMaxAge(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *EndpointCors) validateMaxAge(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MaxAge) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("maxAge", "body", int64(m.MaxAge), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *EndpointCors) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *EndpointCors) UnmarshalBinary(b []byte) error {
+	var res EndpointCors
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,146 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	"encoding/json"
+
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+const (
+	// EndpointRatePerSecond rate limits count requests per second
+	EndpointRatePerSecond string = "second"
+	// EndpointRatePerMinute rate limits count requests per minute
+	EndpointRatePerMinute string = "minute"
+)
+
+// EndpointRateLimit rate limit of the requests to an endpoint
+// swagger:model EndpointRateLimit
+type EndpointRateLimit struct {
+
+	// requests allowed at once, requests when not set
+	// Minimum: 0
+	Burst int64 `json:"burst,omitempty"`
+
+	// unit of time requests are counted over, second when not set
+	// Enum: [second minute]
+	Per string `json:"per,omitempty"`
+
+	// requests allowed per second or minute
+	// Required: true
+	// Minimum: 1
+	Requests int64 `json:"requests"`
+}
+
+// Validate validates this endpoint rate limit
+func (m *EndpointRateLimit) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateBurst(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validatePer(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateRequests(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *EndpointRateLimit) validateBurst(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Burst) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("burst", "body", int64(m.Burst), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var endpointRateLimitTypePerPropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["second","minute"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		endpointRateLimitTypePerPropEnum = append(endpointRateLimitTypePerPropEnum, v)
+	}
+}
+
+// prop value enum
+func (m *EndpointRateLimit) validatePerEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, endpointRateLimitTypePerPropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *EndpointRateLimit) validatePer(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Per) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validatePerEnum("per", "body", m.Per); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *EndpointRateLimit) validateRequests(formats strfmt.Registry) error {
+
+	if err := validate.Required("requests", "body", int64(m.Requests)); err != nil {
+		return err
+	}
+
+	if err := validate.MinimumInt("requests", "body", int64(m.Requests), 1, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *EndpointRateLimit) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *EndpointRateLimit) UnmarshalBinary(b []byte) error {
+	var res EndpointRateLimit
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -38,12 +38,30 @@ type Function struct {
 	// imageURL
 	ImageURL string `json:"-"`
 
+	// maximum number of function instances, 0 means no limit
+	// Minimum: 0
+	MaxScale int64 `json:"maxScale,omitempty"`
+
+	// minimum number of function instances, 0 lets the function scale to zero
+	// Minimum: 0
+	MinScale int64 `json:"minScale,omitempty"`
+
+	// environment variables
+	Env []*EnvVar `json:"env,omitempty"`
+
 	// functionImageURL
 	FunctionImageURL string `json:"functionImageURL,omitempty"`
 
 	// buildTemplate
 	BuildTemplate string `json:"-"`
 
+	// configurations whose values are set as environment variables
+	Configs []string `json:"configs,omitempty"`
+
+	// maximum number of concurrent requests a function instance serves, 0 means no limit (default: 1)
+	// Minimum: 0
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+
 	// serviceAccount
 	ServiceAccount string `json:"-"`
 
@@ -53,6 +71,13 @@ type Function struct {
 	// reason
 	Reason []string `json:"reason,omitempty"`
 
+	// resources
+	Resources *FunctionResources `json:"resources,omitempty"`
+
+	// revisions
+	// Read Only: true
+	Revisions []*FunctionRevision `json:"revisions,omitempty"`
+
 	// schema
 	Schema *Schema `json:"schema,omitempty"`
 
@@ -67,12 +92,30 @@ type Function struct {
 
 	// timeout
 	Timeout int64 `json:"timeout,omitempty"`
+
+	// traffic split between revisions, all traffic goes to the latest revision if empty
+	Traffic []*TrafficTarget `json:"traffic,omitempty"`
 }
 
 // Validate validates this function
 func (m *Function) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateConfigs(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateContainerConcurrency(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateEnv(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateID(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -88,11 +131,31 @@ func (m *Function) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateMaxScale(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateMinScale(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateName(formats); err != nil {
 		// prop
 		res = append(res, err)
 	}
 
+	if err := m.validateResources(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateRevisions(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateSchema(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -118,12 +181,67 @@ func (m *Function) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateTraffic(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Function) validateConfigs(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Configs) { // not required
+		return nil
+	}
+
+	return nil
+}
+
+func (m *Function) validateContainerConcurrency(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ContainerConcurrency) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("containerConcurrency", "body", int64(*m.ContainerConcurrency), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Function) validateEnv(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Env) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Env); i++ {
+
+		if swag.IsZero(m.Env[i]) { // not required
+			continue
+		}
+
+		if m.Env[i] != nil {
+
+			if err := m.Env[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("env" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Function) validateID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.ID) { // not required
@@ -159,6 +277,32 @@ func (m *Function) validateKind(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Function) validateMaxScale(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MaxScale) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("maxScale", "body", int64(m.MaxScale), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Function) validateMinScale(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MinScale) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("minScale", "body", int64(m.MinScale), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Function) validateName(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Name) { // not required
@@ -172,6 +316,54 @@ func (m *Function) validateName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Function) validateResources(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Resources) { // not required
+		return nil
+	}
+
+	if m.Resources != nil {
+
+		if err := m.Resources.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("resources")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *Function) validateRevisions(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Revisions) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Revisions); i++ {
+
+		if swag.IsZero(m.Revisions[i]) { // not required
+			continue
+		}
+
+		if m.Revisions[i] != nil {
+
+			if err := m.Revisions[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("revisions" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Function) validateSchema(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Schema) { // not required
@@ -254,6 +446,34 @@ func (m *Function) validateTags(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Function) validateTraffic(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Traffic) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Traffic); i++ {
+
+		if swag.IsZero(m.Traffic[i]) { // not required
+			continue
+		}
+
+		if m.Traffic[i] != nil {
+
+			if err := m.Traffic[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("traffic" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *Function) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -0,0 +1,104 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// FunctionResources compute resources of a function instance
+// swagger:model FunctionResources
+type FunctionResources struct {
+
+	// resources a function instance may use at most
+	Limits *ResourceList `json:"limits,omitempty"`
+
+	// resources reserved for a function instance
+	Requests *ResourceList `json:"requests,omitempty"`
+}
+
+// Validate validates this function resources
+func (m *FunctionResources) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateLimits(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateRequests(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *FunctionResources) validateLimits(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Limits) { // not required
+		return nil
+	}
+
+	if m.Limits != nil {
+
+		if err := m.Limits.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("limits")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *FunctionResources) validateRequests(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Requests) { // not required
+		return nil
+	}
+
+	if m.Requests != nil {
+
+		if err := m.Requests.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("requests")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *FunctionResources) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *FunctionResources) UnmarshalBinary(b []byte) error {
+	var res FunctionResources
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
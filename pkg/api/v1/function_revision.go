@@ -0,0 +1,91 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// FunctionRevision function revision
+// swagger:model FunctionRevision
+type FunctionRevision struct {
+
+	// created time
+	// Read Only: true
+	CreatedTime int64 `json:"createdTime,omitempty"`
+
+	// name
+	// Read Only: true
+	Name string `json:"name,omitempty"`
+
+	// percent of the function traffic served by the revision
+	// Read Only: true
+	Percent int64 `json:"percent,omitempty"`
+
+	// source archive the revision was built from
+	// Read Only: true
+	SourceURL string `json:"sourceURL,omitempty"`
+
+	// status
+	Status Status `json:"status,omitempty"`
+}
+
+// Validate validates this function revision
+func (m *FunctionRevision) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateStatus(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *FunctionRevision) validateStatus(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Status) { // not required
+		return nil
+	}
+
+	if err := m.Status.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("status")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *FunctionRevision) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *FunctionRevision) UnmarshalBinary(b []byte) error {
+	var res FunctionRevision
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
+
+// LatestRevision is the traffic target revision which sends all traffic to the latest revision of a function
+const LatestRevision = "latest"
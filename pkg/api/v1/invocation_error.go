@@ -19,6 +19,9 @@ import (
 // swagger:model InvocationError
 type InvocationError struct {
 
+	// validation errors of the input or output, one per invalid field
+	Errors []string `json:"errors"`
+
 	// message
 	// Required: true
 	Message *string `json:"message"`
@@ -35,6 +38,11 @@ type InvocationError struct {
 func (m *InvocationError) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateErrors(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateMessage(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -56,6 +64,15 @@ func (m *InvocationError) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *InvocationError) validateErrors(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Errors) { // not required
+		return nil
+	}
+
+	return nil
+}
+
 func (m *InvocationError) validateMessage(formats strfmt.Registry) error {
 
 	if err := validate.Required("message", "body", m.Message); err != nil {
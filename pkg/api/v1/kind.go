@@ -22,6 +22,9 @@ const DriverTypeKind = "DriverType"
 // SubscriptionKind a constant representing the kind of the Subscription API model
 const SubscriptionKind = "Subscription"
 
+// DeadLetterKind a constant representing the kind of the DeadLetter API model
+const DeadLetterKind = "DeadLetter"
+
 // FunctionKind a constant representing the kind of the Function model
 const FunctionKind = "Function"
 
@@ -54,3 +57,6 @@ const ServiceAccountKind = "ServiceAccount"
 
 // OrganizationKind a constant representing the kind of the Organization Model
 const OrganizationKind = "Organization"
+
+// ConfigurationKind a constant representing the kind of the Configuration Model
+const ConfigurationKind = "Configuration"
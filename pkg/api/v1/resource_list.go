@@ -0,0 +1,54 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+)
+
+// NO TESTS
+
+// ResourceList amounts of compute resources, in Kubernetes quantity notation
+// swagger:model ResourceList
+type ResourceList struct {
+
+	// CPU, e.g. 500m for half a core
+	CPU string `json:"cpu,omitempty"`
+
+	// memory, e.g. 128Mi
+	Memory string `json:"memory,omitempty"`
+}
+
+// Validate validates this resource list
+func (m *ResourceList) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *ResourceList) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *ResourceList) UnmarshalBinary(b []byte) error {
+	var res ResourceList
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
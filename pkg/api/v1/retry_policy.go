@@ -0,0 +1,115 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// RetryPolicy retry policy
+// swagger:model RetryPolicy
+type RetryPolicy struct {
+
+	// time to wait before the first retry in milliseconds (1000 if not set), grows with every retry
+	// Minimum: 0
+	Backoff int64 `json:"backoff,omitempty"`
+
+	// how long after it was emitted an event is still retried in milliseconds, 0 for no limit
+	// Minimum: 0
+	MaxAge int64 `json:"maxAge,omitempty"`
+
+	// maximum number of attempts to run the function, 0 for a single attempt
+	// Minimum: 0
+	MaxAttempts int64 `json:"maxAttempts,omitempty"`
+}
+
+// Validate validates this retry policy
+func (m *RetryPolicy) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateBackoff(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateMaxAge(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateMaxAttempts(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *RetryPolicy) validateBackoff(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Backoff) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("backoff", "body", int64(m.Backoff), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *RetryPolicy) validateMaxAge(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MaxAge) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("maxAge", "body", int64(m.MaxAge), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *RetryPolicy) validateMaxAttempts(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.MaxAttempts) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("maxAttempts", "body", int64(m.MaxAttempts), 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *RetryPolicy) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *RetryPolicy) UnmarshalBinary(b []byte) error {
+	var res RetryPolicy
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
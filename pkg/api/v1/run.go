@@ -24,6 +24,10 @@ type Run struct {
 	// blocking
 	Blocking bool `json:"blocking,omitempty"`
 
+	// duration in milliseconds
+	// Read Only: true
+	Duration int64 `json:"duration,omitempty"`
+
 	// error
 	Error *InvocationError `json:"error,omitempty"`
 
@@ -59,6 +63,10 @@ type Run struct {
 	// input bytes
 	InputBytes strfmt.Base64 `json:"inputBytes,omitempty"`
 
+	// input size
+	// Read Only: true
+	InputSize int64 `json:"inputSize,omitempty"`
+
 	// logs
 	Logs *Logs `json:"logs,omitempty"`
 
@@ -73,6 +81,10 @@ type Run struct {
 	// output bytes
 	OutputBytes strfmt.Base64 `json:"outputBytes,omitempty"`
 
+	// output size
+	// Read Only: true
+	OutputSize int64 `json:"outputSize,omitempty"`
+
 	// reason
 	Reason []string `json:"reason,omitempty"`
 
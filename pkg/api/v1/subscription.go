@@ -25,12 +25,15 @@ type Subscription struct {
 	// Read Only: true
 	CreatedTime int64 `json:"createdTime,omitempty"`
 
-	// event type
+	// event type, * matches a single dot separated word and # matches zero or more words
 	// Required: true
 	// Max Length: 128
-	// Pattern: ^[\w\d\-\.]+$
+	// Pattern: ^[\w\d\-\.\*#]+$
 	EventType *string `json:"eventType"`
 
+	// expression evaluated against the event before the function is run, e.g. source == 'vcenter' && [data.vm.name] =~ '^web'
+	Filter string `json:"filter,omitempty"`
+
 	// function
 	// Required: true
 	// Pattern: ^[\w\d\-]+$
@@ -54,6 +57,9 @@ type Subscription struct {
 	// Pattern: ^[\w\d][\w\d\-]*$
 	Name *string `json:"name"`
 
+	// retry policy
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
 	// secrets
 	Secrets []string `json:"secrets"`
 
@@ -94,6 +100,11 @@ func (m *Subscription) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateRetryPolicy(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
 	if err := m.validateSecrets(formats); err != nil {
 		// prop
 		res = append(res, err)
@@ -124,7 +135,7 @@ func (m *Subscription) validateEventType(formats strfmt.Registry) error {
 		return err
 	}
 
-	if err := validate.Pattern("eventType", "body", string(*m.EventType), `^[\w\d\-\.]+$`); err != nil {
+	if err := validate.Pattern("eventType", "body", string(*m.EventType), `^[\w\d\-\.\*#]+$`); err != nil {
 		return err
 	}
 	return nil
@@ -179,6 +190,26 @@ func (m *Subscription) validateName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Subscription) validateRetryPolicy(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.RetryPolicy) { // not required
+		return nil
+	}
+
+	if m.RetryPolicy != nil {
+
+		if err := m.RetryPolicy.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("retryPolicy")
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Subscription) validateSecrets(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Secrets) { // not required
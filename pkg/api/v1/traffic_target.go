@@ -0,0 +1,90 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package v1
+
+import (
+	strfmt "github.com/go-openapi/strfmt"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// NO TESTS
+
+// TrafficTarget traffic target
+// swagger:model TrafficTarget
+type TrafficTarget struct {
+
+	// percent
+	// Maximum: 100
+	// Minimum: 0
+	Percent int64 `json:"percent"`
+
+	// revision
+	// Required: true
+	Revision *string `json:"revision"`
+}
+
+// Validate validates this traffic target
+func (m *TrafficTarget) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validatePercent(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if err := m.validateRevision(formats); err != nil {
+		// prop
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *TrafficTarget) validatePercent(formats strfmt.Registry) error {
+
+	if err := validate.MinimumInt("percent", "body", int64(m.Percent), 0, false); err != nil {
+		return err
+	}
+
+	if err := validate.MaximumInt("percent", "body", int64(m.Percent), 100, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *TrafficTarget) validateRevision(formats strfmt.Registry) error {
+
+	if err := validate.Required("revision", "body", m.Revision); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *TrafficTarget) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *TrafficTarget) UnmarshalBinary(b []byte) error {
+	var res TrafficTarget
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,51 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package applicationmanager
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// NO TESTS
+
+// Application is the application entity type, its members are the resources tagged with its name
+type Application struct {
+	entitystore.BaseEntity
+	Project string `json:"project"`
+}
+
+// ToModel converts an application entity into the API model
+func (a *Application) ToModel() *dapi.Application {
+	var tags []*dapi.Tag
+	for k, v := range a.Tags {
+		tags = append(tags, &dapi.Tag{Key: k, Value: v})
+	}
+	return &dapi.Application{
+		ID:           strfmt.UUID(a.ID),
+		Kind:         dapi.ApplicationKind,
+		Name:         swag.String(a.Name),
+		Status:       dapi.Status(a.Status),
+		CreatedTime:  a.CreatedTime.Unix(),
+		ModifiedTime: a.ModifiedTime.Unix(),
+		Tags:         tags,
+	}
+}
+
+// FromModel populates an application entity from the API model
+func (a *Application) FromModel(m *dapi.Application, orgID, project string) {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	a.BaseEntity.OrganizationID = orgID
+	a.BaseEntity.Name = *m.Name
+	a.BaseEntity.Tags = tags
+	a.Project = project
+}
@@ -0,0 +1,229 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package applicationmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations"
+	appapi "github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations/application"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// Handlers implements the application manager API. Applications only hold their name and tags, the resources
+// belonging to an application are tagged with Application=<name> by their own services.
+type Handlers struct {
+	store     entitystore.EntityStore
+	namespace string
+}
+
+// NewHandlers is the constructor for the application manager API handlers
+func NewHandlers(namespace string, store entitystore.EntityStore) *Handlers {
+	return &Handlers{
+		store:     store,
+		namespace: namespace,
+	}
+}
+
+// ConfigureHandlers registers the application manager handlers to the API
+func ConfigureHandlers(api middleware.RoutableAPI, h *Handlers) {
+	a, ok := api.(*operations.ApplicationManagerAPI)
+	if !ok {
+		panic("Cannot configure api")
+	}
+
+	a.Logger = log.Printf
+	a.ApplicationAddApplicationHandler = appapi.AddApplicationHandlerFunc(h.addApplication)
+	a.ApplicationGetApplicationsHandler = appapi.GetApplicationsHandlerFunc(h.getApplications)
+	a.ApplicationGetApplicationHandler = appapi.GetApplicationHandlerFunc(h.getApplication)
+	a.ApplicationUpdateApplicationHandler = appapi.UpdateApplicationHandlerFunc(h.updateApplication)
+	a.ApplicationDeleteApplicationHandler = appapi.DeleteApplicationHandlerFunc(h.deleteApplication)
+}
+
+func (h *Handlers) addApplication(params appapi.AddApplicationParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	model := params.Application
+	if model == nil {
+		return appapi.NewAddApplicationBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String("missing application"),
+		})
+	}
+	if err := model.Validate(strfmt.Default); err != nil {
+		return appapi.NewAddApplicationBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("error validating the application: %s", err)),
+		})
+	}
+
+	app := new(Application)
+	app.FromModel(model, h.namespace, *params.XDispatchProject)
+	app.Status = entitystore.StatusREADY
+	if _, err := h.store.Add(ctx, app); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return appapi.NewAddApplicationConflict().WithPayload(&dapi.Error{
+				Code:    http.StatusConflict,
+				Message: utils.ErrorMsgAlreadyExists("application", app.Name),
+			})
+		}
+		log.Errorf("%+v", errors.Wrapf(err, "storing application %s", app.Name))
+		return appapi.NewAddApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", app.Name),
+		})
+	}
+	return appapi.NewAddApplicationCreated().WithPayload(app.ToModel())
+}
+
+func (h *Handlers) getApplications(params appapi.GetApplicationsParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	filter, err := utils.ParseTags(projectFilter(*params.XDispatchProject), params.Tags)
+	if err != nil {
+		return appapi.NewGetApplicationsBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	var apps []*Application
+	if err := h.store.List(ctx, h.namespace, entitystore.Options{Filter: filter}, &apps); err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "listing applications"))
+		return appapi.NewGetApplicationsDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when getting applications"),
+		})
+	}
+	models := []*dapi.Application{}
+	for _, app := range apps {
+		models = append(models, app.ToModel())
+	}
+	return appapi.NewGetApplicationsOK().WithPayload(models)
+}
+
+func (h *Handlers) getApplication(params appapi.GetApplicationParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.ApplicationName
+	app, err := h.find(ctx, *params.XDispatchProject, name)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting application %s", name))
+		return appapi.NewGetApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", name),
+		})
+	}
+	if app == nil {
+		return appapi.NewGetApplicationNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("application", name),
+		})
+	}
+	return appapi.NewGetApplicationOK().WithPayload(app.ToModel())
+}
+
+func (h *Handlers) updateApplication(params appapi.UpdateApplicationParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.ApplicationName
+	model := params.Application
+	if model == nil || model.Name == nil || *model.Name != name {
+		return appapi.NewUpdateApplicationBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: utils.ErrorMsgBadRequest("application", name, errors.New("the application name does not match")),
+		})
+	}
+
+	app, err := h.find(ctx, *params.XDispatchProject, name)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting application %s", name))
+		return appapi.NewUpdateApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", name),
+		})
+	}
+	if app == nil {
+		return appapi.NewUpdateApplicationNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("application", name),
+		})
+	}
+
+	app.FromModel(model, h.namespace, app.Project)
+	if _, err := h.store.Update(ctx, app.Revision, app); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "updating application %s", name))
+		return appapi.NewUpdateApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", name),
+		})
+	}
+	return appapi.NewUpdateApplicationCreated().WithPayload(app.ToModel())
+}
+
+func (h *Handlers) deleteApplication(params appapi.DeleteApplicationParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.ApplicationName
+	app, err := h.find(ctx, *params.XDispatchProject, name)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting application %s", name))
+		return appapi.NewDeleteApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", name),
+		})
+	}
+	if app == nil {
+		return appapi.NewDeleteApplicationNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("application", name),
+		})
+	}
+
+	if err := h.store.Delete(ctx, h.namespace, name, app); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "deleting application %s", name))
+		return appapi.NewDeleteApplicationDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("application", name),
+		})
+	}
+	return appapi.NewDeleteApplicationNoContent()
+}
+
+// find gets an application of the project, it returns nil if there is none
+func (h *Handlers) find(ctx context.Context, project, name string) (*Application, error) {
+	app := new(Application)
+	found, err := h.store.Find(ctx, h.namespace, name, entitystore.Options{Filter: projectFilter(project)}, app)
+	if err != nil || !found {
+		return nil, err
+	}
+	return app, nil
+}
+
+func projectFilter(project string) entitystore.Filter {
+	return entitystore.FilterEverything().Add(entitystore.FilterStat{
+		Scope:   entitystore.FilterScopeExtra,
+		Subject: "Project",
+		Verb:    entitystore.FilterVerbEqual,
+		Object:  project,
+	})
+}
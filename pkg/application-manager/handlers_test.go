@@ -0,0 +1,124 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package applicationmanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations"
+	appapi "github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations/application"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+const (
+	testNamespace = "dispatch"
+	testProject   = "default"
+)
+
+func newTestAPI(t *testing.T) *operations.ApplicationManagerAPI {
+	api := operations.NewApplicationManagerAPI(nil)
+	ConfigureHandlers(api, NewHandlers(testNamespace, helpers.MakeEntityStore(t)))
+	return api
+}
+
+func addApplication(t *testing.T, api *operations.ApplicationManagerAPI, project, name string, status int) *dapi.Application {
+	r := httptest.NewRequest("POST", "/v1/application", nil)
+	responder := api.ApplicationAddApplicationHandler.Handle(appapi.AddApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(project),
+		Application:      &dapi.Application{Name: swag.String(name)},
+	})
+	var app dapi.Application
+	helpers.HandlerRequest(t, responder, &app, status)
+	return &app
+}
+
+func getApplication(t *testing.T, api *operations.ApplicationManagerAPI, name string, status int) *dapi.Application {
+	r := httptest.NewRequest("GET", "/v1/application/"+name, nil)
+	responder := api.ApplicationGetApplicationHandler.Handle(appapi.GetApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ApplicationName:  name,
+	})
+	var app dapi.Application
+	helpers.HandlerRequest(t, responder, &app, status)
+	return &app
+}
+
+func TestApplicationLifecycle(t *testing.T) {
+	api := newTestAPI(t)
+
+	created := addApplication(t, api, testProject, "shop", http.StatusCreated)
+	assert.Equal(t, "shop", *created.Name)
+	assert.Equal(t, dapi.StatusREADY, created.Status)
+	assert.Equal(t, dapi.ApplicationKind, created.Kind)
+	addApplication(t, api, testProject, "shop", http.StatusConflict)
+	addApplication(t, api, "other", "blog", http.StatusCreated)
+
+	r := httptest.NewRequest("GET", "/v1/application", nil)
+	responder := api.ApplicationGetApplicationsHandler.Handle(appapi.GetApplicationsParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+	})
+	var apps []dapi.Application
+	helpers.HandlerRequest(t, responder, &apps, http.StatusOK)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "shop", *apps[0].Name)
+
+	r = httptest.NewRequest("PUT", "/v1/application/shop", nil)
+	responder = api.ApplicationUpdateApplicationHandler.Handle(appapi.UpdateApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ApplicationName:  "shop",
+		Application: &dapi.Application{
+			Name: swag.String("shop"),
+			Tags: []*dapi.Tag{{Key: "team", Value: "web"}},
+		},
+	})
+	helpers.HandlerRequest(t, responder, &dapi.Application{}, http.StatusCreated)
+	assert.Equal(t, []*dapi.Tag{{Key: "team", Value: "web"}}, getApplication(t, api, "shop", http.StatusOK).Tags)
+
+	r = httptest.NewRequest("DELETE", "/v1/application/shop", nil)
+	responder = api.ApplicationDeleteApplicationHandler.Handle(appapi.DeleteApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ApplicationName:  "shop",
+	})
+	helpers.HandlerRequest(t, responder, nil, http.StatusNoContent)
+	getApplication(t, api, "shop", http.StatusNotFound)
+}
+
+func TestApplicationErrors(t *testing.T) {
+	api := newTestAPI(t)
+
+	addApplication(t, api, testProject, "not valid", http.StatusBadRequest)
+	getApplication(t, api, "blog", http.StatusNotFound)
+
+	addApplication(t, api, testProject, "shop", http.StatusCreated)
+	r := httptest.NewRequest("PUT", "/v1/application/shop", nil)
+	responder := api.ApplicationUpdateApplicationHandler.Handle(appapi.UpdateApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ApplicationName:  "shop",
+		Application:      &dapi.Application{Name: swag.String("blog")},
+	})
+	helpers.HandlerRequest(t, responder, &dapi.Error{}, http.StatusBadRequest)
+
+	r = httptest.NewRequest("DELETE", "/v1/application/blog", nil)
+	responder = api.ApplicationDeleteApplicationHandler.Handle(appapi.DeleteApplicationParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ApplicationName:  "blog",
+	})
+	helpers.HandlerRequest(t, responder, &dapi.Error{}, http.StatusNotFound)
+}
@@ -105,7 +105,15 @@ func (h *defaultHandlers) getBaseImage(params baseimage.GetBaseImageByNameParams
 	org := h.namespace
 	project := *params.XDispatchProject
 	log.Debugf("getting baseimage %s in %s:%s", name, org, project)
-	img, err := h.backend.GetBaseImage(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+	var img *dapi.BaseImage
+	err := utils.WaitForStatus(ctx, params.WaitFor, params.WaitTimeout, func() (dapi.Status, error) {
+		var err error
+		img, err = h.backend.GetBaseImage(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+		if err != nil {
+			return "", err
+		}
+		return img.Status, nil
+	})
 	if err != nil {
 		if derrors.IsObjectNotFound(err) {
 			log.Debugf("baseimage %s in %s:%s not found", name, org, project)
@@ -0,0 +1,229 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	swaggerclient "github.com/vmware/dispatch/pkg/application-manager/gen/client"
+	applicationclient "github.com/vmware/dispatch/pkg/application-manager/gen/client/application"
+)
+
+// ApplicationsClient defines the applications client interface
+type ApplicationsClient interface {
+	CreateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error)
+	DeleteApplication(ctx context.Context, organizationID string, applicationName string) error
+	UpdateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error)
+	GetApplication(ctx context.Context, organizationID string, applicationName string) (*v1.Application, error)
+	ListApplications(ctx context.Context, organizationID string) ([]v1.Application, error)
+}
+
+// NewApplicationsClient is used to create a new applications client
+func NewApplicationsClient(host string, auth runtime.ClientAuthInfoWriter, organizationID, project string) ApplicationsClient {
+	transport := DefaultHTTPClient(host, swaggerclient.DefaultBasePath)
+	return &DefaultApplicationsClient{
+		baseClient: baseClient{
+			organizationID: organizationID,
+			projectName:    project,
+		},
+		client: swaggerclient.New(transport, strfmt.Default),
+		auth:   auth,
+	}
+}
+
+// DefaultApplicationsClient defines the default applications client
+type DefaultApplicationsClient struct {
+	baseClient
+
+	client *swaggerclient.ApplicationManager
+	auth   runtime.ClientAuthInfoWriter
+}
+
+// CreateApplication creates an application
+func (c *DefaultApplicationsClient) CreateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error) {
+	params := applicationclient.AddApplicationParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		Application:      application,
+	}
+	response, err := c.client.Application.AddApplication(&params)
+	if err != nil {
+		return nil, createApplicationSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func createApplicationSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *applicationclient.AddApplicationBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *applicationclient.AddApplicationUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *applicationclient.AddApplicationForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *applicationclient.AddApplicationConflict:
+		return NewErrorAlreadyExists(v.Payload)
+	case *applicationclient.AddApplicationDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// DeleteApplication deletes an application
+func (c *DefaultApplicationsClient) DeleteApplication(ctx context.Context, organizationID string, applicationName string) error {
+	params := applicationclient.DeleteApplicationParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		ApplicationName:  applicationName,
+	}
+	_, err := c.client.Application.DeleteApplication(&params)
+	if err != nil {
+		return deleteApplicationSwaggerError(err)
+	}
+	return nil
+}
+
+func deleteApplicationSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *applicationclient.DeleteApplicationBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *applicationclient.DeleteApplicationUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *applicationclient.DeleteApplicationForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *applicationclient.DeleteApplicationNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *applicationclient.DeleteApplicationDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// UpdateApplication updates an application
+func (c *DefaultApplicationsClient) UpdateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error) {
+	params := applicationclient.UpdateApplicationParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		Application:      application,
+		ApplicationName:  *application.Name,
+	}
+	response, err := c.client.Application.UpdateApplication(&params)
+	if err != nil {
+		return nil, updateApplicationSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func updateApplicationSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *applicationclient.UpdateApplicationBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *applicationclient.UpdateApplicationUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *applicationclient.UpdateApplicationForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *applicationclient.UpdateApplicationNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *applicationclient.UpdateApplicationDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// GetApplication retrieves an application
+func (c *DefaultApplicationsClient) GetApplication(ctx context.Context, organizationID string, applicationName string) (*v1.Application, error) {
+	params := applicationclient.GetApplicationParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		ApplicationName:  applicationName,
+	}
+	response, err := c.client.Application.GetApplication(&params)
+	if err != nil {
+		return nil, getApplicationSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func getApplicationSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *applicationclient.GetApplicationBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *applicationclient.GetApplicationUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *applicationclient.GetApplicationForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *applicationclient.GetApplicationNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *applicationclient.GetApplicationDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ListApplications lists applications
+func (c *DefaultApplicationsClient) ListApplications(ctx context.Context, organizationID string) ([]v1.Application, error) {
+	params := applicationclient.GetApplicationsParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+	}
+	response, err := c.client.Application.GetApplications(&params)
+	if err != nil {
+		return nil, listApplicationsSwaggerError(err)
+	}
+	applications := []v1.Application{}
+	for _, application := range response.Payload {
+		applications = append(applications, *application)
+	}
+	return applications, nil
+}
+
+func listApplicationsSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *applicationclient.GetApplicationsUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *applicationclient.GetApplicationsForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *applicationclient.GetApplicationsDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
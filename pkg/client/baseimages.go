@@ -8,6 +8,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
@@ -23,6 +24,7 @@ type BaseImagesClient interface {
 	DeleteBaseImage(ctx context.Context, organizationID string, baseImageName string) (*v1.BaseImage, error)
 	UpdateBaseImage(ctx context.Context, organizationID string, baseImage *v1.BaseImage) (*v1.BaseImage, error)
 	GetBaseImage(ctx context.Context, organizationID string, baseImageName string) (*v1.BaseImage, error)
+	WaitBaseImage(ctx context.Context, organizationID string, baseImageName string, status v1.Status, timeout time.Duration) (*v1.BaseImage, error)
 	ListBaseImages(ctx context.Context, organizationID string) ([]v1.BaseImage, error)
 }
 
@@ -166,6 +168,27 @@ func (c *DefaultBaseImagesClient) GetBaseImage(ctx context.Context, organization
 	return response.Payload, nil
 }
 
+// WaitBaseImage gets a base image once it reaches the status or ERROR, or the timeout expires
+func (c *DefaultBaseImagesClient) WaitBaseImage(ctx context.Context, organizationID string, baseImageName string, status v1.Status, timeout time.Duration) (*v1.BaseImage, error) {
+	var baseImage *v1.BaseImage
+	err := waitForStatus(ctx, status, timeout, func(waitFor *string, waitTimeout *int64) (v1.Status, error) {
+		params := baseimageclient.GetBaseImageByNameParams{
+			Context:       ctx,
+			BaseImageName: baseImageName,
+			XDispatchOrg:  swag.String(c.getOrgID(organizationID)),
+			WaitFor:       waitFor,
+			WaitTimeout:   waitTimeout,
+		}
+		response, err := c.client.BaseImage.GetBaseImageByName(&params, c.auth)
+		if err != nil {
+			return "", getBaseImageSwaggerError(err)
+		}
+		baseImage = response.Payload
+		return baseImage.Status, nil
+	})
+	return baseImage, err
+}
+
 func getBaseImageSwaggerError(err error) error {
 	if err == nil {
 		return nil
@@ -7,6 +7,7 @@ package client
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strings"
 
 	"github.com/go-openapi/runtime"
@@ -54,6 +55,32 @@ func DefaultHTTPClient(host, basePath string) *swaggerclient.Runtime {
 	return transport
 }
 
+// HandlerHTTPClient creates a transport serving the requests of clients with handler, in process.  It is used by the
+// services of dispatch-server to call each other without a network round trip or credentials.
+func HandlerHTTPClient(handler http.Handler, basePath string) *swaggerclient.Runtime {
+	transport := swaggerclient.New("localhost", basePath, []string{"http"})
+	transport.Transport = NewTracingRoundTripper(&handlerRoundTripper{handler: handler})
+	return transport
+}
+
+// handlerRoundTripper serves requests with a handler instead of sending them
+type handlerRoundTripper struct {
+	handler http.Handler
+}
+
+func (t *handlerRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	served := r.WithContext(r.Context())
+	served.RequestURI = r.URL.RequestURI()
+	if served.Body == nil {
+		served.Body = http.NoBody
+	}
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, served)
+	resp := recorder.Result()
+	resp.Request = r
+	return resp, nil
+}
+
 // baseClient represents fields & methods common for all Dispatch services.
 type baseClient struct {
 	organizationID string
@@ -0,0 +1,226 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	swaggerclient "github.com/vmware/dispatch/pkg/configs/gen/client"
+	configclient "github.com/vmware/dispatch/pkg/configs/gen/client/config"
+)
+
+// ConfigsClient defines the configs client interface
+type ConfigsClient interface {
+	CreateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error)
+	DeleteConfig(ctx context.Context, organizationID string, configName string) error
+	UpdateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error)
+	GetConfig(ctx context.Context, organizationID string, configName string) (*v1.Configuration, error)
+	ListConfigs(ctx context.Context, organizationID string) ([]v1.Configuration, error)
+}
+
+// NewConfigsClient is used to create a new configs client
+func NewConfigsClient(host string, auth runtime.ClientAuthInfoWriter, organizationID, project string) ConfigsClient {
+	transport := DefaultHTTPClient(host, swaggerclient.DefaultBasePath)
+	return &DefaultConfigsClient{
+		baseClient: baseClient{
+			organizationID: organizationID,
+			projectName:    project,
+		},
+		client: swaggerclient.New(transport, strfmt.Default),
+		auth:   auth,
+	}
+}
+
+// DefaultConfigsClient defines the default configs client
+type DefaultConfigsClient struct {
+	baseClient
+
+	client *swaggerclient.Configs
+	auth   runtime.ClientAuthInfoWriter
+}
+
+// CreateConfig creates a config
+func (c *DefaultConfigsClient) CreateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error) {
+	params := configclient.AddConfigParams{
+		Context:      ctx,
+		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+		Config:       config,
+	}
+	response, err := c.client.Config.AddConfig(&params)
+	if err != nil {
+		return nil, createConfigSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func createConfigSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *configclient.AddConfigBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *configclient.AddConfigUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *configclient.AddConfigForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *configclient.AddConfigConflict:
+		return NewErrorAlreadyExists(v.Payload)
+	case *configclient.AddConfigDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// DeleteConfig deletes a config
+func (c *DefaultConfigsClient) DeleteConfig(ctx context.Context, organizationID string, configName string) error {
+	params := configclient.DeleteConfigParams{
+		Context:      ctx,
+		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+		ConfigName:   configName,
+	}
+	_, err := c.client.Config.DeleteConfig(&params)
+	if err != nil {
+		return deleteConfigSwaggerError(err)
+	}
+	return nil
+}
+
+func deleteConfigSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *configclient.DeleteConfigBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *configclient.DeleteConfigUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *configclient.DeleteConfigForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *configclient.DeleteConfigNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *configclient.DeleteConfigConflict:
+		return NewErrorAlreadyExists(v.Payload)
+	case *configclient.DeleteConfigDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// UpdateConfig updates a config
+func (c *DefaultConfigsClient) UpdateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error) {
+	params := configclient.UpdateConfigParams{
+		Context:      ctx,
+		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+		Config:       config,
+		ConfigName:   config.Name,
+	}
+	response, err := c.client.Config.UpdateConfig(&params)
+	if err != nil {
+		return nil, updateConfigSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func updateConfigSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *configclient.UpdateConfigBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *configclient.UpdateConfigUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *configclient.UpdateConfigForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *configclient.UpdateConfigNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *configclient.UpdateConfigDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// GetConfig retrieves a config
+func (c *DefaultConfigsClient) GetConfig(ctx context.Context, organizationID string, configName string) (*v1.Configuration, error) {
+	params := configclient.GetConfigParams{
+		Context:      ctx,
+		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+		ConfigName:   configName,
+	}
+	response, err := c.client.Config.GetConfig(&params)
+	if err != nil {
+		return nil, getConfigSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func getConfigSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *configclient.GetConfigBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *configclient.GetConfigUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *configclient.GetConfigForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *configclient.GetConfigNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *configclient.GetConfigDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ListConfigs lists configs
+func (c *DefaultConfigsClient) ListConfigs(ctx context.Context, organizationID string) ([]v1.Configuration, error) {
+	params := configclient.GetConfigsParams{
+		Context:      ctx,
+		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+	}
+	response, err := c.client.Config.GetConfigs(&params)
+	if err != nil {
+		return nil, listConfigsSwaggerError(err)
+	}
+	configs := []v1.Configuration{}
+	for _, config := range response.Payload {
+		configs = append(configs, *config)
+	}
+	return configs, nil
+}
+
+func listConfigsSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *configclient.GetConfigsUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *configclient.GetConfigsForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *configclient.GetConfigsDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
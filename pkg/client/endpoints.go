@@ -145,6 +145,8 @@ func updateEndpointSwaggerError(err error) error {
 		return NewErrorForbidden(v.Payload)
 	case *endpoint.UpdateEndpointNotFound:
 		return NewErrorNotFound(v.Payload)
+	case *endpoint.UpdateEndpointConflict:
+		return NewErrorAlreadyExists(v.Payload)
 	case *endpoint.UpdateEndpointDefault:
 		return NewErrorServerUnknownError(v.Payload)
 	default:
@@ -7,6 +7,8 @@ package client
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 )
@@ -50,6 +52,8 @@ func NewErrorServerUnknownError(apiError *v1.Error) *ErrorServerUnknownError {
 // ErrorBadRequest represents client-side request error
 type ErrorBadRequest struct {
 	baseError
+	// Run is the rejected run, with the field errors of its input, if the server returned it
+	Run *v1.Run
 }
 
 // NewErrorBadRequest creates new instance of ErrorBadRequest based on Error Model
@@ -122,6 +126,8 @@ func NewErrorInvalidInput(apiError *v1.Error) *ErrorInvalidInput {
 // ErrorFunctionError represents error that happened when executing the function
 type ErrorFunctionError struct {
 	baseError
+	// Run is the failed run, with the logs of the function, if the server returned it
+	Run *v1.Run
 }
 
 // NewErrorFunctionError creates new instance of ErrorFunctionError based on Error Model
@@ -131,6 +137,33 @@ func NewErrorFunctionError(apiError *v1.Error) *ErrorFunctionError {
 	}
 }
 
+// NewErrorRunBadRequest creates new instance of ErrorBadRequest based on a rejected Run
+func NewErrorRunBadRequest(run *v1.Run) *ErrorBadRequest {
+	message := "run was rejected"
+	if run.Error != nil && run.Error.Message != nil {
+		message = *run.Error.Message
+		if len(run.Error.Errors) > 0 {
+			message = fmt.Sprintf("%s: %s", message, strings.Join(run.Error.Errors, ", "))
+		}
+	}
+	return &ErrorBadRequest{
+		baseError: baseError{code: http.StatusBadRequest, message: message},
+		Run:       run,
+	}
+}
+
+// NewErrorFunctionRunError creates new instance of ErrorFunctionError based on a failed Run
+func NewErrorFunctionRunError(run *v1.Run) *ErrorFunctionError {
+	message := "function error"
+	if run.Error != nil && run.Error.Message != nil {
+		message = *run.Error.Message
+	}
+	return &ErrorFunctionError{
+		baseError: baseError{code: http.StatusBadGateway, message: message},
+		Run:       run,
+	}
+}
+
 func baseErrFromModel(apiError *v1.Error) baseError {
 	message := ""
 	if apiError.Message != nil {
@@ -8,11 +8,13 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	swaggerclient "github.com/vmware/dispatch/pkg/event-manager/gen/client"
+	"github.com/vmware/dispatch/pkg/event-manager/gen/client/deadletters"
 	"github.com/vmware/dispatch/pkg/event-manager/gen/client/drivers"
 	"github.com/vmware/dispatch/pkg/event-manager/gen/client/events"
 	"github.com/vmware/dispatch/pkg/event-manager/gen/client/subscriptions"
@@ -30,10 +32,16 @@ type EventsClient interface {
 	ListSubscriptions(ctx context.Context, organizationID string) ([]v1.Subscription, error)
 	UpdateSubscription(ctx context.Context, organizationID string, subscription *v1.Subscription) (*v1.Subscription, error)
 
+	// Dead Letters
+	GetDeadLetter(ctx context.Context, organizationID string, deadLetterName string) (*v1.DeadLetter, error)
+	ListDeadLetters(ctx context.Context, organizationID string) ([]v1.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, organizationID string, deadLetterName string) (*v1.DeadLetter, error)
+
 	// Event Drivers
 	CreateEventDriver(ctx context.Context, organizationID string, eventDriver *v1.EventDriver) (*v1.EventDriver, error)
 	DeleteEventDriver(ctx context.Context, organizationID string, eventDriverName string) (*v1.EventDriver, error)
 	GetEventDriver(ctx context.Context, organizationID string, eventDriverName string) (*v1.EventDriver, error)
+	WaitEventDriver(ctx context.Context, organizationID string, eventDriverName string, status v1.Status, timeout time.Duration) (*v1.EventDriver, error)
 	ListEventDrivers(ctx context.Context, organizationID string) ([]v1.EventDriver, error)
 	UpdateEventDriver(ctx context.Context, organizationID string, eventDriver *v1.EventDriver) (*v1.EventDriver, error)
 
@@ -273,6 +281,112 @@ func updateSubscriptionSwaggerError(err error) error {
 	}
 }
 
+// GetDeadLetter gets a dead letter by name
+func (c *DefaultEventsClient) GetDeadLetter(ctx context.Context, organizationID string, deadLetterName string) (*v1.DeadLetter, error) {
+	params := deadletters.GetDeadLetterParams{
+		Context:        ctx,
+		DeadLetterName: deadLetterName,
+		XDispatchOrg:   c.getOrgID(organizationID),
+	}
+	response, err := c.client.Deadletters.GetDeadLetter(&params, c.auth)
+	if err != nil {
+		return nil, getDeadLetterSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func getDeadLetterSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *deadletters.GetDeadLetterBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *deadletters.GetDeadLetterUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *deadletters.GetDeadLetterForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *deadletters.GetDeadLetterNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *deadletters.GetDeadLetterDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ListDeadLetters lists all dead letters
+func (c *DefaultEventsClient) ListDeadLetters(ctx context.Context, organizationID string) ([]v1.DeadLetter, error) {
+	params := deadletters.GetDeadLettersParams{
+		Context:      ctx,
+		XDispatchOrg: c.getOrgID(organizationID),
+	}
+	response, err := c.client.Deadletters.GetDeadLetters(&params, c.auth)
+	if err != nil {
+		return nil, listDeadLettersSwaggerError(err)
+	}
+	deadLetters := []v1.DeadLetter{}
+	for _, d := range response.Payload {
+		deadLetters = append(deadLetters, *d)
+	}
+	return deadLetters, nil
+}
+
+func listDeadLettersSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *deadletters.GetDeadLettersBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *deadletters.GetDeadLettersUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *deadletters.GetDeadLettersForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *deadletters.GetDeadLettersDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ReplayDeadLetter publishes the event of a dead letter again
+func (c *DefaultEventsClient) ReplayDeadLetter(ctx context.Context, organizationID string, deadLetterName string) (*v1.DeadLetter, error) {
+	params := deadletters.ReplayDeadLetterParams{
+		Context:        ctx,
+		DeadLetterName: deadLetterName,
+		XDispatchOrg:   c.getOrgID(organizationID),
+	}
+	response, err := c.client.Deadletters.ReplayDeadLetter(&params, c.auth)
+	if err != nil {
+		return nil, replayDeadLetterSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func replayDeadLetterSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *deadletters.ReplayDeadLetterBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *deadletters.ReplayDeadLetterUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *deadletters.ReplayDeadLetterForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *deadletters.ReplayDeadLetterNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *deadletters.ReplayDeadLetterDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
 // CreateEventDriver creates and adds a new event driver
 func (c *DefaultEventsClient) CreateEventDriver(ctx context.Context, organizationID string, driver *v1.EventDriver) (*v1.EventDriver, error) {
 	params := drivers.AddDriverParams{
@@ -357,6 +471,27 @@ func (c *DefaultEventsClient) GetEventDriver(ctx context.Context, organizationID
 	return response.Payload, nil
 }
 
+// WaitEventDriver gets an event driver once it reaches the status or ERROR, or the timeout expires
+func (c *DefaultEventsClient) WaitEventDriver(ctx context.Context, organizationID string, driverName string, status v1.Status, timeout time.Duration) (*v1.EventDriver, error) {
+	var driver *v1.EventDriver
+	err := waitForStatus(ctx, status, timeout, func(waitFor *string, waitTimeout *int64) (v1.Status, error) {
+		params := drivers.GetDriverParams{
+			Context:      ctx,
+			DriverName:   driverName,
+			XDispatchOrg: c.getOrgID(organizationID),
+			WaitFor:      waitFor,
+			WaitTimeout:  waitTimeout,
+		}
+		response, err := c.client.Drivers.GetDriver(&params, c.auth)
+		if err != nil {
+			return "", getDriverSwaggerError(err)
+		}
+		driver = response.Payload
+		return driver.Status, nil
+	})
+	return driver, err
+}
+
 func getDriverSwaggerError(err error) error {
 	if err == nil {
 		return nil
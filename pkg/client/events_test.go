@@ -36,3 +36,22 @@ func TestCreateSubscription(t *testing.T) {
 	assert.Equal(t, subscriptionResponse, subscriptionBody)
 
 }
+
+func TestReplayDeadLetter(t *testing.T) {
+	fakeServer := fakeserver.NewFakeServer(nil)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	eclient := client.NewEventsClient(server.URL, nil, testOrgID)
+
+	deadLetterResponse, err := eclient.ReplayDeadLetter(context.Background(), testOrgID, "dl1")
+	assert.Error(t, err)
+	assert.Nil(t, deadLetterResponse)
+
+	deadLetterBody := &v1.DeadLetter{Name: "dl1", Subscription: "sub1"}
+	deadLetterMap := toMap(t, deadLetterBody)
+	fakeServer.AddResponse("POST", "/v1/event/deadletters/dl1/replay", nil, deadLetterMap, 200)
+	deadLetterResponse, err = eclient.ReplayDeadLetter(context.Background(), testOrgID, "dl1")
+	assert.NoError(t, err)
+	assert.Equal(t, deadLetterBody, deadLetterResponse)
+}
@@ -32,6 +32,7 @@ type FunctionsClient interface {
 	CreateFunction(ctx context.Context, organizationID string, function *v1.Function) (*v1.Function, error)
 	DeleteFunction(ctx context.Context, organizationID string, functionName string) (*v1.Function, error)
 	GetFunction(ctx context.Context, organizationID string, functionName string) (*v1.Function, error)
+	WaitFunction(ctx context.Context, organizationID string, functionName string, status v1.Status, timeout time.Duration) (*v1.Function, error)
 	ListFunctions(ctx context.Context, organizationID string) ([]v1.Function, error)
 	UpdateFunction(ctx context.Context, organizationID string, function *v1.Function) (*v1.Function, error)
 }
@@ -40,7 +41,9 @@ type FunctionsClient interface {
 type FunctionOpts struct {
 	FunctionName *string
 	RunName      *string
+	Status       *string
 	Since        time.Time
+	Before       time.Time
 }
 
 // DefaultFunctionsClient defines the default functions client
@@ -53,7 +56,11 @@ type DefaultFunctionsClient struct {
 
 // NewFunctionsClient is used to create a new functions client
 func NewFunctionsClient(host string, auth runtime.ClientAuthInfoWriter, organizationID, project string) *DefaultFunctionsClient {
-	transport := DefaultHTTPClient(host, swaggerclient.DefaultBasePath)
+	return NewFunctionsClientWithTransport(DefaultHTTPClient(host, swaggerclient.DefaultBasePath), auth, organizationID, project)
+}
+
+// NewFunctionsClientWithTransport creates a functions client sending its requests through transport
+func NewFunctionsClientWithTransport(transport runtime.ClientTransport, auth runtime.ClientAuthInfoWriter, organizationID, project string) *DefaultFunctionsClient {
 	return &DefaultFunctionsClient{
 		baseClient: baseClient{
 			organizationID: organizationID,
@@ -74,6 +81,9 @@ func (c *DefaultFunctionsClient) RunFunction(ctx context.Context, organizationID
 		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
 		Body:         run,
 	}
+	if c.projectName != "" {
+		params.XDispatchProject = swag.String(c.projectName)
+	}
 	ok, accepted, err := c.client.Runner.RunFunction(&params)
 	if err != nil {
 		return nil, runSwaggerError(err)
@@ -93,7 +103,7 @@ func runSwaggerError(err error) error {
 	}
 	switch v := err.(type) {
 	case *runner.RunFunctionBadRequest:
-		return NewErrorBadRequest(v.Payload)
+		return NewErrorRunBadRequest(v.Payload)
 	case *runner.RunFunctionUnauthorized:
 		return NewErrorUnauthorized(v.Payload)
 	case *runner.RunFunctionForbidden:
@@ -103,7 +113,7 @@ func runSwaggerError(err error) error {
 	case *runner.RunFunctionUnprocessableEntity:
 		return NewErrorInvalidInput(v.Payload)
 	case *runner.RunFunctionBadGateway:
-		return NewErrorFunctionError(v.Payload)
+		return NewErrorFunctionRunError(v.Payload)
 	case *runner.RunFunctionDefault:
 		return NewErrorServerUnknownError(v.Payload)
 	default:
@@ -157,8 +167,12 @@ func (c *DefaultFunctionsClient) ListRuns(ctx context.Context, organizationID st
 		Context:      ctx,
 		XDispatchOrg: swag.String(c.getOrgID(organizationID)),
 		FunctionName: opts.FunctionName,
+		Status:       opts.Status,
 		Since:        &s,
 	}
+	if !opts.Before.IsZero() {
+		params.Before = swag.Int64(opts.Before.Unix())
+	}
 	response, err := c.client.Runner.GetRuns(&params)
 	if err != nil {
 		return nil, listRunsSwaggerError(err)
@@ -295,6 +309,27 @@ func getFunctionSwaggerError(err error) error {
 	}
 }
 
+// WaitFunction gets a function once it reaches the status or ERROR, or the timeout expires
+func (c *DefaultFunctionsClient) WaitFunction(ctx context.Context, organizationID string, functionName string, status v1.Status, timeout time.Duration) (*v1.Function, error) {
+	var function *v1.Function
+	err := waitForStatus(ctx, status, timeout, func(waitFor *string, waitTimeout *int64) (v1.Status, error) {
+		params := store.GetFunctionParams{
+			Context:      ctx,
+			XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+			FunctionName: functionName,
+			WaitFor:      waitFor,
+			WaitTimeout:  waitTimeout,
+		}
+		response, err := c.client.Store.GetFunction(&params)
+		if err != nil {
+			return "", getFunctionSwaggerError(err)
+		}
+		function = response.Payload
+		return function.Status, nil
+	})
+	return function, err
+}
+
 // ListFunctions lists all functions
 func (c *DefaultFunctionsClient) ListFunctions(ctx context.Context, organizationID string) ([]v1.Function, error) {
 	params := store.GetFunctionsParams{
@@ -7,10 +7,15 @@ package client_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/swag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/testing/fakeserver"
@@ -36,3 +41,63 @@ func TestCreateFunction(t *testing.T) {
 	assert.Equal(t, functionResponse, functionBody)
 
 }
+
+func TestFunctionsClientWithHandler(t *testing.T) {
+	fakeServer := fakeserver.NewFakeServer(nil)
+	fclient := client.NewFunctionsClientWithTransport(client.HandlerHTTPClient(fakeServer, "/v1"), nil, testOrgID, "")
+
+	functionBody := &v1.Function{Meta: v1.Meta{Name: "hello"}}
+	functionMap := toMap(t, functionBody)
+	fakeServer.AddResponse("POST", "/v1/function", functionMap, functionMap, 201)
+	functionResponse, err := fclient.CreateFunction(context.Background(), testOrgID, functionBody)
+	assert.NoError(t, err)
+	assert.Equal(t, functionBody, functionResponse)
+}
+
+func TestWaitFunction(t *testing.T) {
+	var query []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = append(query, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		// The server holds the request until the function fails
+		json.NewEncoder(w).Encode(&v1.Function{
+			Meta:   v1.Meta{Name: "hello"},
+			Status: v1.StatusERROR,
+			Reason: []string{"image not found"},
+		})
+	}))
+	defer server.Close()
+
+	fclient := client.NewFunctionsClient(server.URL, nil, testOrgID, "")
+	function, err := fclient.WaitFunction(context.Background(), testOrgID, "hello", v1.StatusREADY, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, v1.StatusERROR, function.Status)
+	assert.Equal(t, []string{"image not found"}, function.Reason)
+	assert.Equal(t, []string{"waitFor=READY&waitTimeout=60"}, query)
+}
+
+func TestRunFunctionRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(&v1.Run{
+			Name:   "a2f0a8f4-7a1e-4c5b-9a51-3b1c4c7e9d10",
+			Status: v1.StatusERROR,
+			Error: &v1.InvocationError{
+				Type:    v1.ErrorTypeInputError,
+				Message: swag.String("input does not match the schema of function hello"),
+				Errors:  []string{"input.name in body is required"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	fclient := client.NewFunctionsClient(server.URL, nil, testOrgID, "")
+	_, err := fclient.RunFunction(context.Background(), testOrgID, &v1.Run{FunctionName: "hello"})
+	rejected, ok := err.(*client.ErrorBadRequest)
+	require.True(t, ok, "%T", err)
+	assert.Equal(t, http.StatusBadRequest, rejected.Code())
+	assert.Contains(t, rejected.Message(), "input.name in body is required")
+	require.NotNil(t, rejected.Run)
+	assert.Equal(t, []string{"input.name in body is required"}, rejected.Run.Error.Errors)
+}
@@ -8,6 +8,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
@@ -23,6 +24,7 @@ type ImagesClient interface {
 	DeleteImage(ctx context.Context, organizationID string, imageName string) (*v1.Image, error)
 	UpdateImage(ctx context.Context, organizationID string, image *v1.Image) (*v1.Image, error)
 	GetImage(ctx context.Context, organizationID string, imageName string) (*v1.Image, error)
+	WaitImage(ctx context.Context, organizationID string, imageName string, status v1.Status, timeout time.Duration) (*v1.Image, error)
 	ListImages(ctx context.Context, organizationID string) ([]v1.Image, error)
 }
 
@@ -166,6 +168,27 @@ func (c *DefaultImagesClient) GetImage(ctx context.Context, organizationID strin
 	return response.Payload, nil
 }
 
+// WaitImage gets an image once it reaches the status or ERROR, or the timeout expires
+func (c *DefaultImagesClient) WaitImage(ctx context.Context, organizationID string, imageName string, status v1.Status, timeout time.Duration) (*v1.Image, error) {
+	var image *v1.Image
+	err := waitForStatus(ctx, status, timeout, func(waitFor *string, waitTimeout *int64) (v1.Status, error) {
+		params := imageclient.GetImageByNameParams{
+			Context:      ctx,
+			ImageName:    imageName,
+			XDispatchOrg: swag.String(c.getOrgID(organizationID)),
+			WaitFor:      waitFor,
+			WaitTimeout:  waitTimeout,
+		}
+		response, err := c.client.Image.GetImageByName(&params, c.auth)
+		if err != nil {
+			return "", getImageSwaggerError(err)
+		}
+		image = response.Payload
+		return image.Status, nil
+	})
+	return image, err
+}
+
 func getImageSwaggerError(err error) error {
 	if err == nil {
 		return nil
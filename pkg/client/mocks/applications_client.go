@@ -0,0 +1,118 @@
+// Code generated by mockery v1.0.0
+
+package mocks
+
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+import v1 "github.com/vmware/dispatch/pkg/api/v1"
+
+// ApplicationsClient is an autogenerated mock type for the ApplicationsClient type
+type ApplicationsClient struct {
+	mock.Mock
+}
+
+// CreateApplication provides a mock function with given fields: ctx, organizationID, application
+func (_m *ApplicationsClient) CreateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error) {
+	ret := _m.Called(ctx, organizationID, application)
+
+	var r0 *v1.Application
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.Application) *v1.Application); ok {
+		r0 = rf(ctx, organizationID, application)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Application)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.Application) error); ok {
+		r1 = rf(ctx, organizationID, application)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteApplication provides a mock function with given fields: ctx, organizationID, applicationName
+func (_m *ApplicationsClient) DeleteApplication(ctx context.Context, organizationID string, applicationName string) error {
+	ret := _m.Called(ctx, organizationID, applicationName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, organizationID, applicationName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetApplication provides a mock function with given fields: ctx, organizationID, applicationName
+func (_m *ApplicationsClient) GetApplication(ctx context.Context, organizationID string, applicationName string) (*v1.Application, error) {
+	ret := _m.Called(ctx, organizationID, applicationName)
+
+	var r0 *v1.Application
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.Application); ok {
+		r0 = rf(ctx, organizationID, applicationName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Application)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, applicationName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListApplications provides a mock function with given fields: ctx, organizationID
+func (_m *ApplicationsClient) ListApplications(ctx context.Context, organizationID string) ([]v1.Application, error) {
+	ret := _m.Called(ctx, organizationID)
+
+	var r0 []v1.Application
+	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.Application); ok {
+		r0 = rf(ctx, organizationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.Application)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateApplication provides a mock function with given fields: ctx, organizationID, application
+func (_m *ApplicationsClient) UpdateApplication(ctx context.Context, organizationID string, application *v1.Application) (*v1.Application, error) {
+	ret := _m.Called(ctx, organizationID, application)
+
+	var r0 *v1.Application
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.Application) *v1.Application); ok {
+		r0 = rf(ctx, organizationID, application)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Application)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.Application) error); ok {
+		r1 = rf(ctx, organizationID, application)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
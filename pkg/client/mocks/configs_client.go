@@ -0,0 +1,118 @@
+// Code generated by mockery v1.0.0
+
+package mocks
+
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+import v1 "github.com/vmware/dispatch/pkg/api/v1"
+
+// ConfigsClient is an autogenerated mock type for the ConfigsClient type
+type ConfigsClient struct {
+	mock.Mock
+}
+
+// CreateConfig provides a mock function with given fields: ctx, organizationID, config
+func (_m *ConfigsClient) CreateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error) {
+	ret := _m.Called(ctx, organizationID, config)
+
+	var r0 *v1.Configuration
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.Configuration) *v1.Configuration); ok {
+		r0 = rf(ctx, organizationID, config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Configuration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.Configuration) error); ok {
+		r1 = rf(ctx, organizationID, config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteConfig provides a mock function with given fields: ctx, organizationID, configName
+func (_m *ConfigsClient) DeleteConfig(ctx context.Context, organizationID string, configName string) error {
+	ret := _m.Called(ctx, organizationID, configName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, organizationID, configName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetConfig provides a mock function with given fields: ctx, organizationID, configName
+func (_m *ConfigsClient) GetConfig(ctx context.Context, organizationID string, configName string) (*v1.Configuration, error) {
+	ret := _m.Called(ctx, organizationID, configName)
+
+	var r0 *v1.Configuration
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1.Configuration); ok {
+		r0 = rf(ctx, organizationID, configName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Configuration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, organizationID, configName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListConfigs provides a mock function with given fields: ctx, organizationID
+func (_m *ConfigsClient) ListConfigs(ctx context.Context, organizationID string) ([]v1.Configuration, error) {
+	ret := _m.Called(ctx, organizationID)
+
+	var r0 []v1.Configuration
+	if rf, ok := ret.Get(0).(func(context.Context, string) []v1.Configuration); ok {
+		r0 = rf(ctx, organizationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.Configuration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateConfig provides a mock function with given fields: ctx, organizationID, config
+func (_m *ConfigsClient) UpdateConfig(ctx context.Context, organizationID string, config *v1.Configuration) (*v1.Configuration, error) {
+	ret := _m.Called(ctx, organizationID, config)
+
+	var r0 *v1.Configuration
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1.Configuration) *v1.Configuration); ok {
+		r0 = rf(ctx, organizationID, config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Configuration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1.Configuration) error); ok {
+		r1 = rf(ctx, organizationID, config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -4,6 +4,7 @@ package mocks
 
 import context "context"
 import mock "github.com/stretchr/testify/mock"
+import time "time"
 import v1 "github.com/vmware/dispatch/pkg/api/v1"
 import client "github.com/vmware/dispatch/pkg/client"
 
@@ -195,3 +196,26 @@ func (_m *FunctionsClient) UpdateFunction(ctx context.Context, organizationID st
 
 	return r0, r1
 }
+
+// WaitFunction provides a mock function with given fields: ctx, organizationID, functionName, status, timeout
+func (_m *FunctionsClient) WaitFunction(ctx context.Context, organizationID string, functionName string, status v1.Status, timeout time.Duration) (*v1.Function, error) {
+	ret := _m.Called(ctx, organizationID, functionName, status, timeout)
+
+	var r0 *v1.Function
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, v1.Status, time.Duration) *v1.Function); ok {
+		r0 = rf(ctx, organizationID, functionName, status, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Function)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, v1.Status, time.Duration) error); ok {
+		r1 = rf(ctx, organizationID, functionName, status, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
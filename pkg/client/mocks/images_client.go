@@ -4,6 +4,7 @@ package mocks
 
 import context "context"
 import mock "github.com/stretchr/testify/mock"
+import time "time"
 import v1 "github.com/vmware/dispatch/pkg/api/v1"
 
 // ImagesClient is an autogenerated mock type for the ImagesClient type
@@ -240,3 +241,49 @@ func (_m *ImagesClient) UpdateImage(ctx context.Context, organizationID string,
 
 	return r0, r1
 }
+
+// WaitBaseImage provides a mock function with given fields: ctx, organizationID, baseImageName, status, timeout
+func (_m *ImagesClient) WaitBaseImage(ctx context.Context, organizationID string, baseImageName string, status v1.Status, timeout time.Duration) (*v1.BaseImage, error) {
+	ret := _m.Called(ctx, organizationID, baseImageName, status, timeout)
+
+	var r0 *v1.BaseImage
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, v1.Status, time.Duration) *v1.BaseImage); ok {
+		r0 = rf(ctx, organizationID, baseImageName, status, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.BaseImage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, v1.Status, time.Duration) error); ok {
+		r1 = rf(ctx, organizationID, baseImageName, status, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WaitImage provides a mock function with given fields: ctx, organizationID, imageName, status, timeout
+func (_m *ImagesClient) WaitImage(ctx context.Context, organizationID string, imageName string, status v1.Status, timeout time.Duration) (*v1.Image, error) {
+	ret := _m.Called(ctx, organizationID, imageName, status, timeout)
+
+	var r0 *v1.Image
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, v1.Status, time.Duration) *v1.Image); ok {
+		r0 = rf(ctx, organizationID, imageName, status, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, v1.Status, time.Duration) error); ok {
+		r1 = rf(ctx, organizationID, imageName, status, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
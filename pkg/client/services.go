@@ -0,0 +1,274 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	swaggerclient "github.com/vmware/dispatch/pkg/service-manager/gen/client"
+	serviceclassclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_class"
+	serviceinstanceclient "github.com/vmware/dispatch/pkg/service-manager/gen/client/service_instance"
+)
+
+// ServicesClient defines the services client interface
+type ServicesClient interface {
+	// Service Classes
+	GetServiceClass(ctx context.Context, organizationID string, serviceClassName string) (*v1.ServiceClass, error)
+	ListServiceClasses(ctx context.Context, organizationID string) ([]v1.ServiceClass, error)
+
+	// Service Instances
+	CreateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error)
+	DeleteServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) error
+	GetServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) (*v1.ServiceInstance, error)
+	ListServiceInstances(ctx context.Context, organizationID string) ([]v1.ServiceInstance, error)
+}
+
+// NewServicesClient is used to create a new services client
+func NewServicesClient(host string, auth runtime.ClientAuthInfoWriter, organizationID, project string) ServicesClient {
+	transport := DefaultHTTPClient(host, swaggerclient.DefaultBasePath)
+	return &DefaultServicesClient{
+		baseClient: baseClient{
+			organizationID: organizationID,
+			projectName:    project,
+		},
+		client: swaggerclient.New(transport, strfmt.Default),
+		auth:   auth,
+	}
+}
+
+// DefaultServicesClient defines the default services client
+type DefaultServicesClient struct {
+	baseClient
+
+	client *swaggerclient.Services
+	auth   runtime.ClientAuthInfoWriter
+}
+
+// GetServiceClass retrieves a service class
+func (c *DefaultServicesClient) GetServiceClass(ctx context.Context, organizationID string, serviceClassName string) (*v1.ServiceClass, error) {
+	params := serviceclassclient.GetServiceClassParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		ServiceClassName: serviceClassName,
+	}
+	response, err := c.client.ServiceClass.GetServiceClass(&params)
+	if err != nil {
+		return nil, getServiceClassSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func getServiceClassSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceclassclient.GetServiceClassBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceclassclient.GetServiceClassUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceclassclient.GetServiceClassForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceclassclient.GetServiceClassNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *serviceclassclient.GetServiceClassDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ListServiceClasses lists service classes
+func (c *DefaultServicesClient) ListServiceClasses(ctx context.Context, organizationID string) ([]v1.ServiceClass, error) {
+	params := serviceclassclient.GetServiceClassesParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+	}
+	response, err := c.client.ServiceClass.GetServiceClasses(&params)
+	if err != nil {
+		return nil, listServiceClassesSwaggerError(err)
+	}
+	serviceClasses := []v1.ServiceClass{}
+	for _, serviceClass := range response.Payload {
+		serviceClasses = append(serviceClasses, *serviceClass)
+	}
+	return serviceClasses, nil
+}
+
+func listServiceClassesSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceclassclient.GetServiceClassesBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceclassclient.GetServiceClassesUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceclassclient.GetServiceClassesForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceclassclient.GetServiceClassesDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// CreateServiceInstance creates a service instance
+func (c *DefaultServicesClient) CreateServiceInstance(ctx context.Context, organizationID string, serviceInstance *v1.ServiceInstance) (*v1.ServiceInstance, error) {
+	params := serviceinstanceclient.AddServiceInstanceParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+		ServiceInstance:  serviceInstance,
+	}
+	response, err := c.client.ServiceInstance.AddServiceInstance(&params)
+	if err != nil {
+		return nil, createServiceInstanceSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func createServiceInstanceSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceinstanceclient.AddServiceInstanceBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceinstanceclient.AddServiceInstanceUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceinstanceclient.AddServiceInstanceForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceinstanceclient.AddServiceInstanceConflict:
+		return NewErrorAlreadyExists(v.Payload)
+	case *serviceinstanceclient.AddServiceInstanceDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// DeleteServiceInstance deletes a service instance
+func (c *DefaultServicesClient) DeleteServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) error {
+	params := serviceinstanceclient.DeleteServiceInstanceParams{
+		Context:             ctx,
+		XDispatchOrg:        swag.String(c.getOrgID(organizationID)),
+		XDispatchProject:    swag.String(c.projectName),
+		ServiceInstanceName: serviceInstanceName,
+	}
+	_, err := c.client.ServiceInstance.DeleteServiceInstance(&params)
+	if err != nil {
+		return deleteServiceInstanceSwaggerError(err)
+	}
+	return nil
+}
+
+func deleteServiceInstanceSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceinstanceclient.DeleteServiceInstanceBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceinstanceclient.DeleteServiceInstanceUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceinstanceclient.DeleteServiceInstanceForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceinstanceclient.DeleteServiceInstanceNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *serviceinstanceclient.DeleteServiceInstanceConflict:
+		return NewErrorAlreadyExists(v.Payload)
+	case *serviceinstanceclient.DeleteServiceInstanceDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// GetServiceInstance retrieves a service instance
+func (c *DefaultServicesClient) GetServiceInstance(ctx context.Context, organizationID string, serviceInstanceName string) (*v1.ServiceInstance, error) {
+	params := serviceinstanceclient.GetServiceInstanceParams{
+		Context:             ctx,
+		XDispatchOrg:        swag.String(c.getOrgID(organizationID)),
+		XDispatchProject:    swag.String(c.projectName),
+		ServiceInstanceName: serviceInstanceName,
+	}
+	response, err := c.client.ServiceInstance.GetServiceInstance(&params)
+	if err != nil {
+		return nil, getServiceInstanceSwaggerError(err)
+	}
+	return response.Payload, nil
+}
+
+func getServiceInstanceSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceinstanceclient.GetServiceInstanceBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceinstanceclient.GetServiceInstanceUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceinstanceclient.GetServiceInstanceForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceinstanceclient.GetServiceInstanceNotFound:
+		return NewErrorNotFound(v.Payload)
+	case *serviceinstanceclient.GetServiceInstanceDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
+
+// ListServiceInstances lists service instances
+func (c *DefaultServicesClient) ListServiceInstances(ctx context.Context, organizationID string) ([]v1.ServiceInstance, error) {
+	params := serviceinstanceclient.GetServiceInstancesParams{
+		Context:          ctx,
+		XDispatchOrg:     swag.String(c.getOrgID(organizationID)),
+		XDispatchProject: swag.String(c.projectName),
+	}
+	response, err := c.client.ServiceInstance.GetServiceInstances(&params)
+	if err != nil {
+		return nil, listServiceInstancesSwaggerError(err)
+	}
+	serviceInstances := []v1.ServiceInstance{}
+	for _, serviceInstance := range response.Payload {
+		serviceInstances = append(serviceInstances, *serviceInstance)
+	}
+	return serviceInstances, nil
+}
+
+func listServiceInstancesSwaggerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch v := err.(type) {
+	case *serviceinstanceclient.GetServiceInstancesBadRequest:
+		return NewErrorBadRequest(v.Payload)
+	case *serviceinstanceclient.GetServiceInstancesUnauthorized:
+		return NewErrorUnauthorized(v.Payload)
+	case *serviceinstanceclient.GetServiceInstancesForbidden:
+		return NewErrorForbidden(v.Payload)
+	case *serviceinstanceclient.GetServiceInstancesDefault:
+		return NewErrorServerUnknownError(v.Payload)
+	default:
+		// shouldn't happen, but we need to be prepared:
+		return fmt.Errorf("unexpected error received from server: %s", err)
+	}
+}
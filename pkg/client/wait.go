@@ -0,0 +1,42 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// waitRetryInterval is the pause before asking again when the server returned a resource before it reached the status
+var waitRetryInterval = time.Second
+
+// waitForStatus long-polls get, which passes waitFor and waitTimeout (in seconds) to the server, until the resource
+// reaches status or ERROR, or timeout expires. The request is repeated as the server bounds how long it holds one.
+func waitForStatus(ctx context.Context, status v1.Status, timeout time.Duration, get func(waitFor *string, waitTimeout *int64) (v1.Status, error)) error {
+	waitFor := string(status)
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := deadline.Sub(time.Now())
+		if remaining < 0 {
+			remaining = 0
+		}
+		seconds := int64((remaining + time.Second - 1) / time.Second)
+		current, err := get(&waitFor, &seconds)
+		if err != nil {
+			return err
+		}
+		if current == status || current == v1.StatusERROR || !time.Now().Before(deadline) {
+			return nil
+		}
+		select {
+		case <-time.After(waitRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
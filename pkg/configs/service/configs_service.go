@@ -0,0 +1,43 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// ConfigNotFound is the error type when the configuration is not found
+type ConfigNotFound struct {
+	error
+}
+
+// ConfigAlreadyExists is the error type when a configuration with the same name exists
+type ConfigAlreadyExists struct {
+	error
+}
+
+// ConfigInUse is the error type when a configuration is used by functions
+type ConfigInUse struct {
+	error
+}
+
+// ConfigsService defines the configurations service interface
+type ConfigsService interface {
+	AddConfig(ctx context.Context, config *v1.Configuration) (*v1.Configuration, error)
+	GetConfigs(ctx context.Context, meta *v1.Meta) ([]*v1.Configuration, error)
+	GetConfig(ctx context.Context, meta *v1.Meta) (*v1.Configuration, error)
+	UpdateConfig(ctx context.Context, config *v1.Configuration) (*v1.Configuration, error)
+	DeleteConfig(ctx context.Context, meta *v1.Meta) error
+}
+
+// FunctionRoller restarts the functions using a configuration, so that they get its current values
+type FunctionRoller interface {
+	RollConfig(ctx context.Context, meta *v1.Meta) error
+	// ConfigUsers returns the names of the functions using a configuration
+	ConfigUsers(ctx context.Context, meta *v1.Meta) ([]string, error)
+}
@@ -0,0 +1,49 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"github.com/pkg/errors"
+	k8sv1 "k8s.io/api/core/v1"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/utils"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
+)
+
+//FromConfig converts from Dispatch configuration to k8s config map, the values are the data of the config map
+func FromConfig(config *dapi.Configuration) *k8sv1.ConfigMap {
+	if config == nil {
+		return nil
+	}
+
+	return &k8sv1.ConfigMap{
+		ObjectMeta: knaming.ToObjectMeta(config.Meta, *config),
+		Data:       config.Values,
+	}
+}
+
+//ToConfig converts from k8s config map to Dispatch configuration
+func ToConfig(configMap *k8sv1.ConfigMap) *dapi.Configuration {
+	if configMap == nil {
+		return nil
+	}
+
+	objMeta := &configMap.ObjectMeta
+
+	var config dapi.Configuration
+	if err := knaming.FromJSONString(objMeta.Annotations[knaming.InitialObjectAnnotation], &config); err != nil {
+		// TODO the right thing
+		panic(errors.Wrap(err, "decoding into configuration"))
+	}
+	utils.AdjustMeta(&config.Meta, dapi.Meta{CreatedTime: configMap.CreationTimestamp.Unix()})
+	config.Kind = dapi.ConfigurationKind
+	config.Revision = objMeta.ResourceVersion
+
+	config.Values = configMap.Data
+
+	return &config
+}
@@ -0,0 +1,130 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
+)
+
+// K8sConfigsService stores configurations as k8s config maps
+type K8sConfigsService struct {
+	K8sAPI k8sv1.CoreV1Interface
+	// Functions rolls the functions using a configuration when it is updated, optional
+	Functions FunctionRoller
+}
+
+// GetConfig gets a specific configuration
+func (s *K8sConfigsService) GetConfig(ctx context.Context, meta *dispatchv1.Meta) (*dispatchv1.Configuration, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	configName := knaming.ConfigName(*meta)
+	configMap, err := s.K8sAPI.ConfigMaps(meta.Org).Get(configName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, ConfigNotFound{err}
+		}
+		return nil, errors.Wrapf(err, "getting a config map from k8s API: '%s'", configName)
+	}
+
+	return ToConfig(configMap), nil
+}
+
+// GetConfigs gets all the configurations of a project
+func (s *K8sConfigsService) GetConfigs(ctx context.Context, meta *dispatchv1.Meta) ([]*dispatchv1.Configuration, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	configMapList, err := s.K8sAPI.ConfigMaps(meta.Org).List(metav1.ListOptions{
+		LabelSelector: knaming.ToLabelSelector(map[string]string{
+			knaming.ProjectLabel: meta.Project,
+		}),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing config maps from k8s API")
+	}
+
+	var configs []*dispatchv1.Configuration
+	for i := range configMapList.Items {
+		configs = append(configs, ToConfig(&configMapList.Items[i]))
+	}
+
+	return configs, nil
+}
+
+// AddConfig adds a configuration
+func (s *K8sConfigsService) AddConfig(ctx context.Context, config *dispatchv1.Configuration) (*dispatchv1.Configuration, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	createdConfigMap, err := s.K8sAPI.ConfigMaps(config.Meta.Org).Create(FromConfig(config))
+	if err != nil {
+		if kerrors.IsAlreadyExists(err) {
+			return nil, ConfigAlreadyExists{err}
+		}
+		return nil, errors.Wrapf(err, "creating a k8s config map '%s'", config.Meta.Name)
+	}
+
+	return ToConfig(createdConfigMap), nil
+}
+
+// DeleteConfig deletes a configuration, unless functions still use it
+func (s *K8sConfigsService) DeleteConfig(ctx context.Context, meta *dispatchv1.Meta) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if s.Functions != nil {
+		users, err := s.Functions.ConfigUsers(ctx, meta)
+		if err != nil {
+			return errors.Wrapf(err, "looking up the functions using configuration '%s'", meta.Name)
+		}
+		if len(users) > 0 {
+			return ConfigInUse{errors.Errorf("configuration '%s' is used by functions: %s", meta.Name, strings.Join(users, ", "))}
+		}
+	}
+
+	configName := knaming.ConfigName(*meta)
+	err := s.K8sAPI.ConfigMaps(meta.Org).Delete(configName, &metav1.DeleteOptions{})
+	if kerrors.IsNotFound(err) {
+		return ConfigNotFound{err}
+	}
+
+	return errors.Wrapf(err, "deleting config map from k8s API: '%s'", configName)
+}
+
+// UpdateConfig updates a configuration and rolls the functions using it
+func (s *K8sConfigsService) UpdateConfig(ctx context.Context, config *dispatchv1.Configuration) (*dispatchv1.Configuration, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	updatedConfigMap, err := s.K8sAPI.ConfigMaps(config.Meta.Org).Update(FromConfig(config))
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, ConfigNotFound{err}
+		}
+		return nil, errors.Wrapf(err, "updating a k8s config map '%s'", config.Meta.Name)
+	}
+
+	if s.Functions != nil {
+		// The configuration is updated either way, functions pick it up when they are rolled next
+		if err := s.Functions.RollConfig(ctx, &config.Meta); err != nil {
+			log.Errorf("error when rolling the functions using configuration %s: %+v", config.Meta.Name, err)
+		}
+	}
+
+	return ToConfig(updatedConfigMap), nil
+}
@@ -0,0 +1,117 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	dispatchv1 "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+const (
+	testOrg     = "vmware"
+	testProject = "project"
+)
+
+type fakeRoller struct {
+	rolled []string
+	users  map[string][]string
+}
+
+func (r *fakeRoller) RollConfig(ctx context.Context, meta *dispatchv1.Meta) error {
+	r.rolled = append(r.rolled, meta.Name)
+	return nil
+}
+
+func (r *fakeRoller) ConfigUsers(ctx context.Context, meta *dispatchv1.Meta) ([]string, error) {
+	return r.users[meta.Name], nil
+}
+
+func testConfig(name string, values map[string]string) *dispatchv1.Configuration {
+	return &dispatchv1.Configuration{
+		Meta:   dispatchv1.Meta{Org: testOrg, Project: testProject, Name: name},
+		Values: values,
+	}
+}
+
+func k8sConfigsService(t *testing.T) (*K8sConfigsService, *fakeRoller) {
+	roller := &fakeRoller{}
+	s := &K8sConfigsService{
+		K8sAPI:    fake.NewSimpleClientset().CoreV1(),
+		Functions: roller,
+	}
+	_, err := s.AddConfig(context.Background(), testConfig("settings", map[string]string{"LOG_LEVEL": "debug"}))
+	require.NoError(t, err)
+	_, err = s.AddConfig(context.Background(), testConfig("limits", map[string]string{"MAX_ITEMS": "10"}))
+	require.NoError(t, err)
+	return s, roller
+}
+
+func TestK8sConfigsGet(t *testing.T) {
+	s, _ := k8sConfigsService(t)
+
+	config, err := s.GetConfig(context.Background(), &dispatchv1.Meta{Org: testOrg, Project: testProject, Name: "settings"})
+	require.NoError(t, err)
+	assert.Equal(t, dispatchv1.ConfigurationKind, config.Kind)
+	assert.Equal(t, "debug", config.Values["LOG_LEVEL"])
+
+	configs, err := s.GetConfigs(context.Background(), &dispatchv1.Meta{Org: testOrg, Project: testProject})
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+
+	_, err = s.GetConfig(context.Background(), &dispatchv1.Meta{Org: testOrg, Project: testProject, Name: "missing"})
+	assert.IsType(t, ConfigNotFound{}, err)
+}
+
+func TestK8sConfigsAddDuplicate(t *testing.T) {
+	s, _ := k8sConfigsService(t)
+
+	_, err := s.AddConfig(context.Background(), testConfig("settings", nil))
+	assert.IsType(t, ConfigAlreadyExists{}, err)
+}
+
+func TestK8sConfigsUpdateRollsFunctions(t *testing.T) {
+	s, roller := k8sConfigsService(t)
+
+	updated, err := s.UpdateConfig(context.Background(), testConfig("settings", map[string]string{"LOG_LEVEL": "info"}))
+	require.NoError(t, err)
+	assert.Equal(t, "info", updated.Values["LOG_LEVEL"])
+	assert.Equal(t, []string{"settings"}, roller.rolled)
+
+	_, err = s.UpdateConfig(context.Background(), testConfig("missing", nil))
+	assert.IsType(t, ConfigNotFound{}, err)
+	assert.Equal(t, []string{"settings"}, roller.rolled)
+}
+
+func TestK8sConfigsDelete(t *testing.T) {
+	s, _ := k8sConfigsService(t)
+
+	meta := &dispatchv1.Meta{Org: testOrg, Project: testProject, Name: "settings"}
+	require.NoError(t, s.DeleteConfig(context.Background(), meta))
+	_, err := s.GetConfig(context.Background(), meta)
+	assert.IsType(t, ConfigNotFound{}, err)
+	assert.IsType(t, ConfigNotFound{}, s.DeleteConfig(context.Background(), meta))
+}
+
+func TestK8sConfigsDeleteInUse(t *testing.T) {
+	s, roller := k8sConfigsService(t)
+	roller.users = map[string][]string{"settings": {"hello"}}
+
+	meta := &dispatchv1.Meta{Org: testOrg, Project: testProject, Name: "settings"}
+	err := s.DeleteConfig(context.Background(), meta)
+	assert.IsType(t, ConfigInUse{}, err)
+	assert.Contains(t, err.Error(), "hello")
+	_, err = s.GetConfig(context.Background(), meta)
+	assert.NoError(t, err)
+
+	roller.users = nil
+	assert.NoError(t, s.DeleteConfig(context.Background(), meta))
+}
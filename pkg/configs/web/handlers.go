@@ -0,0 +1,177 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/configs/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/configs/gen/restapi/operations/config"
+	"github.com/vmware/dispatch/pkg/configs/service"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// Handlers encapsulates the configuration store handlers
+type Handlers struct {
+	configsService service.ConfigsService
+}
+
+// NewHandlers create new handlers for configuration store
+func NewHandlers(configsService service.ConfigsService) *Handlers {
+	return &Handlers{configsService: configsService}
+}
+
+// ConfigureHandlers registers configuration store handlers to the API
+func ConfigureHandlers(api middleware.RoutableAPI, h *Handlers) {
+	a, ok := api.(*operations.ConfigsAPI)
+	if !ok {
+		panic("Cannot configure api")
+	}
+
+	a.ConfigGetConfigsHandler = config.GetConfigsHandlerFunc(h.getConfigs)
+	a.ConfigAddConfigHandler = config.AddConfigHandlerFunc(h.addConfig)
+	a.ConfigGetConfigHandler = config.GetConfigHandlerFunc(h.getConfig)
+	a.ConfigDeleteConfigHandler = config.DeleteConfigHandlerFunc(h.deleteConfig)
+	a.ConfigUpdateConfigHandler = config.UpdateConfigHandlerFunc(h.updateConfig)
+}
+
+func (h *Handlers) addConfig(params config.AddConfigParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := *params.XDispatchOrg
+	project := *params.XDispatchProject
+
+	utils.AdjustMeta(&params.Config.Meta, v1.Meta{Org: org, Project: project})
+
+	created, err := h.configsService.AddConfig(ctx, params.Config)
+	if err != nil {
+		if _, ok := err.(service.ConfigAlreadyExists); ok {
+			return config.NewAddConfigConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: utils.ErrorMsgAlreadyExists("config", params.Config.Name),
+			})
+		}
+		log.Errorf("error when creating the config with k8s APIs: %+v", err)
+		return config.NewAddConfigDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("config", params.Config.Name),
+		})
+	}
+
+	return config.NewAddConfigCreated().WithPayload(created)
+}
+
+func (h *Handlers) getConfigs(params config.GetConfigsParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := *params.XDispatchOrg
+	project := *params.XDispatchProject
+
+	configs, err := h.configsService.GetConfigs(ctx, &v1.Meta{Org: org, Project: project})
+	if err != nil {
+		log.Errorf("error when listing configs from k8s APIs: %+v", err)
+		return config.NewGetConfigsDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when listing configs from k8s APIs"),
+		})
+	}
+
+	return config.NewGetConfigsOK().WithPayload(configs)
+}
+
+func (h *Handlers) getConfig(params config.GetConfigParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := *params.XDispatchOrg
+	project := *params.XDispatchProject
+
+	found, err := h.configsService.GetConfig(ctx, &v1.Meta{Org: org, Project: project, Name: params.ConfigName})
+	if err != nil {
+		if _, ok := err.(service.ConfigNotFound); ok {
+			return config.NewGetConfigNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("config", params.ConfigName),
+			})
+		}
+
+		log.Errorf("error when reading the config from k8s APIs: %+v", err)
+		return config.NewGetConfigDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("config", params.ConfigName),
+		})
+	}
+
+	return config.NewGetConfigOK().WithPayload(found)
+}
+
+func (h *Handlers) updateConfig(params config.UpdateConfigParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := *params.XDispatchOrg
+	project := *params.XDispatchProject
+
+	utils.AdjustMeta(&params.Config.Meta, v1.Meta{Org: org, Project: project, Name: params.ConfigName})
+
+	updated, err := h.configsService.UpdateConfig(ctx, params.Config)
+	if err != nil {
+		if _, ok := err.(service.ConfigNotFound); ok {
+			return config.NewUpdateConfigNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("config", params.ConfigName),
+			})
+		}
+
+		log.Errorf("error when updating config from k8s APIs: %+v", err)
+		return config.NewUpdateConfigDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("config", params.ConfigName),
+		})
+	}
+
+	return config.NewUpdateConfigCreated().WithPayload(updated)
+}
+
+func (h *Handlers) deleteConfig(params config.DeleteConfigParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := *params.XDispatchOrg
+	project := *params.XDispatchProject
+
+	err := h.configsService.DeleteConfig(ctx, &v1.Meta{Org: org, Project: project, Name: params.ConfigName})
+	if err != nil {
+		if _, ok := err.(service.ConfigNotFound); ok {
+			return config.NewDeleteConfigNotFound().WithPayload(&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("config", params.ConfigName),
+			})
+		}
+		if _, ok := err.(service.ConfigInUse); ok {
+			return config.NewDeleteConfigConflict().WithPayload(&v1.Error{
+				Code:    http.StatusConflict,
+				Message: swag.String(err.Error()),
+			})
+		}
+
+		log.Errorf("error when deleting config from k8s APIs: %+v", err)
+		return config.NewDeleteConfigDefault(http.StatusInternalServerError).WithPayload(&v1.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("config", params.ConfigName),
+		})
+	}
+	return config.NewDeleteConfigNoContent()
+}
@@ -8,6 +8,8 @@ package controller
 import (
 	"context"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -31,15 +33,24 @@ type EntityHandler interface {
 	Sync(ctx context.Context, resyncPeriod time.Duration) ([]entitystore.Entity, error)
 }
 
-const defaultWorkers = 1
+const (
+	defaultWorkers = 1
+
+	watchRetryInterval = 5 * time.Second
+)
 
 // Options defines controller configuration
 type Options struct {
 	ServiceName string
 
-	ResyncPeriod      time.Duration
-	Workers           int
-	ZookeeperLocation string
+	ResyncPeriod time.Duration
+	Workers      int
+
+	// Store is watched for the changes of the entities handled, when set, so changes made through other replicas
+	// are processed too.  The periodic resync then only catches up with the underlying drivers.
+	Store entitystore.EntityStore
+	// LeaderElector, when set, elects the replica processing the entities.  The other replicas only serve the API.
+	LeaderElector LeaderElector
 }
 
 // WatchEvent captures entity together with the associated context
@@ -79,9 +90,13 @@ type Controller interface {
 
 // DefaultController defines a struct for a generic controller
 type DefaultController struct {
-	done    chan bool
 	watcher chan WatchEvent
 	options Options
+	workers *semaphore.Weighted
+	leading int32
+
+	stop    context.CancelFunc
+	stopped sync.WaitGroup
 
 	entityHandlers map[reflect.Type]EntityHandler
 }
@@ -92,26 +107,49 @@ func NewController(options Options) Controller {
 		options.Workers = defaultWorkers
 	}
 	return &DefaultController{
-		done:    make(chan bool),
 		watcher: make(chan WatchEvent),
 		options: options,
+		workers: semaphore.NewWeighted(int64(options.Workers)),
 
 		entityHandlers: map[reflect.Type]EntityHandler{},
 	}
 }
 
-// Start starts the controller watch loop
+// Start starts the controller watch loop.  With a leader elector, the loop only runs while the replica is the leader.
 func (dc *DefaultController) Start() {
-	// Run sync once at the beginning to synchronize resources at service startup.
-	// This should block until resources are synced to ensure proper handling of requests.
-	dc.sync()
-
-	go dc.run(dc.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	dc.stop = cancel
+
+	// The watcher is drained by every replica, so the API handlers of a replica which is not the leader don't block.
+	go dc.dispatch()
+
+	dc.stopped.Add(1)
+	if dc.options.LeaderElector == nil {
+		// Run sync once at the beginning to synchronize resources at service startup.
+		// This should block until resources are synced to ensure proper handling of requests.
+		dc.startLeading(ctx)
+		go func() {
+			defer dc.stopped.Done()
+			dc.run(ctx)
+		}()
+		return
+	}
+	go func() {
+		defer dc.stopped.Done()
+		dc.options.LeaderElector.Run(ctx, func(ctx context.Context) {
+			log.Infof("%s controller is the leader", dc.options.ServiceName)
+			dc.startLeading(ctx)
+			dc.run(ctx)
+			log.Infof("%s controller is not the leader anymore", dc.options.ServiceName)
+		})
+	}()
 }
 
-// Shutdown stops the controller loop
+// Shutdown stops the controller loop, once the entities being processed are done and the leadership is released
 func (dc *DefaultController) Shutdown() {
-	dc.done <- true
+	dc.stop()
+	dc.stopped.Wait()
+	close(dc.watcher)
 }
 
 // Watcher returns a watcher channel for the controller
@@ -156,6 +194,75 @@ func (dc *DefaultController) processItem(ctx context.Context, e entitystore.Enti
 	return err
 }
 
+// watchedStatus tells whether an entity in the status is processed when it changes in the store.  The other statuses
+// are the outcome of the processing, handling them would loop.
+func watchedStatus(status entitystore.Status) bool {
+	switch status {
+	case entitystore.StatusINITIALIZED, entitystore.StatusCREATING, entitystore.StatusUPDATING, entitystore.StatusDELETING:
+		return true
+	}
+	return false
+}
+
+// watchStore starts processing the changes of the entities of a handler in the store
+func (dc *DefaultController) watchStore(ctx context.Context, h EntityHandler) {
+	dataType := entitystore.DataType(h.Type().Elem().Name())
+	events, err := dc.options.Store.Watch(ctx, dataType, "", 0)
+	go dc.processChanges(ctx, h, dataType, events, err)
+}
+
+// processChanges processes the changes streamed by a watch, watching again from the last change processed whenever
+// the stream ends
+func (dc *DefaultController) processChanges(ctx context.Context, h EntityHandler, dataType entitystore.DataType, events <-chan entitystore.WatchEvent, err error) {
+	var last uint64
+	for {
+		switch {
+		case err == entitystore.ErrCompacted:
+			log.Warnf("%s missed changes of %s entities, syncing", dc.options.ServiceName, dataType)
+			last = 0
+			if err := dc.sync(ctx); err != nil {
+				log.Error(err)
+			}
+		case err != nil:
+			log.Errorf("error watching %s entities: %v", dataType, err)
+			select {
+			case <-time.After(watchRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+		default:
+			for event := range events {
+				last = event.Revision
+				if event.Type == entitystore.WatchEventDeleted {
+					continue
+				}
+				e := reflect.New(h.Type().Elem()).Interface().(entitystore.Entity)
+				if err := event.Decode(e); err != nil {
+					log.Error(err)
+					continue
+				}
+				if !watchedStatus(e.GetStatus()) {
+					continue
+				}
+				if err := dc.workers.Acquire(ctx, 1); err != nil {
+					return
+				}
+				go func(e entitystore.Entity) {
+					defer dc.workers.Release(1)
+					log.Infof("received change=%s entity=%s", e.GetStatus(), e.GetName())
+					if err := dc.processItem(context.Background(), e); err != nil {
+						log.Error(err)
+					}
+				}(e)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		events, err = dc.options.Store.Watch(ctx, dataType, "", last)
+	}
+}
+
 func defaultSyncFilter(resyncPeriod time.Duration) entitystore.Filter {
 	now := time.Now().Add(-resyncPeriod)
 	return entitystore.FilterEverything().Add(
@@ -201,25 +308,26 @@ func DefaultSync(ctx context.Context, store entitystore.EntityStore, entityType
 	return entities, nil
 }
 
-func (dc *DefaultController) sync() error {
-	span, ctx := trace.Trace(context.Background(), "controller sync")
+// sync processes the entities returned by the Sync of the handlers
+func (dc *DefaultController) sync(ctx context.Context) error {
+	// The entities are processed to the end even if ctx is done meanwhile, ctx only stops the sync
+	span, spanCtx := trace.Trace(context.Background(), "controller sync")
 	defer span.Finish()
 
-	sem := semaphore.NewWeighted(int64(dc.options.Workers))
 	for _, handler := range dc.entityHandlers {
-		entities, err := handler.Sync(ctx, dc.options.ResyncPeriod)
+		entities, err := handler.Sync(spanCtx, dc.options.ResyncPeriod)
 		if err != nil {
 			return err
 		}
 		for _, e := range entities {
-			if err := sem.Acquire(ctx, 1); err != nil {
+			if err := dc.workers.Acquire(ctx, 1); err != nil {
 				log.Printf("Failed to acquire semaphore: %v", err)
-				break
+				return nil
 			}
 			go func(e entitystore.Entity) {
-				defer sem.Release(1)
+				defer dc.workers.Release(1)
 				log.Debugf("sync: processing entity %s (%v)", e.GetName(), e.GetStatus())
-				if err := dc.processItem(ctx, e); err != nil {
+				if err := dc.processItem(spanCtx, e); err != nil {
 					span.LogKV("error", err)
 					log.Error(err)
 				}
@@ -229,42 +337,64 @@ func (dc *DefaultController) sync() error {
 	return nil
 }
 
-// run runs the control loop
-func (dc *DefaultController) run(stopChan <-chan bool) {
+// startLeading starts processing the entities: watching the store when set, and syncing once
+func (dc *DefaultController) startLeading(ctx context.Context) {
+	atomic.StoreInt32(&dc.leading, 1)
+	// Start watching the store before syncing, so no change made in between is missed.
+	if dc.options.Store != nil {
+		for _, handler := range dc.entityHandlers {
+			dc.watchStore(ctx, handler)
+		}
+	}
+	if err := dc.sync(ctx); err != nil {
+		log.Error(err)
+	}
+}
+
+// run syncs periodically until ctx is done, then waits for the entities being processed so that the next leader
+// doesn't process them at the same time
+func (dc *DefaultController) run(ctx context.Context) {
 	resyncTicker := time.NewTicker(dc.options.ResyncPeriod)
 	defer resyncTicker.Stop()
 
-	defer close(dc.watcher)
-
-	// Start a worker pool.  The pool scales up to dc.options.Workers.
-	go func() {
-		sem := semaphore.NewWeighted(int64(dc.options.Workers))
-		for watchEvent := range dc.watcher {
-			if err := sem.Acquire(context.Background(), 1); err != nil {
-				log.Warnf("Failed to acquire semaphore: %v", err)
-				break
+	for {
+		select {
+		case <-resyncTicker.C:
+			log.Debugf("%s periodic syncing with the underlying driver", dc.options.ServiceName)
+			if err := dc.sync(ctx); err != nil {
+				log.Error(err)
 			}
-			go func(event WatchEvent) {
-				e := event.Entity
-				defer sem.Release(1)
-				log.Infof("received event=%s entity=%s", e.GetStatus(), e.GetName())
-				if err := dc.processItem(event.Ctx, e); err != nil {
-					log.Error(err)
-				}
-			}(watchEvent)
+		case <-ctx.Done():
+			atomic.StoreInt32(&dc.leading, 0)
+			dc.workers.Acquire(context.Background(), int64(dc.options.Workers))
+			dc.workers.Release(int64(dc.options.Workers))
+			return
 		}
-	}()
+	}
+}
 
-	go func() {
-		for range resyncTicker.C {
-			func() {
-				log.Debugf("%s periodic syncing with the underlying driver", dc.options.ServiceName)
-				if err := dc.sync(); err != nil {
-					log.Error(err)
-				}
-			}()
+// dispatch processes the entities pushed onto the watcher, while leading.  The pool scales up to dc.options.Workers.
+func (dc *DefaultController) dispatch() {
+	for watchEvent := range dc.watcher {
+		if atomic.LoadInt32(&dc.leading) == 0 {
+			log.Debugf("not the leader, skipping entity update: %s - %s", watchEvent.Entity.GetName(), watchEvent.Entity.GetStatus())
+			continue
 		}
-	}()
-
-	<-stopChan
+		if dc.options.Store != nil && watchedStatus(watchEvent.Entity.GetStatus()) {
+			// The change comes from the store as well
+			continue
+		}
+		if err := dc.workers.Acquire(context.Background(), 1); err != nil {
+			log.Warnf("Failed to acquire semaphore: %v", err)
+			break
+		}
+		go func(event WatchEvent) {
+			e := event.Entity
+			defer dc.workers.Release(1)
+			log.Infof("received event=%s entity=%s", e.GetStatus(), e.GetName())
+			if err := dc.processItem(event.Ctx, e); err != nil {
+				log.Error(err)
+			}
+		}(watchEvent)
+	}
 }
@@ -110,3 +110,43 @@ func TestController(t *testing.T) {
 		t.Logf("deleted %s", name)
 	}
 }
+
+func TestControllerWatchesStore(t *testing.T) {
+	ctx := context.Background()
+	store := helpers.MakeEntityStore(t)
+
+	deleteCounter := make(chan string, 100)
+	addCounter := make(chan string, 100)
+
+	controller := NewController(Options{
+		ResyncPeriod: time.Hour,
+		Store:        store,
+	})
+	controller.AddEntityHandler(&testEntityHandler{t: t, store: store, addCounter: addCounter, deleteCounter: deleteCounter})
+	controller.Start()
+	defer controller.Shutdown()
+
+	// Changes made through another replica are not pushed to the watcher of this controller
+	ent := &testEntity{entitystore.BaseEntity{
+		OrganizationID: testOrgID,
+		Name:           "test-watched",
+		Status:         entitystore.StatusCREATING,
+	}}
+	_, err := store.Add(ctx, ent)
+	assert.NoError(t, err)
+	select {
+	case name := <-addCounter:
+		assert.Equal(t, "test-watched", name)
+	case <-time.After(testSleepDuration):
+		t.Fatal("add not processed")
+	}
+
+	assert.NoError(t, store.SoftDelete(ctx, ent))
+	select {
+	case name := <-deleteCounter:
+		assert.Equal(t, "test-watched", name)
+	case <-time.After(testSleepDuration):
+		t.Fatal("delete not processed")
+	}
+	assert.NoError(t, store.Delete(ctx, testOrgID, ent.GetName(), ent))
+}
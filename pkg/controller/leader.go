@@ -0,0 +1,206 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+// LeaderElector elects a single leader among the replicas of a service
+type LeaderElector interface {
+	// Run campaigns for the leadership until ctx is done.  lead is called once elected, with a context done when the
+	// leadership is lost, and the leadership is only released or campaigned for again once lead has returned.
+	Run(ctx context.Context, lead func(ctx context.Context))
+}
+
+// LeaseRecord is the state of a lease
+type LeaseRecord struct {
+	Holder        string        `json:"holder"`
+	LeaseDuration time.Duration `json:"leaseDuration"`
+	RenewTime     time.Time     `json:"renewTime"`
+}
+
+// LeaseLock stores a lease shared by the replicas of a service
+type LeaseLock interface {
+	// Get gets the record of the lease, nil if the lease doesn't exist
+	Get(ctx context.Context) (*LeaseRecord, error)
+	// Create creates the lease, failing if it exists
+	Create(ctx context.Context, record LeaseRecord) error
+	// Update updates the lease, failing if it changed since it was last read or written
+	Update(ctx context.Context, record LeaseRecord) error
+}
+
+// LeaderElectionConfig defines the configuration of a leader elector
+type LeaderElectionConfig struct {
+	Lock LeaseLock
+	// Identity is unique to each replica, generated from the host name if empty
+	Identity string
+
+	// LeaseDuration is how long the other replicas wait for the leader to renew its lease before taking it over
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader tries to renew its lease before giving up the leadership, it is shorter
+	// than LeaseDuration so that the leader stops before another replica takes over
+	RenewDeadline time.Duration
+	// RetryPeriod is how often the lease is renewed, or tried to be acquired
+	RetryPeriod time.Duration
+}
+
+type leaseElector struct {
+	config LeaderElectionConfig
+
+	observed     LeaseRecord
+	observedTime time.Time
+}
+
+// NewLeaderElector creates a leader elector holding a lease
+func NewLeaderElector(config LeaderElectionConfig) LeaderElector {
+	if config.Identity == "" {
+		hostname, _ := os.Hostname()
+		config.Identity = fmt.Sprintf("%s_%s", hostname, uuid.NewV4().String())
+	}
+	if config.LeaseDuration == 0 {
+		config.LeaseDuration = defaultLeaseDuration
+	}
+	if config.RenewDeadline == 0 {
+		config.RenewDeadline = defaultRenewDeadline
+	}
+	if config.RetryPeriod == 0 {
+		config.RetryPeriod = defaultRetryPeriod
+	}
+	return &leaseElector{config: config}
+}
+
+// Run campaigns for the leadership until ctx is done
+func (le *leaseElector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		if !le.acquire(ctx) {
+			return
+		}
+		log.Infof("%s acquired the lease", le.config.Identity)
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		led := make(chan struct{})
+		go func() {
+			defer close(led)
+			lead(leaderCtx)
+		}()
+		le.renew(leaderCtx)
+		cancel()
+		<-led
+
+		if ctx.Err() != nil {
+			le.release()
+			return
+		}
+		log.Warnf("%s lost the lease", le.config.Identity)
+	}
+}
+
+// acquire tries to acquire the lease every retry period, until it does or ctx is done
+func (le *leaseElector) acquire(ctx context.Context) bool {
+	ticker := time.NewTicker(le.config.RetryPeriod)
+	defer ticker.Stop()
+	for {
+		if le.tryAcquireOrRenew(ctx) {
+			return true
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// renew renews the lease every retry period, until ctx is done or the lease couldn't be renewed before the deadline
+func (le *leaseElector) renew(ctx context.Context) {
+	ticker := time.NewTicker(le.config.RetryPeriod)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if le.tryAcquireOrRenew(ctx) {
+			renewed = time.Now()
+			continue
+		}
+		if time.Since(renewed) > le.config.RenewDeadline {
+			return
+		}
+	}
+}
+
+// tryAcquireOrRenew takes the lease if it is free or expired, or renews it if held already.  The expiry is measured
+// with the local clock from when the record was observed, so that the clocks of the replicas don't have to agree.
+func (le *leaseElector) tryAcquireOrRenew(ctx context.Context) bool {
+	now := time.Now()
+	record := LeaseRecord{
+		Holder:        le.config.Identity,
+		LeaseDuration: le.config.LeaseDuration,
+		RenewTime:     now,
+	}
+
+	current, err := le.config.Lock.Get(ctx)
+	if err != nil {
+		log.Errorf("error getting the lease: %v", err)
+		return false
+	}
+	if current == nil {
+		if err := le.config.Lock.Create(ctx, record); err != nil {
+			log.Debugf("error creating the lease: %v", err)
+			return false
+		}
+		le.observe(record, now)
+		return true
+	}
+
+	if current.Holder != le.observed.Holder || !current.RenewTime.Equal(le.observed.RenewTime) {
+		le.observe(*current, now)
+	}
+	if current.Holder != "" && current.Holder != le.config.Identity && now.Before(le.observedTime.Add(current.LeaseDuration)) {
+		return false
+	}
+	if err := le.config.Lock.Update(ctx, record); err != nil {
+		log.Debugf("error updating the lease: %v", err)
+		return false
+	}
+	le.observe(record, now)
+	return true
+}
+
+// release gives the lease up, so that another replica takes over without waiting for it to expire
+func (le *leaseElector) release() {
+	if le.observed.Holder != le.config.Identity {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), le.config.RetryPeriod)
+	defer cancel()
+	if err := le.config.Lock.Update(ctx, LeaseRecord{RenewTime: time.Now()}); err != nil {
+		log.Warnf("error releasing the lease: %v", err)
+		return
+	}
+	log.Infof("%s released the lease", le.config.Identity)
+}
+
+func (le *leaseElector) observe(record LeaseRecord, now time.Time) {
+	le.observed = record
+	le.observedTime = now
+}
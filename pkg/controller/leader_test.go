@@ -0,0 +1,189 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func testElector(lock LeaseLock, identity string) LeaderElector {
+	return NewLeaderElector(LeaderElectionConfig{
+		Lock:          lock,
+		Identity:      identity,
+		LeaseDuration: 300 * time.Millisecond,
+		RenewDeadline: 200 * time.Millisecond,
+		RetryPeriod:   20 * time.Millisecond,
+	})
+}
+
+// campaign runs an elector in the background, reporting when it starts and stops leading
+func campaign(ctx context.Context, elector LeaderElector, identity string, leaders chan<- string) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		elector.Run(ctx, func(ctx context.Context) {
+			leaders <- identity
+			<-ctx.Done()
+			leaders <- "-" + identity
+		})
+	}()
+	return done
+}
+
+func nextLeader(t *testing.T, leaders <-chan string) string {
+	select {
+	case leader := <-leaders:
+		return leader
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no leader change")
+	}
+	return ""
+}
+
+func TestLeaderElectionHandOff(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	leaders := make(chan string, 10)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := campaign(ctxA, testElector(NewStoreLock(store, testOrgID, "test-lease"), "a"), "a", leaders)
+	assert.Equal(t, "a", nextLeader(t, leaders))
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	doneB := campaign(ctxB, testElector(NewStoreLock(store, testOrgID, "test-lease"), "b"), "b", leaders)
+
+	// b waits while a renews its lease
+	select {
+	case leader := <-leaders:
+		t.Fatalf("unexpected leader change %s", leader)
+	case <-time.After(time.Second):
+	}
+
+	// a stops leading before releasing the lease, b then takes over without waiting for the lease to expire
+	stopped := time.Now()
+	cancelA()
+	<-doneA
+	assert.Equal(t, "-a", nextLeader(t, leaders))
+	assert.Equal(t, "b", nextLeader(t, leaders))
+	assert.True(t, time.Since(stopped) < 300*time.Millisecond)
+
+	cancelB()
+	<-doneB
+	assert.Equal(t, "-b", nextLeader(t, leaders))
+}
+
+// failingLock stops accepting updates once failing is set, like a store which became unreachable
+type failingLock struct {
+	LeaseLock
+	failing chan struct{}
+}
+
+func (l *failingLock) Update(ctx context.Context, record LeaseRecord) error {
+	select {
+	case <-l.failing:
+		return assert.AnError
+	default:
+		return l.LeaseLock.Update(ctx, record)
+	}
+}
+
+func TestLeaderElectionLostLease(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	leaders := make(chan string, 10)
+	lock := &failingLock{NewStoreLock(store, testOrgID, "test-lost-lease"), make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	campaign(ctx, testElector(lock, "a"), "a", leaders)
+	assert.Equal(t, "a", nextLeader(t, leaders))
+
+	// The leader gives up once it couldn't renew the lease before the deadline, before it expires for the others
+	failed := time.Now()
+	close(lock.failing)
+	assert.Equal(t, "-a", nextLeader(t, leaders))
+	assert.True(t, time.Since(failed) < 300*time.Millisecond)
+}
+
+func TestConfigMapLock(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	lock := NewConfigMapLock(client, "dispatch", "events-leader")
+
+	record, err := lock.Get(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	renewed := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, lock.Create(context.Background(), LeaseRecord{Holder: "a", LeaseDuration: time.Minute, RenewTime: renewed}))
+	assert.Error(t, lock.Create(context.Background(), LeaseRecord{Holder: "b"}))
+
+	other := NewConfigMapLock(client, "dispatch", "events-leader")
+	record, err = other.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", record.Holder)
+	assert.Equal(t, time.Minute, record.LeaseDuration)
+	assert.True(t, renewed.Equal(record.RenewTime))
+
+	require.NoError(t, other.Update(context.Background(), LeaseRecord{Holder: "b", LeaseDuration: time.Minute}))
+	record, err = lock.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "b", record.Holder)
+
+	configMap, err := client.CoreV1().ConfigMaps("dispatch").Get("events-leader", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, configMap.Annotations[LeaderAnnotation], `"holder":"b"`)
+}
+
+func TestStoreLockConflict(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	a := NewStoreLock(store, testOrgID, "test-conflict")
+	b := NewStoreLock(store, testOrgID, "test-conflict")
+
+	require.NoError(t, a.Create(context.Background(), LeaseRecord{Holder: "a"}))
+	_, err := b.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, a.Update(context.Background(), LeaseRecord{Holder: "a"}))
+
+	// b read the lease before a renewed it
+	assert.Error(t, b.Update(context.Background(), LeaseRecord{Holder: "b"}))
+	defer store.Delete(context.Background(), testOrgID, "test-conflict", &leaseEntity{})
+}
+
+func TestControllerNotLeader(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	addCounter := make(chan string, 100)
+
+	// Another replica holds the lease
+	lock := NewStoreLock(store, testOrgID, "test-controller-lease")
+	require.NoError(t, lock.Create(context.Background(), LeaseRecord{Holder: "other", LeaseDuration: time.Hour, RenewTime: time.Now()}))
+
+	controller := NewController(Options{
+		ResyncPeriod:  testResyncPeriod,
+		LeaderElector: testElector(NewStoreLock(store, testOrgID, "test-controller-lease"), "a"),
+	})
+	controller.AddEntityHandler(&testEntityHandler{t: t, store: store, addCounter: addCounter})
+	watcher := controller.Watcher()
+	controller.Start()
+
+	// The entity is not processed, and pushing it does not block
+	ent := &testEntity{entitystore.BaseEntity{Name: "test-not-leader", Status: entitystore.StatusCREATING}}
+	watcher.OnAction(context.Background(), ent)
+	select {
+	case name := <-addCounter:
+		t.Fatalf("%s processed by a replica which is not the leader", name)
+	case <-time.After(500 * time.Millisecond):
+	}
+	controller.Shutdown()
+}
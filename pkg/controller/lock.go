@@ -0,0 +1,166 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// LeaderAnnotation is the annotation of the config map holding the record of a lease
+const LeaderAnnotation = "dispatchframework.io/leader"
+
+type configMapLock struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+
+	configMap *corev1.ConfigMap
+}
+
+// NewConfigMapLock creates a lock storing the lease in an annotation of a Kubernetes config map.  Kubernetes rejects
+// the update of a config map changed since it was read, based on its resource version.
+func NewConfigMapLock(client kubernetes.Interface, namespace, name string) LeaseLock {
+	return &configMapLock{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (l *configMapLock) Get(ctx context.Context) (*LeaseRecord, error) {
+	configMap, err := l.client.CoreV1().ConfigMaps(l.namespace).Get(l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting config map %s/%s", l.namespace, l.name)
+	}
+	l.configMap = configMap
+
+	record := &LeaseRecord{}
+	if annotation, ok := configMap.Annotations[LeaderAnnotation]; ok {
+		if err := json.Unmarshal([]byte(annotation), record); err != nil {
+			return nil, errors.Wrapf(err, "error decoding the lease of config map %s/%s", l.namespace, l.name)
+		}
+	}
+	return record, nil
+}
+
+func (l *configMapLock) Create(ctx context.Context, record LeaseRecord) error {
+	annotation, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error encoding the lease")
+	}
+	configMap, err := l.client.CoreV1().ConfigMaps(l.namespace).Create(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        l.name,
+			Namespace:   l.namespace,
+			Annotations: map[string]string{LeaderAnnotation: string(annotation)},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error creating config map %s/%s", l.namespace, l.name)
+	}
+	l.configMap = configMap
+	return nil
+}
+
+func (l *configMapLock) Update(ctx context.Context, record LeaseRecord) error {
+	if l.configMap == nil {
+		return errors.New("the lease must be read before it is updated")
+	}
+	annotation, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "error encoding the lease")
+	}
+	configMap := l.configMap.DeepCopy()
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[LeaderAnnotation] = string(annotation)
+	configMap, err = l.client.CoreV1().ConfigMaps(l.namespace).Update(configMap)
+	if err != nil {
+		return errors.Wrapf(err, "error updating config map %s/%s", l.namespace, l.name)
+	}
+	l.configMap = configMap
+	return nil
+}
+
+// leaseEntity stores a lease in the entity store
+type leaseEntity struct {
+	entitystore.BaseEntity
+	LeaseRecord
+}
+
+type storeLock struct {
+	store          entitystore.EntityStore
+	organizationID string
+	name           string
+
+	lease *leaseEntity
+}
+
+// NewStoreLock creates a lock storing the lease in the entity store.  The store rejects the update of an entity
+// changed since it was read, based on its revision.
+func NewStoreLock(store entitystore.EntityStore, organizationID, name string) LeaseLock {
+	return &storeLock{
+		store:          store,
+		organizationID: organizationID,
+		name:           name,
+	}
+}
+
+func (l *storeLock) Get(ctx context.Context) (*LeaseRecord, error) {
+	lease := &leaseEntity{}
+	found, err := l.store.Find(ctx, l.organizationID, l.name, entitystore.Options{}, lease)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting lease %s", l.name)
+	}
+	if !found {
+		return nil, nil
+	}
+	l.lease = lease
+	record := lease.LeaseRecord
+	return &record, nil
+}
+
+func (l *storeLock) Create(ctx context.Context, record LeaseRecord) error {
+	lease := &leaseEntity{
+		BaseEntity: entitystore.BaseEntity{
+			OrganizationID: l.organizationID,
+			Name:           l.name,
+			Status:         entitystore.StatusREADY,
+		},
+		LeaseRecord: record,
+	}
+	if _, err := l.store.Add(ctx, lease); err != nil {
+		return errors.Wrapf(err, "error creating lease %s", l.name)
+	}
+	l.lease = lease
+	return nil
+}
+
+func (l *storeLock) Update(ctx context.Context, record LeaseRecord) error {
+	if l.lease == nil {
+		return errors.New("the lease must be read before it is updated")
+	}
+	lease := *l.lease
+	lease.LeaseRecord = record
+	if _, err := l.store.Update(ctx, lease.Revision, &lease); err != nil {
+		return errors.Wrapf(err, "error updating lease %s", l.name)
+	}
+	l.lease = &lease
+	return nil
+}
@@ -0,0 +1,273 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// applicationMember is a resource tagged as belonging to an application
+type applicationMember struct {
+	Kind   string    `json:"kind"`
+	Name   string    `json:"name"`
+	Status v1.Status `json:"status"`
+}
+
+// applicationMemberKind lists and deletes the resources of one kind belonging to an application
+type applicationMemberKind struct {
+	kind   string
+	list   func(ctx context.Context, application string) ([]applicationMember, error)
+	delete func(ctx context.Context, name string) error
+}
+
+// applicationMemberKinds returns the kinds of application members, in the order they are deleted: subscriptions
+// and endpoints first as they point to functions, event drivers before their types, then the functions, and last the
+// service instances, configs, images, base images and secrets they use.
+func applicationMemberKinds() []applicationMemberKind {
+	eventClient := eventManagerClient()
+	endpointClient := endpointsClient()
+	fnClient := functionsClient()
+	svcClient := servicesClient()
+	cfgClient := configsClient()
+	imgClient := imagesClient()
+	baseImgClient := baseImagesClient()
+	secClient := secretsClient()
+	org := dispatchConfig.Organization
+
+	return []applicationMemberKind{
+		{
+			kind: v1.SubscriptionKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				subscriptions, err := eventClient.ListSubscriptions(ctx, org)
+				var members []applicationMember
+				for _, s := range subscriptions {
+					if inApplication(s.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.SubscriptionKind, Name: *s.Name, Status: s.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteSubscription(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.EndpointKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				endpoints, err := endpointClient.ListEndpoints(ctx, org)
+				var members []applicationMember
+				for _, e := range endpoints {
+					if inApplication(e.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.EndpointKind, Name: e.Name, Status: e.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := endpointClient.DeleteEndpoint(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.DriverKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				drivers, err := eventClient.ListEventDrivers(ctx, org)
+				var members []applicationMember
+				for _, d := range drivers {
+					if inApplication(d.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.DriverKind, Name: *d.Name, Status: d.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteEventDriver(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.DriverTypeKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				driverTypes, err := eventClient.ListEventDriverTypes(ctx, org)
+				var members []applicationMember
+				for _, d := range driverTypes {
+					if inApplication(d.Tags, application) {
+						// Event driver types have no status, they are usable as soon as they exist
+						members = append(members, applicationMember{Kind: v1.DriverTypeKind, Name: *d.Name, Status: v1.StatusREADY})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteEventDriverType(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.FunctionKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				functions, err := fnClient.ListFunctions(ctx, org)
+				var members []applicationMember
+				for _, f := range functions {
+					if inApplication(f.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.FunctionKind, Name: f.Name, Status: f.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := fnClient.DeleteFunction(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.ServiceInstanceKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				instances, err := svcClient.ListServiceInstances(ctx, org)
+				var members []applicationMember
+				for _, i := range instances {
+					if inApplication(i.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.ServiceInstanceKind, Name: *i.Name, Status: i.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				return svcClient.DeleteServiceInstance(ctx, org, name)
+			},
+		},
+		{
+			kind: v1.ConfigurationKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				configs, err := cfgClient.ListConfigs(ctx, org)
+				var members []applicationMember
+				for _, c := range configs {
+					if inApplication(c.Tags, application) {
+						// Configs have no status, they are usable as soon as they exist
+						members = append(members, applicationMember{Kind: v1.ConfigurationKind, Name: c.Name, Status: v1.StatusREADY})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				return cfgClient.DeleteConfig(ctx, org, name)
+			},
+		},
+		{
+			kind: v1.ImageKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				images, err := imgClient.ListImages(ctx, org)
+				var members []applicationMember
+				for _, i := range images {
+					if inApplication(i.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.ImageKind, Name: i.Name, Status: i.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := imgClient.DeleteImage(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.BaseImageKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				baseImages, err := baseImgClient.ListBaseImages(ctx, org)
+				var members []applicationMember
+				for _, b := range baseImages {
+					if inApplication(b.Tags, application) {
+						members = append(members, applicationMember{Kind: v1.BaseImageKind, Name: b.Name, Status: b.Status})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				_, err := baseImgClient.DeleteBaseImage(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.SecretKind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				secrets, err := secClient.ListSecrets(ctx, org)
+				var members []applicationMember
+				for _, s := range secrets {
+					if inApplication(s.Tags, application) {
+						// Secrets have no status, they are usable as soon as they exist
+						members = append(members, applicationMember{Kind: v1.SecretKind, Name: *s.Name, Status: v1.StatusREADY})
+					}
+				}
+				return members, err
+			},
+			delete: func(ctx context.Context, name string) error {
+				return secClient.DeleteSecret(ctx, org, name)
+			},
+		},
+	}
+}
+
+func inApplication(tags []*v1.Tag, application string) bool {
+	for _, tag := range tags {
+		if tag.Key == applicationTag && tag.Value == application {
+			return true
+		}
+	}
+	return false
+}
+
+// listApplicationMembers returns the members of the application, grouped by kind
+func listApplicationMembers(ctx context.Context, kinds []applicationMemberKind, application string) ([]applicationMember, error) {
+	var members []applicationMember
+	for _, kind := range kinds {
+		found, err := kind.list(ctx, application)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, found...)
+	}
+	return members, nil
+}
+
+// deleteApplicationMembers deletes the members of the application kind by kind, it stops at the first error so the
+// remaining members can still be found through the application
+func deleteApplicationMembers(ctx context.Context, out io.Writer, kinds []applicationMemberKind, application string) error {
+	for _, kind := range kinds {
+		members, err := kind.list(ctx, application)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if err := kind.delete(ctx, member.Name); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Deleted %s: %s\n", member.Kind, member.Name)
+		}
+	}
+	return nil
+}
+
+// aggregateStatus sums up the status of the application members: ERROR if any member failed, otherwise the status
+// of the first member which is not READY, and the status of the application itself when all of them are
+func aggregateStatus(status v1.Status, members []applicationMember) v1.Status {
+	for _, member := range members {
+		if member.Status == v1.StatusERROR {
+			return v1.StatusERROR
+		}
+	}
+	for _, member := range members {
+		if member.Status != "" && member.Status != v1.StatusREADY {
+			return member.Status
+		}
+	}
+	return status
+}
@@ -0,0 +1,169 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+)
+
+// fakeMemberKinds serves the resources of a few kinds, recording the deletions
+type fakeMemberKinds struct {
+	resources map[string][]applicationMember
+	tags      map[string]string
+	deleted   []string
+	failOn    string
+}
+
+func (f *fakeMemberKinds) kinds(names ...string) []applicationMemberKind {
+	var kinds []applicationMemberKind
+	for _, name := range names {
+		kind := name
+		kinds = append(kinds, applicationMemberKind{
+			kind: kind,
+			list: func(ctx context.Context, application string) ([]applicationMember, error) {
+				var members []applicationMember
+				for _, m := range f.resources[kind] {
+					if f.tags[m.Name] == application {
+						members = append(members, m)
+					}
+				}
+				return members, nil
+			},
+			delete: func(ctx context.Context, name string) error {
+				if name == f.failOn {
+					return errors.New("in use")
+				}
+				f.deleted = append(f.deleted, kind+"/"+name)
+				return nil
+			},
+		})
+	}
+	return kinds
+}
+
+func newFakeMemberKinds() *fakeMemberKinds {
+	return &fakeMemberKinds{
+		resources: map[string][]applicationMember{
+			v1.SubscriptionKind: {{Kind: v1.SubscriptionKind, Name: "on-order", Status: v1.StatusREADY}},
+			v1.FunctionKind: {
+				{Kind: v1.FunctionKind, Name: "checkout", Status: v1.StatusREADY},
+				{Kind: v1.FunctionKind, Name: "blog-post", Status: v1.StatusREADY},
+			},
+			v1.SecretKind: {{Kind: v1.SecretKind, Name: "stripe", Status: v1.StatusREADY}},
+		},
+		tags: map[string]string{"on-order": "shop", "checkout": "shop", "stripe": "shop", "blog-post": "blog"},
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	members := []applicationMember{
+		{Kind: v1.FunctionKind, Name: "a", Status: v1.StatusREADY},
+		{Kind: v1.SecretKind, Name: "b", Status: ""},
+	}
+	assert.Equal(t, v1.StatusREADY, aggregateStatus(v1.StatusREADY, members))
+	assert.Equal(t, v1.StatusREADY, aggregateStatus(v1.StatusREADY, nil))
+
+	members = append(members, applicationMember{Kind: v1.ImageKind, Name: "c", Status: v1.StatusCREATING})
+	assert.Equal(t, v1.StatusCREATING, aggregateStatus(v1.StatusREADY, members))
+
+	members = append(members, applicationMember{Kind: v1.EndpointKind, Name: "d", Status: v1.StatusERROR})
+	assert.Equal(t, v1.StatusERROR, aggregateStatus(v1.StatusREADY, members))
+}
+
+func TestGetApplication(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cli := NewCLI(os.Stdin, &stdout, &stderr)
+	dispatchConfig.Output = "json"
+	defer func() { dispatchConfig.Output = "" }()
+
+	ac := &mocks.ApplicationsClient{}
+	app := &v1.Application{Name: swag.String("shop"), Status: v1.StatusREADY}
+	ac.On("GetApplication", mock.Anything, mock.Anything, "shop").Return(app, nil)
+
+	fake := newFakeMemberKinds()
+	fake.resources[v1.FunctionKind][0].Status = v1.StatusUPDATING
+	err := getApplication(&stdout, &stderr, cli, []string{"shop"}, ac, fake.kinds(v1.SubscriptionKind, v1.FunctionKind, v1.SecretKind))
+	require.NoError(t, err)
+
+	var details applicationDetails
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &details))
+	assert.Equal(t, "shop", *details.Application.Name)
+	assert.Equal(t, v1.StatusUPDATING, details.Status)
+	assert.Equal(t, []applicationMember{
+		{Kind: v1.SubscriptionKind, Name: "on-order", Status: v1.StatusREADY},
+		{Kind: v1.FunctionKind, Name: "checkout", Status: v1.StatusUPDATING},
+		{Kind: v1.SecretKind, Name: "stripe", Status: v1.StatusREADY},
+	}, details.Members)
+}
+
+func TestDeleteApplicationCascade(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cli := NewCLI(os.Stdin, &stdout, &stderr)
+
+	ac := &mocks.ApplicationsClient{}
+	app := &v1.Application{Name: swag.String("shop")}
+	ac.On("GetApplication", mock.Anything, mock.Anything, "shop").Return(app, nil)
+	ac.On("DeleteApplication", mock.Anything, mock.Anything, "shop").Once().Return(nil)
+
+	fake := newFakeMemberKinds()
+	kinds := fake.kinds(v1.SubscriptionKind, v1.FunctionKind, v1.SecretKind)
+	err := deleteApplication(&stdout, &stderr, cli, []string{"shop"}, ac, kinds)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Subscription/on-order", "Function/checkout", "Secret/stripe"}, fake.deleted)
+	assert.Contains(t, stdout.String(), "Deleted application: shop")
+	ac.AssertExpectations(t)
+}
+
+func TestDeleteApplicationCascadeError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cli := NewCLI(os.Stdin, &stdout, &stderr)
+
+	ac := &mocks.ApplicationsClient{}
+	app := &v1.Application{Name: swag.String("shop")}
+	ac.On("GetApplication", mock.Anything, mock.Anything, "shop").Return(app, nil)
+
+	fake := newFakeMemberKinds()
+	fake.failOn = "checkout"
+	kinds := fake.kinds(v1.SubscriptionKind, v1.FunctionKind, v1.SecretKind)
+	err := deleteApplication(&stdout, &stderr, cli, []string{"shop"}, ac, kinds)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"Subscription/on-order"}, fake.deleted)
+	ac.AssertNotCalled(t, "DeleteApplication", mock.Anything, mock.Anything, "shop")
+}
+
+func TestWithApplication(t *testing.T) {
+	cmdFlagApplication = ""
+	assert.Nil(t, withApplication(nil))
+
+	cmdFlagApplication = "shop"
+	defer func() { cmdFlagApplication = "" }()
+	assert.Equal(t, []*v1.Tag{{Key: "Application", Value: "shop"}}, withApplication(nil))
+	tags := []*v1.Tag{{Key: "team", Value: "web"}, {Key: "Application", Value: "blog"}}
+	assert.Equal(t, []*v1.Tag{{Key: "team", Value: "web"}, {Key: "Application", Value: "shop"}}, withApplication(tags))
+}
+
+func TestApplicationMemberKinds(t *testing.T) {
+	var kinds []string
+	for _, kind := range applicationMemberKinds() {
+		kinds = append(kinds, kind.kind)
+	}
+	assert.Equal(t, []string{
+		v1.SubscriptionKind, v1.EndpointKind, v1.DriverKind, v1.DriverTypeKind, v1.FunctionKind,
+		v1.ServiceInstanceKind, v1.ConfigurationKind, v1.ImageKind, v1.BaseImageKind, v1.SecretKind,
+	}, kinds)
+}
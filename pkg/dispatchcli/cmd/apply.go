@@ -0,0 +1,811 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	applyLong = i18n.T(`Apply a manifest: resources missing on the server are created, the ones which changed since they
+were last applied are updated, and with --prune the ones previously applied with the same label which are no longer
+in the manifest are deleted. The manifest is a YAML file, or a directory of them.`)
+
+	applyExample = i18n.T(`# Create or update the resources of a manifest, waiting for them to be ready
+dispatch apply -f ./shop.yaml --wait
+# Apply a directory of manifests, relative paths being based on ./shop
+dispatch apply -f ./shop/manifests -w ./shop
+# Label the applied resources, deleting the ones with the label which were removed from the manifest
+dispatch apply -f ./shop.yaml --label app=shop --prune`)
+
+	applyLabel = ""
+	applyPrune = false
+)
+
+// appliedTag is the tag key holding the hash of the manifest a resource was last applied from
+const appliedTag = "LastApplied"
+
+// appliedConfigTag is the tag key holding the fields a resource was last applied with, to tell the fields removed
+// from the manifest
+const appliedConfigTag = "LastAppliedConfig"
+
+const (
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyDelete    = "delete"
+	applyUnchanged = "unchanged"
+)
+
+// applyKind reads and writes the resources of one kind for apply. get returns nil when the resource does not exist.
+type applyKind struct {
+	kind   string
+	get    func(ctx context.Context, name string) (interface{}, error)
+	list   func(ctx context.Context) ([]interface{}, error)
+	create ModelAction
+	update ModelAction
+	delete func(ctx context.Context, name string) error
+}
+
+// applyChange is one step of an apply plan
+type applyChange struct {
+	Action string   `json:"action"`
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"`
+	// Drift is set when the manifest did not change since it was last applied but the live resource did
+	Drift bool `json:"drift,omitempty"`
+
+	model interface{}
+}
+
+// manifestDocument is a decoded document of a manifest, along with the hash it is applied with
+type manifestDocument struct {
+	kind  string
+	name  string
+	model interface{}
+	hash  string
+}
+
+// NewCmdApply creates command responsible for applying manifests.
+func NewCmdApply(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "apply -f FILE|DIR [--label KEY=VALUE [--prune]]",
+		Short:   i18n.T("Create, update and delete resources to match a manifest."),
+		Long:    applyLong,
+		Example: applyExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if file == "" {
+				runHelp(cmd, args)
+				return
+			}
+			err := runApply(out, errOut, applyKinds(), true)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file or directory of YAML files")
+	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
+	cmd.Flags().StringVarP(&applyLabel, "label", "l", "", "Label KEY=VALUE tagged on the applied resources")
+	cmd.Flags().BoolVar(&applyPrune, "prune", false, "Delete the resources with the label which are no longer in the manifest")
+	addWaitFlags(cmd.Flags())
+
+	return cmd
+}
+
+// runApply computes the plan for the manifest and prints it, then carries it out when execute is set
+func runApply(out, errOut io.Writer, kinds []applyKind, execute bool) error {
+	label, err := parseApplyLabel(applyLabel)
+	if err != nil {
+		return err
+	}
+	if applyPrune && label == nil {
+		return errors.New("--prune requires a --label to select the resources previously applied")
+	}
+	docs, err := readManifest(path.Join(workDir, file))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	plan, err := computePlan(ctx, kinds, docs, label, applyPrune)
+	if err != nil {
+		return err
+	}
+
+	planOut := out
+	if dispatchConfig.Output != "" {
+		planOut = errOut
+		if _, err := formatOutput(out, true, plan); err != nil {
+			return err
+		}
+	}
+	printPlan(planOut, plan)
+	if !execute {
+		return nil
+	}
+	return executePlan(ctx, planOut, kinds, plan)
+}
+
+func parseApplyLabel(label string) (*v1.Tag, error) {
+	if label == "" {
+		return nil, nil
+	}
+	kv := strings.SplitN(label, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return nil, errors.Errorf("invalid label %s, expected KEY=VALUE", label)
+	}
+	return &v1.Tag{Key: kv[0], Value: kv[1]}, nil
+}
+
+// readManifest decodes the documents of a manifest file, or of the .yaml and .yml files of a manifest directory in
+// lexical order
+func readManifest(manifestPath string) ([]*manifestDocument, error) {
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading manifest %s", manifestPath)
+	}
+	files := []string{manifestPath}
+	if info.IsDir() {
+		files = nil
+		entries, err := ioutil.ReadDir(manifestPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading manifest directory %s", manifestPath)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(manifestPath, entry.Name()))
+			}
+		}
+	}
+
+	var docs []*manifestDocument
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading file %s", f)
+		}
+		for _, doc := range splitDocuments(b) {
+			docKind, m, err := decodeDocument(doc)
+			if err != nil {
+				return nil, err
+			}
+			if m == nil {
+				if docKind != "" {
+					return nil, errors.Errorf("unknown kind %s in %s", docKind, f)
+				}
+				continue
+			}
+			d := &manifestDocument{kind: docKind, name: documentName(m), model: m}
+			if fn, ok := m.(*v1.Function); ok && fn.SourcePath != "" {
+				// The source archive holds the modification times of the files, hash their content instead
+				digest, err := sourceDigest(filepath.Join(workDir, fn.SourcePath))
+				if err != nil {
+					return nil, err
+				}
+				d.hash = digest
+			}
+			docs = append(docs, d)
+		}
+	}
+	return docs, nil
+}
+
+// sourceDigest hashes the names and content of the files of a function source
+func sourceDigest(sourcePath string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(sourcePath, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(sourcePath, p)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(content))
+		h.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "Error when reading content of %s", sourcePath)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// appliedHash hashes the model of a manifest document, the source digest of a function standing in for its archive
+func appliedHash(d *manifestDocument) (string, error) {
+	m := d.model
+	if fn, ok := m.(*v1.Function); ok && d.hash != "" {
+		copied := *fn
+		copied.Source = nil
+		m = &copied
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error encoding %s %s", d.kind, d.name)
+	}
+	h := sha256.New()
+	h.Write(b)
+	h.Write([]byte(d.hash))
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// computePlan compares the manifest with the live resources. Resources are created and updated in the order of the
+// kinds, so the resources they depend on exist first, and pruned in the reverse order.
+func computePlan(ctx context.Context, kinds []applyKind, docs []*manifestDocument, label *v1.Tag, prune bool) ([]*applyChange, error) {
+	byKind := make(map[string][]*manifestDocument)
+	seen := make(map[string]bool)
+	for _, d := range docs {
+		if !applySupported(kinds, d.kind) {
+			return nil, errors.Errorf("kind %s is not supported by apply", d.kind)
+		}
+		key := d.kind + "/" + d.name
+		if seen[key] {
+			return nil, errors.Errorf("%s %s appears more than once in the manifest", d.kind, d.name)
+		}
+		seen[key] = true
+		byKind[d.kind] = append(byKind[d.kind], d)
+	}
+
+	var plan []*applyChange
+	for _, kind := range kinds {
+		for _, d := range byKind[kind.kind] {
+			tags := modelTags(d.model)
+			if label != nil {
+				*tags = setTag(*tags, label.Key, label.Value)
+			}
+			hash, err := appliedHash(d)
+			if err != nil {
+				return nil, err
+			}
+			config, err := appliedConfig(d.model)
+			if err != nil {
+				return nil, err
+			}
+			*tags = setTag(*tags, appliedTag, hash)
+			*tags = setTag(*tags, appliedConfigTag, config)
+
+			change := &applyChange{Kind: d.kind, Name: d.name, model: d.model}
+			live, err := kind.get(ctx, d.name)
+			if err != nil {
+				return nil, err
+			}
+			if live == nil {
+				change.Action = applyCreate
+				plan = append(plan, change)
+				continue
+			}
+			liveTags := *modelTags(live)
+			if change.Fields, err = changedFields(d.model, tagValue(liveTags, appliedConfigTag), live); err != nil {
+				return nil, err
+			}
+			switch {
+			case tagValue(liveTags, appliedTag) != hash:
+				change.Action = applyUpdate
+			case len(change.Fields) > 0:
+				change.Action = applyUpdate
+				change.Drift = true
+			default:
+				change.Action = applyUnchanged
+			}
+			plan = append(plan, change)
+		}
+	}
+	if !prune {
+		return plan, nil
+	}
+
+	for i := len(kinds) - 1; i >= 0; i-- {
+		kind := kinds[i]
+		live, err := kind.list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var deleted []*applyChange
+		for _, m := range live {
+			tags := *modelTags(m)
+			name := documentName(m)
+			if tagValue(tags, appliedTag) == "" || tagValue(tags, label.Key) != label.Value || seen[kind.kind+"/"+name] {
+				continue
+			}
+			deleted = append(deleted, &applyChange{Action: applyDelete, Kind: kind.kind, Name: name})
+		}
+		sort.Slice(deleted, func(i, j int) bool { return deleted[i].Name < deleted[j].Name })
+		plan = append(plan, deleted...)
+	}
+	return plan, nil
+}
+
+func applySupported(kinds []applyKind, kind string) bool {
+	for _, k := range kinds {
+		if k.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredField tells the fields left out of the comparison with the live resource. The live resources do not hold
+// tags and function sources as they were applied, and secret values are not recorded in tags.
+func ignoredField(key string) bool {
+	return key == "tags" || key == "source" || key == "secrets"
+}
+
+// appliedConfig encodes the fields set in the manifest model, to be recorded in the appliedConfigTag
+func appliedConfig(model interface{}) (string, error) {
+	fields, err := toFields(model)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range fields {
+		if ignoredField(key) || isZeroField(value) {
+			delete(fields, key)
+		}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// changedFields lists the fields to update on the live resource, comparing the manifest model, the config it was last
+// applied with and the live resource: the fields set in the manifest which differ from the live resource, whether
+// they were changed in the manifest or on the server, and the fields removed from the manifest which are still set.
+// Fields the live resource does not return can't be compared and are left out.
+func changedFields(model interface{}, lastApplied string, live interface{}) ([]string, error) {
+	wanted, err := toFields(model)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := toFields(live)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]interface{})
+	if lastApplied != "" {
+		// A config which can't be decoded only loses the fields removed from the manifest
+		json.Unmarshal([]byte(lastApplied), &last)
+	}
+	var fields []string
+	for key, value := range wanted {
+		current, ok := actual[key]
+		if ignoredField(key) || isZeroField(value) || !ok {
+			continue
+		}
+		if !reflect.DeepEqual(value, current) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range last {
+		if ignoredField(key) || !isZeroField(wanted[key]) || isZeroField(actual[key]) {
+			continue
+		}
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func toFields(m interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	return fields, json.Unmarshal(b, &fields)
+}
+
+func isZeroField(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// modelTags returns the tags of the models apply supports
+func modelTags(m interface{}) *[]*v1.Tag {
+	switch m := m.(type) {
+	case *v1.Application:
+		return &m.Tags
+	case *v1.Secret:
+		return &m.Tags
+	case *v1.Configuration:
+		return &m.Tags
+	case *v1.BaseImage:
+		return &m.Tags
+	case *v1.Image:
+		return &m.Tags
+	case *v1.Function:
+		return &m.Tags
+	case *v1.Endpoint:
+		return &m.Tags
+	case *v1.EventDriverType:
+		return &m.Tags
+	case *v1.EventDriver:
+		return &m.Tags
+	case *v1.Subscription:
+		return &m.Tags
+	}
+	return &[]*v1.Tag{}
+}
+
+func setTag(tags []*v1.Tag, key, value string) []*v1.Tag {
+	for _, tag := range tags {
+		if tag.Key == key {
+			tag.Value = value
+			return tags
+		}
+	}
+	return append(tags, &v1.Tag{Key: key, Value: value})
+}
+
+func tagValue(tags []*v1.Tag, key string) string {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+func printPlan(out io.Writer, plan []*applyChange) {
+	counts := make(map[string]int)
+	for _, change := range plan {
+		counts[change.Action]++
+		switch change.Action {
+		case applyCreate:
+			fmt.Fprintf(out, "+ %s: %s\n", change.Kind, change.Name)
+		case applyUpdate:
+			var details []string
+			if len(change.Fields) > 0 {
+				details = append(details, strings.Join(change.Fields, ", "))
+			}
+			if change.Drift {
+				details = append(details, "changed on the server")
+			}
+			if len(details) > 0 {
+				fmt.Fprintf(out, "~ %s: %s (%s)\n", change.Kind, change.Name, strings.Join(details, "; "))
+			} else {
+				fmt.Fprintf(out, "~ %s: %s\n", change.Kind, change.Name)
+			}
+		case applyDelete:
+			fmt.Fprintf(out, "- %s: %s\n", change.Kind, change.Name)
+		default:
+			fmt.Fprintf(out, "  %s: %s\n", change.Kind, change.Name)
+		}
+	}
+	fmt.Fprintf(out, "Plan: %d to create, %d to update, %d to delete, %d unchanged\n",
+		counts[applyCreate], counts[applyUpdate], counts[applyDelete], counts[applyUnchanged])
+}
+
+// executePlan carries out the changes of the plan in order, it stops at the first error so applying again picks up
+// where it failed
+func executePlan(ctx context.Context, out io.Writer, kinds []applyKind, plan []*applyChange) error {
+	byKind := make(map[string]applyKind)
+	for _, kind := range kinds {
+		byKind[kind.kind] = kind
+	}
+	for _, change := range plan {
+		kind := byKind[change.Kind]
+		var err error
+		switch change.Action {
+		case applyCreate:
+			err = kind.create(change.model)
+			if err == nil {
+				fmt.Fprintf(out, "Created %s: %s\n", change.Kind, change.Name)
+			}
+		case applyUpdate:
+			err = kind.update(change.model)
+			if err == nil {
+				fmt.Fprintf(out, "Updated %s: %s\n", change.Kind, change.Name)
+			}
+		case applyDelete:
+			err = kind.delete(ctx, change.Name)
+			if err == nil {
+				fmt.Fprintf(out, "Deleted %s: %s\n", change.Kind, change.Name)
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error applying %s %s", change.Kind, change.Name)
+		}
+	}
+	return nil
+}
+
+func notFound(err error) bool {
+	_, ok := err.(*client.ErrorNotFound)
+	return ok
+}
+
+// applyKinds returns the kinds apply supports, in the order they are created: a resource comes after the ones it
+// refers to
+func applyKinds() []applyKind {
+	appClient := applicationsClient()
+	secClient := secretsClient()
+	cfgClient := configsClient()
+	baseImgClient := baseImagesClient()
+	imgClient := imagesClient()
+	fnClient := functionsClient()
+	endpointClient := endpointsClient()
+	eventClient := eventManagerClient()
+	org := dispatchConfig.Organization
+
+	return []applyKind{
+		{
+			kind: v1.ApplicationKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := appClient.GetApplication(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := appClient.ListApplications(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateApplication(appClient),
+			update: CallUpdateApplication(appClient),
+			delete: func(ctx context.Context, name string) error {
+				return appClient.DeleteApplication(ctx, org, name)
+			},
+		},
+		{
+			kind: v1.SecretKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := secClient.GetSecret(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := secClient.ListSecrets(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateSecret(secClient),
+			update: CallUpdateSecret(secClient),
+			delete: func(ctx context.Context, name string) error {
+				return secClient.DeleteSecret(ctx, org, name)
+			},
+		},
+		{
+			kind: v1.ConfigurationKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := cfgClient.GetConfig(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := cfgClient.ListConfigs(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateConfig(cfgClient),
+			update: CallUpdateConfig(cfgClient),
+			delete: func(ctx context.Context, name string) error {
+				return cfgClient.DeleteConfig(ctx, org, name)
+			},
+		},
+		{
+			kind: v1.BaseImageKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := baseImgClient.GetBaseImage(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := baseImgClient.ListBaseImages(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateBaseImage(baseImgClient),
+			update: CallUpdateBaseImage(baseImgClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := baseImgClient.DeleteBaseImage(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.ImageKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := imgClient.GetImage(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := imgClient.ListImages(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateImage(imgClient),
+			update: CallUpdateImage(imgClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := imgClient.DeleteImage(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.FunctionKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := fnClient.GetFunction(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := fnClient.ListFunctions(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateFunction(fnClient),
+			update: CallUpdateFunction(fnClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := fnClient.DeleteFunction(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.EndpointKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := endpointClient.GetEndpoint(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := endpointClient.ListEndpoints(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateEndpoint(endpointClient),
+			update: CallUpdateEndpoint(endpointClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := endpointClient.DeleteEndpoint(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.DriverTypeKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := eventClient.GetEventDriverType(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := eventClient.ListEventDriverTypes(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateEventDriverType(eventClient),
+			update: CallUpdateDriverType(eventClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteEventDriverType(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.DriverKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := eventClient.GetEventDriver(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := eventClient.ListEventDrivers(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateEventDriver(eventClient),
+			update: CallUpdateDriver(eventClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteEventDriver(ctx, org, name)
+				return err
+			},
+		},
+		{
+			kind: v1.SubscriptionKind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				m, err := eventClient.GetSubscription(ctx, org, name)
+				if notFound(err) {
+					return nil, nil
+				}
+				return m, err
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				l, err := eventClient.ListSubscriptions(ctx, org)
+				var models []interface{}
+				for i := range l {
+					models = append(models, &l[i])
+				}
+				return models, err
+			},
+			create: CallCreateSubscription(eventClient),
+			update: CallUpdateSubscription(eventClient),
+			delete: func(ctx context.Context, name string) error {
+				_, err := eventClient.DeleteSubscription(ctx, org, name)
+				return err
+			},
+		},
+	}
+}
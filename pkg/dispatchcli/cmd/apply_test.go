@@ -0,0 +1,257 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// fakeApplyServer holds the resources of a few kinds in memory, recording the calls made
+type fakeApplyServer struct {
+	resources map[string]map[string][]byte
+	calls     []string
+}
+
+func (f *fakeApplyServer) kinds() []applyKind {
+	newModels := map[string]func() interface{}{
+		v1.SecretKind:        func() interface{} { return &v1.Secret{} },
+		v1.ConfigurationKind: func() interface{} { return &v1.Configuration{} },
+	}
+	var kinds []applyKind
+	for _, name := range []string{v1.SecretKind, v1.ConfigurationKind} {
+		kind := name
+		decode := func(b []byte) interface{} {
+			m := newModels[kind]()
+			json.Unmarshal(b, m)
+			return m
+		}
+		store := func(m interface{}) error {
+			b, err := json.Marshal(m)
+			f.resources[kind][documentName(m)] = b
+			return err
+		}
+		kinds = append(kinds, applyKind{
+			kind: kind,
+			get: func(ctx context.Context, name string) (interface{}, error) {
+				if b, ok := f.resources[kind][name]; ok {
+					return decode(b), nil
+				}
+				return nil, nil
+			},
+			list: func(ctx context.Context) ([]interface{}, error) {
+				var models []interface{}
+				for _, b := range f.resources[kind] {
+					models = append(models, decode(b))
+				}
+				return models, nil
+			},
+			create: func(m interface{}) error {
+				f.calls = append(f.calls, "create "+kind+"/"+documentName(m))
+				return store(m)
+			},
+			update: func(m interface{}) error {
+				f.calls = append(f.calls, "update "+kind+"/"+documentName(m))
+				return store(m)
+			},
+			delete: func(ctx context.Context, name string) error {
+				f.calls = append(f.calls, "delete "+kind+"/"+name)
+				delete(f.resources[kind], name)
+				return nil
+			},
+		})
+	}
+	return kinds
+}
+
+func newFakeApplyServer() *fakeApplyServer {
+	return &fakeApplyServer{
+		resources: map[string]map[string][]byte{
+			v1.SecretKind:        {},
+			v1.ConfigurationKind: {},
+		},
+	}
+}
+
+func writeManifest(t *testing.T, dir, name, content string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func resetApplyFlags(dir string) {
+	file = dir
+	workDir = ""
+	applyLabel = "app=shop"
+	applyPrune = true
+	dispatchConfig.Output = ""
+}
+
+func TestApplyManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	resetApplyFlags(dir)
+
+	writeManifest(t, dir, "a.yaml", `
+kind: Configuration
+name: settings
+values:
+  color: blue
+---
+kind: Secret
+name: stripe
+secrets:
+  key: abc
+`)
+	writeManifest(t, dir, "b.yml", `
+kind: Configuration
+name: limits
+values:
+  max: "10"
+`)
+	writeManifest(t, dir, "README.md", "not a manifest")
+
+	server := newFakeApplyServer()
+	var out, errOut bytes.Buffer
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	// Secrets are created before configurations, in the order of the kinds
+	assert.Equal(t, []string{"create Secret/stripe", "create Configuration/settings", "create Configuration/limits"}, server.calls)
+	assert.Contains(t, out.String(), "+ Configuration: settings\n")
+	assert.Contains(t, out.String(), "Plan: 3 to create, 0 to update, 0 to delete, 0 unchanged\n")
+	assert.Contains(t, out.String(), "Created Secret: stripe\n")
+
+	// Applying the same manifest again changes nothing
+	server.calls = nil
+	out.Reset()
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Empty(t, server.calls)
+	assert.Contains(t, out.String(), "Plan: 0 to create, 0 to update, 0 to delete, 3 unchanged\n")
+
+	// A changed resource is updated, and the one removed from the manifest pruned
+	writeManifest(t, dir, "a.yaml", `
+kind: Configuration
+name: settings
+values:
+  color: red
+`)
+	out.Reset()
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), false))
+	assert.Empty(t, server.calls)
+	assert.Contains(t, out.String(), "~ Configuration: settings (values)\n")
+	assert.Contains(t, out.String(), "- Secret: stripe\n")
+	assert.Contains(t, out.String(), "  Configuration: limits\n")
+
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Equal(t, []string{"update Configuration/settings", "delete Secret/stripe"}, server.calls)
+}
+
+func TestApplyDrift(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	resetApplyFlags(dir)
+	writeManifest(t, dir, "a.yaml", "kind: Configuration\nname: settings\nvalues:\n  color: blue\n")
+
+	server := newFakeApplyServer()
+	var out, errOut bytes.Buffer
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+
+	// The values changed on the server are put back, although the manifest did not change
+	live, err := server.kinds()[1].get(context.Background(), "settings")
+	require.NoError(t, err)
+	live.(*v1.Configuration).Values = map[string]string{"color": "green"}
+	b, err := json.Marshal(live)
+	require.NoError(t, err)
+	server.resources[v1.ConfigurationKind]["settings"] = b
+	server.calls = nil
+	out.Reset()
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Contains(t, out.String(), "~ Configuration: settings (values; changed on the server)\n")
+	assert.Equal(t, []string{"update Configuration/settings"}, server.calls)
+
+	// Values removed from the manifest are removed from the server
+	writeManifest(t, dir, "a.yaml", "kind: Configuration\nname: settings\n")
+	server.calls = nil
+	out.Reset()
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Contains(t, out.String(), "~ Configuration: settings (values)\n")
+	assert.Equal(t, []string{"update Configuration/settings"}, server.calls)
+
+	server.calls = nil
+	out.Reset()
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Empty(t, server.calls)
+	assert.Contains(t, out.String(), "Plan: 0 to create, 0 to update, 0 to delete, 1 unchanged\n")
+}
+
+func TestApplyPruneLabel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	resetApplyFlags(dir)
+	writeManifest(t, dir, "a.yaml", "kind: Configuration\nname: settings\n")
+
+	server := newFakeApplyServer()
+	// Created by hand, and applied with another label: neither is pruned
+	server.resources[v1.ConfigurationKind]["manual"] = []byte(`{"name": "manual"}`)
+	server.resources[v1.ConfigurationKind]["blog"] = []byte(`{"name": "blog", "tags": [{"key": "app", "value": "blog"}, {"key": "LastApplied", "value": "1234"}]}`)
+	server.resources[v1.ConfigurationKind]["old"] = []byte(`{"name": "old", "tags": [{"key": "app", "value": "shop"}, {"key": "LastApplied", "value": "1234"}]}`)
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Equal(t, []string{"create Configuration/settings", "delete Configuration/old"}, server.calls)
+
+	applyLabel = ""
+	assert.Error(t, runApply(&out, &errOut, server.kinds(), true))
+}
+
+func TestApplyManifestErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	resetApplyFlags(dir)
+	server := newFakeApplyServer()
+	var out, errOut bytes.Buffer
+
+	writeManifest(t, dir, "a.yaml", "kind: Function\nname: hello\n")
+	assert.Error(t, runApply(&out, &errOut, server.kinds(), true))
+
+	writeManifest(t, dir, "a.yaml", "kind: Secret\nname: stripe\n---\nkind: Secret\nname: stripe\n")
+	assert.Error(t, runApply(&out, &errOut, server.kinds(), true))
+
+	writeManifest(t, dir, "a.yaml", "kind: Widget\nname: stripe\n")
+	assert.Error(t, runApply(&out, &errOut, server.kinds(), true))
+	assert.Empty(t, server.calls)
+}
+
+func TestSourceDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeManifest(t, dir, "handler.py", "def handle(ctx, payload):\n    return {}\n")
+
+	digest, err := sourceDigest(dir)
+	require.NoError(t, err)
+	// Touching the file keeps the digest, changing it does not
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "handler.py"), time.Unix(0, 0), time.Unix(0, 0)))
+	touched, err := sourceDigest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, digest, touched)
+
+	writeManifest(t, dir, "handler.py", "def handle(ctx, payload):\n    return None\n")
+	changed, err := sourceDigest(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, changed)
+}
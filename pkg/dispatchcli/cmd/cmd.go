@@ -186,8 +186,13 @@ func NewCLI(in io.Reader, out, errOut io.Writer) *cobra.Command {
 	cmds.AddCommand(NewCmdGet(out, errOut))
 	cmds.AddCommand(NewCmdCreate(out, errOut))
 	cmds.AddCommand(NewCmdUpdate(out, errOut))
+	cmds.AddCommand(NewCmdApply(out, errOut))
+	cmds.AddCommand(NewCmdDiff(out, errOut))
+	cmds.AddCommand(NewCmdWait(out, errOut))
 	cmds.AddCommand(NewCmdExec(in, out, errOut))
 	cmds.AddCommand(NewCmdDelete(out, errOut))
+	cmds.AddCommand(NewCmdRollback(out, errOut))
+	cmds.AddCommand(NewCmdReplay(out, errOut))
 	cmds.AddCommand(NewCmdLogin(in, out, errOut))
 	cmds.AddCommand(NewCmdLogout(in, out, errOut))
 	cmds.AddCommand(NewCmdEmit(out, errOut))
@@ -30,11 +30,30 @@ var (
 	createExample = i18n.T(``)
 	file          = i18n.T(``)
 	workDir       = i18n.T(``)
+
+	cmdFlagApplication = ""
 )
 
+// applicationTag is the tag key marking the resources which belong to an application
+const applicationTag = "Application"
+
 // ModelAction is the function type for CLI actions
 type ModelAction func(interface{}) error
 
+// withApplication adds the application given with --application to the tags of a resource being created
+func withApplication(tags []*v1.Tag) []*v1.Tag {
+	if cmdFlagApplication == "" {
+		return tags
+	}
+	for _, tag := range tags {
+		if tag.Key == applicationTag {
+			tag.Value = cmdFlagApplication
+			return tags
+		}
+	}
+	return append(tags, &v1.Tag{Key: applicationTag, Value: cmdFlagApplication})
+}
+
 type importFunction struct {
 	v1.Function
 }
@@ -63,198 +82,190 @@ func importFile(out io.Writer, errOut io.Writer, cmd *cobra.Command, args []stri
 }
 
 func importBytes(out io.Writer, b []byte, actionMap map[string]ModelAction, actionName string) error {
+	for _, doc := range splitDocuments(b) {
+		docKind, m, err := decodeDocument(doc)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			continue
+		}
+		action, ok := actionMap[docKind]
+		if !ok {
+			// Some kinds can't be updated, e.g. service instances
+			fmt.Fprintf(out, "Skipped %s: %s\n", docKind, documentName(m))
+			continue
+		}
+		err = action(m)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, documentName(m))
+	}
+	return nil
+}
 
-	var err error
-
-	// Manually split up the yaml doc.  This is NOT a streaming parser.
-	docs := bytes.Split(b, []byte("---"))
+// splitDocuments manually splits up the yaml doc.  This is NOT a streaming parser.
+func splitDocuments(b []byte) [][]byte {
+	return bytes.Split(b, []byte("---"))
+}
 
+// decodeDocument decodes a document of a manifest into the model of its kind, reading the files it refers to. The
+// model is nil for unknown kinds.
+func decodeDocument(doc []byte) (string, interface{}, error) {
 	type kind struct {
 		Kind string `json:"kind"`
 	}
 
-	type output struct {
-		Endpoints        []*v1.Endpoint        `json:"endpoint"`
-		BaseImages       []*v1.BaseImage       `json:"baseImages"`
-		Images           []*v1.Image           `json:"images"`
-		DriverTypes      []*v1.EventDriverType `json:"driverTypes"`
-		Drivers          []*v1.EventDriver     `json:"drivers"`
-		Subscriptions    []*v1.Subscription    `json:"subscriptions"`
-		Functions        []*v1.Function        `json:"functions"`
-		Secrets          []*v1.Secret          `json:"secrets"`
-		Policies         []*v1.Policy          `json:"policies"`
-		ServiceInstances []*v1.ServiceInstance `json:"serviceInstances"`
-		ServiceAccounts  []*v1.ServiceAccount  `json:"serviceaccounts"`
-		Organizations    []*v1.Organization    `json:"organizations"`
+	k := &kind{}
+	if err := yaml.Unmarshal(doc, k); err != nil {
+		return "", nil, errors.Wrapf(err, "Error decoding document %s", doc)
 	}
-
-	o := output{}
-
-	for _, doc := range docs {
-		k := &kind{}
-		err = yaml.Unmarshal(doc, k)
-		if err != nil {
-			return errors.Wrapf(err, "Error decoding document %s", doc)
+	switch docKind := k.Kind; docKind {
+	case v1.EndpointKind:
+		m := &v1.Endpoint{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding api document %s", doc)
 		}
-		switch docKind := k.Kind; docKind {
-		case v1.EndpointKind:
-			m := &v1.Endpoint{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding api document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Endpoints = append(o.Endpoints, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, m.Name)
-		case v1.BaseImageKind:
-			m := &v1.BaseImage{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding base image document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.BaseImages = append(o.BaseImages, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, m.Name)
-		case v1.ImageKind:
-			m := &v1.Image{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding image document %s", doc)
-			}
-			if m.RuntimeDependencies != nil {
-				manifest, err := resolveFileReference(m.RuntimeDependencies.Manifest)
-				if err != nil {
-					return err
-				}
-				m.RuntimeDependencies.Manifest = manifest
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Images = append(o.Images, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, m.Name)
-		case v1.FunctionKind:
-			m := &v1.Function{}
-			if err := yaml.Unmarshal(doc, m); err != nil {
-				return errors.Wrapf(err, "Error decoding function document %s", doc)
-			}
-			if m.SourcePath != "" {
-				sourcePath := filepath.Join(workDir, m.SourcePath)
-				isDir, err := utils.IsDir(sourcePath)
-				if err != nil {
-					return err
-				}
-				if isDir && m.Handler == "" {
-					return fmt.Errorf("error creating function %s: handler is required, source path %s is a directory", m.Name, sourcePath)
-				}
-				sourceTarGz, err := utils.TarGzBytes(sourcePath)
-				if err != nil {
-					return errors.Wrapf(err, "Error when reading content of %s", sourcePath)
-				}
-				m.Source = sourceTarGz
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Functions = append(o.Functions, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, m.Name)
-		case v1.DriverTypeKind:
-			m := &v1.EventDriverType{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error when decoding driver type document of %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.DriverTypes = append(o.DriverTypes, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.DriverKind:
-			m := &v1.EventDriver{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding driver document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Drivers = append(o.Drivers, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.SubscriptionKind:
-			m := &v1.Subscription{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding subscription document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Subscriptions = append(o.Subscriptions, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.SecretKind:
-			m := &v1.Secret{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding secret document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Secrets = append(o.Secrets, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.PolicyKind:
-			m := &v1.Policy{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding policy document %s", doc)
-			}
-			err = actionMap[docKind](m)
-			if err != nil {
-				return err
-			}
-			o.Policies = append(o.Policies, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.ServiceAccountKind:
-			m := &v1.ServiceAccount{}
-			err = yaml.Unmarshal(doc, m)
+		return docKind, m, nil
+	case v1.BaseImageKind:
+		m := &v1.BaseImage{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding base image document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.ImageKind:
+		m := &v1.Image{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding image document %s", doc)
+		}
+		if m.RuntimeDependencies != nil {
+			manifest, err := resolveFileReference(m.RuntimeDependencies.Manifest)
 			if err != nil {
-				return errors.Wrapf(err, "Error decoding service account document %s", doc)
+				return "", nil, err
 			}
-			err = actionMap[docKind](m)
+			m.RuntimeDependencies.Manifest = manifest
+		}
+		return docKind, m, nil
+	case v1.FunctionKind:
+		m := &v1.Function{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding function document %s", doc)
+		}
+		if m.SourcePath != "" {
+			sourcePath := filepath.Join(workDir, m.SourcePath)
+			isDir, err := utils.IsDir(sourcePath)
 			if err != nil {
-				return err
+				return "", nil, err
 			}
-			o.ServiceAccounts = append(o.ServiceAccounts, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		case v1.OrganizationKind:
-			m := &v1.Organization{}
-			err = yaml.Unmarshal(doc, m)
-			if err != nil {
-				return errors.Wrapf(err, "Error decoding organization document %s", doc)
+			if isDir && m.Handler == "" {
+				return "", nil, fmt.Errorf("error creating function %s: handler is required, source path %s is a directory", m.Name, sourcePath)
 			}
-			err = actionMap[docKind](m)
+			sourceTarGz, err := utils.TarGzBytes(sourcePath)
 			if err != nil {
-				return err
+				return "", nil, errors.Wrapf(err, "Error when reading content of %s", sourcePath)
 			}
-			o.Organizations = append(o.Organizations, m)
-			fmt.Fprintf(out, "%s %s: %s\n", actionName, docKind, *m.Name)
-		default:
-			continue
+			m.Source = sourceTarGz
+		}
+		return docKind, m, nil
+	case v1.DriverTypeKind:
+		m := &v1.EventDriverType{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error when decoding driver type document of %s", doc)
+		}
+		return docKind, m, nil
+	case v1.DriverKind:
+		m := &v1.EventDriver{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding driver document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.SubscriptionKind:
+		m := &v1.Subscription{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding subscription document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.SecretKind:
+		m := &v1.Secret{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding secret document %s", doc)
 		}
+		return docKind, m, nil
+	case v1.ConfigurationKind:
+		m := &v1.Configuration{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding config document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.ApplicationKind:
+		m := &v1.Application{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding application document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.ServiceInstanceKind:
+		m := &v1.ServiceInstance{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding service instance document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.PolicyKind:
+		m := &v1.Policy{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding policy document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.ServiceAccountKind:
+		m := &v1.ServiceAccount{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding service account document %s", doc)
+		}
+		return docKind, m, nil
+	case v1.OrganizationKind:
+		m := &v1.Organization{}
+		if err := yaml.Unmarshal(doc, m); err != nil {
+			return "", nil, errors.Wrapf(err, "Error decoding organization document %s", doc)
+		}
+		return docKind, m, nil
+	default:
+		return docKind, nil, nil
 	}
-	return nil
+}
+
+// documentName returns the name of a model decoded by decodeDocument
+func documentName(m interface{}) string {
+	switch m := m.(type) {
+	case *v1.Endpoint:
+		return m.Name
+	case *v1.BaseImage:
+		return m.Name
+	case *v1.Image:
+		return m.Name
+	case *v1.Function:
+		return m.Name
+	case *v1.Configuration:
+		return m.Name
+	case *v1.EventDriverType:
+		return *m.Name
+	case *v1.EventDriver:
+		return *m.Name
+	case *v1.Subscription:
+		return *m.Name
+	case *v1.Secret:
+		return *m.Name
+	case *v1.Application:
+		return *m.Name
+	case *v1.ServiceInstance:
+		return *m.Name
+	case *v1.Policy:
+		return *m.Name
+	case *v1.ServiceAccount:
+		return *m.Name
+	case *v1.Organization:
+		return *m.Name
+	}
+	return ""
 }
 
 var createMap map[string]ModelAction
@@ -266,20 +277,26 @@ func initCreateMap() {
 	eventClient := eventManagerClient()
 	endpointClient := endpointsClient()
 	secClient := secretsClient()
+	cfgClient := configsClient()
+	svcClient := servicesClient()
+	appClient := applicationsClient()
 	iamClient := identityManagerClient()
 
 	createMap = map[string]ModelAction{
-		v1.ImageKind:          CallCreateImage(imgClient),
-		v1.BaseImageKind:      CallCreateBaseImage(baseImgClient),
-		v1.FunctionKind:       CallCreateFunction(fnClient),
-		v1.SecretKind:         CallCreateSecret(secClient),
-		v1.PolicyKind:         CallCreatePolicy(iamClient),
-		v1.ServiceAccountKind: CallCreateServiceAccount(iamClient),
-		v1.DriverTypeKind:     CallCreateEventDriverType(eventClient),
-		v1.DriverKind:         CallCreateEventDriver(eventClient),
-		v1.SubscriptionKind:   CallCreateSubscription(eventClient),
-		v1.EndpointKind:       CallCreateEndpoint(endpointClient),
-		v1.OrganizationKind:   callCreateOrganization(iamClient),
+		v1.ImageKind:           CallCreateImage(imgClient),
+		v1.BaseImageKind:       CallCreateBaseImage(baseImgClient),
+		v1.FunctionKind:        CallCreateFunction(fnClient),
+		v1.SecretKind:          CallCreateSecret(secClient),
+		v1.ConfigurationKind:   CallCreateConfig(cfgClient),
+		v1.ServiceInstanceKind: CallCreateServiceInstance(svcClient),
+		v1.ApplicationKind:     CallCreateApplication(appClient),
+		v1.PolicyKind:          CallCreatePolicy(iamClient),
+		v1.ServiceAccountKind:  CallCreateServiceAccount(iamClient),
+		v1.DriverTypeKind:      CallCreateEventDriverType(eventClient),
+		v1.DriverKind:          CallCreateEventDriver(eventClient),
+		v1.SubscriptionKind:    CallCreateSubscription(eventClient),
+		v1.EndpointKind:        CallCreateEndpoint(endpointClient),
+		v1.OrganizationKind:    callCreateOrganization(iamClient),
 	}
 }
 
@@ -307,11 +324,16 @@ func NewCmdCreate(out io.Writer, errOut io.Writer) *cobra.Command {
 
 	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file")
 	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
+	cmd.PersistentFlags().StringVarP(&cmdFlagApplication, "application", "a", "", "Application the created resources belong to")
+	addWaitFlags(cmd.PersistentFlags())
 
 	cmd.AddCommand(NewCmdCreateBaseImage(out, errOut))
 	cmd.AddCommand(NewCmdCreateImage(out, errOut))
 	cmd.AddCommand(NewCmdCreateFunction(out, errOut))
 	cmd.AddCommand(NewCmdCreateSecret(out, errOut))
+	cmd.AddCommand(NewCmdCreateConfig(out, errOut))
+	cmd.AddCommand(NewCmdCreateServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdCreateApplication(out, errOut))
 	cmd.AddCommand(NewCmdCreateAPI(out, errOut))
 	cmd.AddCommand(NewCmdCreateSubscription(out, errOut))
 	cmd.AddCommand(NewCmdCreateEventDriver(out, errOut))
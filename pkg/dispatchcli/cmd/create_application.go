@@ -0,0 +1,76 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	createApplicationLong = i18n.T(`Create an application.
+	Resources are added to the application by creating them with --application.`)
+
+	createApplicationExample = i18n.T(`dispatch create application shop
+dispatch create function --application shop --image=python3 checkout ./checkout.py`)
+)
+
+// NewCmdCreateApplication creates command responsible for application creation.
+func NewCmdCreateApplication(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "application APPLICATION_NAME",
+		Short:   i18n.T("Create application"),
+		Long:    createApplicationLong,
+		Example: createApplicationExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"app"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := applicationsClient()
+			err := createApplication(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallCreateApplication makes the API call to create an application
+func CallCreateApplication(c client.ApplicationsClient) ModelAction {
+	return func(a interface{}) error {
+		applicationModel := a.(*v1.Application)
+
+		created, err := c.CreateApplication(context.TODO(), dispatchConfig.Organization, applicationModel)
+		if err != nil {
+			return err
+		}
+
+		*applicationModel = *created
+		return nil
+	}
+}
+
+func createApplication(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ApplicationsClient) error {
+	body := &v1.Application{
+		Name: swag.String(args[0]),
+	}
+
+	err := CallCreateApplication(c)(body)
+	if err != nil {
+		return err
+	}
+	if w, err := formatOutput(out, false, body); w {
+		return err
+	}
+	fmt.Fprintf(out, "Created application: %s\n", *body.Name)
+	return nil
+}
@@ -51,13 +51,14 @@ func NewCmdCreateBaseImage(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateBaseImage(c client.BaseImagesClient) ModelAction {
 	return func(bi interface{}) error {
 		baseImage := bi.(*v1.BaseImage)
+		baseImage.Tags = withApplication(baseImage.Tags)
 
 		created, err := c.CreateBaseImage(context.TODO(), dispatchConfig.Organization, baseImage)
 		if err != nil {
 			return err
 		}
 		*baseImage = *created
-		return nil
+		return waitIfRequested(v1.BaseImageKind, baseImage.Name, baseImageWaiter(c, baseImage))
 	}
 }
 
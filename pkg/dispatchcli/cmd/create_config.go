@@ -0,0 +1,97 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	createConfigLong = i18n.T(`Create a dispatch config.
+	VALUES_FILE - the path to a .json file containing the config values
+
+	Example: config.json:
+		{
+			"LOG_LEVEL": "debug"
+		}`)
+
+	// TODO: add examples
+	createConfigExample = i18n.T(`create a config`)
+)
+
+// NewCmdCreateConfig creates command responsible for config creation.
+func NewCmdCreateConfig(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config CONFIG_NAME VALUES_FILE",
+		Short:   i18n.T("Create config"),
+		Long:    createConfigLong,
+		Example: createConfigExample,
+		Args:    cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := configsClient()
+			err := createConfiguration(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallCreateConfig makes the API call to create a config
+func CallCreateConfig(c client.ConfigsClient) ModelAction {
+	return func(s interface{}) error {
+		configModel := s.(*v1.Configuration)
+		configModel.Tags = withApplication(configModel.Tags)
+
+		created, err := c.CreateConfig(context.TODO(), dispatchConfig.Organization, configModel)
+		if err != nil {
+			return err
+		}
+
+		*configModel = *created
+		return nil
+	}
+}
+
+func createConfiguration(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ConfigsClient) error {
+	configPath := args[1]
+
+	body := &v1.Configuration{
+		Meta: v1.Meta{
+			Name: args[0],
+		},
+	}
+
+	if configPath != "" {
+		configContent, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			return errors.Wrapf(err, "error when reading content of %s", configPath)
+		}
+		if err := json.Unmarshal(configContent, &body.Values); err != nil {
+			return errors.Wrapf(err, "Error when parsing JSON from %s", configContent)
+		}
+	}
+
+	err := CallCreateConfig(c)(body)
+	if err != nil {
+		return err
+	}
+	if w, err := formatOutput(out, false, body); w {
+		return err
+	}
+	fmt.Fprintf(out, "Created config: %s\n", body.Meta.Name)
+	return nil
+}
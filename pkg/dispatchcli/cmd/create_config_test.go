@@ -0,0 +1,71 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+)
+
+func TestCreateConfig(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	cli := NewCLI(os.Stdin, &stdout, &stderr)
+
+	cc := &mocks.ConfigsClient{}
+
+	values := map[string]string{"LOG_LEVEL": "debug"}
+
+	tmpfile, err := ioutil.TempFile("", "createConfig")
+	assert.NoError(t, err)
+	defer os.Remove(tmpfile.Name()) // clean up
+
+	err = json.NewEncoder(tmpfile).Encode(values)
+	require.NoError(t, err)
+
+	args := []string{"test", tmpfile.Name()}
+	dispatchConfig.Output = "json"
+
+	config := &v1.Configuration{
+		Meta: v1.Meta{
+			Name: args[0],
+		},
+		Values: values,
+	}
+
+	cc.On("CreateConfig", mock.Anything, mock.Anything, config).Once().Return(config, nil)
+	err = createConfiguration(&stdout, &stderr, cli, args, cc)
+	assert.NoError(t, err)
+
+	var cfgObj v1.Configuration
+	err = json.Unmarshal(stdout.Bytes(), &cfgObj)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", cfgObj.Meta.Name)
+	assert.Equal(t, values, cfgObj.Values)
+}
+
+func TestParseEnvVars(t *testing.T) {
+	env, err := parseEnvVars([]string{"LOG_LEVEL=debug", "GREETING=a=b", "EMPTY="})
+	require.NoError(t, err)
+	require.Len(t, env, 3)
+	assert.Equal(t, "LOG_LEVEL", *env[0].Name)
+	assert.Equal(t, "debug", env[0].Value)
+	assert.Equal(t, "a=b", env[1].Value)
+	assert.Equal(t, "", env[2].Value)
+
+	_, err = parseEnvVars([]string{"LOG_LEVEL"})
+	assert.Error(t, err)
+	_, err = parseEnvVars([]string{"=debug"})
+	assert.Error(t, err)
+}
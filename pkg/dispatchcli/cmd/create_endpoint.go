@@ -8,7 +8,10 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/vmware/dispatch/pkg/client"
 	"golang.org/x/net/context"
@@ -34,12 +37,32 @@ Note:
 	paths     = []string{"/"}
 	methods   = []string{"GET"}
 	auth      = "public"
+	match     = "exact"
+	rewrite   = ""
+
+	authSecret    = ""
+	authQuota     = int64(0)
+	authKeyQuotas = []string{}
+	authIssuer    = ""
+	authJwksURI   = ""
+	authAudiences = []string{}
+
+	corsOrigins = []string{}
+	corsMethods = []string{}
+	corsHeaders = []string{}
+	corsMaxAge  = int64(0)
+
+	rateLimit         = ""
+	rateBurst         = int64(0)
+	consumerRateLimit = ""
+	consumerRateBurst = int64(0)
+	maxBodySize       = int64(0)
 )
 
 // NewCmdCreateAPI creates command responsible for dispatch function endpoint creation.
 func NewCmdCreateAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "endpoint ENDPOINT_NAME FUNCTION_NAME [--auth AUTH_METHOD] [--domain DOMAINNAME...] [--method METHOD...] [--path PATH...] [--disable] [--cors] [--https-only]",
+		Use:     "endpoint ENDPOINT_NAME FUNCTION_NAME [--auth AUTH_METHOD [--auth-secret SECRET] [--quota QUOTA] [--key-quota KEY=QUOTA...] [--issuer ISSUER] [--jwks-uri URI] [--audience AUDIENCE...]] [--domain DOMAINNAME...] [--method METHOD...] [--path PATH...] [--match MATCH] [--rewrite PATH] [--disable] [--cors [--cors-origin ORIGIN...] [--cors-method METHOD...] [--cors-header HEADER...] [--cors-max-age SECONDS]] [--https-only] [--rate-limit REQUESTS/PER [--rate-burst BURST]] [--consumer-rate-limit REQUESTS/PER [--consumer-rate-burst BURST]] [--max-body-size BYTES]",
 		Short:   i18n.T("Create endpoint"),
 		Long:    createEndpointLong,
 		Example: createEndpointExample,
@@ -53,11 +76,28 @@ func NewCmdCreateAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 
 	cmd.Flags().StringArrayVarP(&hosts, "domain", "d", []string{}, "domain names that point to your API (multi-values), default: empty")
 	cmd.Flags().StringArrayVarP(&paths, "path", "p", []string{"/"}, "relative paths that point to your API (multi-values), default: /")
+	cmd.Flags().StringVar(&match, "match", "exact", "how paths are matched, (e.g. exact, prefix, regex), {NAME} segments of exact and prefix paths match any segment, default: exact")
+	cmd.Flags().StringVar(&rewrite, "rewrite", "", "path the matched path (or path prefix) is rewritten to before calling the function, may use the {NAME} segments of the path")
 	cmd.Flags().StringArrayVarP(&methods, "method", "m", []string{"GET"}, "methods that point to your API, default: GET")
 	cmd.Flags().BoolVar(&httpsOnly, "https-only", false, "only support https connections, default: false")
 	cmd.Flags().BoolVar(&disable, "disable", false, "disable the api, default: false")
 	cmd.Flags().BoolVar(&cors, "cors", false, "enable CORS, default: false")
-	cmd.Flags().StringVar(&auth, "auth", "public", "specify end-user authentication method, (e.g. public, basic, oauth2), default: public")
+	cmd.Flags().StringArrayVar(&corsOrigins, "cors-origin", []string{}, "origins allowed to make cross-origin requests, implies --cors (multi-values), default: any")
+	cmd.Flags().StringArrayVar(&corsMethods, "cors-method", []string{}, "methods allowed in cross-origin requests, implies --cors (multi-values), default: the endpoint methods")
+	cmd.Flags().StringArrayVar(&corsHeaders, "cors-header", []string{}, "request headers allowed in cross-origin requests, implies --cors (multi-values), default: any")
+	cmd.Flags().Int64Var(&corsMaxAge, "cors-max-age", 0, "how long, in seconds, browsers cache preflight requests, implies --cors")
+	cmd.Flags().StringVar(&rateLimit, "rate-limit", "", "requests allowed to all consumers as REQUESTS/second or REQUESTS/minute, per replica serving endpoints, default: no limit")
+	cmd.Flags().Int64Var(&rateBurst, "rate-burst", 0, "requests allowed at once to all consumers, default: the requests of --rate-limit")
+	cmd.Flags().StringVar(&consumerRateLimit, "consumer-rate-limit", "", "requests allowed to each consumer (authenticated user or client address) as REQUESTS/second or REQUESTS/minute, per replica serving endpoints, default: no limit")
+	cmd.Flags().Int64Var(&consumerRateBurst, "consumer-rate-burst", 0, "requests allowed at once to each consumer, default: the requests of --consumer-rate-limit")
+	cmd.Flags().Int64Var(&maxBodySize, "max-body-size", 0, "maximum size, in bytes, of request bodies, default: no limit")
+	cmd.Flags().StringVar(&auth, "auth", "public", "specify end-user authentication method, (e.g. public, basic, apikey, oauth2), default: public")
+	cmd.Flags().StringVar(&authSecret, "auth-secret", "", "secret holding the passwords by username (basic) or the API keys by name (apikey)")
+	cmd.Flags().Int64Var(&authQuota, "quota", 0, "requests per hour allowed to each API key (apikey), default: no limit")
+	cmd.Flags().StringArrayVar(&authKeyQuotas, "key-quota", []string{}, "requests per hour allowed to an API key as KEY=QUOTA, overriding --quota (apikey, multi-values)")
+	cmd.Flags().StringVar(&authIssuer, "issuer", "", "issuer of the JWT, its keys are discovered unless --jwks-uri is set (oauth2)")
+	cmd.Flags().StringVar(&authJwksURI, "jwks-uri", "", "URI of the JSON Web Key Set validating the JWT (oauth2)")
+	cmd.Flags().StringArrayVar(&authAudiences, "audience", []string{}, "audiences accepted in the JWT (oauth2, multi-values), default: any")
 	return cmd
 }
 
@@ -65,6 +105,7 @@ func NewCmdCreateAPI(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateEndpoint(c client.EndpointsClient) ModelAction {
 	return func(f interface{}) error {
 		model := f.(*v1.Endpoint)
+		model.Tags = withApplication(model.Tags)
 
 		created, err := c.CreateEndpoint(context.TODO(), "", model)
 		if err != nil {
@@ -75,6 +116,59 @@ func CallCreateEndpoint(c client.EndpointsClient) ModelAction {
 	}
 }
 
+// parseEndpointAuth builds the end-user authentication of an endpoint from the flags, nil for public endpoints
+func parseEndpointAuth() (*v1.EndpointAuth, error) {
+	if auth == v1.EndpointAuthPublic {
+		return nil, nil
+	}
+	endpointAuth := &v1.EndpointAuth{
+		Type:      auth,
+		Secret:    authSecret,
+		Quota:     authQuota,
+		Issuer:    authIssuer,
+		JwksURI:   authJwksURI,
+		Audiences: authAudiences,
+	}
+	for _, keyQuota := range authKeyQuotas {
+		kv := strings.SplitN(keyQuota, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("invalid API key quota %s, expected KEY=QUOTA", keyQuota)
+		}
+		quota, err := strconv.ParseInt(kv[1], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid quota '%s' of API key %s", kv[1], kv[0])
+		}
+		if endpointAuth.KeyQuotas == nil {
+			endpointAuth.KeyQuotas = make(map[string]int64)
+		}
+		endpointAuth.KeyQuotas[kv[0]] = quota
+	}
+	return endpointAuth, nil
+}
+
+// parseRateLimit builds a rate limit from a REQUESTS/PER flag, nil if not set
+func parseRateLimit(limit string, burst int64) (*v1.EndpointRateLimit, error) {
+	if limit == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(limit, "/", 2)
+	requests, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || requests < 1 {
+		return nil, errors.Errorf("invalid rate limit %s, expected REQUESTS/second or REQUESTS/minute", limit)
+	}
+	rateLimit := &v1.EndpointRateLimit{Requests: requests, Burst: burst, Per: v1.EndpointRatePerSecond}
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "s", v1.EndpointRatePerSecond:
+		case "m", v1.EndpointRatePerMinute:
+			rateLimit.Per = v1.EndpointRatePerMinute
+		default:
+			return nil, errors.Errorf("invalid rate limit %s, expected REQUESTS/second or REQUESTS/minute", limit)
+		}
+	}
+	return rateLimit, nil
+}
+
 func createEndpoint(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.EndpointsClient) error {
 
 	name := args[0]
@@ -85,22 +179,54 @@ func createEndpoint(out, errOut io.Writer, cmd *cobra.Command, args []string, c
 		protocols = []string{"https"}
 	}
 
+	endpointAuth, err := parseEndpointAuth()
+	if err != nil {
+		return err
+	}
+
+	endpointRateLimit, err := parseRateLimit(rateLimit, rateBurst)
+	if err != nil {
+		return err
+	}
+	endpointConsumerRateLimit, err := parseRateLimit(consumerRateLimit, consumerRateBurst)
+	if err != nil {
+		return err
+	}
+
+	var corsPolicy *v1.EndpointCors
+	if len(corsOrigins) > 0 || len(corsMethods) > 0 || len(corsHeaders) > 0 || corsMaxAge > 0 {
+		corsPolicy = &v1.EndpointCors{
+			AllowOrigins: corsOrigins,
+			AllowMethods: corsMethods,
+			AllowHeaders: corsHeaders,
+			MaxAge:       corsMaxAge,
+		}
+	}
+
 	model := &v1.Endpoint{
 		Meta: v1.Meta{
 			Kind: v1.EndpointKind,
 			Name: name,
 			Tags: []*v1.Tag{},
 		},
-		Function:  function,
-		Protocols: protocols,
-		Methods:   methods,
-		Uris:      paths,
-		Hosts:     hosts,
-		Enabled:   !disable,
-		Cors:      cors,
+		Function:   function,
+		Protocols:  protocols,
+		Methods:    methods,
+		Uris:       paths,
+		Match:      match,
+		Rewrite:    rewrite,
+		Hosts:      hosts,
+		Enabled:    !disable,
+		Cors:       cors || corsPolicy != nil,
+		CorsPolicy: corsPolicy,
+		Auth:       endpointAuth,
+
+		RateLimit:         endpointRateLimit,
+		ConsumerRateLimit: endpointConsumerRateLimit,
+		MaxBodySize:       maxBodySize,
 	}
 
-	err := CallCreateEndpoint(c)(model)
+	err = CallCreateEndpoint(c)(model)
 	if err != nil {
 		return err
 	}
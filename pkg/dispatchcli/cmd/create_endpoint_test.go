@@ -11,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
 )
 
 func TestCmdCreateAPI(t *testing.T) {
@@ -24,3 +26,44 @@ func TestCmdCreateAPI(t *testing.T) {
 	assert.True(t, strings.Contains(buf.String(), "Create dispatch function endpoint."))
 
 }
+
+func TestParseEndpointAuth(t *testing.T) {
+	defer func() {
+		auth, authSecret, authQuota, authKeyQuotas = "public", "", 0, []string{}
+	}()
+
+	endpointAuth, err := parseEndpointAuth()
+	assert.NoError(t, err)
+	assert.Nil(t, endpointAuth)
+
+	auth, authSecret, authQuota, authKeyQuotas = "apikey", "keys", 100, []string{"ci=1000"}
+	endpointAuth, err = parseEndpointAuth()
+	assert.NoError(t, err)
+	assert.Equal(t, "apikey", endpointAuth.Type)
+	assert.Equal(t, "keys", endpointAuth.Secret)
+	assert.Equal(t, int64(100), endpointAuth.Quota)
+	assert.Equal(t, map[string]int64{"ci": 1000}, endpointAuth.KeyQuotas)
+
+	authKeyQuotas = []string{"ci"}
+	_, err = parseEndpointAuth()
+	assert.Error(t, err)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	rateLimit, err := parseRateLimit("", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, rateLimit)
+
+	rateLimit, err = parseRateLimit("10", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &v1.EndpointRateLimit{Requests: 10, Per: v1.EndpointRatePerSecond}, rateLimit)
+
+	rateLimit, err = parseRateLimit("100/minute", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, &v1.EndpointRateLimit{Requests: 100, Per: v1.EndpointRatePerMinute, Burst: 20}, rateLimit)
+
+	for _, invalid := range []string{"0/second", "ten/second", "10/hour"} {
+		_, err = parseRateLimit(invalid, 0)
+		assert.Error(t, err, invalid)
+	}
+}
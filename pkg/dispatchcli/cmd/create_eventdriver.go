@@ -55,13 +55,14 @@ func NewCmdCreateEventDriver(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateEventDriver(c client.EventsClient) ModelAction {
 	return func(driver interface{}) error {
 		ev := driver.(*v1.EventDriver)
+		ev.Tags = withApplication(ev.Tags)
 
 		created, err := c.CreateEventDriver(context.TODO(), "", ev)
 		if err != nil {
 			return err
 		}
 		*ev = *created
-		return nil
+		return waitIfRequested(v1.DriverKind, *ev.Name, eventDriverWaiter(c, ev))
 	}
 }
 
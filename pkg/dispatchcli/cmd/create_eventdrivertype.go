@@ -48,6 +48,7 @@ func NewCmdCreateEventDriverType(out io.Writer, errOut io.Writer) *cobra.Command
 func CallCreateEventDriverType(c client.EventsClient) ModelAction {
 	return func(driver interface{}) error {
 		evt := driver.(*v1.EventDriverType)
+		evt.Tags = withApplication(evt.Tags)
 
 		created, err := c.CreateEventDriverType(context.TODO(), "", evt)
 		if err != nil {
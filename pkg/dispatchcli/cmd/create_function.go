@@ -11,8 +11,10 @@ import (
 	"io"
 	"io/ioutil"
 	"path"
+	"strings"
 
 	"github.com/go-openapi/spec"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
@@ -33,8 +35,18 @@ var (
 	schemaInFile          = ""
 	schemaOutFile         = ""
 	fnSecrets             []string
+	fnConfigs             []string
+	fnEnv                 []string
 	fnServices            []string
 	timeout               int64
+
+	fnCPURequest    = ""
+	fnMemoryRequest = ""
+	fnCPULimit      = ""
+	fnMemoryLimit   = ""
+	fnConcurrency   int64
+	fnMinScale      int64
+	fnMaxScale      int64
 )
 
 // NewCmdCreateFunction creates command responsible for dispatch function creation.
@@ -54,21 +66,90 @@ func NewCmdCreateFunction(out io.Writer, errOut io.Writer) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&depsImage, "image", "", "REQUIRED: image to build function on")
 	cmd.Flags().StringVar(&handler, "handler", "", "REQUIRED: fully-qualified function impl name (e.g. Java class or Python def)")
-	// cmd.Flags().StringVarP(&cmdFlagApplication, "application", "a", "", "associate with an application")
 	// cmd.Flags().StringVar(&functionImage, "function-image", "", "REQUIRED: image to build function on")
 	cmd.Flags().StringVar(&schemaInFile, "schema-in", "", "path to file with input validation schema")
 	cmd.Flags().StringVar(&schemaOutFile, "schema-out", "", "path to file with output validation schema")
 	cmd.Flags().StringArrayVar(&fnSecrets, "secret", []string{}, "Function secrets, can be specified multiple times or a comma-delimited string")
+	cmd.Flags().StringArrayVar(&fnConfigs, "config", []string{}, "Configs exposed to the function as environment variables, can be specified multiple times")
+	cmd.Flags().StringArrayVar(&fnEnv, "env", []string{}, "Function environment variable as KEY=VALUE, can be specified multiple times")
 	cmd.Flags().StringArrayVar(&fnServices, "service", []string{}, "Service instances this function uses, can be specified multiple times or a comma-delimited string")
-	cmd.Flags().Int64Var(&timeout, "timeout", 0, "A timeout to limit function execution time (in milliseconds). Default: 0 (no timeout)")
+	cmd.Flags().Int64Var(&timeout, "timeout", 0, "A timeout to limit function execution time (in milliseconds). Default: 0 (server default of 60 seconds)")
+	addFunctionScalingFlags(cmd)
 	cmd.MarkFlagRequired("image")
 	return cmd
 }
 
+// addFunctionScalingFlags adds the flags setting the resources and scaling of a function
+func addFunctionScalingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&fnCPURequest, "cpu-request", "", "CPU reserved for each function instance (e.g. 250m)")
+	cmd.Flags().StringVar(&fnMemoryRequest, "memory-request", "", "Memory reserved for each function instance (e.g. 64Mi)")
+	cmd.Flags().StringVar(&fnCPULimit, "cpu-limit", "", "CPU each function instance may use at most (e.g. 1)")
+	cmd.Flags().StringVar(&fnMemoryLimit, "memory-limit", "", "Memory each function instance may use at most (e.g. 1Gi)")
+	cmd.Flags().Int64Var(&fnConcurrency, "concurrency", 1, "Maximum number of concurrent requests served by a function instance, 0 for no limit")
+	cmd.Flags().Int64Var(&fnMinScale, "min-scale", 0, "Minimum number of function instances, 0 allows scaling to zero")
+	cmd.Flags().Int64Var(&fnMaxScale, "max-scale", 0, "Maximum number of function instances, 0 for no limit")
+}
+
+// setFunctionScaling sets the resources and scaling given on the command line, other settings are left as they are
+func setFunctionScaling(cmd *cobra.Command, function *v1.Function) {
+	flags := cmd.Flags()
+	resources := function.Resources
+	if resources == nil {
+		resources = new(v1.FunctionResources)
+	}
+	if resources.Requests == nil {
+		resources.Requests = new(v1.ResourceList)
+	}
+	if resources.Limits == nil {
+		resources.Limits = new(v1.ResourceList)
+	}
+	for flag, value := range map[string]*string{
+		"cpu-request":    &resources.Requests.CPU,
+		"memory-request": &resources.Requests.Memory,
+		"cpu-limit":      &resources.Limits.CPU,
+		"memory-limit":   &resources.Limits.Memory,
+	} {
+		if flags.Changed(flag) {
+			*value, _ = flags.GetString(flag)
+		}
+	}
+	if *resources.Requests == (v1.ResourceList{}) {
+		resources.Requests = nil
+	}
+	if *resources.Limits == (v1.ResourceList{}) {
+		resources.Limits = nil
+	}
+	function.Resources = nil
+	if resources.Requests != nil || resources.Limits != nil {
+		function.Resources = resources
+	}
+
+	if flags.Changed("concurrency") {
+		function.ContainerConcurrency = swag.Int64(fnConcurrency)
+	}
+	if flags.Changed("min-scale") {
+		function.MinScale = fnMinScale
+	}
+	if flags.Changed("max-scale") {
+		function.MaxScale = fnMaxScale
+	}
+}
+
+// functionScalingChanged tells whether resources or scaling were given on the command line
+func functionScalingChanged(cmd *cobra.Command) bool {
+	for _, flag := range []string{"cpu-request", "memory-request", "cpu-limit", "memory-limit", "concurrency", "min-scale", "max-scale"} {
+		if cmd.Flags().Changed(flag) {
+			return true
+		}
+	}
+	return false
+}
+
 // CallCreateFunction makes the API call to create a function
 func CallCreateFunction(c client.FunctionsClient) ModelAction {
 	return func(f interface{}) error {
 		function := f.(*v1.Function)
+		function.Tags = withApplication(function.Tags)
 
 		// How will we verify that the user has permissions for the org?
 		created, err := c.CreateFunction(context.TODO(), "", function)
@@ -76,10 +157,23 @@ func CallCreateFunction(c client.FunctionsClient) ModelAction {
 			return err
 		}
 		*function = *created
-		return nil
+		return waitIfRequested(v1.FunctionKind, function.Name, functionWaiter(c, function))
 	}
 }
 
+// parseEnvVars parses environment variables given as KEY=VALUE
+func parseEnvVars(vars []string) ([]*v1.EnvVar, error) {
+	var env []*v1.EnvVar
+	for _, v := range vars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid environment variable %s, expected KEY=VALUE", v)
+		}
+		env = append(env, &v1.EnvVar{Name: swag.String(parts[0]), Value: parts[1]})
+	}
+	return env, nil
+}
+
 func createFunction(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.FunctionsClient) error {
 	sourcePath := args[1]
 	isDir, err := utils.IsDir(sourcePath)
@@ -107,8 +201,13 @@ func createFunction(out, errOut io.Writer, cmd *cobra.Command, args []string, c
 		Source:   codeFileContent,
 		Handler:  handler,
 		Secrets:  fnSecrets,
+		Configs:  fnConfigs,
 		Timeout:  timeout,
 	}
+	if function.Env, err = parseEnvVars(fnEnv); err != nil {
+		return err
+	}
+	setFunctionScaling(cmd, function)
 
 	var schemaIn, schemaOut *spec.Schema
 	if schemaInFile != "" {
@@ -52,13 +52,14 @@ func NewCmdCreateImage(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateImage(c client.ImagesClient) ModelAction {
 	return func(i interface{}) error {
 		imageModel := i.(*v1.Image)
+		imageModel.Tags = withApplication(imageModel.Tags)
 
 		created, err := c.CreateImage(context.TODO(), dispatchConfig.Organization, imageModel)
 		if err != nil {
 			return err
 		}
 		*imageModel = *created
-		return nil
+		return waitIfRequested(v1.ImageKind, imageModel.Name, imageWaiter(c, imageModel))
 	}
 }
 
@@ -54,6 +54,7 @@ func NewCmdCreateSecret(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateSecret(c client.SecretsClient) ModelAction {
 	return func(s interface{}) error {
 		secretModel := s.(*v1.Secret)
+		secretModel.Tags = withApplication(secretModel.Tags)
 
 		created, err := c.CreateSecret(context.TODO(), dispatchConfig.Organization, secretModel)
 		if err != nil {
@@ -0,0 +1,94 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	createServiceInstanceLong = i18n.T(`Create a service instance of a service class and plan.
+	The credentials of bindable instances are injected into the functions listing the instance with --service.`)
+
+	createServiceInstanceExample = i18n.T(`dispatch create serviceinstance orders-db postgres small --params '{"size": "1G"}' --secret-params db-password`)
+
+	serviceInstanceParams       = ""
+	serviceInstanceSecretParams []string
+)
+
+// NewCmdCreateServiceInstance creates command responsible for service instance creation.
+func NewCmdCreateServiceInstance(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceinstance SERVICE_INSTANCE_NAME SERVICE_CLASS SERVICE_PLAN",
+		Short:   i18n.T("Create service instance"),
+		Long:    createServiceInstanceLong,
+		Example: createServiceInstanceExample,
+		Args:    cobra.ExactArgs(3),
+		Aliases: []string{"serviceinstances"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := servicesClient()
+			err := createServiceInstance(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&serviceInstanceParams, "params", "", "Provisioning parameters as a JSON object")
+	cmd.Flags().StringArrayVar(&serviceInstanceSecretParams, "secret-params", []string{}, "Secrets merged into the provisioning parameters, can be specified multiple times")
+	return cmd
+}
+
+// CallCreateServiceInstance makes the API call to create a service instance
+func CallCreateServiceInstance(c client.ServicesClient) ModelAction {
+	return func(s interface{}) error {
+		serviceInstanceModel := s.(*v1.ServiceInstance)
+		serviceInstanceModel.Tags = withApplication(serviceInstanceModel.Tags)
+
+		created, err := c.CreateServiceInstance(context.TODO(), dispatchConfig.Organization, serviceInstanceModel)
+		if err != nil {
+			return err
+		}
+
+		*serviceInstanceModel = *created
+		return nil
+	}
+}
+
+func createServiceInstance(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ServicesClient) error {
+	body := &v1.ServiceInstance{
+		Name:             swag.String(args[0]),
+		ServiceClass:     swag.String(args[1]),
+		ServicePlan:      swag.String(args[2]),
+		SecretParameters: serviceInstanceSecretParams,
+	}
+
+	if serviceInstanceParams != "" {
+		var params map[string]interface{}
+		if err := json.Unmarshal([]byte(serviceInstanceParams), &params); err != nil {
+			return errors.Wrapf(err, "Error when parsing JSON from %s", serviceInstanceParams)
+		}
+		body.Parameters = params
+	}
+
+	err := CallCreateServiceInstance(c)(body)
+	if err != nil {
+		return err
+	}
+	if w, err := formatOutput(out, false, body); w {
+		return err
+	}
+	fmt.Fprintf(out, "Created serviceinstance: %s\n", *body.Name)
+	return nil
+}
@@ -0,0 +1,59 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+)
+
+func TestCreateServiceInstance(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	cli := NewCLI(os.Stdin, &stdout, &stderr)
+
+	sc := &mocks.ServicesClient{}
+
+	args := []string{"orders-db", "postgres", "small"}
+	dispatchConfig.Output = "json"
+	serviceInstanceParams = `{"size": "1G"}`
+	serviceInstanceSecretParams = []string{"db-password"}
+	defer func() {
+		serviceInstanceParams = ""
+		serviceInstanceSecretParams = nil
+	}()
+
+	serviceInstance := &v1.ServiceInstance{
+		Name:             swag.String("orders-db"),
+		ServiceClass:     swag.String("postgres"),
+		ServicePlan:      swag.String("small"),
+		Parameters:       map[string]interface{}{"size": "1G"},
+		SecretParameters: []string{"db-password"},
+	}
+
+	sc.On("CreateServiceInstance", mock.Anything, mock.Anything, serviceInstance).Once().Return(serviceInstance, nil)
+	err := createServiceInstance(&stdout, &stderr, cli, args, sc)
+	require.NoError(t, err)
+
+	var instanceObj v1.ServiceInstance
+	err = json.Unmarshal(stdout.Bytes(), &instanceObj)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-db", *instanceObj.Name)
+	assert.Equal(t, "small", *instanceObj.ServicePlan)
+	assert.Equal(t, []string{"db-password"}, instanceObj.SecretParameters)
+
+	serviceInstanceParams = "size=1G"
+	err = createServiceInstance(&stdout, &stderr, cli, args, sc)
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/go-openapi/swag"
 	"github.com/spf13/cobra"
@@ -21,17 +22,25 @@ import (
 var (
 	createSubscriptionLong = i18n.T(`Create dispatch event subscription.`)
 
-	// TODO: add examples
-	createSubscriptionExample   = i18n.T(``)
-	createSubscriptionSecrets   []string
-	createSubscriptionEventType string
-	createSubscriptionName      string
+	createSubscriptionExample = i18n.T(`
+# Run function "hello-py" for every "user.created" event, retrying failed runs up to 5 times within 10 minutes
+dispatch create subscription hello-py --event-type user.created --max-attempts 5 --backoff 1s --max-age 10m
+
+# Run function "web-vm" for every vm event of vcenter1 about a vm whose name starts with "web"
+dispatch create subscription web-vm --event-type "vcenter.vm.*" --filter "source == 'vcenter1' && [data.vm.name] =~ '^web'"`)
+	createSubscriptionSecrets     []string
+	createSubscriptionEventType   string
+	createSubscriptionFilter      string
+	createSubscriptionName        string
+	createSubscriptionMaxAttempts int64
+	createSubscriptionBackoff     time.Duration
+	createSubscriptionMaxAge      time.Duration
 )
 
 // NewCmdCreateSubscription creates command responsible for subscription creation.
 func NewCmdCreateSubscription(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "subscription FUNCTION_NAME [--name SUBSCRIPTION_NAME] [--event-type EVENT.TYPE] [--secret SECRET1,SECRET2...]",
+		Use:     "subscription FUNCTION_NAME [--name SUBSCRIPTION_NAME] [--event-type EVENT.TYPE] [--filter EXPRESSION] [--secret SECRET1,SECRET2...] [--max-attempts N] [--backoff DURATION] [--max-age DURATION]",
 		Short:   i18n.T("Create subscription"),
 		Long:    createSubscriptionLong,
 		Example: createSubscriptionExample,
@@ -45,7 +54,11 @@ func NewCmdCreateSubscription(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().StringArrayVar(&createSubscriptionSecrets, "secret", []string{}, "Function secrets, can be specified multiple times or a comma-delimited string")
 
 	cmd.Flags().StringVar(&createSubscriptionName, "name", "", "Subscription name. If not specified, will be randomly generated.")
-	cmd.Flags().StringVar(&createSubscriptionEventType, "event-type", "", "Event Type to filter on.")
+	cmd.Flags().StringVar(&createSubscriptionEventType, "event-type", "", "Event Type to filter on. * matches a single dot separated word, # matches zero or more words.")
+	cmd.Flags().StringVar(&createSubscriptionFilter, "filter", "", "Expression over the event attributes, extensions and data, the function only runs for events it matches. Names containing dots must be enclosed in brackets, e.g. [extensions.region] or [data.vm.name].")
+	cmd.Flags().Int64Var(&createSubscriptionMaxAttempts, "max-attempts", 1, "Maximum number of attempts to run the function for an event, events which are never delivered become dead letters.")
+	cmd.Flags().DurationVar(&createSubscriptionBackoff, "backoff", time.Second, "Time to wait before retrying a failed run, grows with every retry.")
+	cmd.Flags().DurationVar(&createSubscriptionMaxAge, "max-age", 0, "How long after it was emitted an event is still delivered. Default: 0 (no limit)")
 
 	return cmd
 }
@@ -54,6 +67,7 @@ func NewCmdCreateSubscription(out io.Writer, errOut io.Writer) *cobra.Command {
 func CallCreateSubscription(c client.EventsClient) ModelAction {
 	return func(i interface{}) error {
 		subscription := i.(*v1.Subscription)
+		subscription.Tags = withApplication(subscription.Tags)
 
 		created, err := c.CreateSubscription(context.TODO(), "", subscription)
 		if err != nil {
@@ -68,8 +82,14 @@ func createSubscription(out, errOut io.Writer, cmd *cobra.Command, args []string
 	subscription := &v1.Subscription{
 		Name:      swag.String(resourceName(createSubscriptionName)),
 		EventType: &createSubscriptionEventType,
+		Filter:    createSubscriptionFilter,
 		Function:  &args[0],
 		Secrets:   createSubscriptionSecrets,
+		RetryPolicy: &v1.RetryPolicy{
+			MaxAttempts: createSubscriptionMaxAttempts,
+			Backoff:     int64(createSubscriptionBackoff / time.Millisecond),
+			MaxAge:      int64(createSubscriptionMaxAge / time.Millisecond),
+		},
 	}
 	err := CallCreateSubscription(c)(subscription)
 	if err != nil {
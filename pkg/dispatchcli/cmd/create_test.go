@@ -71,3 +71,24 @@ func TestCreateBatchSecret(t *testing.T) {
 	err = importFile(&stdout, &stderr, cli, nil, createMap, "Created")
 	assert.Nil(t, err)
 }
+
+func TestImportSkipsKindsWithoutAction(t *testing.T) {
+	var stdout bytes.Buffer
+
+	sc := &mocks.SecretsClient{}
+	sc.On("CreateSecret", mock.Anything, mock.Anything, mock.Anything).Once().Return(&v1.Secret{Name: swag.String("open-sesame")}, nil)
+	actionMap := map[string]ModelAction{
+		v1.SecretKind: CallCreateSecret(sc),
+	}
+
+	manifest := secretSeed + `
+---
+kind: ServiceInstance
+name: database
+serviceClass: postgres
+servicePlan: small`
+	err := importBytes(&stdout, []byte(manifest), actionMap, "Updated")
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated Secret: open-sesame\nSkipped ServiceInstance: database\n", stdout.String())
+	sc.AssertExpectations(t)
+}
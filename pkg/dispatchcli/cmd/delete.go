@@ -44,19 +44,25 @@ func NewCmdDelete(out io.Writer, errOut io.Writer) *cobra.Command {
 			eventClient := eventManagerClient()
 			endptClient := endpointsClient()
 			secClient := secretsClient()
+			cfgClient := configsClient()
+			svcClient := servicesClient()
+			appClient := applicationsClient()
 			iamClient := identityManagerClient()
 
 			deleteMap := map[string]ModelAction{
-				v1.ImageKind:          CallDeleteImage(imgClient),
-				v1.BaseImageKind:      CallDeleteBaseImage(baseImgClient),
-				v1.FunctionKind:       CallDeleteFunction(fnClient),
-				v1.SecretKind:         CallDeleteSecret(secClient),
-				v1.PolicyKind:         CallDeletePolicy(iamClient),
-				v1.ServiceAccountKind: CallDeleteServiceAccount(iamClient),
-				v1.DriverTypeKind:     CallDeleteEventDriverType(eventClient),
-				v1.DriverKind:         CallDeleteEventDriver(eventClient),
-				v1.SubscriptionKind:   CallDeleteSubscription(eventClient),
-				v1.EndpointKind:       CallDeleteEndpoint(endptClient),
+				v1.ImageKind:           CallDeleteImage(imgClient),
+				v1.BaseImageKind:       CallDeleteBaseImage(baseImgClient),
+				v1.FunctionKind:        CallDeleteFunction(fnClient),
+				v1.SecretKind:          CallDeleteSecret(secClient),
+				v1.ConfigurationKind:   CallDeleteConfig(cfgClient),
+				v1.ServiceInstanceKind: CallDeleteServiceInstance(svcClient),
+				v1.ApplicationKind:     CallDeleteApplication(appClient),
+				v1.PolicyKind:          CallDeletePolicy(iamClient),
+				v1.ServiceAccountKind:  CallDeleteServiceAccount(iamClient),
+				v1.DriverTypeKind:      CallDeleteEventDriverType(eventClient),
+				v1.DriverKind:          CallDeleteEventDriver(eventClient),
+				v1.SubscriptionKind:    CallDeleteSubscription(eventClient),
+				v1.EndpointKind:        CallDeleteEndpoint(endptClient),
 			}
 
 			err := importFile(out, errOut, cmd, args, deleteMap, "Deleted")
@@ -68,6 +74,9 @@ func NewCmdDelete(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdDeleteImage(out, errOut))
 	cmd.AddCommand(NewCmdDeleteFunction(out, errOut))
 	cmd.AddCommand(NewCmdDeleteSecret(out, errOut))
+	cmd.AddCommand(NewCmdDeleteConfig(out, errOut))
+	cmd.AddCommand(NewCmdDeleteServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdDeleteApplication(out, errOut))
 	cmd.AddCommand(NewCmdDeleteEndpoint(out, errOut))
 	cmd.AddCommand(NewCmdDeleteSubscription(out, errOut))
 	cmd.AddCommand(NewCmdDeleteEventDriver(out, errOut))
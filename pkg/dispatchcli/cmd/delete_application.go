@@ -0,0 +1,97 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	deleteApplicationsLong = i18n.T(`Delete applications. Without --cascade the members of the application are kept.`)
+
+	deleteApplicationsExample = i18n.T(`dispatch delete application shop --cascade`)
+
+	deleteApplicationCascade = false
+)
+
+// NewCmdDeleteApplication creates command responsible for deleting applications.
+func NewCmdDeleteApplication(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "application APPLICATION_NAME",
+		Short:   i18n.T("Delete applications"),
+		Long:    deleteApplicationsLong,
+		Example: deleteApplicationsExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"applications", "app", "apps"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := applicationsClient()
+			var kinds []applicationMemberKind
+			if deleteApplicationCascade {
+				kinds = applicationMemberKinds()
+			}
+			err := deleteApplication(out, errOut, cmd, args, c, kinds)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVar(&deleteApplicationCascade, "cascade", false, "Also delete the subscriptions, endpoints, functions, images and secrets of the application")
+	return cmd
+}
+
+// CallDeleteApplication makes the API call to delete an application
+func CallDeleteApplication(c client.ApplicationsClient) ModelAction {
+	return func(a interface{}) error {
+		applicationModel := a.(*v1.Application)
+
+		err := c.DeleteApplication(context.TODO(), dispatchConfig.Organization, *applicationModel.Name)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// deleteApplication deletes the application, after the members of the given kinds when cascading
+func deleteApplication(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ApplicationsClient, kinds []applicationMemberKind) error {
+	ctx := context.TODO()
+	applicationModel, err := c.GetApplication(ctx, dispatchConfig.Organization, args[0])
+	if err != nil {
+		return err
+	}
+	// With json or yaml output only the application is written to out, the deleted members go to errOut
+	membersOut := out
+	if dispatchConfig.Output != "" {
+		membersOut = errOut
+	}
+	if err := deleteApplicationMembers(ctx, membersOut, kinds, *applicationModel.Name); err != nil {
+		return err
+	}
+	err = CallDeleteApplication(c)(applicationModel)
+	if err != nil {
+		return err
+	}
+	return formatDeleteApplicationOutput(out, false, []*v1.Application{applicationModel})
+}
+
+func formatDeleteApplicationOutput(out io.Writer, list bool, applications []*v1.Application) error {
+	if w, err := formatOutput(out, list, applications); w {
+		return err
+	}
+	for _, i := range applications {
+		_, err := fmt.Fprintf(out, "Deleted application: %s\n", *i.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
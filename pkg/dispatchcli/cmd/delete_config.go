@@ -0,0 +1,82 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	deleteConfigsLong = i18n.T(`Delete configs.`)
+
+	// TODO: add examples
+	deleteConfigsExample = i18n.T(``)
+)
+
+// NewCmdDeleteConfig creates command responsible for deleting configs.
+func NewCmdDeleteConfig(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config CONFIG_NAME",
+		Short:   i18n.T("Delete configs"),
+		Long:    deleteConfigsLong,
+		Example: deleteConfigsExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"configs"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := configsClient()
+			err := deleteConfig(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteConfig makes the API call to delete a config
+func CallDeleteConfig(c client.ConfigsClient) ModelAction {
+	return func(s interface{}) error {
+		configModel := s.(*v1.Configuration)
+
+		err := c.DeleteConfig(context.TODO(), dispatchConfig.Organization, configModel.Meta.Name)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+func deleteConfig(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ConfigsClient) error {
+	configModel := v1.Configuration{
+		Meta: v1.Meta{
+			Name: args[0],
+		},
+	}
+	err := CallDeleteConfig(c)(&configModel)
+	if err != nil {
+		return err
+	}
+	return formatDeleteConfigOutput(out, false, []*v1.Configuration{&configModel})
+}
+
+func formatDeleteConfigOutput(out io.Writer, list bool, configs []*v1.Configuration) error {
+	if w, err := formatOutput(out, list, configs); w {
+		return err
+	}
+	for _, i := range configs {
+		_, err := fmt.Fprintf(out, "Deleted config: %s\n", i.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	deleteServiceInstancesLong = i18n.T(`Delete service instances, their binding secret is removed and the broker deprovisions them.`)
+
+	// TODO: add examples
+	deleteServiceInstancesExample = i18n.T(``)
+)
+
+// NewCmdDeleteServiceInstance creates command responsible for deleting service instances.
+func NewCmdDeleteServiceInstance(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceinstance SERVICE_INSTANCE_NAME",
+		Short:   i18n.T("Delete service instances"),
+		Long:    deleteServiceInstancesLong,
+		Example: deleteServiceInstancesExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"serviceinstances"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := servicesClient()
+			err := deleteServiceInstance(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+// CallDeleteServiceInstance makes the API call to delete a service instance
+func CallDeleteServiceInstance(c client.ServicesClient) ModelAction {
+	return func(s interface{}) error {
+		serviceInstanceModel := s.(*v1.ServiceInstance)
+
+		err := c.DeleteServiceInstance(context.TODO(), dispatchConfig.Organization, *serviceInstanceModel.Name)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+func deleteServiceInstance(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ServicesClient) error {
+	serviceInstanceModel := v1.ServiceInstance{
+		Name: swag.String(args[0]),
+	}
+	err := CallDeleteServiceInstance(c)(&serviceInstanceModel)
+	if err != nil {
+		return err
+	}
+	return formatDeleteServiceInstanceOutput(out, false, []*v1.ServiceInstance{&serviceInstanceModel})
+}
+
+func formatDeleteServiceInstanceOutput(out io.Writer, list bool, serviceInstances []*v1.ServiceInstance) error {
+	if w, err := formatOutput(out, list, serviceInstances); w {
+		return err
+	}
+	for _, i := range serviceInstances {
+		_, err := fmt.Fprintf(out, "Deleted serviceinstance: %s\n", *i.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
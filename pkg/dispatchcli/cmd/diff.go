@@ -0,0 +1,47 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	diffLong = i18n.T(`Show the plan apply would carry out for a manifest, without changing anything.`)
+
+	// TODO: Add examples
+	diffExample = i18n.T(``)
+)
+
+// NewCmdDiff creates command showing the changes apply would make.
+func NewCmdDiff(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "diff -f FILE|DIR [--label KEY=VALUE [--prune]]",
+		Short:   i18n.T("Show the changes apply would make for a manifest."),
+		Long:    diffLong,
+		Example: diffExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if file == "" {
+				runHelp(cmd, args)
+				return
+			}
+			err := runApply(out, errOut, applyKinds(), false)
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file or directory of YAML files")
+	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
+	cmd.Flags().StringVarP(&applyLabel, "label", "l", "", "Label KEY=VALUE tagged on the applied resources")
+	cmd.Flags().BoolVar(&applyPrune, "prune", false, "Show the resources with the label which are no longer in the manifest")
+
+	return cmd
+}
@@ -7,6 +7,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 
@@ -22,12 +23,23 @@ import (
 var (
 	execLong = i18n.T(`Execute a dispatch function.`)
 
-	// TODO: Add examples
-	execExample = i18n.T(``)
+	execExample = i18n.T(`
+# Execute a function and wait for its output
+dispatch exec hello-py < input.json
+
+# Execute a function and print what it wrote to stdout and stderr
+dispatch exec hello-py --logs < input.json
+
+# Execute a function without waiting, then check on the run
+dispatch exec hello-py --async < input.json
+dispatch get runs hello-py RUN_ID
+`)
 
 	contentType = ""
 	accept      = ""
 	execSecrets = []string{}
+	execAsync   = false
+	execLogs    = false
 )
 
 // NewCmdExec creates a command to execute a dispatch function.
@@ -48,6 +60,8 @@ func NewCmdExec(in io.Reader, out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().StringArrayVar(&execSecrets, "secret", []string{}, "Function secrets, can be specified multiple times or a comma-delimited string")
 	cmd.Flags().StringVarP(&contentType, "content-type", "c", "application/json", "Input Content-Type")
 	cmd.Flags().StringVarP(&accept, "accept", "a", "application/json", "Output Content-Type")
+	cmd.Flags().BoolVar(&execAsync, "async", false, "Do not wait for the function to finish, print the run instead of its output")
+	cmd.Flags().BoolVar(&execLogs, "logs", false, "Print the logs of the function to stderr")
 	return cmd
 }
 
@@ -65,7 +79,7 @@ func runExec(in io.Reader, out, errOut io.Writer, cmd *cobra.Command, args []str
 		return errors.Wrap(err, "reading stdin")
 	}
 	run := &v1.Run{
-		Blocking:     true,
+		Blocking:     !execAsync,
 		InputBytes:   inBytes,
 		HTTPContext:  map[string]interface{}{"Content-Type": contentType, "Accept": accept},
 		Secrets:      execSecrets,
@@ -75,14 +89,38 @@ func runExec(in io.Reader, out, errOut io.Writer, cmd *cobra.Command, args []str
 	functionResult, err := c.RunFunction(context.TODO(), "", run)
 
 	if err != nil {
+		// Failed runs still have the logs of the function
+		if failed, ok := err.(*client.ErrorFunctionError); ok && failed.Run != nil && execLogs {
+			formatExecLogs(errOut, failed.Run.Logs)
+		}
 		return errors.Wrap(err, "api client error")
 	}
 
+	if execAsync {
+		return formatExecOutput(out, functionResult)
+	}
+
 	out.Write(functionResult.OutputBytes)
 
+	if execLogs {
+		formatExecLogs(errOut, functionResult.Logs)
+	}
 	return nil
 }
 
+// formatExecLogs prints the lines the function wrote to stdout and stderr
+func formatExecLogs(out io.Writer, logs *v1.Logs) {
+	if logs == nil {
+		return
+	}
+	for _, line := range logs.Stdout {
+		fmt.Fprintf(out, "[stdout] %s\n", line)
+	}
+	for _, line := range logs.Stderr {
+		fmt.Fprintf(out, "[stderr] %s\n", line)
+	}
+}
+
 func formatExecOutput(out io.Writer, run *v1.Run) error {
 	// Always return json for execution
 	encoder := json.NewEncoder(out)
@@ -10,7 +10,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/go-openapi/swag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/client/mocks"
 )
 
 func TestCmdExec(t *testing.T) {
@@ -23,3 +30,39 @@ func TestCmdExec(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, strings.Contains(buf.String(), "Execute a dispatch function"))
 }
+
+func TestExecLogs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	fc := &mocks.FunctionsClient{}
+	fc.On("RunFunction", mock.Anything, mock.Anything, mock.Anything).Return(&v1.Run{
+		OutputBytes: []byte("hello world"),
+		Logs:        &v1.Logs{Stdout: []string{"saying hello"}, Stderr: []string{"oops"}},
+	}, nil)
+
+	execLogs = true
+	defer func() { execLogs = false }()
+	err := runExec(strings.NewReader("world"), &stdout, &stderr, nil, []string{"hello"}, fc)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", stdout.String())
+	assert.Equal(t, "[stdout] saying hello\n[stderr] oops\n", stderr.String())
+}
+
+func TestExecLogsFailedRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	fc := &mocks.FunctionsClient{}
+	fc.On("RunFunction", mock.Anything, mock.Anything, mock.Anything).Return(nil, client.NewErrorFunctionRunError(&v1.Run{
+		Status: v1.StatusERROR,
+		Error:  &v1.InvocationError{Type: v1.ErrorTypeFunctionError, Message: swag.String("division by zero")},
+		Logs:   &v1.Logs{Stderr: []string{"dividing by zero"}},
+	}))
+
+	execLogs = true
+	defer func() { execLogs = false }()
+	err := runExec(strings.NewReader("world"), &stdout, &stderr, nil, []string{"hello"}, fc)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "division by zero")
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "[stderr] dividing by zero\n", stderr.String())
+}
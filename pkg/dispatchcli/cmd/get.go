@@ -44,8 +44,13 @@ func NewCmdGet(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdGetFunction(out, errOut))
 	cmd.AddCommand(NewCmdGetRun(out, errOut))
 	cmd.AddCommand(NewCmdGetSecret(out, errOut))
+	cmd.AddCommand(NewCmdGetConfig(out, errOut))
+	cmd.AddCommand(NewCmdGetServiceClass(out, errOut))
+	cmd.AddCommand(NewCmdGetServiceInstance(out, errOut))
+	cmd.AddCommand(NewCmdGetApplication(out, errOut))
 	cmd.AddCommand(NewCmdGetEndpoint(out, errOut))
 	cmd.AddCommand(NewCmdGetSubscription(out, errOut))
+	cmd.AddCommand(NewCmdGetDeadLetter(out, errOut))
 	cmd.AddCommand(NewCmdGetEventDriver(out, errOut))
 	cmd.AddCommand(NewCmdGetEventDriverType(out, errOut))
 	return cmd
@@ -0,0 +1,112 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	getApplicationsLong = i18n.T(`Get applications. Getting a single application also shows its member functions,
+	endpoints, subscriptions, secrets and images, and their aggregate status.`)
+
+	// TODO: add examples
+	getApplicationsExample = i18n.T(``)
+)
+
+// applicationDetails is an application along with its members
+type applicationDetails struct {
+	Application *v1.Application     `json:"application"`
+	Status      v1.Status           `json:"status"`
+	Members     []applicationMember `json:"members"`
+}
+
+// NewCmdGetApplication creates command responsible for getting applications.
+func NewCmdGetApplication(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "application [APPLICATION_NAME]",
+		Short:   i18n.T("Get applications"),
+		Long:    getApplicationsLong,
+		Example: getApplicationsExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"applications", "app", "apps"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := applicationsClient()
+			if len(args) == 1 {
+				err = getApplication(out, errOut, cmd, args, c, applicationMemberKinds())
+			} else {
+				err = getApplications(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getApplication(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ApplicationsClient, kinds []applicationMemberKind) error {
+	ctx := context.TODO()
+	application, err := c.GetApplication(ctx, dispatchConfig.Organization, args[0])
+	if err != nil {
+		return err
+	}
+	members, err := listApplicationMembers(ctx, kinds, *application.Name)
+	if err != nil {
+		return err
+	}
+	details := applicationDetails{
+		Application: application,
+		Status:      aggregateStatus(application.Status, members),
+		Members:     members,
+	}
+	if w, err := formatOutput(out, false, details); w {
+		return err
+	}
+
+	fmt.Fprintf(out, "Application: %s\nStatus: %s\n\n", *application.Name, details.Status)
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Kind", "Name", "Status"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, member := range members {
+		table.Append([]string{member.Kind, member.Name, string(member.Status)})
+	}
+	table.Render()
+	return nil
+}
+
+func getApplications(out, errOut io.Writer, cmd *cobra.Command, c client.ApplicationsClient) error {
+	resp, err := c.ListApplications(context.TODO(), dispatchConfig.Organization)
+	if err != nil {
+		return err
+	}
+	return formatApplicationOutput(out, true, resp)
+}
+
+func formatApplicationOutput(out io.Writer, list bool, applications []v1.Application) error {
+	if w, err := formatOutput(out, list, applications); w {
+		return err
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Status"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, application := range applications {
+		table.Append([]string{*application.Name, string(application.Status)})
+	}
+	table.Render()
+	return nil
+}
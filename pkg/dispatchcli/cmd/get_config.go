@@ -0,0 +1,90 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+	"golang.org/x/net/context"
+)
+
+var (
+	getConfigsLong = i18n.T(`Get configs.`)
+
+	// TODO: add examples
+	getConfigsExample = i18n.T(``)
+)
+
+// NewCmdGetConfig creates command responsible for getting configs.
+func NewCmdGetConfig(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "config [CONFIG_NAME ...]",
+		Short:   i18n.T("Get configs"),
+		Long:    getConfigsLong,
+		Example: getConfigsExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"configs"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := configsClient()
+			if len(args) == 1 {
+				err = getConfig(out, errOut, cmd, args, c)
+			} else {
+				err = getConfigs(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getConfig(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ConfigsClient) error {
+	configName := args[0]
+
+	resp, err := c.GetConfig(context.TODO(), dispatchConfig.Organization, configName)
+	if err != nil {
+		return err
+	}
+
+	return formatConfigOutput(out, false, []v1.Configuration{*resp})
+}
+
+func getConfigs(out, errOut io.Writer, cmd *cobra.Command, c client.ConfigsClient) error {
+	resp, err := c.ListConfigs(context.TODO(), dispatchConfig.Organization)
+	if err != nil {
+		return err
+	}
+	return formatConfigOutput(out, true, resp)
+}
+
+func formatConfigOutput(out io.Writer, list bool, configs []v1.Configuration) error {
+	if w, err := formatOutput(out, list, configs); w {
+		return err
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Values"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, config := range configs {
+		var values []string
+		for key, value := range config.Values {
+			values = append(values, fmt.Sprintf("%s=%s", key, value))
+		}
+		sort.Strings(values)
+		table.Append([]string{config.Meta.Name, strings.Join(values, "\n")})
+	}
+	table.Render()
+	return nil
+}
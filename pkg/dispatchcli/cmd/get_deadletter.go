@@ -0,0 +1,90 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/client"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	getDeadLettersLong = i18n.T(`Get dead letters, the events subscriptions failed to deliver to their function.`)
+
+	getDeadLettersExample = i18n.T(`
+# List all dead letters
+dispatch get deadletters
+
+# Show a dead letter, including its event
+dispatch get deadletter DEAD_LETTER --json`)
+)
+
+// NewCmdGetDeadLetter creates command responsible for getting dead letters.
+func NewCmdGetDeadLetter(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deadletter [DEAD_LETTER]",
+		Short:   i18n.T("Get dead letters"),
+		Long:    getDeadLettersLong,
+		Example: getDeadLettersExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"deadletters"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := eventManagerClient()
+			if len(args) > 0 {
+				err = getDeadLetter(out, errOut, cmd, args, c)
+			} else {
+				err = getDeadLetters(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+
+	return cmd
+}
+
+func getDeadLetter(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.EventsClient) error {
+	resp, err := c.GetDeadLetter(context.TODO(), "", args[0])
+	if err != nil {
+		return err
+	}
+	return formatDeadLetterOutput(out, false, []v1.DeadLetter{*resp})
+}
+
+func getDeadLetters(out, errOut io.Writer, cmd *cobra.Command, c client.EventsClient) error {
+	resp, err := c.ListDeadLetters(context.TODO(), "")
+	if err != nil {
+		return err
+	}
+	return formatDeadLetterOutput(out, true, resp)
+}
+
+func formatDeadLetterOutput(out io.Writer, list bool, deadLetters []v1.DeadLetter) error {
+	if w, err := formatOutput(out, list, deadLetters); w {
+		return err
+	}
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Subscription", "Function name", "Event type", "Attempts", "Reason", "Created date"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, d := range deadLetters {
+		var eventType string
+		if d.Event != nil {
+			eventType = d.Event.EventType
+		}
+		table.Append([]string{d.Name, d.Subscription, d.Function, eventType, fmt.Sprintf("%d", d.Attempts), d.Reason, time.Unix(d.CreatedTime, 0).Local().Format(time.UnixDate)})
+	}
+	table.Render()
+	return nil
+}
@@ -38,12 +38,16 @@ dispatch get runs example-function
 # Get a specific run
 dispatch get runs example-function f98d0a7f-0c1d-4020-a488-cabc501b08e0
 
+# Get failed runs for a specific function
+dispatch get runs example-function --status ERROR
+
 # Follow runs for a specific function
 dispatch get runs example-function --follow
 `)
 
 	followRuns = false
 	last       = false
+	runStatus  = ""
 )
 
 // NewCmdGetRun creates command responsible for getting runs.
@@ -58,6 +62,10 @@ func NewCmdGetRun(out io.Writer, errOut io.Writer) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			var err error
 			c := functionsClient()
+			var status *string
+			if runStatus != "" {
+				status = &runStatus
+			}
 			if len(args) == 2 {
 				opts := client.FunctionOpts{
 					FunctionName: &args[0],
@@ -67,10 +75,13 @@ func NewCmdGetRun(out io.Writer, errOut io.Writer) *cobra.Command {
 			} else if len(args) == 1 {
 				opts := client.FunctionOpts{
 					FunctionName: &args[0],
+					Status:       status,
 				}
 				err = getRuns(out, errOut, cmd, opts, c)
 			} else {
-				opts := client.FunctionOpts{}
+				opts := client.FunctionOpts{
+					Status: status,
+				}
 				err = getRuns(out, errOut, cmd, opts, c)
 			}
 			CheckErr(err)
@@ -79,6 +90,7 @@ func NewCmdGetRun(out io.Writer, errOut io.Writer) *cobra.Command {
 
 	cmd.Flags().BoolVarP(&followRuns, "follow", "f", false, "follow function runs, default: false")
 	cmd.Flags().BoolVar(&last, "last", false, "get last executed run, default: false")
+	cmd.Flags().StringVar(&runStatus, "status", "", "filter runs by status [INITIALIZED|READY|ERROR]")
 	return cmd
 }
 
@@ -0,0 +1,85 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	getServiceClassesLong = i18n.T(`Get service classes offered by the service brokers.`)
+
+	// TODO: add examples
+	getServiceClassesExample = i18n.T(``)
+)
+
+// NewCmdGetServiceClass creates command responsible for getting service classes.
+func NewCmdGetServiceClass(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceclass [SERVICE_CLASS_NAME]",
+		Short:   i18n.T("Get service classes"),
+		Long:    getServiceClassesLong,
+		Example: getServiceClassesExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"serviceclasses"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := servicesClient()
+			if len(args) == 1 {
+				err = getServiceClass(out, errOut, cmd, args, c)
+			} else {
+				err = getServiceClasses(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getServiceClass(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ServicesClient) error {
+	resp, err := c.GetServiceClass(context.TODO(), dispatchConfig.Organization, args[0])
+	if err != nil {
+		return err
+	}
+	return formatServiceClassOutput(out, false, []v1.ServiceClass{*resp})
+}
+
+func getServiceClasses(out, errOut io.Writer, cmd *cobra.Command, c client.ServicesClient) error {
+	resp, err := c.ListServiceClasses(context.TODO(), dispatchConfig.Organization)
+	if err != nil {
+		return err
+	}
+	return formatServiceClassOutput(out, true, resp)
+}
+
+func formatServiceClassOutput(out io.Writer, list bool, serviceClasses []v1.ServiceClass) error {
+	if w, err := formatOutput(out, list, serviceClasses); w {
+		return err
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Broker", "Plans", "Description"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, serviceClass := range serviceClasses {
+		var plans []string
+		for _, plan := range serviceClass.Plans {
+			plans = append(plans, plan.Name)
+		}
+		table.Append([]string{*serviceClass.Name, *serviceClass.Broker, strings.Join(plans, "\n"), serviceClass.Description})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,84 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	getServiceInstancesLong = i18n.T(`Get service instances.`)
+
+	// TODO: add examples
+	getServiceInstancesExample = i18n.T(``)
+)
+
+// NewCmdGetServiceInstance creates command responsible for getting service instances.
+func NewCmdGetServiceInstance(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceinstance [SERVICE_INSTANCE_NAME]",
+		Short:   i18n.T("Get service instances"),
+		Long:    getServiceInstancesLong,
+		Example: getServiceInstancesExample,
+		Args:    cobra.MaximumNArgs(1),
+		Aliases: []string{"serviceinstances"},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			c := servicesClient()
+			if len(args) == 1 {
+				err = getServiceInstance(out, errOut, cmd, args, c)
+			} else {
+				err = getServiceInstances(out, errOut, cmd, c)
+			}
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func getServiceInstance(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.ServicesClient) error {
+	resp, err := c.GetServiceInstance(context.TODO(), dispatchConfig.Organization, args[0])
+	if err != nil {
+		return err
+	}
+	return formatServiceInstanceOutput(out, false, []v1.ServiceInstance{*resp})
+}
+
+func getServiceInstances(out, errOut io.Writer, cmd *cobra.Command, c client.ServicesClient) error {
+	resp, err := c.ListServiceInstances(context.TODO(), dispatchConfig.Organization)
+	if err != nil {
+		return err
+	}
+	return formatServiceInstanceOutput(out, true, resp)
+}
+
+func formatServiceInstanceOutput(out io.Writer, list bool, serviceInstances []v1.ServiceInstance) error {
+	if w, err := formatOutput(out, list, serviceInstances); w {
+		return err
+	}
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Class", "Plan", "Status", "Binding"})
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	for _, serviceInstance := range serviceInstances {
+		binding := ""
+		if serviceInstance.Binding != nil {
+			binding = string(serviceInstance.Binding.Status)
+		}
+		table.Append([]string{*serviceInstance.Name, *serviceInstance.ServiceClass, *serviceInstance.ServicePlan, string(serviceInstance.Status), binding})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,35 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	replayLong = i18n.T(`Replay resources. See subcommands for resources that can be replayed.`)
+
+	replayExample = i18n.T(`
+# Emit the event of a dead letter again
+dispatch replay deadletter DEAD_LETTER`)
+)
+
+// NewCmdReplay creates a command object for the generic "replay" action.
+func NewCmdReplay(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "replay TYPE NAME",
+		Short:   i18n.T("Replay resources."),
+		Long:    replayLong,
+		Example: replayExample,
+		Run:     runHelp,
+	}
+	cmd.AddCommand(NewCmdReplayDeadLetter(out, errOut))
+	return cmd
+}
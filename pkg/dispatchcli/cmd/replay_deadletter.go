@@ -0,0 +1,61 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vmware/dispatch/pkg/client"
+	"golang.org/x/net/context"
+
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	replayDeadLetterLong = i18n.T(`Emit the event of a dead letter again and remove the dead letter.
+All subscriptions to the event type of the event receive it again.`)
+
+	replayDeadLetterExample = i18n.T(`
+# Emit the event of a dead letter again
+dispatch replay deadletter DEAD_LETTER`)
+)
+
+// NewCmdReplayDeadLetter creates command responsible for replaying dead letters.
+func NewCmdReplayDeadLetter(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deadletter DEAD_LETTER [DEAD_LETTER...]",
+		Short:   i18n.T("Replay dead letters"),
+		Long:    replayDeadLetterLong,
+		Example: replayDeadLetterExample,
+		Args:    cobra.MinimumNArgs(1),
+		Aliases: []string{"deadletters"},
+		Run: func(cmd *cobra.Command, args []string) {
+			c := eventManagerClient()
+			err := replayDeadLetters(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func replayDeadLetters(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.EventsClient) error {
+	for _, name := range args {
+		deadLetter, err := c.ReplayDeadLetter(context.TODO(), "", name)
+		if err != nil {
+			return err
+		}
+		if w, err := formatOutput(out, false, deadLetter); w {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(out, "Replayed dead letter: %s\n", deadLetter.Name)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdReplayDeadLetter(t *testing.T) {
+	var buf bytes.Buffer
+
+	cli := NewCLI(os.Stdin, &buf, &buf)
+	cli.SetOutput(&buf)
+	cli.SetArgs([]string{"replay", "deadletter", "--help"})
+	err := cli.Execute()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), "Emit the event of a dead letter again"))
+}
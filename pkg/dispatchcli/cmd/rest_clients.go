@@ -66,6 +66,10 @@ func secretsClient() client.SecretsClient {
 	return client.NewSecretsClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig(), getProjectFromConfig())
 }
 
+func configsClient() client.ConfigsClient {
+	return client.NewConfigsClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig(), getProjectFromConfig())
+}
+
 func endpointsClient() client.EndpointsClient {
 	return client.NewEndpointsClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig())
 }
@@ -77,3 +81,11 @@ func eventManagerClient() client.EventsClient {
 func identityManagerClient() client.IdentityClient {
 	return client.NewIdentityClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig())
 }
+
+func servicesClient() client.ServicesClient {
+	return client.NewServicesClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig(), getProjectFromConfig())
+}
+
+func applicationsClient() client.ApplicationsClient {
+	return client.NewApplicationsClient(getDispatchHost(), GetAuthInfoWriter(), getOrgFromConfig(), getProjectFromConfig())
+}
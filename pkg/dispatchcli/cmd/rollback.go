@@ -0,0 +1,35 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	rollbackLong = i18n.T(`Roll back a resource to an earlier revision. See subcommands for resources that can be rolled back.`)
+
+	rollbackExample = i18n.T(`
+# Send all traffic of function "hello-py" to the revision before the current one
+dispatch rollback function hello-py`)
+)
+
+// NewCmdRollback creates a command object for the generic "rollback" action.
+func NewCmdRollback(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rollback TYPE NAME",
+		Short:   i18n.T("Roll back resources to an earlier revision."),
+		Long:    rollbackLong,
+		Example: rollbackExample,
+		Run:     runHelp,
+	}
+	cmd.AddCommand(NewCmdRollbackFunction(out, errOut))
+	return cmd
+}
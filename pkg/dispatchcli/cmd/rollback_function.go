@@ -0,0 +1,79 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	rollbackFunctionLong = i18n.T(`Roll back a function. All traffic of the function is sent to the last READY revision
+created before the revision currently serving most of the traffic.`)
+
+	rollbackFunctionExample = i18n.T(`
+dispatch rollback function hello-py`)
+)
+
+// NewCmdRollbackFunction creates command responsible for rolling back functions.
+func NewCmdRollbackFunction(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "function FUNCTION_NAME",
+		Short:   i18n.T("Roll back function"),
+		Long:    rollbackFunctionLong,
+		Example: rollbackFunctionExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := functionsClient()
+			err := rollbackFunction(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	return cmd
+}
+
+func rollbackFunction(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.FunctionsClient) error {
+	function, err := c.GetFunction(context.TODO(), dispatchConfig.Organization, args[0])
+	if err != nil {
+		return err
+	}
+	revision, err := rollbackRevision(function.Revisions)
+	if err != nil {
+		return errors.Wrapf(err, "rolling back function %s", args[0])
+	}
+	traffic := []*v1.TrafficTarget{{Revision: swag.String(revision), Percent: 100}}
+	return patchFunction(out, c, args[0], func(function *v1.Function) {
+		function.Traffic = traffic
+	})
+}
+
+// rollbackRevision returns the last READY revision created before the revision serving most of the traffic (or the
+// latest revision if none serves traffic). Revisions are ordered by creation.
+func rollbackRevision(revisions []*v1.FunctionRevision) (string, error) {
+	if len(revisions) == 0 {
+		return "", errors.New("function has no revisions")
+	}
+	current := len(revisions) - 1
+	for i, revision := range revisions {
+		if revision.Percent > revisions[current].Percent {
+			current = i
+		}
+	}
+	for i := current - 1; i >= 0; i-- {
+		if revisions[i].Status == v1.StatusREADY {
+			return revisions[i].Name, nil
+		}
+	}
+	return "", errors.Errorf("no READY revision before %s", revisions[current].Name)
+}
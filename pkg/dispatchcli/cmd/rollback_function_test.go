@@ -0,0 +1,34 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestRollbackRevision(t *testing.T) {
+	revisions := []*v1.FunctionRevision{
+		{Name: "rev1", Status: v1.StatusREADY},
+		{Name: "rev2", Status: v1.StatusERROR},
+		{Name: "rev3", Status: v1.StatusREADY, Percent: 100},
+		{Name: "rev4", Status: v1.StatusREADY},
+	}
+	revision, err := rollbackRevision(revisions)
+	assert.NoError(t, err)
+	assert.Equal(t, "rev1", revision)
+
+	// Without traffic information the latest revision is the current one
+	revisions[2].Percent = 0
+	revision, err = rollbackRevision(revisions)
+	assert.NoError(t, err)
+	assert.Equal(t, "rev3", revision)
+
+	_, err = rollbackRevision(revisions[:1])
+	assert.Error(t, err)
+}
@@ -42,6 +42,8 @@ func NewCmdUpdate(out io.Writer, errOut io.Writer) *cobra.Command {
 			eventClient := eventManagerClient()
 			endptClient := endpointsClient()
 			secClient := secretsClient()
+			cfgClient := configsClient()
+			appClient := applicationsClient()
 			iamClient := identityManagerClient()
 
 			updateMap := map[string]ModelAction{
@@ -52,7 +54,9 @@ func NewCmdUpdate(out io.Writer, errOut io.Writer) *cobra.Command {
 				v1.FunctionKind:       CallUpdateFunction(fnClient),
 				v1.ImageKind:          CallUpdateImage(imgClient),
 				v1.SecretKind:         CallUpdateSecret(secClient),
+				v1.ConfigurationKind:  CallUpdateConfig(cfgClient),
 				v1.SubscriptionKind:   CallUpdateSubscription(eventClient),
+				v1.ApplicationKind:    CallUpdateApplication(appClient),
 				v1.PolicyKind:         CallUpdatePolicy(iamClient),
 				v1.ServiceAccountKind: CallUpdateServiceAccount(iamClient),
 				v1.OrganizationKind:   CallUpdateOrganization(iamClient),
@@ -63,6 +67,10 @@ func NewCmdUpdate(out io.Writer, errOut io.Writer) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(NewCmdUpdateFunction(out, errOut))
+
+	addWaitFlags(cmd.PersistentFlags())
+
 	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to YAML file")
 	cmd.Flags().StringVarP(&workDir, "work-dir", "w", "", "Working directory relative paths are based on")
 
@@ -110,7 +118,7 @@ func CallUpdateBaseImage(c client.BaseImagesClient) ModelAction {
 			return err
 		}
 
-		return nil
+		return waitUpdatedIfRequested(v1.BaseImageKind, baseImage.Name, baseImageWaiter(c, baseImage))
 	}
 }
 
@@ -124,7 +132,7 @@ func CallUpdateDriver(c client.EventsClient) ModelAction {
 			return err
 		}
 
-		return nil
+		return waitUpdatedIfRequested(v1.DriverKind, *eventDriver.Name, eventDriverWaiter(c, eventDriver))
 	}
 }
 
@@ -161,7 +169,7 @@ func CallUpdateImage(c client.ImagesClient) ModelAction {
 			return err
 		}
 
-		return nil
+		return waitUpdatedIfRequested(v1.ImageKind, img.Name, imageWaiter(c, img))
 	}
 }
 
@@ -208,6 +216,16 @@ func CallUpdateOrganization(c client.IdentityClient) ModelAction {
 	}
 }
 
+// CallUpdateConfig makes the API call to update a config
+func CallUpdateConfig(c client.ConfigsClient) ModelAction {
+	return func(input interface{}) error {
+		configModel := input.(*v1.Configuration)
+
+		_, err := c.UpdateConfig(context.TODO(), "", configModel)
+		return err
+	}
+}
+
 // CallUpdateSecret makes the API call to update a secret
 func CallUpdateSecret(c client.SecretsClient) ModelAction {
 	return func(input interface{}) error {
@@ -235,3 +253,13 @@ func CallUpdateSubscription(c client.EventsClient) ModelAction {
 		return nil
 	}
 }
+
+// CallUpdateApplication makes the API call to update an application
+func CallUpdateApplication(c client.ApplicationsClient) ModelAction {
+	return func(input interface{}) error {
+		application := input.(*v1.Application)
+
+		_, err := c.UpdateApplication(context.TODO(), "", application)
+		return err
+	}
+}
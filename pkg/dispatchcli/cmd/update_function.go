@@ -7,11 +7,57 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	updateFunctionLong = i18n.T(`Update the traffic split, resources or scaling of a function. The traffic of a function can
+be split between two of its revisions, use "dispatch get function FUNCTION_NAME -o yaml" to list the revisions.`)
+
+	updateFunctionExample = i18n.T(`
+# Send 10% of the traffic to a new revision
+dispatch update function hello-py --traffic d-fn-default-hello-py-00001=90,d-fn-default-hello-py-00002=10
+
+# Send all traffic to the latest revision again
+dispatch update function hello-py --traffic latest
+
+# Serve up to 50 concurrent requests per instance and keep at least one instance running
+dispatch update function hello-py --concurrency 50 --min-scale 1 --memory-limit 256Mi
+`)
+
+	functionTraffic = ""
 )
 
+// NewCmdUpdateFunction creates command responsible for updating functions.
+func NewCmdUpdateFunction(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "function FUNCTION_NAME [--traffic REVISION=PERCENT[,REVISION=PERCENT]] [--concurrency N] [--min-scale N] [--max-scale N]",
+		Short:   i18n.T("Update function"),
+		Long:    updateFunctionLong,
+		Example: updateFunctionExample,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := functionsClient()
+			err := updateFunction(out, errOut, cmd, args, c)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&functionTraffic, "traffic", "", "Traffic split between revisions, or \"latest\" to send all traffic to the latest revision")
+	addFunctionScalingFlags(cmd)
+	return cmd
+}
+
 // CallUpdateFunction makes the API call to update a function
 func CallUpdateFunction(c client.FunctionsClient) ModelAction {
 	return func(input interface{}) error {
@@ -22,6 +68,74 @@ func CallUpdateFunction(c client.FunctionsClient) ModelAction {
 			return err
 		}
 
-		return nil
+		return waitUpdatedIfRequested(v1.FunctionKind, function.Name, functionWaiter(c, function))
+	}
+}
+
+func updateFunction(out, errOut io.Writer, cmd *cobra.Command, args []string, c client.FunctionsClient) error {
+	if functionTraffic == "" && !functionScalingChanged(cmd) {
+		return errors.New("nothing to update, use --traffic to split the traffic between revisions or the resource and scaling flags")
+	}
+	var traffic []*v1.TrafficTarget
+	if functionTraffic != "" {
+		var err error
+		if traffic, err = parseTraffic(functionTraffic); err != nil {
+			return err
+		}
+	}
+	return patchFunction(out, c, args[0], func(function *v1.Function) {
+		if traffic != nil {
+			function.Traffic = traffic
+		}
+		setFunctionScaling(cmd, function)
+	})
+}
+
+// patchFunction fetches a function, applies patch to it and updates it
+func patchFunction(out io.Writer, c client.FunctionsClient, functionName string, patch func(function *v1.Function)) error {
+	function, err := c.GetFunction(context.TODO(), dispatchConfig.Organization, functionName)
+	if err != nil {
+		return err
+	}
+	patch(function)
+	function.Revisions = nil
+	function.BackingObject = nil
+
+	updated, err := c.UpdateFunction(context.TODO(), dispatchConfig.Organization, function)
+	if err != nil {
+		return err
+	}
+	if err := waitUpdatedIfRequested(v1.FunctionKind, updated.Name, functionWaiter(c, updated)); err != nil {
+		return err
+	}
+	if w, err := formatOutput(out, false, updated); w {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Updated function: %s\n", updated.Meta.Name)
+	return err
+}
+
+// parseTraffic parses a traffic split in the form REVISION=PERCENT[,REVISION=PERCENT], a single revision gets all
+// traffic
+func parseTraffic(traffic string) ([]*v1.TrafficTarget, error) {
+	parts := strings.Split(traffic, ",")
+	var targets []*v1.TrafficTarget
+	for _, part := range parts {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		target := &v1.TrafficTarget{Revision: swag.String(kv[0]), Percent: 100}
+		if len(kv) == 2 {
+			percent, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid traffic percentage '%s' of revision %s", kv[1], kv[0])
+			}
+			target.Percent = percent
+		} else if len(parts) > 1 {
+			return nil, errors.Errorf("missing traffic percentage of revision %s", kv[0])
+		}
+		if kv[0] == "" {
+			return nil, errors.Errorf("invalid traffic split '%s'", traffic)
+		}
+		targets = append(targets, target)
 	}
+	return targets, nil
 }
@@ -0,0 +1,57 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestParseTraffic(t *testing.T) {
+	traffic, err := parseTraffic("rev1=90, rev2=10")
+	assert.NoError(t, err)
+	assert.Len(t, traffic, 2)
+	assert.Equal(t, "rev2", *traffic[1].Revision)
+	assert.Equal(t, int64(10), traffic[1].Percent)
+
+	traffic, err = parseTraffic("latest")
+	assert.NoError(t, err)
+	assert.Equal(t, []*v1.TrafficTarget{{Revision: traffic[0].Revision, Percent: 100}}, traffic)
+	assert.Equal(t, v1.LatestRevision, *traffic[0].Revision)
+
+	_, err = parseTraffic("rev1,rev2=10")
+	assert.Error(t, err)
+	_, err = parseTraffic("rev1=ten")
+	assert.Error(t, err)
+}
+
+func TestSetFunctionScaling(t *testing.T) {
+	cmd := NewCmdUpdateFunction(ioutil.Discard, ioutil.Discard)
+	require.NoError(t, cmd.ParseFlags([]string{"--memory-limit", "1Gi", "--concurrency", "0", "--max-scale", "5"}))
+	assert.True(t, functionScalingChanged(cmd))
+
+	function := &v1.Function{
+		Resources: &v1.FunctionResources{Requests: &v1.ResourceList{CPU: "250m"}},
+		MinScale:  1,
+	}
+	setFunctionScaling(cmd, function)
+	assert.Equal(t, &v1.FunctionResources{
+		Requests: &v1.ResourceList{CPU: "250m"},
+		Limits:   &v1.ResourceList{Memory: "1Gi"},
+	}, function.Resources)
+	assert.Equal(t, swag.Int64(0), function.ContainerConcurrency)
+	assert.Equal(t, int64(1), function.MinScale)
+	assert.Equal(t, int64(5), function.MaxScale)
+
+	cmd = NewCmdUpdateFunction(ioutil.Discard, ioutil.Discard)
+	require.NoError(t, cmd.ParseFlags([]string{"--traffic", "latest"}))
+	assert.False(t, functionScalingChanged(cmd))
+}
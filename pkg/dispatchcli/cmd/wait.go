@@ -0,0 +1,185 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/dispatchcli/i18n"
+)
+
+var (
+	waitLong = i18n.T(`Wait until a base image, image, function or event driver reaches a status, READY by default.
+Fails when the resource goes to ERROR instead, with the reason of the error, or when the timeout expires.`)
+
+	// TODO: Add examples
+	waitExample = i18n.T(``)
+
+	waitStatus  = ""
+	waitTimeout = 5 * time.Minute
+
+	cmdFlagWait        = false
+	cmdFlagWaitTimeout = 5 * time.Minute
+
+	// updateSettleTimeout bounds the wait for an updated resource to leave READY
+	updateSettleTimeout = 10 * time.Second
+)
+
+// waiter waits for a resource to reach a status, it returns the status reached and the reason of an ERROR
+type waiter func(ctx context.Context, name string, status v1.Status, timeout time.Duration) (v1.Status, []string, error)
+
+// NewCmdWait creates command waiting for a resource to reach a status.
+func NewCmdWait(out io.Writer, errOut io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "wait KIND NAME [--for STATUS] [--timeout DURATION]",
+		Short:   i18n.T("Wait for a resource to reach a status."),
+		Long:    waitLong,
+		Example: waitExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := waitResource(out, errOut, cmd, args)
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVar(&waitStatus, "for", string(v1.StatusREADY), "Status to wait for")
+	cmd.Flags().DurationVar(&waitTimeout, "timeout", 5*time.Minute, "How long to wait")
+	return cmd
+}
+
+// addWaitFlags adds the flags of the commands waiting for the resources they create or update to be READY
+func addWaitFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&cmdFlagWait, "wait", false, "Wait for base images, images, functions and event drivers to be READY")
+	flags.DurationVar(&cmdFlagWaitTimeout, "wait-timeout", 5*time.Minute, "How long to wait for a resource with --wait")
+}
+
+func waitResource(out, errOut io.Writer, cmd *cobra.Command, args []string) error {
+	var model interface{}
+	var kind string
+	var wait waiter
+	switch strings.TrimSuffix(strings.Replace(strings.ToLower(args[0]), "-", "", -1), "s") {
+	case "baseimage":
+		m := &v1.BaseImage{}
+		kind, model, wait = v1.BaseImageKind, m, baseImageWaiter(baseImagesClient(), m)
+	case "image":
+		m := &v1.Image{}
+		kind, model, wait = v1.ImageKind, m, imageWaiter(imagesClient(), m)
+	case "function":
+		m := &v1.Function{}
+		kind, model, wait = v1.FunctionKind, m, functionWaiter(functionsClient(), m)
+	case "eventdriver":
+		m := &v1.EventDriver{}
+		kind, model, wait = v1.DriverKind, m, eventDriverWaiter(eventManagerClient(), m)
+	default:
+		return errors.Errorf("cannot wait for %s, only base images, images, functions and event drivers have a status", args[0])
+	}
+
+	status := v1.Status(strings.ToUpper(waitStatus))
+	if err := waitFor(context.TODO(), kind, args[1], status, waitTimeout, wait); err != nil {
+		return err
+	}
+	if w, err := formatOutput(out, false, model); w {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%s %s is %s\n", kind, args[1], status)
+	return err
+}
+
+// waitFor waits for a resource to reach a status, turning an ERROR status or a timeout into an error
+func waitFor(ctx context.Context, kind, name string, status v1.Status, timeout time.Duration, wait waiter) error {
+	reached, reason, err := wait(ctx, name, status, timeout)
+	if err != nil {
+		return err
+	}
+	switch {
+	case reached == status:
+		return nil
+	case reached == v1.StatusERROR && len(reason) > 0:
+		return errors.Errorf("%s %s failed: %s", kind, name, strings.Join(reason, "; "))
+	case reached == v1.StatusERROR:
+		return errors.Errorf("%s %s failed", kind, name)
+	}
+	return errors.Errorf("timed out after %s waiting for %s %s to be %s, it is %s", timeout, kind, name, status, reached)
+}
+
+// waitIfRequested waits for a resource created or updated to be READY when --wait is set
+func waitIfRequested(kind, name string, wait waiter) error {
+	if !cmdFlagWait {
+		return nil
+	}
+	return waitFor(context.TODO(), kind, name, v1.StatusREADY, cmdFlagWaitTimeout, wait)
+}
+
+// waitUpdatedIfRequested waits for a resource updated to be READY when --wait is set. The resource stays READY until
+// the server picks the update up, so it first waits for the resource to leave READY, for at most updateSettleTimeout
+// as updates which don't roll anything out leave the resource READY.
+func waitUpdatedIfRequested(kind, name string, wait waiter) error {
+	if !cmdFlagWait {
+		return nil
+	}
+	settle := updateSettleTimeout
+	if settle > cmdFlagWaitTimeout {
+		settle = cmdFlagWaitTimeout
+	}
+	start := time.Now()
+	if _, _, err := wait(context.TODO(), name, v1.StatusUPDATING, settle); err != nil {
+		return err
+	}
+	return waitFor(context.TODO(), kind, name, v1.StatusREADY, cmdFlagWaitTimeout-time.Since(start), wait)
+}
+
+func baseImageWaiter(c client.BaseImagesClient, into *v1.BaseImage) waiter {
+	return func(ctx context.Context, name string, status v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		baseImage, err := c.WaitBaseImage(ctx, dispatchConfig.Organization, name, status, timeout)
+		if err != nil {
+			return "", nil, err
+		}
+		*into = *baseImage
+		return baseImage.Status, baseImage.Reason, nil
+	}
+}
+
+func imageWaiter(c client.ImagesClient, into *v1.Image) waiter {
+	return func(ctx context.Context, name string, status v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		image, err := c.WaitImage(ctx, dispatchConfig.Organization, name, status, timeout)
+		if err != nil {
+			return "", nil, err
+		}
+		*into = *image
+		return image.Status, image.Reason, nil
+	}
+}
+
+func functionWaiter(c client.FunctionsClient, into *v1.Function) waiter {
+	return func(ctx context.Context, name string, status v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		function, err := c.WaitFunction(ctx, dispatchConfig.Organization, name, status, timeout)
+		if err != nil {
+			return "", nil, err
+		}
+		*into = *function
+		return function.Status, function.Reason, nil
+	}
+}
+
+func eventDriverWaiter(c client.EventsClient, into *v1.EventDriver) waiter {
+	return func(ctx context.Context, name string, status v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		driver, err := c.WaitEventDriver(ctx, dispatchConfig.Organization, name, status, timeout)
+		if err != nil {
+			return "", nil, err
+		}
+		*into = *driver
+		return driver.Status, driver.Reason, nil
+	}
+}
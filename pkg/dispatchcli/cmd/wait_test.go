@@ -0,0 +1,83 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+)
+
+func reaching(status v1.Status, reason ...string) waiter {
+	return func(ctx context.Context, name string, want v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		return status, reason, nil
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, waitFor(ctx, v1.FunctionKind, "hello", v1.StatusREADY, time.Minute, reaching(v1.StatusREADY)))
+	assert.NoError(t, waitFor(ctx, v1.FunctionKind, "hello", v1.StatusERROR, time.Minute, reaching(v1.StatusERROR)))
+
+	err := waitFor(ctx, v1.ImageKind, "python", v1.StatusREADY, time.Minute, reaching(v1.StatusERROR, "pull failed", "retrying"))
+	assert.EqualError(t, err, "Image python failed: pull failed; retrying")
+
+	err = waitFor(ctx, v1.ImageKind, "python", v1.StatusREADY, time.Minute, reaching(v1.StatusCREATING))
+	assert.EqualError(t, err, "timed out after 1m0s waiting for Image python to be READY, it is CREATING")
+
+	failing := func(ctx context.Context, name string, want v1.Status, timeout time.Duration) (v1.Status, []string, error) {
+		return "", nil, errors.New("not found")
+	}
+	assert.EqualError(t, waitFor(ctx, v1.ImageKind, "python", v1.StatusREADY, time.Minute, failing), "not found")
+}
+
+func TestCreateFunctionWait(t *testing.T) {
+	defer func() { cmdFlagWait = false }()
+
+	fnClient := &mocks.FunctionsClient{}
+	function := &v1.Function{Meta: v1.Meta{Name: "hello"}}
+	creating := &v1.Function{Meta: v1.Meta{Name: "hello"}, Status: v1.StatusCREATING}
+	ready := &v1.Function{Meta: v1.Meta{Name: "hello"}, Status: v1.StatusREADY}
+	fnClient.On("CreateFunction", mock.Anything, mock.Anything, mock.Anything).Return(creating, nil)
+
+	// Without --wait the function is returned as created
+	require.NoError(t, CallCreateFunction(fnClient)(function))
+	assert.Equal(t, v1.StatusCREATING, function.Status)
+	fnClient.AssertNotCalled(t, "WaitFunction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	cmdFlagWait = true
+	cmdFlagWaitTimeout = time.Minute
+	function = &v1.Function{Meta: v1.Meta{Name: "hello"}}
+	fnClient.On("WaitFunction", mock.Anything, mock.Anything, "hello", v1.StatusREADY, time.Minute).Return(ready, nil)
+	require.NoError(t, CallCreateFunction(fnClient)(function))
+	assert.Equal(t, v1.StatusREADY, function.Status)
+}
+
+func TestUpdateFunctionWait(t *testing.T) {
+	defer func() { cmdFlagWait = false }()
+	cmdFlagWait = true
+	cmdFlagWaitTimeout = time.Minute
+
+	fnClient := &mocks.FunctionsClient{}
+	function := &v1.Function{Meta: v1.Meta{Name: "hello"}}
+	updating := &v1.Function{Meta: v1.Meta{Name: "hello"}, Status: v1.StatusUPDATING}
+	ready := &v1.Function{Meta: v1.Meta{Name: "hello"}, Status: v1.StatusREADY}
+	fnClient.On("UpdateFunction", mock.Anything, mock.Anything, mock.Anything).Return(ready, nil)
+	// The function is READY right after the update, until the update is picked up
+	fnClient.On("WaitFunction", mock.Anything, mock.Anything, "hello", v1.StatusUPDATING, updateSettleTimeout).Return(updating, nil).Once()
+	fnClient.On("WaitFunction", mock.Anything, mock.Anything, "hello", v1.StatusREADY, mock.Anything).Return(ready, nil).Once()
+
+	require.NoError(t, CallUpdateFunction(fnClient)(function))
+	assert.Equal(t, v1.StatusREADY, function.Status)
+	fnClient.AssertExpectations(t)
+}
@@ -0,0 +1,268 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/secrets/service"
+)
+
+const (
+	// HeaderAPIKey is the header API key clients send their key in
+	HeaderAPIKey = "X-API-Key"
+
+	// HeaderMethod tells the function how the caller was authenticated
+	HeaderMethod = "X-Dispatch-Auth-Method"
+	// HeaderSubject tells the function who the caller is: the username, the name of the API key or the JWT subject
+	HeaderSubject = "X-Dispatch-Auth-Subject"
+	// HeaderClaims passes the claims of the JWT to the function, as base64 encoded JSON
+	HeaderClaims = "X-Dispatch-Auth-Claims"
+
+	// credentialsMaxAge is how long the credentials of an endpoint are cached, changes of the secret take up to that
+	// long to apply
+	credentialsMaxAge = 30 * time.Second
+)
+
+// Identity is the verified identity of the caller of an endpoint
+type Identity struct {
+	Method  string
+	Subject string
+	Claims  map[string]interface{}
+}
+
+type identityKey struct{}
+
+// NewContext returns a context carrying the identity of the caller
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity of the caller, nil if the endpoint is public
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// SetHeaders replaces the identity headers of a request forwarded to a function, so that callers can't pass an
+// identity of their own
+func SetHeaders(header http.Header, identity *Identity) {
+	header.Del(HeaderMethod)
+	header.Del(HeaderSubject)
+	header.Del(HeaderClaims)
+	if identity == nil {
+		return
+	}
+	header.Set(HeaderMethod, identity.Method)
+	header.Set(HeaderSubject, identity.Subject)
+	if len(identity.Claims) > 0 {
+		claims, err := json.Marshal(identity.Claims)
+		if err != nil {
+			log.Warnf("error encoding the claims of %s: %v", identity.Subject, err)
+			return
+		}
+		header.Set(HeaderClaims, base64.StdEncoding.EncodeToString(claims))
+	}
+}
+
+// Error is an authentication failure, turned into a 401 or a 429 response
+type Error struct {
+	Code       int
+	Challenge  string
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WriteResponse writes the response of an authentication failure
+func (e *Error) WriteResponse(w http.ResponseWriter) {
+	if e.Challenge != "" {
+		w.Header().Set("WWW-Authenticate", e.Challenge)
+	}
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((e.RetryAfter+time.Second-1)/time.Second)))
+	}
+	http.Error(w, e.Message, e.Code)
+}
+
+func unauthorized(challenge, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:      http.StatusUnauthorized,
+		Challenge: challenge,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+// Validate checks that the authentication of an endpoint is complete
+func Validate(auth *dapi.EndpointAuth) error {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Type {
+	case "", dapi.EndpointAuthPublic:
+	case dapi.EndpointAuthBasic, dapi.EndpointAuthAPIKey:
+		if auth.Secret == "" {
+			return errors.Errorf("%s authentication requires a secret", auth.Type)
+		}
+	case dapi.EndpointAuthOAuth2:
+		if auth.Issuer == "" && auth.JwksURI == "" {
+			return errors.New("oauth2 authentication requires an issuer or a JWKS URI")
+		}
+	default:
+		return errors.Errorf("unsupported authentication method %s", auth.Type)
+	}
+	for key, quota := range auth.KeyQuotas {
+		if quota < 0 {
+			return errors.Errorf("negative quota for API key %s", key)
+		}
+	}
+	return nil
+}
+
+// IsPublic tells whether an endpoint is reachable without authentication
+func IsPublic(endpoint *dapi.Endpoint) bool {
+	return endpoint.Auth == nil || endpoint.Auth.Type == "" || endpoint.Auth.Type == dapi.EndpointAuthPublic
+}
+
+// Authenticator authenticates the callers of endpoints.  It is shared by all endpoints, keeping the API key quotas
+// and the JWT signing keys across requests.
+type Authenticator struct {
+	secrets service.SecretsService
+	quotas  *quotas
+	keys    *keySets
+
+	credentialsLock sync.Mutex
+	credentialsSets map[string]*cachedCredentials
+}
+
+type cachedCredentials struct {
+	values  dapi.SecretValue
+	fetched time.Time
+}
+
+// NewAuthenticator creates an authenticator reading credentials from secrets
+func NewAuthenticator(secrets service.SecretsService, client *http.Client) *Authenticator {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Authenticator{
+		secrets:         secrets,
+		quotas:          newQuotas(),
+		keys:            newKeySets(client),
+		credentialsSets: make(map[string]*cachedCredentials),
+	}
+}
+
+// Authenticate authenticates the caller of an endpoint, returning a nil identity for public endpoints and an *Error
+// when the caller is rejected
+func (a *Authenticator) Authenticate(r *http.Request, endpoint *dapi.Endpoint) (*Identity, error) {
+	if IsPublic(endpoint) {
+		return nil, nil
+	}
+	switch endpoint.Auth.Type {
+	case dapi.EndpointAuthBasic:
+		return a.basic(r, endpoint)
+	case dapi.EndpointAuthAPIKey:
+		return a.apiKey(r, endpoint)
+	case dapi.EndpointAuthOAuth2:
+		return a.oauth2(r, endpoint)
+	}
+	return nil, errors.Errorf("unsupported authentication method %s", endpoint.Auth.Type)
+}
+
+// credentials returns the values of the secret of an endpoint, cached for credentialsMaxAge
+func (a *Authenticator) credentials(ctx context.Context, endpoint *dapi.Endpoint) (dapi.SecretValue, error) {
+	key := strings.Join([]string{endpoint.Org, endpoint.Project, endpoint.Auth.Secret}, "/")
+	a.credentialsLock.Lock()
+	cached := a.credentialsSets[key]
+	a.credentialsLock.Unlock()
+	if cached != nil && time.Since(cached.fetched) < credentialsMaxAge {
+		return cached.values, nil
+	}
+
+	secret, err := a.secrets.GetSecret(ctx, &dapi.Meta{
+		Name:    endpoint.Auth.Secret,
+		Org:     endpoint.Org,
+		Project: endpoint.Project,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting the credentials of endpoint %s", endpoint.Name)
+	}
+	a.credentialsLock.Lock()
+	a.credentialsSets[key] = &cachedCredentials{values: secret.Secrets, fetched: time.Now()}
+	a.credentialsLock.Unlock()
+	return secret.Secrets, nil
+}
+
+func (a *Authenticator) basic(r *http.Request, endpoint *dapi.Endpoint) (*Identity, error) {
+	challenge := fmt.Sprintf(`Basic realm="%s"`, endpoint.Name)
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, unauthorized(challenge, "missing credentials")
+	}
+	credentials, err := a.credentials(r.Context(), endpoint)
+	if err != nil {
+		return nil, err
+	}
+	expected, found := credentials[username]
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(password)) != 1 || !found {
+		return nil, unauthorized(challenge, "invalid credentials")
+	}
+	return &Identity{Method: dapi.EndpointAuthBasic, Subject: username}, nil
+}
+
+func (a *Authenticator) apiKey(r *http.Request, endpoint *dapi.Endpoint) (*Identity, error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		return nil, unauthorized("", "missing API key")
+	}
+	keys, err := a.credentials(r.Context(), endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// Keys are compared in constant time, so the name of the key is found by going through all of them
+	name := ""
+	for keyName, expected := range keys {
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(key)) == 1 {
+			name = keyName
+		}
+	}
+	if name == "" {
+		return nil, unauthorized("", "invalid API key")
+	}
+
+	quota := endpoint.Auth.Quota
+	if keyQuota, ok := endpoint.Auth.KeyQuotas[name]; ok {
+		quota = keyQuota
+	}
+	if quota > 0 {
+		quotaKey := strings.Join([]string{endpoint.Org, endpoint.Project, endpoint.Name, name}, "/")
+		if retryAfter, ok := a.quotas.take(quotaKey, quota, time.Now()); !ok {
+			return nil, &Error{
+				Code:       http.StatusTooManyRequests,
+				RetryAfter: retryAfter,
+				Message:    fmt.Sprintf("quota of %d requests per hour exceeded", quota),
+			}
+		}
+	}
+	return &Identity{Method: dapi.EndpointAuthAPIKey, Subject: name}, nil
+}
@@ -0,0 +1,288 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/secrets/service"
+)
+
+type testSecrets struct {
+	service.SecretsService
+	secrets map[string]dapi.SecretValue
+	gets    int
+}
+
+func (s *testSecrets) GetSecret(ctx context.Context, meta *dapi.Meta) (*dapi.Secret, error) {
+	s.gets++
+	value, ok := s.secrets[meta.Name]
+	if !ok {
+		return nil, service.SecretNotFound{}
+	}
+	return &dapi.Secret{Name: &meta.Name, Secrets: value}, nil
+}
+
+func testAuthenticator() *Authenticator {
+	return NewAuthenticator(&testSecrets{secrets: map[string]dapi.SecretValue{
+		"users": {"alice": "secret"},
+		"keys":  {"ci": "key-ci", "app": "key-app"},
+	}}, nil)
+}
+
+func testEndpoint(auth *dapi.EndpointAuth) *dapi.Endpoint {
+	return &dapi.Endpoint{
+		Meta: dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Auth: auth,
+	}
+}
+
+func assertRejected(t *testing.T, err error, code int) {
+	require.Error(t, err)
+	authErr, ok := err.(*Error)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, code, authErr.Code)
+}
+
+func TestPublic(t *testing.T) {
+	identity, err := testAuthenticator().Authenticate(httptest.NewRequest("GET", "/hello", nil), testEndpoint(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+}
+
+func TestBasic(t *testing.T) {
+	a := testAuthenticator()
+	endpoint := testEndpoint(&dapi.EndpointAuth{Type: dapi.EndpointAuthBasic, Secret: "users"})
+
+	r := httptest.NewRequest("GET", "/hello", nil)
+	_, err := a.Authenticate(r, endpoint)
+	assertRejected(t, err, http.StatusUnauthorized)
+	assert.Equal(t, `Basic realm="hello"`, err.(*Error).Challenge)
+
+	r.SetBasicAuth("alice", "wrong")
+	_, err = a.Authenticate(r, endpoint)
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	r.SetBasicAuth("bob", "")
+	_, err = a.Authenticate(r, endpoint)
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	r.SetBasicAuth("alice", "secret")
+	identity, err := a.Authenticate(r, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Method: dapi.EndpointAuthBasic, Subject: "alice"}, identity)
+	// The credentials are read once, then cached
+	assert.Equal(t, 1, a.secrets.(*testSecrets).gets)
+}
+
+func TestAPIKeyQuota(t *testing.T) {
+	a := testAuthenticator()
+	endpoint := testEndpoint(&dapi.EndpointAuth{
+		Type:      dapi.EndpointAuthAPIKey,
+		Secret:    "keys",
+		Quota:     2,
+		KeyQuotas: map[string]int64{"ci": 0},
+	})
+
+	r := httptest.NewRequest("GET", "/hello", nil)
+	_, err := a.Authenticate(r, endpoint)
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	r.Header.Set(HeaderAPIKey, "key-app")
+	for i := 0; i < 2; i++ {
+		identity, err := a.Authenticate(r, endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, "app", identity.Subject)
+	}
+	_, err = a.Authenticate(r, endpoint)
+	assertRejected(t, err, http.StatusTooManyRequests)
+	assert.True(t, err.(*Error).RetryAfter > 0)
+
+	// The quota of ci is not limited
+	r.Header.Set(HeaderAPIKey, "key-ci")
+	for i := 0; i < 5; i++ {
+		_, err := a.Authenticate(r, endpoint)
+		require.NoError(t, err)
+	}
+}
+
+func TestQuotaWindow(t *testing.T) {
+	q := newQuotas()
+	now := time.Now()
+	_, ok := q.take("k", 1, now)
+	assert.True(t, ok)
+	retryAfter, ok := q.take("k", 1, now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, 59*time.Minute, retryAfter)
+	_, ok = q.take("k", 1, now.Add(quotaPeriod))
+	assert.True(t, ok)
+}
+
+// testIssuer serves the OpenID configuration and key set of an issuer signing tokens with an RSA key
+func testIssuer(t *testing.T) (*httptest.Server, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	return server, key
+}
+
+func signToken(t *testing.T, key interface{}, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestOAuth2(t *testing.T) {
+	server, key := testIssuer(t)
+	defer server.Close()
+
+	a := testAuthenticator()
+	endpoint := testEndpoint(&dapi.EndpointAuth{
+		Type:      dapi.EndpointAuthOAuth2,
+		Issuer:    server.URL,
+		Audiences: []string{"hello-api"},
+	})
+	authenticate := func(token string) (*Identity, error) {
+		r := httptest.NewRequest("GET", "/hello", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return a.Authenticate(r, endpoint)
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   server.URL,
+			"sub":   "alice",
+			"aud":   []string{"other", "hello-api"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"email": "alice@example.com",
+		}
+	}
+
+	identity, err := authenticate(signToken(t, key, jwt.SigningMethodRS256, claims()))
+	require.NoError(t, err)
+	assert.Equal(t, dapi.EndpointAuthOAuth2, identity.Method)
+	assert.Equal(t, "alice", identity.Subject)
+	assert.Equal(t, "alice@example.com", identity.Claims["email"])
+
+	_, err = authenticate("")
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	expired := claims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err = authenticate(signToken(t, key, jwt.SigningMethodRS256, expired))
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	// Tokens without an expiration would be valid forever
+	noExpiration := claims()
+	delete(noExpiration, "exp")
+	_, err = authenticate(signToken(t, key, jwt.SigningMethodRS256, noExpiration))
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	otherIssuer := claims()
+	otherIssuer["iss"] = "https://issuer.example.com"
+	_, err = authenticate(signToken(t, key, jwt.SigningMethodRS256, otherIssuer))
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	otherAudience := claims()
+	otherAudience["aud"] = "other"
+	_, err = authenticate(signToken(t, key, jwt.SigningMethodRS256, otherAudience))
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = authenticate(signToken(t, otherKey, jwt.SigningMethodRS256, claims()))
+	assertRejected(t, err, http.StatusUnauthorized)
+
+	// Tokens signed with a shared secret are rejected, even if the secret is the public key
+	_, err = authenticate(signToken(t, key.N.Bytes(), jwt.SigningMethodHS256, claims()))
+	assertRejected(t, err, http.StatusUnauthorized)
+}
+
+func TestSetHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set(HeaderSubject, "spoofed")
+	SetHeaders(header, nil)
+	assert.Empty(t, header.Get(HeaderSubject))
+
+	SetHeaders(header, &Identity{Method: "oauth2", Subject: "alice", Claims: map[string]interface{}{"sub": "alice"}})
+	assert.Equal(t, "oauth2", header.Get(HeaderMethod))
+	assert.Equal(t, "alice", header.Get(HeaderSubject))
+	claims, err := base64.StdEncoding.DecodeString(header.Get(HeaderClaims))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sub":"alice"}`, string(claims))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(&dapi.EndpointAuth{Type: dapi.EndpointAuthPublic}))
+	assert.Error(t, Validate(&dapi.EndpointAuth{Type: dapi.EndpointAuthBasic}))
+	assert.NoError(t, Validate(&dapi.EndpointAuth{Type: dapi.EndpointAuthAPIKey, Secret: "keys"}))
+	assert.Error(t, Validate(&dapi.EndpointAuth{Type: dapi.EndpointAuthOAuth2}))
+	assert.NoError(t, Validate(&dapi.EndpointAuth{Type: dapi.EndpointAuthOAuth2, JwksURI: "https://example.com/keys"}))
+}
+
+func TestKeySetsFetchPerURI(t *testing.T) {
+	fast, key := testIssuer(t)
+	defer fast.Close()
+	started, blocked := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-blocked
+		http.NotFound(w, r)
+	}))
+	defer slow.Close()
+	defer close(blocked)
+
+	ks := newKeySets(&http.Client{})
+	go ks.key(context.Background(), "", slow.URL+"/keys", "test")
+	<-started
+
+	// A key set being fetched doesn't hold up the key sets of other issuers
+	done := make(chan interface{})
+	go func() {
+		fetched, _ := ks.key(context.Background(), fast.URL, "", "test")
+		done <- fetched
+	}()
+	select {
+	case fetched := <-done:
+		assert.Equal(t, &key.PublicKey, fetched)
+	case <-time.After(5 * time.Second):
+		t.Fatal("key set fetch held up by another issuer")
+	}
+}
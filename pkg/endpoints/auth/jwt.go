@@ -0,0 +1,303 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+const (
+	// keySetMaxAge is how long key sets are cached
+	keySetMaxAge = time.Hour
+	// keySetMinAge is how long a key set is used before it is fetched again for an unknown key ID
+	keySetMinAge = time.Minute
+)
+
+func (a *Authenticator) oauth2(r *http.Request, endpoint *dapi.Endpoint) (*Identity, error) {
+	auth := endpoint.Auth
+	challenge := fmt.Sprintf(`Bearer realm="%s"`, endpoint.Name)
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, unauthorized(challenge, "missing bearer token")
+	}
+
+	token, err := jwt.Parse(strings.TrimPrefix(header, "Bearer "), func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, errors.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.key(r.Context(), auth.Issuer, auth.JwksURI, kid)
+	})
+	if err != nil {
+		log.Debugf("invalid token for endpoint %s: %v", endpoint.Name, err)
+		return nil, unauthorized(challenge+`, error="invalid_token"`, "invalid token")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	// Tokens without an expiration would be valid forever
+	if _, ok := claims["exp"]; !ok {
+		return nil, unauthorized(challenge+`, error="invalid_token"`, "token without expiration")
+	}
+	if auth.Issuer != "" && !claims.VerifyIssuer(auth.Issuer, true) {
+		return nil, unauthorized(challenge+`, error="invalid_token"`, "invalid token issuer")
+	}
+	if len(auth.Audiences) > 0 && !verifyAudiences(claims, auth.Audiences) {
+		return nil, unauthorized(challenge+`, error="invalid_token"`, "invalid token audience")
+	}
+	subject, _ := claims["sub"].(string)
+	return &Identity{Method: dapi.EndpointAuthOAuth2, Subject: subject, Claims: claims}, nil
+}
+
+// verifyAudiences checks that the token was issued for one of the audiences, aud being either a string or a list
+func verifyAudiences(claims jwt.MapClaims, audiences []string) bool {
+	var tokenAudiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		tokenAudiences = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				tokenAudiences = append(tokenAudiences, s)
+			}
+		}
+	}
+	for _, expected := range audiences {
+		for _, aud := range tokenAudiences {
+			if aud == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type keySet struct {
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+// keySets caches the JSON Web Key Sets of issuers. The maps are guarded by the mutex, while the fetches of an issuer
+// configuration or a key set only hold the lock of their uri, so that a slow issuer doesn't hold up the others.
+type keySets struct {
+	sync.Mutex
+	client *http.Client
+	uris   map[string]string
+	sets   map[string]*keySet
+	// fetching holds the locks of the uris being fetched
+	fetching map[string]*sync.Mutex
+}
+
+func newKeySets(client *http.Client) *keySets {
+	return &keySets{
+		client:   client,
+		uris:     make(map[string]string),
+		sets:     make(map[string]*keySet),
+		fetching: make(map[string]*sync.Mutex),
+	}
+}
+
+// lockURI locks the fetches of a uri, returning the function unlocking it
+func (ks *keySets) lockURI(uri string) func() {
+	ks.Lock()
+	lock, ok := ks.fetching[uri]
+	if !ok {
+		lock = new(sync.Mutex)
+		ks.fetching[uri] = lock
+	}
+	ks.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// key returns the key a token was signed with, from the key set at jwksURI or discovered from the issuer
+func (ks *keySets) key(ctx context.Context, issuer, jwksURI, kid string) (interface{}, error) {
+	if jwksURI == "" {
+		uri, err := ks.discover(ctx, issuer)
+		if err != nil {
+			return nil, err
+		}
+		jwksURI = uri
+	}
+
+	set, err := ks.keySet(ctx, jwksURI, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if key := set.keys[kid]; key != nil {
+		return key, nil
+	}
+	// Tokens without a key ID are accepted if the set has a single key
+	if kid == "" && len(set.keys) == 1 {
+		for _, key := range set.keys {
+			return key, nil
+		}
+	}
+	return nil, errors.Errorf("key %s not found in %s", kid, jwksURI)
+}
+
+// keySet returns the key set at jwksURI, fetching it again when it is old or doesn't have the key
+func (ks *keySets) keySet(ctx context.Context, jwksURI, kid string) (*keySet, error) {
+	unlock := ks.lockURI(jwksURI)
+	defer unlock()
+
+	ks.Lock()
+	set := ks.sets[jwksURI]
+	ks.Unlock()
+	age := time.Duration(0)
+	if set != nil {
+		age = time.Since(set.fetched)
+	}
+	if set != nil && age <= keySetMaxAge && (set.keys[kid] != nil || age <= keySetMinAge) {
+		return set, nil
+	}
+	set, err := ks.fetch(ctx, jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	ks.Lock()
+	ks.sets[jwksURI] = set
+	ks.Unlock()
+	return set, nil
+}
+
+func (ks *keySets) discover(ctx context.Context, issuer string) (string, error) {
+	unlock := ks.lockURI(issuer)
+	defer unlock()
+
+	ks.Lock()
+	uri, ok := ks.uris[issuer]
+	ks.Unlock()
+	if ok {
+		return uri, nil
+	}
+	configuration := struct {
+		JwksURI string `json:"jwks_uri"`
+	}{}
+	if err := ks.get(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &configuration); err != nil {
+		return "", errors.Wrapf(err, "error discovering the OpenID configuration of %s", issuer)
+	}
+	if configuration.JwksURI == "" {
+		return "", errors.Errorf("no jwks_uri in the OpenID configuration of %s", issuer)
+	}
+	ks.Lock()
+	ks.uris[issuer] = configuration.JwksURI
+	ks.Unlock()
+	return configuration.JwksURI, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (ks *keySets) fetch(ctx context.Context, jwksURI string) (*keySet, error) {
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := ks.get(ctx, jwksURI, &jwks); err != nil {
+		return nil, errors.Wrapf(err, "error fetching the key set %s", jwksURI)
+	}
+	set := &keySet{keys: make(map[string]interface{}), fetched: time.Now()}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Warnf("skipping key %s of %s: %v", jwk.Kid, jwksURI, err)
+			continue
+		}
+		set.keys[jwk.Kid] = key
+	}
+	return set, nil
+}
+
+func (ks *keySets) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ks.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (jwk *jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,57 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// quotaPeriod is the period API key quotas are counted over
+const quotaPeriod = time.Hour
+
+type quotaWindow struct {
+	start time.Time
+	count int64
+}
+
+// quotas counts the requests of each API key in fixed windows of an hour
+type quotas struct {
+	sync.Mutex
+	windows map[string]*quotaWindow
+	swept   time.Time
+}
+
+func newQuotas() *quotas {
+	return &quotas{windows: make(map[string]*quotaWindow)}
+}
+
+// take counts a request of key against its quota, returning how long to wait for the next window when exceeded
+func (q *quotas) take(key string, limit int64, now time.Time) (time.Duration, bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	// Windows of keys not used for a period are dropped
+	if now.Sub(q.swept) > quotaPeriod {
+		for k, w := range q.windows {
+			if now.Sub(w.start) >= quotaPeriod {
+				delete(q.windows, k)
+			}
+		}
+		q.swept = now
+	}
+
+	w, ok := q.windows[key]
+	if !ok || now.Sub(w.start) >= quotaPeriod {
+		w = &quotaWindow{start: now}
+		q.windows[key] = w
+	}
+	if w.count >= limit {
+		return w.start.Add(quotaPeriod).Sub(now), false
+	}
+	w.count++
+	return 0, true
+}
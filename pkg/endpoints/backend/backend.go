@@ -13,6 +13,15 @@ import (
 	"github.com/vmware/dispatch/pkg/api/v1"
 )
 
+// HeaderEndpoint names the endpoint (as org/project/name) of a request routed to the endpoints proxy
+const HeaderEndpoint = "X-Dispatch-Endpoint"
+
+// HeaderScheme is set to http on the requests of HTTPS-only endpoints routed to the endpoints proxy to be redirected
+const HeaderScheme = "X-Dispatch-Scheme"
+
+// HeaderPathParams passes the parameters of templated uris to the function, as base64 encoded JSON
+const HeaderPathParams = "X-Dispatch-Path-Params"
+
 // Backend is the interface for a endpoints backend, such as Knative
 type Backend interface {
 	Add(ctx context.Context, endpoint *v1.Endpoint) (*v1.Endpoint, error)
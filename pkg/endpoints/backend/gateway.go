@@ -0,0 +1,299 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/routes"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// GatewayConfig contains the settings of the gateway backend
+type GatewayConfig struct {
+	// DispatchHost is the domain of the default host of endpoints, <project>.<org>.<DispatchHost>
+	DispatchHost string
+}
+
+// Router is a backend routing the requests of endpoints itself, no gateway required
+type Router interface {
+	Backend
+	// Handler routes requests to the endpoint serving them, naming it in the X-Dispatch-Endpoint header for next
+	Handler(next http.Handler) http.Handler
+}
+
+// GatewayEndpoint is the stored endpoint of the gateway backend, named by its ID
+type GatewayEndpoint struct {
+	entitystore.BaseEntity
+	Endpoint dapi.Endpoint `json:"endpoint"`
+}
+
+type gatewayEndpoint struct {
+	key      string
+	entity   *GatewayEndpoint
+	endpoint *dapi.Endpoint
+	patterns []*routes.Pattern
+}
+
+// gatewayRoute is a uri of an endpoint
+type gatewayRoute struct {
+	*gatewayEndpoint
+	pattern *routes.Pattern
+}
+
+type gateway struct {
+	config GatewayConfig
+	store  entitystore.EntityStore
+
+	sync.RWMutex
+	// endpoints are persisted in the store, and loaded from it at startup
+	endpoints map[string]*gatewayEndpoint
+	// table holds the uris of all endpoints from the most specific, the order requests are matched in
+	table []gatewayRoute
+}
+
+// Gateway returns an endpoints backend routing requests from a route table, which is rebuilt from the endpoints
+// persisted in the store
+func Gateway(config GatewayConfig, store entitystore.EntityStore) (Router, error) {
+	h := &gateway{
+		config:    config,
+		store:     store,
+		endpoints: make(map[string]*gatewayEndpoint),
+	}
+
+	var entities []*GatewayEndpoint
+	if err := store.ListGlobal(context.Background(), entitystore.Options{}, &entities); err != nil {
+		return nil, errors.Wrap(err, "error listing gateway endpoints")
+	}
+	for _, entity := range entities {
+		patterns, err := routes.CompileAll(&entity.Endpoint)
+		if err != nil {
+			log.Errorf("skipping gateway endpoint %s: %v", gatewayKey(&entity.Endpoint.Meta), err)
+			continue
+		}
+		h.set(entity, patterns)
+	}
+	h.buildTable()
+	return h, nil
+}
+
+func gatewayKey(meta *dapi.Meta) string {
+	return fmt.Sprintf("%s/%s/%s", meta.Org, meta.Project, meta.Name)
+}
+
+func (h *gateway) Add(ctx context.Context, endpoint *dapi.Endpoint) (*dapi.Endpoint, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := gatewayKey(&endpoint.Meta)
+	if _, ok := h.endpoints[key]; ok {
+		return nil, AlreadyExists{errors.Errorf("endpoint '%s' already exists", key)}
+	}
+	endpoint.ID = strfmt.UUID(uuid.NewV4().String())
+	endpoint.CreatedTime = time.Now().Unix()
+	stored, patterns, err := h.prepare(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &GatewayEndpoint{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           string(stored.ID),
+			OrganizationID: stored.Org,
+		},
+		Endpoint: *stored,
+	}
+	if _, err := h.store.Add(ctx, entity); err != nil {
+		return nil, errors.Wrapf(err, "error storing endpoint '%s'", key)
+	}
+	h.set(entity, patterns)
+	h.buildTable()
+
+	result := entity.Endpoint
+	return &result, nil
+}
+
+func (h *gateway) Get(ctx context.Context, meta *dapi.Meta) (*dapi.Endpoint, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	e, ok := h.endpoints[gatewayKey(meta)]
+	if !ok {
+		return nil, NotFound{errors.Errorf("endpoint '%s' not found", gatewayKey(meta))}
+	}
+	endpoint := *e.endpoint
+	return &endpoint, nil
+}
+
+func (h *gateway) Delete(ctx context.Context, meta *dapi.Meta) error {
+	h.Lock()
+	defer h.Unlock()
+
+	key := gatewayKey(meta)
+	e, ok := h.endpoints[key]
+	if !ok {
+		return NotFound{errors.Errorf("endpoint '%s' not found", key)}
+	}
+	if err := h.store.Delete(ctx, e.entity.OrganizationID, e.entity.Name, e.entity); err != nil {
+		return errors.Wrapf(err, "error deleting endpoint '%s'", key)
+	}
+	delete(h.endpoints, key)
+	h.buildTable()
+	return nil
+}
+
+func (h *gateway) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Endpoint, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	var endpoints []*dapi.Endpoint
+	for _, e := range h.endpoints {
+		if e.endpoint.Org == meta.Org && e.endpoint.Project == meta.Project {
+			endpoint := *e.endpoint
+			endpoints = append(endpoints, &endpoint)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Name < endpoints[j].Name })
+	return endpoints, nil
+}
+
+func (h *gateway) Update(ctx context.Context, endpoint *dapi.Endpoint) (*dapi.Endpoint, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := gatewayKey(&endpoint.Meta)
+	e, ok := h.endpoints[key]
+	if !ok {
+		return nil, NotFound{errors.Errorf("endpoint '%s' not found", key)}
+	}
+	endpoint.ID = e.endpoint.ID
+	endpoint.CreatedTime = e.endpoint.CreatedTime
+	stored, patterns, err := h.prepare(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := *e.entity
+	entity.Endpoint = *stored
+	if _, err := h.store.Update(ctx, e.entity.Revision, &entity); err != nil {
+		return nil, errors.Wrapf(err, "error storing endpoint '%s'", key)
+	}
+	h.set(&entity, patterns)
+	h.buildTable()
+
+	result := entity.Endpoint
+	return &result, nil
+}
+
+// prepare compiles the routes of an endpoint and returns the endpoint to store
+func (h *gateway) prepare(endpoint *dapi.Endpoint) (*dapi.Endpoint, []*routes.Pattern, error) {
+	patterns, err := routes.CompileAll(endpoint)
+	if err != nil {
+		return nil, nil, ValidationError{err}
+	}
+	stored := *endpoint
+	stored.Kind = dapi.EndpointKind
+	stored.ModifiedTime = time.Now().Unix()
+	stored.Status = dapi.StatusREADY
+	if len(stored.Hosts) == 0 {
+		stored.Hosts = []string{routes.DefaultHost(&stored, h.config.DispatchHost)}
+	}
+	return &stored, patterns, nil
+}
+
+// set puts a stored endpoint in memory, the caller must hold the lock and rebuild the table
+func (h *gateway) set(entity *GatewayEndpoint, patterns []*routes.Pattern) {
+	key := gatewayKey(&entity.Endpoint.Meta)
+	h.endpoints[key] = &gatewayEndpoint{key: key, entity: entity, endpoint: &entity.Endpoint, patterns: patterns}
+}
+
+// buildTable sorts the uris of the endpoints into the route table, the caller must hold the lock.  Equally specific
+// uris of different endpoints are ordered by endpoint, so that routing doesn't depend on the order of the map.
+func (h *gateway) buildTable() {
+	var table []gatewayRoute
+	for _, e := range h.endpoints {
+		for _, pattern := range e.patterns {
+			table = append(table, gatewayRoute{gatewayEndpoint: e, pattern: pattern})
+		}
+	}
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.pattern.Match == b.pattern.Match && a.pattern.URI == b.pattern.URI {
+			return a.key < b.key
+		}
+		return routes.MoreSpecific(a.pattern, b.pattern)
+	})
+	h.table = table
+}
+
+func (h *gateway) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The routing headers are only set by the gateway
+		r.Header.Del(HeaderEndpoint)
+		r.Header.Del(HeaderScheme)
+		r.Header.Del(HeaderPathParams)
+
+		endpoint := h.route(r)
+		if endpoint == nil {
+			http.Error(w, "unknown endpoint", http.StatusNotFound)
+			return
+		}
+		r.Header.Set(HeaderEndpoint, strings.Join([]string{endpoint.Org, endpoint.Project, endpoint.Name}, "/"))
+		// As with Knative, the proxy redirects HTTP requests of HTTPS-only endpoints
+		if httpsOnly(endpoint.Protocols) && r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https" {
+			r.Header.Set(HeaderScheme, "http")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// route returns the endpoint with the most specific uri serving a request, nil if none.  CORS preflight requests are
+// routed to endpoints of any method.
+func (h *gateway) route(r *http.Request) *dapi.Endpoint {
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	h.RLock()
+	defer h.RUnlock()
+
+	for _, route := range h.table {
+		if !containsFold(route.endpoint.Hosts, host) {
+			continue
+		}
+		if !containsFold(route.endpoint.Methods, r.Method) && !(preflight && route.endpoint.Cors) {
+			continue
+		}
+		if _, ok := route.pattern.MatchPath(r.URL.Path); ok {
+			return route.endpoint
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
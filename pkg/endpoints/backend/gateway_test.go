@@ -0,0 +1,190 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package backend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func testGateway(t *testing.T, store entitystore.EntityStore) Router {
+	be, err := Gateway(GatewayConfig{DispatchHost: "test.dispatch.local"}, store)
+	require.NoError(t, err)
+	return be
+}
+
+func TestGateway_CRUD(t *testing.T) {
+	be := testGateway(t, helpers.MakeEntityStore(t))
+	ctx := context.Background()
+
+	added, err := be.Add(ctx, e2())
+	require.NoError(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, v1.StatusREADY, added.Status)
+	assert.Equal(t, []string{"dispatch.vmware.test.dispatch.local"}, added.Hosts)
+
+	_, err = be.Add(ctx, e2())
+	assert.IsType(t, AlreadyExists{}, err)
+
+	invalid := e1()
+	invalid.Uris = []string{"no-slash"}
+	_, err = be.Add(ctx, invalid)
+	assert.IsType(t, ValidationError{}, err)
+
+	updated := e2()
+	updated.Uris = []string{"/updated"}
+	updated, err = be.Update(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, added.ID, updated.ID)
+	assert.Equal(t, []string{"/updated"}, updated.Uris)
+
+	_, err = be.Update(ctx, e1())
+	assert.IsType(t, NotFound{}, err)
+
+	got, err := be.Get(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en2})
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	_, err = be.Add(ctx, e1())
+	require.NoError(t, err)
+	endpoints, err := be.List(ctx, &v1.Meta{Org: testOrg, Project: testProject})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, en1, endpoints[0].Name)
+	endpoints, err = be.List(ctx, &v1.Meta{Org: testOrg, Project: "other"})
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	require.NoError(t, be.Delete(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en2}))
+	_, err = be.Get(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en2})
+	assert.IsType(t, NotFound{}, err)
+	assert.IsType(t, NotFound{}, be.Delete(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en2}))
+}
+
+func TestGateway_Restart(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	be := testGateway(t, store)
+	ctx := context.Background()
+
+	added, err := be.Add(ctx, e1())
+	require.NoError(t, err)
+	updated := e2()
+	updated.Uris = []string{"/updated"}
+	_, err = be.Add(ctx, e2())
+	require.NoError(t, err)
+	updated, err = be.Update(ctx, updated)
+	require.NoError(t, err)
+
+	// A new gateway on the same store serves the endpoints of the previous one
+	be = testGateway(t, store)
+	got, err := be.Get(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en1})
+	require.NoError(t, err)
+	assert.Equal(t, added, got)
+	got, err = be.Get(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en2})
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	r := httptest.NewRequest("GET", "http://dispatch.vmware.test.dispatch.local/updated", nil)
+	routed := be.(*gateway).route(r)
+	require.NotNil(t, routed)
+	assert.Equal(t, en2, routed.Name)
+
+	require.NoError(t, be.Delete(ctx, &v1.Meta{Org: testOrg, Project: testProject, Name: en1}))
+	be = testGateway(t, store)
+	endpoints, err := be.List(ctx, &v1.Meta{Org: testOrg, Project: testProject})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, en2, endpoints[0].Name)
+}
+
+func TestGateway_Handler(t *testing.T) {
+	be := testGateway(t, helpers.MakeEntityStore(t))
+	_, err := be.Add(context.Background(), e1())
+	require.NoError(t, err)
+	e := e2()
+	e.Match = v1.EndpointMatchPrefix
+	e.Protocols = []string{"https"}
+	_, err = be.Add(context.Background(), e)
+	require.NoError(t, err)
+
+	var routed *http.Request
+	handler := be.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed = r
+	}))
+	request := func(method, target string) *httptest.ResponseRecorder {
+		routed = nil
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set(HeaderEndpoint, "spoofed")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("POST", "http://dispatch.vmware.test.dispatch.local:8083/test-fn1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, routed)
+	assert.Equal(t, "vmware/dispatch/e1", routed.Header.Get(HeaderEndpoint))
+	assert.Empty(t, routed.Header.Get(HeaderScheme))
+
+	// HTTP requests to HTTPS-only endpoints are marked for the proxy to redirect them
+	w = request("GET", "http://dispatch.vmware.test.dispatch.local/test-fn2/more")
+	require.NotNil(t, routed)
+	assert.Equal(t, "vmware/dispatch/e2", routed.Header.Get(HeaderEndpoint))
+	assert.Equal(t, "http", routed.Header.Get(HeaderScheme))
+
+	for _, target := range []struct{ method, url string }{
+		{"DELETE", "http://dispatch.vmware.test.dispatch.local/test-fn1"},
+		{"GET", "http://other.test.dispatch.local/test-fn1"},
+		{"GET", "http://dispatch.vmware.test.dispatch.local/test-fn3"},
+	} {
+		w = request(target.method, target.url)
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", target.method, target.url)
+		assert.Nil(t, routed)
+	}
+}
+
+func TestGateway_RouteMostSpecific(t *testing.T) {
+	be := testGateway(t, helpers.MakeEntityStore(t))
+	add := func(name, match, uri string) {
+		e := e1()
+		e.Name, e.Match, e.Uris = name, match, []string{uri}
+		_, err := be.Add(context.Background(), e)
+		require.NoError(t, err)
+	}
+	// Added from the least specific, the order of the map doesn't matter
+	add("regex", v1.EndpointMatchRegex, "/api/.*")
+	add("prefix", v1.EndpointMatchPrefix, "/api")
+	add("users", v1.EndpointMatchPrefix, "/api/users")
+	add("user", v1.EndpointMatchExact, "/api/users/{id}")
+	add("me", v1.EndpointMatchExact, "/api/users/me")
+
+	for path, name := range map[string]string{
+		"/api/users/me":      "me",
+		"/api/users/alice":   "user",
+		"/api/users/alice/x": "users",
+		"/api/other":         "prefix",
+	} {
+		for i := 0; i < 10; i++ {
+			r := httptest.NewRequest("GET", "http://dispatch.vmware.test.dispatch.local"+path, nil)
+			routed := be.(*gateway).route(r)
+			require.NotNil(t, routed, path)
+			assert.Equal(t, name, routed.Name, path)
+		}
+	}
+
+	require.NoError(t, be.Delete(context.Background(), &v1.Meta{Org: testOrg, Project: testProject, Name: "prefix"}))
+	r := httptest.NewRequest("GET", "http://dispatch.vmware.test.dispatch.local/api/other", nil)
+	assert.Equal(t, "regex", be.(*gateway).route(r).Name)
+}
@@ -8,6 +8,7 @@ package backend
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/go-openapi/strfmt"
@@ -20,19 +21,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+	"github.com/vmware/dispatch/pkg/endpoints/routes"
 	"github.com/vmware/dispatch/pkg/utils"
 	"github.com/vmware/dispatch/pkg/utils/knaming"
 )
 
-type knativeEndpointsConfig struct {
+// KnativeConfig configures the Knative endpoints backend
+type KnativeConfig struct {
 	InternalGateway string
 	SharedGateway   string
 	DispatchHost    string
+
+	// ProxyHost and ProxyPort address the endpoints proxy, which authenticates the callers of non-public endpoints
+	ProxyHost string
+	ProxyPort int
 }
 
 type knative struct {
 	knClient sharedclientset.Interface
-	config   knativeEndpointsConfig
+	config   KnativeConfig
 }
 
 func knClient(kubeconfPath string) sharedclientset.Interface {
@@ -44,19 +52,18 @@ func knClient(kubeconfPath string) sharedclientset.Interface {
 }
 
 //Knative returns a Knative functions backend
-func Knative(kubeconfPath, internalGateway, sharedGateway, dispatchHost string) Backend {
+func Knative(kubeconfPath string, config KnativeConfig) Backend {
 	return &knative{
 		knClient: knClient(kubeconfPath),
-		config: knativeEndpointsConfig{
-			InternalGateway: internalGateway,
-			SharedGateway:   sharedGateway,
-			DispatchHost:    dispatchHost,
-		},
+		config:   config,
 	}
 }
 
 func (h *knative) Add(ctx context.Context, endpoint *dapi.Endpoint) (*dapi.Endpoint, error) {
-	virtualService := h.fromEndpoint(endpoint)
+	virtualService, err := h.fromEndpoint(endpoint)
+	if err != nil {
+		return nil, ValidationError{err}
+	}
 
 	newVirtualService, err := h.knClient.NetworkingV1alpha3().VirtualServices(endpoint.Org).Create(virtualService)
 	if err != nil {
@@ -128,7 +135,10 @@ func (h *knative) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Endpoint,
 }
 
 func (h *knative) Update(ctx context.Context, endpoint *dapi.Endpoint) (*dapi.Endpoint, error) {
-	virtualService := h.fromEndpoint(endpoint)
+	virtualService, err := h.fromEndpoint(endpoint)
+	if err != nil {
+		return nil, ValidationError{err}
+	}
 	virtualServices := h.knClient.NetworkingV1alpha3().VirtualServices(endpoint.Org)
 
 	updatedVirtualService, err := virtualServices.Update(virtualService)
@@ -142,54 +152,174 @@ func (h *knative) Update(ctx context.Context, endpoint *dapi.Endpoint) (*dapi.En
 	return h.toEndpoint(updatedVirtualService)
 }
 
-func (h *knative) fromEndpoint(model *dapi.Endpoint) *v1alpha3.VirtualService {
+func (h *knative) fromEndpoint(model *dapi.Endpoint) (*v1alpha3.VirtualService, error) {
 	virtualService := &v1alpha3.VirtualService{
 		ObjectMeta: knaming.ToObjectMeta(model.Meta, *model),
 	}
 
 	hosts := model.Hosts
 	if len(model.Hosts) == 0 {
-		hosts = append(hosts, fmt.Sprintf("%s.%s.%s", model.Project, model.Org, h.config.DispatchHost))
+		hosts = append(hosts, routes.DefaultHost(model, h.config.DispatchHost))
 	}
 
 	virtualService.Spec.Hosts = hosts
 	virtualService.Spec.Gateways = []string{h.config.SharedGateway, "mesh"}
 
-	var routes []v1alpha3.HTTPRoute
-	for _, prefix := range model.Uris {
-		// TODO: Check for conflicts/duplicate paths
+	patterns, err := routes.CompileAll(model)
+	if err != nil {
+		return nil, err
+	}
+	// Istio tries the routes of a virtual service in order
+	routes.Sort(patterns)
+
+	var httpRoutes []v1alpha3.HTTPRoute
+	for _, pattern := range patterns {
 		fName := knaming.FunctionName(dapi.Meta{Name: model.Function, Project: model.Project, Org: model.Org})
-		log.Debugf("creating route for prefix %s to %s.%s.svc.cluster.local", prefix, fName, model.Org)
-		var matches []v1alpha3.HTTPMatchRequest
-		for _, method := range model.Methods {
-			match := v1alpha3.HTTPMatchRequest{
-				Uri:    &v1alpha1.StringMatch{Exact: prefix},
-				Method: &v1alpha1.StringMatch{Exact: strings.ToUpper(method)},
+		log.Debugf("creating route for uri %s to %s.%s.svc.cluster.local", pattern.URI, fName, model.Org)
+		uriMatch := uriStringMatch(pattern)
+		matches := methodMatches(model, uriMatch)
+
+		if !model.Enabled {
+			// Disabled endpoints keep their routes, failing requests instead of running the function
+			route := h.functionRoute(model, matches)
+			route.Fault = &v1alpha3.HTTPFaultInjection{
+				Abort: &v1alpha3.InjectAbort{Perecent: 100, HttpStatus: http.StatusNotFound},
+			}
+			httpRoutes = append(httpRoutes, route)
+			continue
+		}
+
+		if httpsOnly(model.Protocols) {
+			// HTTP requests are sent to the proxy, which redirects them to HTTPS
+			var httpMatches []v1alpha3.HTTPMatchRequest
+			for _, match := range matches {
+				match.Scheme = &v1alpha1.StringMatch{Exact: "http"}
+				httpMatches = append(httpMatches, match)
 			}
-			matches = append(matches, match)
+			route := h.proxyRoute(model, httpMatches)
+			route.AppendHeaders[HeaderScheme] = "http"
+			httpRoutes = append(httpRoutes, route)
 		}
-		route := v1alpha3.HTTPRoute{
-			Match: matches,
-			Route: []v1alpha3.DestinationWeight{
-				v1alpha3.DestinationWeight{
-					Destination: v1alpha3.Destination{
-						Host: h.config.InternalGateway,
-						Port: v1alpha3.PortSelector{
-							Number: 80,
-						},
+		if model.Cors {
+			// The proxy answers CORS preflight requests
+			httpRoutes = append(httpRoutes, h.proxyRoute(model, []v1alpha3.HTTPMatchRequest{{
+				Uri:    uriMatch,
+				Method: &v1alpha1.StringMatch{Exact: http.MethodOptions},
+			}}))
+		}
+
+		if proxied(model, pattern) {
+			httpRoutes = append(httpRoutes, h.proxyRoute(model, matches))
+		} else if pattern.Match == dapi.EndpointMatchPrefix && model.Rewrite != "" {
+			httpRoutes = append(httpRoutes, h.rewrittenPrefixRoutes(model, pattern)...)
+		} else {
+			route := h.functionRoute(model, matches)
+			if model.Rewrite != "" {
+				route.Rewrite.Uri = model.Rewrite
+			}
+			httpRoutes = append(httpRoutes, route)
+		}
+	}
+	log.Infof("Routes: %+v", httpRoutes)
+	virtualService.Spec.Http = httpRoutes
+	return virtualService, nil
+}
+
+// proxied tells whether the requests of a uri are routed to the endpoints proxy, for what the gateway can't do
+func proxied(model *dapi.Endpoint, pattern *routes.Pattern) bool {
+	limited := model.RateLimit != nil || model.ConsumerRateLimit != nil || model.MaxBodySize > 0
+	return model.Cors || !auth.IsPublic(model) || pattern.Templated() || limited
+}
+
+// uriStringMatch matches the paths of a uri, prefixes and templates being matched with regular expressions so that
+// prefixes only match whole segments
+func uriStringMatch(pattern *routes.Pattern) *v1alpha1.StringMatch {
+	if pattern.Match == dapi.EndpointMatchExact && !pattern.Templated() {
+		return &v1alpha1.StringMatch{Exact: pattern.URI}
+	}
+	return &v1alpha1.StringMatch{Regex: pattern.Regex()}
+}
+
+// methodMatches matches the requests of a uri with the methods of an endpoint
+func methodMatches(model *dapi.Endpoint, uriMatch *v1alpha1.StringMatch) []v1alpha3.HTTPMatchRequest {
+	var matches []v1alpha3.HTTPMatchRequest
+	for _, method := range model.Methods {
+		matches = append(matches, v1alpha3.HTTPMatchRequest{
+			Uri:    uriMatch,
+			Method: &v1alpha1.StringMatch{Exact: strings.ToUpper(method)},
+		})
+	}
+	return matches
+}
+
+// rewrittenPrefixRoutes routes the requests of a literal prefix to the function, rewriting their path.  Istio only
+// rewrites the part of the path matched by a prefix, so the prefix itself and the paths below it are matched apart.
+func (h *knative) rewrittenPrefixRoutes(model *dapi.Endpoint, pattern *routes.Pattern) []v1alpha3.HTTPRoute {
+	var httpRoutes []v1alpha3.HTTPRoute
+	if !strings.HasSuffix(pattern.URI, "/") {
+		route := h.functionRoute(model, methodMatches(model, &v1alpha1.StringMatch{Exact: pattern.URI}))
+		route.Rewrite.Uri = model.Rewrite
+		httpRoutes = append(httpRoutes, route)
+	}
+	below := &v1alpha1.StringMatch{Prefix: strings.TrimSuffix(pattern.URI, "/") + "/"}
+	route := h.functionRoute(model, methodMatches(model, below))
+	route.Rewrite.Uri = strings.TrimSuffix(model.Rewrite, "/") + "/"
+	return append(httpRoutes, route)
+}
+
+// functionRoute routes requests to the function of an endpoint, through the internal gateway
+func (h *knative) functionRoute(model *dapi.Endpoint, matches []v1alpha3.HTTPMatchRequest) v1alpha3.HTTPRoute {
+	fName := knaming.FunctionName(dapi.Meta{Name: model.Function, Project: model.Project, Org: model.Org})
+	return v1alpha3.HTTPRoute{
+		Match: matches,
+		Route: []v1alpha3.DestinationWeight{
+			v1alpha3.DestinationWeight{
+				Destination: v1alpha3.Destination{
+					Host: h.config.InternalGateway,
+					Port: v1alpha3.PortSelector{
+						Number: 80,
 					},
-					Weight: 100,
 				},
+				Weight: 100,
 			},
-			Rewrite: &v1alpha3.HTTPRewrite{
-				Authority: fmt.Sprintf("%s.%s.svc.cluster.local", fName, model.Org),
+		},
+		Rewrite: &v1alpha3.HTTPRewrite{
+			Authority: fmt.Sprintf("%s.%s.svc.cluster.local", fName, model.Org),
+		},
+	}
+}
+
+// proxyRoute routes requests to the endpoints proxy, which authenticates callers, applies the CORS policy, redirects
+// HTTP requests of HTTPS-only endpoints, enforces rate limits and body sizes and passes the parameters of templated
+// uris.  The host is kept so that the proxy can check the request against the endpoint.
+func (h *knative) proxyRoute(model *dapi.Endpoint, matches []v1alpha3.HTTPMatchRequest) v1alpha3.HTTPRoute {
+	return v1alpha3.HTTPRoute{
+		Match: matches,
+		Route: []v1alpha3.DestinationWeight{
+			v1alpha3.DestinationWeight{
+				Destination: v1alpha3.Destination{
+					Host: h.config.ProxyHost,
+					Port: v1alpha3.PortSelector{
+						Number: uint32(h.config.ProxyPort),
+					},
+				},
+				Weight: 100,
 			},
+		},
+		AppendHeaders: map[string]string{
+			HeaderEndpoint: strings.Join([]string{model.Org, model.Project, model.Name}, "/"),
+		},
+	}
+}
+
+// httpsOnly tells whether HTTP requests are redirected to HTTPS
+func httpsOnly(protocols []string) bool {
+	for _, protocol := range protocols {
+		if strings.ToLower(protocol) == "http" {
+			return false
 		}
-		routes = append(routes, route)
 	}
-	log.Infof("Routes: %+v", routes)
-	virtualService.Spec.Http = routes
-	return virtualService
+	return len(protocols) > 0
 }
 
 func (h *knative) toEndpoint(virtualService *v1alpha3.VirtualService) (*dapi.Endpoint, error) {
@@ -206,6 +336,33 @@ func (h *knative) toEndpoint(virtualService *v1alpha3.VirtualService) (*dapi.End
 	endpoint.ModifiedTime = virtualService.CreationTimestamp.Unix()
 	endpoint.Hosts = virtualService.Spec.Hosts
 
+	// The state of the endpoint is read from its routes, so that it reflects what is served
+	redirected, preflight, aborted := false, false, false
+	for _, route := range virtualService.Spec.Http {
+		if route.Fault != nil && route.Fault.Abort != nil {
+			aborted = true
+		}
+		for _, match := range route.Match {
+			if match.Scheme != nil && match.Scheme.Exact == "http" {
+				redirected = true
+			}
+			if match.Method != nil && match.Method.Exact == http.MethodOptions && route.AppendHeaders[HeaderEndpoint] != "" {
+				preflight = true
+			}
+		}
+	}
+	endpoint.Enabled = len(virtualService.Spec.Http) > 0 && !aborted
+	if endpoint.Enabled {
+		endpoint.Protocols = []string{"http", "https"}
+		if redirected {
+			endpoint.Protocols = []string{"https"}
+		}
+		endpoint.Cors = preflight
+		if !preflight {
+			endpoint.CorsPolicy = nil
+		}
+	}
+
 	endpoint.BackingObject = virtualService
 	return endpoint, nil
 }
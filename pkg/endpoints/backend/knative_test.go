@@ -31,10 +31,12 @@ func e1() *v1.Endpoint {
 			Project: testProject,
 			Name:    en1,
 		},
-		Function: "test-fn1",
-		Uris:     []string{"/test-fn1"},
-		Methods:  []string{"GET", "POST"},
-		Hosts:    []string{"dispatch.vmware.test.dispatch.local"},
+		Function:  "test-fn1",
+		Uris:      []string{"/test-fn1"},
+		Methods:   []string{"GET", "POST"},
+		Hosts:     []string{"dispatch.vmware.test.dispatch.local"},
+		Protocols: []string{"http", "https"},
+		Enabled:   true,
 	}
 }
 
@@ -55,10 +57,12 @@ func e2() *v1.Endpoint {
 func testBackend() *knative {
 	be := &knative{
 		knClient: fake.NewSimpleClientset(),
-		config: knativeEndpointsConfig{
+		config: KnativeConfig{
 			InternalGateway: "knative-ingressgateway.istio-system.svc.cluster.local",
 			SharedGateway:   "knative-shared-gateway.knative-serving.svc.cluster.local",
 			DispatchHost:    "test.dispatch.local",
+			ProxyHost:       "dispatch-server.dispatch.svc.cluster.local",
+			ProxyPort:       8082,
 		},
 	}
 	return be
@@ -136,3 +140,172 @@ func TestKnative_Delete(t *testing.T) {
 	assert.Equal(t, 1, len(endpoints))
 	assert.Equal(t, en2, endpoints[0].Meta.Name)
 }
+
+func TestKnative_AuthRoutes(t *testing.T) {
+	be := testBackend()
+
+	virtualService, err := be.fromEndpoint(e1())
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 1)
+	route := virtualService.Spec.Http[0]
+	assert.Equal(t, be.config.InternalGateway, route.Route[0].Destination.Host)
+	assert.Equal(t, "d-fn-dispatch-test-fn1.vmware.svc.cluster.local", route.Rewrite.Authority)
+	assert.Empty(t, route.AppendHeaders)
+
+	// Non-public endpoints go through the proxy, which authenticates the caller
+	e := e1()
+	e.Auth = &v1.EndpointAuth{Type: v1.EndpointAuthBasic, Secret: "users"}
+	virtualService, err = be.fromEndpoint(e)
+	require.NoError(t, err)
+	route = virtualService.Spec.Http[0]
+	assert.Equal(t, "dispatch-server.dispatch.svc.cluster.local", route.Route[0].Destination.Host)
+	assert.Equal(t, uint32(8082), route.Route[0].Destination.Port.Number)
+	assert.Nil(t, route.Rewrite)
+	assert.Equal(t, "vmware/dispatch/e1", route.AppendHeaders[HeaderEndpoint])
+}
+
+func TestKnative_Disabled(t *testing.T) {
+	be := testBackend()
+
+	e := e1()
+	e.Enabled = false
+	virtualService, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 1)
+	assert.Equal(t, 404, virtualService.Spec.Http[0].Fault.Abort.HttpStatus)
+
+	endpoint, err := be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.False(t, endpoint.Enabled)
+
+	// The state is read from the routes
+	e.Enabled = true
+	enabled, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	virtualService.Annotations = enabled.Annotations
+	endpoint, err = be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.False(t, endpoint.Enabled)
+}
+
+func TestKnative_HTTPSOnly(t *testing.T) {
+	be := testBackend()
+
+	e := e1()
+	e.Protocols = []string{"https"}
+	virtualService, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 2)
+	redirect := virtualService.Spec.Http[0]
+	assert.Equal(t, "http", redirect.Match[0].Scheme.Exact)
+	assert.Equal(t, "/test-fn1", redirect.Match[0].Uri.Exact)
+	assert.Equal(t, be.config.ProxyHost, redirect.Route[0].Destination.Host)
+	assert.Equal(t, "http", redirect.AppendHeaders[HeaderScheme])
+	assert.Nil(t, virtualService.Spec.Http[1].Match[0].Scheme)
+
+	endpoint, err := be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https"}, endpoint.Protocols)
+
+	// Without the redirect, HTTP is served
+	virtualService.Spec.Http = virtualService.Spec.Http[1:]
+	endpoint, err = be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http", "https"}, endpoint.Protocols)
+}
+
+func TestKnative_Cors(t *testing.T) {
+	be := testBackend()
+
+	e := e1()
+	e.Cors = true
+	e.CorsPolicy = &v1.EndpointCors{AllowOrigins: []string{"https://example.com"}, MaxAge: 600}
+	virtualService, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 2)
+	preflight := virtualService.Spec.Http[0]
+	assert.Equal(t, "OPTIONS", preflight.Match[0].Method.Exact)
+	for _, route := range virtualService.Spec.Http {
+		assert.Equal(t, be.config.ProxyHost, route.Route[0].Destination.Host)
+	}
+
+	endpoint, err := be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.True(t, endpoint.Cors)
+	assert.Equal(t, e.CorsPolicy, endpoint.CorsPolicy)
+
+	virtualService.Spec.Http = virtualService.Spec.Http[1:]
+	endpoint, err = be.toEndpoint(virtualService)
+	require.NoError(t, err)
+	assert.False(t, endpoint.Cors)
+	assert.Nil(t, endpoint.CorsPolicy)
+}
+
+func TestKnative_Matching(t *testing.T) {
+	be := testBackend()
+
+	e := e1()
+	e.Methods = []string{"GET"}
+	e.Match = v1.EndpointMatchPrefix
+	e.Uris = []string{"/api", "/users/{id}"}
+	e.Rewrite = "/v1"
+	virtualService, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 3)
+
+	// Routes are ordered from the most specific uri, a rewritten prefix is matched apart from the paths below it
+	prefix := virtualService.Spec.Http[1]
+	assert.Equal(t, "/api", prefix.Match[0].Uri.Exact)
+	assert.Equal(t, "/v1", prefix.Rewrite.Uri)
+	assert.Equal(t, be.config.InternalGateway, prefix.Route[0].Destination.Host)
+	below := virtualService.Spec.Http[2]
+	assert.Equal(t, "/api/", below.Match[0].Uri.Prefix)
+	assert.Equal(t, "/v1/", below.Rewrite.Uri)
+	assert.Equal(t, be.config.InternalGateway, below.Route[0].Destination.Host)
+
+	// Templates are matched with regular expressions, the proxy passing the parameters to the function
+	template := virtualService.Spec.Http[0]
+	assert.Equal(t, "/users/[^/]+(/.*)?", template.Match[0].Uri.Regex)
+	assert.Equal(t, be.config.ProxyHost, template.Route[0].Destination.Host)
+
+	// Prefixes which are not rewritten only match whole segments
+	e.Rewrite = ""
+	e.Uris = []string{"/api"}
+	virtualService, err = be.fromEndpoint(e)
+	require.NoError(t, err)
+	require.Len(t, virtualService.Spec.Http, 1)
+	assert.Equal(t, "/api(/.*)?", virtualService.Spec.Http[0].Match[0].Uri.Regex)
+
+	e = e1()
+	e.Match = v1.EndpointMatchRegex
+	e.Uris = []string{"/items/[0-9]+"}
+	virtualService, err = be.fromEndpoint(e)
+	require.NoError(t, err)
+	assert.Equal(t, "/items/[0-9]+", virtualService.Spec.Http[0].Match[0].Uri.Regex)
+
+	e.Uris = []string{"/items/[0-9"}
+	_, err = be.fromEndpoint(e)
+	assert.Error(t, err)
+	_, err = be.Add(context.TODO(), e)
+	assert.IsType(t, ValidationError{}, err)
+}
+
+func TestKnative_Limits(t *testing.T) {
+	be := testBackend()
+
+	e := e1()
+	e.RateLimit = &v1.EndpointRateLimit{Requests: 10}
+	virtualService, err := be.fromEndpoint(e)
+	require.NoError(t, err)
+	for _, route := range virtualService.Spec.Http {
+		assert.Equal(t, be.config.ProxyHost, route.Route[0].Destination.Host)
+	}
+
+	e = e1()
+	e.MaxBodySize = 1024
+	virtualService, err = be.fromEndpoint(e)
+	require.NoError(t, err)
+	for _, route := range virtualService.Spec.Http {
+		assert.Equal(t, be.config.ProxyHost, route.Route[0].Destination.Host)
+	}
+}
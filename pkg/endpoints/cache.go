@@ -0,0 +1,72 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/backend"
+	"github.com/vmware/dispatch/pkg/endpoints/routes"
+)
+
+// endpointMaxAge is how long the proxy serves an endpoint before getting it again, changes of endpoints take up to
+// that long to apply
+const endpointMaxAge = 10 * time.Second
+
+type cachedEndpoint struct {
+	endpoint *dapi.Endpoint
+	// patterns are the compiled uris of the endpoint, nil if they are invalid
+	patterns []*routes.Pattern
+	fetched  time.Time
+}
+
+// endpointCache caches the endpoints served by the proxy along with their compiled uris, so that requests don't get
+// the endpoint from the backend and compile its uris every time
+type endpointCache struct {
+	sync.Mutex
+	backend backend.Backend
+	entries map[string]*cachedEndpoint
+}
+
+func newEndpointCache(endpointsBackend backend.Backend) *endpointCache {
+	return &endpointCache{backend: endpointsBackend, entries: make(map[string]*cachedEndpoint)}
+}
+
+func (c *endpointCache) get(ctx context.Context, meta *dapi.Meta, now time.Time) (*cachedEndpoint, error) {
+	key := strings.Join([]string{meta.Org, meta.Project, meta.Name}, "/")
+	c.Lock()
+	entry := c.entries[key]
+	c.Unlock()
+	if entry != nil && now.Sub(entry.fetched) < endpointMaxAge {
+		return entry, nil
+	}
+
+	endpoint, err := c.backend.Get(ctx, meta)
+	if err != nil {
+		if _, ok := err.(backend.NotFound); ok {
+			c.Lock()
+			delete(c.entries, key)
+			c.Unlock()
+		}
+		return nil, err
+	}
+	patterns, err := routes.CompileAll(endpoint)
+	if err != nil {
+		log.Warnf("invalid uris of endpoint %s: %v", endpoint.Name, err)
+	}
+	routes.Sort(patterns)
+	entry = &cachedEndpoint{endpoint: endpoint, patterns: patterns, fetched: now}
+	c.Lock()
+	c.entries[key] = entry
+	c.Unlock()
+	return entry, nil
+}
@@ -0,0 +1,48 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func TestEndpointCache(t *testing.T) {
+	endpoint := &dapi.Endpoint{
+		Meta: dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Uris: []string{"/hello/{name}"},
+	}
+	b := &testBackend{endpoints: map[string]*dapi.Endpoint{"hello": endpoint}}
+	c := newEndpointCache(b)
+	meta := &dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"}
+	now := time.Now()
+
+	cached, err := c.get(context.Background(), meta, now)
+	require.NoError(t, err)
+	assert.Equal(t, endpoint, cached.endpoint)
+	require.Len(t, cached.patterns, 1)
+	params, ok := cached.patterns[0].MatchPath("/hello/alice")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"name": "alice"}, params)
+
+	_, err = c.get(context.Background(), meta, now.Add(endpointMaxAge/2))
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.gets)
+
+	// The endpoint is got again once it is too old
+	_, err = c.get(context.Background(), meta, now.Add(endpointMaxAge))
+	require.NoError(t, err)
+	assert.Equal(t, 2, b.gets)
+
+	_, err = c.get(context.Background(), &dapi.Meta{Name: "missing", Org: "dispatch", Project: "default"}, now)
+	assert.Error(t, err)
+}
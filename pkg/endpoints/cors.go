@@ -0,0 +1,86 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func corsPolicy(endpoint *dapi.Endpoint) *dapi.EndpointCors {
+	if endpoint.CorsPolicy != nil {
+		return endpoint.CorsPolicy
+	}
+	return &dapi.EndpointCors{}
+}
+
+// isPreflight tells whether a request is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// allowOrigin sets the CORS headers of the response to a cross-origin request, if its origin is allowed
+func allowOrigin(w http.ResponseWriter, r *http.Request, endpoint *dapi.Endpoint) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	policy := corsPolicy(endpoint)
+	header := w.Header()
+	anyOrigin := len(policy.AllowOrigins) == 0 || containsFold(policy.AllowOrigins, "*")
+	switch {
+	case policy.AllowCredentials:
+		// Credentials are only allowed for the origins listed, which validation requires
+		if !containsFold(policy.AllowOrigins, origin) {
+			return false
+		}
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	case anyOrigin:
+		header.Set("Access-Control-Allow-Origin", "*")
+	case containsFold(policy.AllowOrigins, origin):
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	default:
+		return false
+	}
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(policy.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+	}
+	return true
+}
+
+// preflight answers a CORS preflight request, which isn't authenticated
+func preflight(w http.ResponseWriter, r *http.Request, endpoint *dapi.Endpoint) {
+	policy := corsPolicy(endpoint)
+	methods := policy.AllowMethods
+	if len(methods) == 0 {
+		methods = endpoint.Methods
+	}
+	if !containsFold(methods, r.Header.Get("Access-Control-Request-Method")) || !allowOrigin(w, r, endpoint) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Access-Control-Allow-Methods", strings.ToUpper(strings.Join(methods, ", ")))
+	if len(policy.AllowHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowHeaders, ", "))
+	} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.FormatInt(policy.MaxAge, 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@
 package endpoints
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -15,9 +16,11 @@ import (
 	"github.com/go-openapi/swag"
 
 	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
 	"github.com/vmware/dispatch/pkg/endpoints/backend"
 	"github.com/vmware/dispatch/pkg/endpoints/gen/restapi/operations"
 	"github.com/vmware/dispatch/pkg/endpoints/gen/restapi/operations/endpoint"
+	"github.com/vmware/dispatch/pkg/endpoints/routes"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
 )
@@ -34,13 +37,16 @@ type EndpointHandlers interface {
 type defaultHandlers struct {
 	backend   backend.Backend
 	namespace string
+	// dispatchHost is the domain of the default host of endpoints
+	dispatchHost string
 }
 
 // NewHandlers is the constructor for the endpoint handlers
-func NewHandlers(kubeconfPath, namespace, internalGateway, sharedGateway, dispatchHost string) EndpointHandlers {
+func NewHandlers(namespace, dispatchHost string, endpointsBackend backend.Backend) EndpointHandlers {
 	return &defaultHandlers{
-		backend:   backend.Knative(kubeconfPath, internalGateway, sharedGateway, dispatchHost),
-		namespace: namespace,
+		backend:      endpointsBackend,
+		namespace:    namespace,
+		dispatchHost: dispatchHost,
 	}
 }
 
@@ -81,6 +87,28 @@ func (h *defaultHandlers) AddEndpoint(params endpoint.AddEndpointParams, princip
 	model := params.Body
 	utils.AdjustMeta(&model.Meta, dapi.Meta{Org: org, Project: project})
 
+	if err := validateEndpoint(model); err != nil {
+		return endpoint.NewAddEndpointBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+	conflict, err := h.findConflict(ctx, model)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "checking endpoint conflicts"))
+		return endpoint.NewAddEndpointDefault(http.StatusInternalServerError).WithPayload(
+			&dapi.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String(err.Error()),
+			})
+	}
+	if conflict != nil {
+		return endpoint.NewAddEndpointConflict().WithPayload(&dapi.Error{
+			Code:    http.StatusConflict,
+			Message: swag.String(conflict.Error()),
+		})
+	}
+
 	createdEndpoint, err := h.backend.Add(ctx, model)
 	if err != nil {
 		log.Errorf("%+v", errors.Wrap(err, "creating endpoint"))
@@ -151,6 +179,28 @@ func (h *defaultHandlers) UpdateEndpoint(params endpoint.UpdateEndpointParams, p
 	model := params.Body
 	utils.AdjustMeta(&model.Meta, dapi.Meta{Org: org, Project: project})
 
+	if err := validateEndpoint(model); err != nil {
+		return endpoint.NewUpdateEndpointBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+	conflict, err := h.findConflict(ctx, model)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "checking endpoint conflicts"))
+		return endpoint.NewUpdateEndpointDefault(http.StatusInternalServerError).WithPayload(
+			&dapi.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String(err.Error()),
+			})
+	}
+	if conflict != nil {
+		return endpoint.NewUpdateEndpointConflict().WithPayload(&dapi.Error{
+			Code:    http.StatusConflict,
+			Message: swag.String(conflict.Error()),
+		})
+	}
+
 	updatedEndpoint, err := h.backend.Update(ctx, model)
 	log.Infof("Updated Endpoint: %+v", updatedEndpoint)
 	if err != nil {
@@ -187,3 +237,26 @@ func (h *defaultHandlers) DeleteEndpoint(params endpoint.DeleteEndpointParams, p
 
 	return endpoint.NewDeleteEndpointOK()
 }
+
+// validateEndpoint checks the parts of an endpoint the API spec doesn't
+func validateEndpoint(model *dapi.Endpoint) error {
+	if err := auth.Validate(model.Auth); err != nil {
+		return err
+	}
+	// Allowing credentials for any origin would let any site call the endpoint with the credentials of its users
+	if policy := model.CorsPolicy; policy != nil && policy.AllowCredentials &&
+		(len(policy.AllowOrigins) == 0 || containsFold(policy.AllowOrigins, "*")) {
+		return errors.New("cors credentials are only allowed for a list of origins")
+	}
+	_, err := routes.CompileAll(model)
+	return err
+}
+
+// findConflict returns the endpoint of the project already serving requests of model, nil if none
+func (h *defaultHandlers) findConflict(ctx context.Context, model *dapi.Endpoint) (*routes.Conflict, error) {
+	others, err := h.backend.List(ctx, &dapi.Meta{Org: model.Org, Project: model.Project})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing endpoints")
+	}
+	return routes.FindConflict(model, others, h.dispatchHost)
+}
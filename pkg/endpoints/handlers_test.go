@@ -5,4 +5,85 @@
 
 package endpoints
 
-//TODO add tests
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/gen/restapi/operations/endpoint"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+// listBackend lists and updates the endpoints of a map
+type listBackend struct {
+	testBackend
+}
+
+func (b *listBackend) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Endpoint, error) {
+	var endpoints []*dapi.Endpoint
+	for _, e := range b.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+func (b *listBackend) Update(ctx context.Context, model *dapi.Endpoint) (*dapi.Endpoint, error) {
+	b.endpoints[model.Name] = model
+	return model, nil
+}
+
+func TestUpdateEndpointConflict(t *testing.T) {
+	hello := &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Function: "hello-fn",
+		Uris:     []string{"/hello"},
+		Methods:  []string{"GET"},
+	}
+	h := NewHandlers("dispatch", "dispatch.local", &listBackend{testBackend{endpoints: map[string]*dapi.Endpoint{"hello": hello}}})
+
+	update := func(hosts []string, statusCode int) {
+		params := endpoint.NewUpdateEndpointParams()
+		params.HTTPRequest = httptest.NewRequest("PUT", "/v1/endpoint/hi", nil)
+		params.XDispatchProject = swag.String("default")
+		params.Endpoint = "hi"
+		params.Body = &dapi.Endpoint{
+			Meta:     dapi.Meta{Name: "hi"},
+			Function: "hi-fn",
+			Hosts:    hosts,
+			Uris:     []string{"/hello"},
+			Methods:  []string{"GET"},
+		}
+		var out interface{}
+		if statusCode == http.StatusOK {
+			out = &dapi.Endpoint{}
+		} else {
+			out = &dapi.Error{}
+		}
+		helpers.HandlerRequest(t, h.UpdateEndpoint(params, "testCookie"), out, statusCode)
+		if e, ok := out.(*dapi.Error); ok {
+			assert.Equal(t, "GET default.dispatch.dispatch.local/hello is already served by endpoint hello", *e.Message)
+		}
+	}
+
+	// The endpoint without hosts is served on the default host of the project
+	update([]string{"default.dispatch.dispatch.local"}, http.StatusConflict)
+	update(nil, http.StatusConflict)
+	update([]string{"www.example.com"}, http.StatusOK)
+}
+
+func TestValidateEndpointCors(t *testing.T) {
+	e := &dapi.Endpoint{
+		Uris:       []string{"/hello"},
+		CorsPolicy: &dapi.EndpointCors{AllowCredentials: true},
+	}
+	assert.Error(t, validateEndpoint(e))
+	e.CorsPolicy.AllowOrigins = []string{"*"}
+	assert.Error(t, validateEndpoint(e))
+	e.CorsPolicy.AllowOrigins = []string{"https://example.com"}
+	assert.NoError(t, validateEndpoint(e))
+}
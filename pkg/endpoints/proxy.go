@@ -0,0 +1,233 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+	"github.com/vmware/dispatch/pkg/endpoints/backend"
+	"github.com/vmware/dispatch/pkg/endpoints/routes"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
+)
+
+// Proxy authenticates the callers of non-public endpoints, applies CORS policies, redirects HTTP requests of
+// HTTPS-only endpoints, enforces rate limits and body sizes, passes the parameters of templated uris and forwards
+// requests to the functions.  The gateway routes the requests of these endpoints to the proxy, naming the endpoint in
+// the X-Dispatch-Endpoint header.
+type Proxy struct {
+	endpoints       *endpointCache
+	authenticator   *auth.Authenticator
+	limiters        *rateLimiters
+	internalGateway string
+	// forward sends the checked requests to the functions
+	forward http.Handler
+}
+
+// NewProxy creates an endpoints proxy forwarding requests to functions through the internal gateway
+func NewProxy(endpointsBackend backend.Backend, authenticator *auth.Authenticator, internalGateway string) *Proxy {
+	p := &Proxy{
+		endpoints:       newEndpointCache(endpointsBackend),
+		authenticator:   authenticator,
+		limiters:        newRateLimiters(),
+		internalGateway: internalGateway,
+	}
+	p.forward = &httputil.ReverseProxy{Director: p.direct, ModifyResponse: modifyResponse}
+	return p
+}
+
+// NewRunProxy creates an endpoints proxy running functions through the functions API
+func NewRunProxy(endpointsBackend backend.Backend, authenticator *auth.Authenticator, functions FunctionsClients) *Proxy {
+	return &Proxy{
+		endpoints:     newEndpointCache(endpointsBackend),
+		authenticator: authenticator,
+		limiters:      newRateLimiters(),
+		forward:       &functionRunner{functions: functions},
+	}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.Header.Get(backend.HeaderEndpoint), "/")
+	if len(parts) != 3 {
+		http.Error(w, "unknown endpoint", http.StatusNotFound)
+		return
+	}
+	meta := &dapi.Meta{Org: parts[0], Project: parts[1], Name: parts[2]}
+	cached, err := p.endpoints.get(r.Context(), meta, time.Now())
+	if err != nil {
+		if _, ok := err.(backend.NotFound); ok {
+			http.Error(w, "unknown endpoint", http.StatusNotFound)
+			return
+		}
+		log.Errorf("error getting endpoint %s: %+v", meta.Name, err)
+		http.Error(w, "error getting endpoint", http.StatusInternalServerError)
+		return
+	}
+
+	// The endpoint header could be set by the caller, the request must be one the endpoint serves so that it is
+	// authenticated as configured for the route it came through
+	endpoint := cached.endpoint
+	matched := matchPath(endpoint, cached.patterns, r)
+	if !endpoint.Enabled || matched == nil {
+		http.Error(w, "unknown endpoint", http.StatusNotFound)
+		return
+	}
+	if endpoint.Cors && isPreflight(r) {
+		preflight(w, r, endpoint)
+		return
+	}
+	if !containsFold(endpoint.Methods, r.Method) {
+		http.Error(w, "unknown endpoint", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get(backend.HeaderScheme) == "http" && !containsFold(endpoint.Protocols, "http") {
+		http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusMovedPermanently)
+		return
+	}
+	if endpoint.Cors {
+		allowOrigin(w, r, endpoint)
+	}
+	// The limit of the endpoint is checked first, so that floods of requests are throttled before they are
+	// authenticated
+	now := time.Now()
+	if endpoint.RateLimit != nil {
+		if retryAfter, ok := p.limiters.take(limiterKey(endpoint, ""), endpoint.RateLimit, now); !ok {
+			tooManyRequests(w, retryAfter)
+			return
+		}
+	}
+	if endpoint.MaxBodySize > 0 {
+		ok, err := limitBody(r, endpoint.MaxBodySize)
+		if err != nil {
+			http.Error(w, "error reading the request body", http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+
+	identity, err := p.authenticator.Authenticate(r, endpoint)
+	if err != nil {
+		if authErr, ok := err.(*auth.Error); ok {
+			authErr.WriteResponse(w)
+			return
+		}
+		log.Errorf("error authenticating a request to endpoint %s: %+v", endpoint.Name, err)
+		http.Error(w, "error authenticating the request", http.StatusInternalServerError)
+		return
+	}
+	if endpoint.ConsumerRateLimit != nil {
+		if retryAfter, ok := p.limiters.take(limiterKey(endpoint, consumer(r, identity)), endpoint.ConsumerRateLimit, now); !ok {
+			tooManyRequests(w, retryAfter)
+			return
+		}
+	}
+
+	ctx := auth.NewContext(r.Context(), identity)
+	ctx = withRoute(ctx, matched)
+	p.forward.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// direct forwards a request to the function of the endpoint, through the internal gateway
+func (p *Proxy) direct(r *http.Request) {
+	matched := routeFromContext(r.Context())
+	endpoint := matched.endpoint
+	fName := knaming.FunctionName(dapi.Meta{Name: endpoint.Function, Project: endpoint.Project, Org: endpoint.Org})
+	r.URL.Scheme = "http"
+	r.URL.Host = p.internalGateway
+	r.Host = fmt.Sprintf("%s.%s.svc.cluster.local", fName, endpoint.Org)
+	r.Header.Del(backend.HeaderEndpoint)
+	r.Header.Del(backend.HeaderScheme)
+	auth.SetHeaders(r.Header, auth.FromContext(r.Context()))
+	setPathParams(r.Header, matched.params)
+	if endpoint.Rewrite != "" {
+		r.URL.Path = matched.pattern.Rewrite(endpoint.Rewrite, r.URL.Path, matched.params)
+		r.URL.RawPath = ""
+	}
+}
+
+// route is the uri of an endpoint a request matched
+type route struct {
+	endpoint *dapi.Endpoint
+	pattern  *routes.Pattern
+	params   map[string]string
+}
+
+type routeKey struct{}
+
+func withRoute(ctx context.Context, matched *route) context.Context {
+	return context.WithValue(ctx, routeKey{}, matched)
+}
+
+func routeFromContext(ctx context.Context) *route {
+	return ctx.Value(routeKey{}).(*route)
+}
+
+// setPathParams replaces the path parameters header of a request forwarded to a function
+func setPathParams(header http.Header, params map[string]string) {
+	header.Del(backend.HeaderPathParams)
+	if len(params) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		log.Warnf("error encoding path parameters: %v", err)
+		return
+	}
+	header.Set(backend.HeaderPathParams, base64.StdEncoding.EncodeToString(encoded))
+}
+
+// modifyResponse drops the CORS headers set by functions of endpoints with a CORS policy, the proxy sets them
+func modifyResponse(resp *http.Response) error {
+	if routeFromContext(resp.Request.Context()).endpoint.Cors {
+		for header := range resp.Header {
+			if strings.HasPrefix(header, "Access-Control-") {
+				resp.Header.Del(header)
+			}
+		}
+	}
+	return nil
+}
+
+// matchPath returns the uri of an endpoint matching the host and path of a request, nil if none
+func matchPath(endpoint *dapi.Endpoint, patterns []*routes.Pattern, r *http.Request) *route {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if len(endpoint.Hosts) > 0 && !containsFold(endpoint.Hosts, host) {
+		return nil
+	}
+	for _, pattern := range patterns {
+		if params, ok := pattern.MatchPath(r.URL.Path); ok {
+			return &route{endpoint: endpoint, pattern: pattern, params: params}
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
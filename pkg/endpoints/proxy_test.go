@@ -0,0 +1,284 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+	"github.com/vmware/dispatch/pkg/endpoints/backend"
+	"github.com/vmware/dispatch/pkg/secrets/service"
+)
+
+type testBackend struct {
+	backend.Backend
+	endpoints map[string]*dapi.Endpoint
+	gets      int
+}
+
+func (b *testBackend) Get(ctx context.Context, meta *dapi.Meta) (*dapi.Endpoint, error) {
+	b.gets++
+	endpoint, ok := b.endpoints[meta.Name]
+	if !ok || endpoint.Org != meta.Org || endpoint.Project != meta.Project {
+		return nil, errors.Errorf("endpoint %s not found", meta.Name)
+	}
+	return endpoint, nil
+}
+
+type testSecrets struct {
+	service.SecretsService
+}
+
+func (s *testSecrets) GetSecret(ctx context.Context, meta *dapi.Meta) (*dapi.Secret, error) {
+	return &dapi.Secret{Name: &meta.Name, Secrets: dapi.SecretValue{"alice": "secret"}}, nil
+}
+
+func TestProxy(t *testing.T) {
+	var forwarded *http.Request
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r
+		w.Write([]byte("hello"))
+	}))
+	defer gateway.Close()
+	gatewayURL, _ := url.Parse(gateway.URL)
+
+	endpoint := &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Function: "hello-fn",
+		Hosts:    []string{"default.dispatch.dispatch.local"},
+		Uris:     []string{"/hello"},
+		Methods:  []string{"GET"},
+		Enabled:  true,
+		Auth:     &dapi.EndpointAuth{Type: dapi.EndpointAuthBasic, Secret: "users"},
+	}
+	public := &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "public", Org: "dispatch", Project: "default"},
+		Function: "hello-fn",
+		Hosts:    []string{"default.dispatch.dispatch.local"},
+		Uris:     []string{"/public"},
+		Methods:  []string{"GET"},
+		Enabled:  true,
+	}
+	proxy := NewProxy(
+		&testBackend{endpoints: map[string]*dapi.Endpoint{"hello": endpoint, "public": public}},
+		auth.NewAuthenticator(&testSecrets{}, nil),
+		gatewayURL.Host)
+
+	request := func(path, endpointName string, authenticate bool) *httptest.ResponseRecorder {
+		forwarded = nil
+		r := httptest.NewRequest("GET", "http://default.dispatch.dispatch.local"+path, nil)
+		r.Header.Set(backend.HeaderEndpoint, "dispatch/default/"+endpointName)
+		r.Header.Set(auth.HeaderSubject, "spoofed")
+		if authenticate {
+			r.SetBasicAuth("alice", "secret")
+		}
+		w := httptest.NewRecorder()
+		proxy.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("/hello", "hello", false)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="hello"`, w.Header().Get("WWW-Authenticate"))
+	assert.Nil(t, forwarded)
+
+	w = request("/hello", "hello", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "d-fn-default-hello-fn.dispatch.svc.cluster.local", forwarded.Host)
+	assert.Equal(t, "/hello", forwarded.URL.Path)
+	assert.Equal(t, "basic", forwarded.Header.Get(auth.HeaderMethod))
+	assert.Equal(t, "alice", forwarded.Header.Get(auth.HeaderSubject))
+	assert.Empty(t, forwarded.Header.Get(backend.HeaderEndpoint))
+
+	// A request naming an endpoint it isn't routed by is rejected
+	w = request("/hello", "public", false)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Nil(t, forwarded)
+
+	// Identities passed by callers of public endpoints are dropped
+	w = request("/public", "public", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, forwarded)
+	assert.Empty(t, forwarded.Header.Get(auth.HeaderSubject))
+}
+
+func TestProxyCorsAndRedirect(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write([]byte("hello"))
+	}))
+	defer gateway.Close()
+	gatewayURL, _ := url.Parse(gateway.URL)
+
+	endpoint := &dapi.Endpoint{
+		Meta:       dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Function:   "hello-fn",
+		Hosts:      []string{"default.dispatch.dispatch.local"},
+		Uris:       []string{"/hello"},
+		Methods:    []string{"GET", "POST"},
+		Protocols:  []string{"https"},
+		Enabled:    true,
+		Cors:       true,
+		CorsPolicy: &dapi.EndpointCors{AllowOrigins: []string{"https://example.com"}, MaxAge: 600},
+	}
+	proxy := NewProxy(
+		&testBackend{endpoints: map[string]*dapi.Endpoint{"hello": endpoint}},
+		auth.NewAuthenticator(&testSecrets{}, nil),
+		gatewayURL.Host)
+
+	request := func(method, origin string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "http://default.dispatch.dispatch.local/hello?name=alice", nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		r.Header.Set(backend.HeaderEndpoint, "dispatch/default/hello")
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		proxy.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("OPTIONS", "https://example.com", http.Header{
+		"Access-Control-Request-Method":  {"POST"},
+		"Access-Control-Request-Headers": {"Content-Type"},
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = request("OPTIONS", "https://other.example.com", http.Header{"Access-Control-Request-Method": {"POST"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// The CORS headers of the function are replaced by the policy of the endpoint
+	w = request("GET", "https://example.com", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"https://example.com"}, w.Header()["Access-Control-Allow-Origin"])
+
+	w = request("GET", "", http.Header{backend.HeaderScheme: {"http"}})
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "https://default.dispatch.dispatch.local/hello?name=alice", w.Header().Get("Location"))
+
+	// Credentials are only allowed for the listed origins
+	endpoint.CorsPolicy.AllowCredentials = true
+	w = request("GET", "https://example.com", nil)
+	assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	w = request("OPTIONS", "https://evil.example.com", http.Header{"Access-Control-Request-Method": {"POST"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	endpoint.Enabled = false
+	w = request("GET", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProxyPathParams(t *testing.T) {
+	var forwarded *http.Request
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r
+	}))
+	defer gateway.Close()
+	gatewayURL, _ := url.Parse(gateway.URL)
+
+	endpoint := &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "orders", Org: "dispatch", Project: "default"},
+		Function: "orders-fn",
+		Uris:     []string{"/users/{user}/orders/{order}"},
+		Methods:  []string{"GET"},
+		Rewrite:  "/orders/{order}",
+		Enabled:  true,
+	}
+	proxy := NewProxy(
+		&testBackend{endpoints: map[string]*dapi.Endpoint{"orders": endpoint}},
+		auth.NewAuthenticator(&testSecrets{}, nil),
+		gatewayURL.Host)
+
+	r := httptest.NewRequest("GET", "http://default.dispatch.dispatch.local/users/alice/orders/42?full=true", nil)
+	r.Header.Set(backend.HeaderEndpoint, "dispatch/default/orders")
+	w := httptest.NewRecorder()
+	proxy.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "/orders/42", forwarded.URL.Path)
+	assert.Equal(t, "true", forwarded.URL.Query().Get("full"))
+	params, err := base64.StdEncoding.DecodeString(forwarded.Header.Get(backend.HeaderPathParams))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"alice","order":"42"}`, string(params))
+
+	r = httptest.NewRequest("GET", "http://default.dispatch.dispatch.local/users/alice/orders", nil)
+	r.Header.Set(backend.HeaderEndpoint, "dispatch/default/orders")
+	w = httptest.NewRecorder()
+	proxy.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProxyLimits(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer gateway.Close()
+	gatewayURL, _ := url.Parse(gateway.URL)
+
+	endpoint := &dapi.Endpoint{
+		Meta:              dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Function:          "hello-fn",
+		Uris:              []string{"/hello"},
+		Methods:           []string{"POST"},
+		Enabled:           true,
+		ConsumerRateLimit: &dapi.EndpointRateLimit{Requests: 1, Per: dapi.EndpointRatePerMinute},
+		MaxBodySize:       16,
+	}
+	proxy := NewProxy(
+		&testBackend{endpoints: map[string]*dapi.Endpoint{"hello": endpoint}},
+		auth.NewAuthenticator(&testSecrets{}, nil),
+		gatewayURL.Host)
+
+	request := func(body, client string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://default.dispatch.dispatch.local/hello", strings.NewReader(body))
+		r.Header.Set(backend.HeaderEndpoint, "dispatch/default/hello")
+		r.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		proxy.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request(strings.Repeat("x", 17), "203.0.113.1")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = request("hello", "203.0.113.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request("hello", "203.0.113.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// Consumers are limited separately
+	w = request("hello", "203.0.113.2")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The limit of the endpoint throttles requests before they are authenticated
+	endpoint.Auth = &dapi.EndpointAuth{Type: dapi.EndpointAuthBasic, Secret: "users"}
+	endpoint.RateLimit = &dapi.EndpointRateLimit{Requests: 1, Per: dapi.EndpointRatePerMinute}
+	w = request("hello", "203.0.113.3")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = request("hello", "203.0.113.3")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
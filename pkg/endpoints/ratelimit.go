@@ -0,0 +1,144 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+)
+
+// limiterIdle is how long the bucket of an endpoint or consumer is kept without requests
+const limiterIdle = 10 * time.Minute
+
+type limiter struct {
+	*rate.Limiter
+	limit dapi.EndpointRateLimit
+	used  time.Time
+}
+
+// rateLimiters holds the token buckets limiting the requests to endpoints and of their consumers.  The buckets are in
+// the memory of each replica serving endpoints, so the limits apply per replica and start over when it restarts.
+type rateLimiters struct {
+	sync.Mutex
+	limiters map[string]*limiter
+	swept    time.Time
+}
+
+func newRateLimiters() *rateLimiters {
+	return &rateLimiters{limiters: make(map[string]*limiter)}
+}
+
+// limiterKey is the key of the bucket of an endpoint, or of one of its consumers
+func limiterKey(endpoint *dapi.Endpoint, consumer string) string {
+	key := strings.Join([]string{endpoint.Org, endpoint.Project, endpoint.Name}, "/")
+	if consumer != "" {
+		key += "/" + consumer
+	}
+	return key
+}
+
+// take counts a request against the rate limit of the bucket of key, returning how long to wait when exceeded.  A
+// rejected request doesn't count against the limit.
+func (l *rateLimiters) take(key string, limit *dapi.EndpointRateLimit, now time.Time) (time.Duration, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	// Buckets of endpoints and consumers without requests for a while are full again, they are dropped
+	if now.Sub(l.swept) > limiterIdle {
+		for key, lim := range l.limiters {
+			if now.Sub(lim.used) > limiterIdle {
+				delete(l.limiters, key)
+			}
+		}
+		l.swept = now
+	}
+
+	r := l.reserve(key, limit, now)
+	if wait := r.DelayFrom(now); wait > 0 {
+		r.CancelAt(now)
+		return wait, false
+	}
+	return 0, true
+}
+
+// reserve takes a token from the bucket of key, created or replaced if the limit changed
+func (l *rateLimiters) reserve(key string, limit *dapi.EndpointRateLimit, now time.Time) *rate.Reservation {
+	lim, ok := l.limiters[key]
+	if !ok || lim.limit != *limit {
+		period := time.Second
+		if limit.Per == dapi.EndpointRatePerMinute {
+			period = time.Minute
+		}
+		burst := limit.Burst
+		if burst == 0 {
+			burst = limit.Requests
+		}
+		lim = &limiter{
+			Limiter: rate.NewLimiter(rate.Limit(float64(limit.Requests)/period.Seconds()), int(burst)),
+			limit:   *limit,
+		}
+		l.limiters[key] = lim
+	}
+	lim.used = now
+	return lim.ReserveN(now, 1)
+}
+
+// consumer identifies the caller of an endpoint: the authenticated subject, or the client address for public
+// endpoints.  The gateway appends the client address to X-Forwarded-For.
+func consumer(r *http.Request, identity *auth.Identity) string {
+	if identity != nil && identity.Subject != "" {
+		return identity.Method + ":" + identity.Subject
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addresses := strings.Split(forwarded, ",")
+		return strings.TrimSpace(addresses[len(addresses)-1])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// tooManyRequests writes the response to a throttled request
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+	http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+}
+
+// limitBody checks that the body of a request is at most max bytes.  Bodies of unknown length are read in memory to
+// be measured.
+func limitBody(r *http.Request, max int64) (bool, error) {
+	if r.ContentLength > max {
+		return false, nil
+	}
+	if r.ContentLength >= 0 {
+		return true, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	r.Body.Close()
+	if err != nil {
+		return false, err
+	}
+	if int64(len(body)) > max {
+		return false, nil
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.TransferEncoding = nil
+	return true, nil
+}
@@ -0,0 +1,88 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+)
+
+func TestRateLimiters(t *testing.T) {
+	l := newRateLimiters()
+	endpoint := &dapi.Endpoint{Meta: dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"}}
+	endpointLimit := &dapi.EndpointRateLimit{Requests: 3, Per: dapi.EndpointRatePerMinute}
+	consumerLimit := &dapi.EndpointRateLimit{Requests: 1, Burst: 2}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		_, ok := l.take(limiterKey(endpoint, "alice"), consumerLimit, now)
+		assert.True(t, ok)
+	}
+	// alice used her burst, refilled at one request per second
+	retryAfter, ok := l.take(limiterKey(endpoint, "alice"), consumerLimit, now)
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+	_, ok = l.take(limiterKey(endpoint, "bob"), consumerLimit, now)
+	assert.True(t, ok)
+
+	for i := 0; i < 3; i++ {
+		_, ok = l.take(limiterKey(endpoint, ""), endpointLimit, now)
+		assert.True(t, ok)
+	}
+	// The rejected request doesn't count against the endpoint
+	retryAfter, ok = l.take(limiterKey(endpoint, ""), endpointLimit, now)
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, retryAfter)
+	_, ok = l.take(limiterKey(endpoint, ""), endpointLimit, now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	// Changing the limit replaces the bucket
+	_, ok = l.take(limiterKey(endpoint, ""), &dapi.EndpointRateLimit{Requests: 100}, now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	// Idle buckets are dropped
+	l.take(limiterKey(endpoint, "carol"), consumerLimit, now.Add(limiterIdle+time.Hour))
+	assert.Len(t, l.limiters, 1)
+}
+
+func TestConsumer(t *testing.T) {
+	r := httptest.NewRequest("GET", "/hello", nil)
+	assert.Equal(t, "basic:alice", consumer(r, &auth.Identity{Method: dapi.EndpointAuthBasic, Subject: "alice"}))
+	assert.Equal(t, "192.0.2.1", consumer(r, nil))
+	r.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.1")
+	assert.Equal(t, "198.51.100.1", consumer(r, nil))
+}
+
+func TestLimitBody(t *testing.T) {
+	r := httptest.NewRequest("POST", "/hello", strings.NewReader("hello"))
+	ok, err := limitBody(r, 4)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	r = httptest.NewRequest("POST", "/hello", strings.NewReader("hello"))
+	r.ContentLength = -1
+	ok, err = limitBody(r, 4)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	r = httptest.NewRequest("POST", "/hello", strings.NewReader("hello"))
+	r.ContentLength = -1
+	ok, err = limitBody(r, 5)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), r.ContentLength)
+	body, _ := ioutil.ReadAll(r.Body)
+	assert.Equal(t, "hello", string(body))
+}
@@ -0,0 +1,338 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package routes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+var paramSegment = regexp.MustCompile(`^\{([A-Za-z_]\w*)\}$`)
+
+// Pattern matches request paths against a uri of an endpoint.  The uris of exact and prefix endpoints are templates
+// when they have {parameter} segments, which match any single segment of the path.  Prefixes match whole segments,
+// "/users" matches "/users" and "/users/me" but not "/usersX".
+type Pattern struct {
+	Match string
+	URI   string
+
+	params []string
+	// expr is the regular expression matching the paths of prefix, template and regex uris, empty for exact literal uris
+	expr   string
+	regexp *regexp.Regexp
+}
+
+// Compile compiles the pattern of a uri
+func Compile(match, uri string) (*Pattern, error) {
+	if match == "" {
+		match = dapi.EndpointMatchExact
+	}
+	p := &Pattern{Match: match, URI: uri}
+
+	switch match {
+	case dapi.EndpointMatchRegex:
+		p.expr = uri
+	case dapi.EndpointMatchExact, dapi.EndpointMatchPrefix:
+		if !strings.HasPrefix(uri, "/") {
+			return nil, errors.Errorf("invalid uri %s: must start with /", uri)
+		}
+		if !strings.ContainsAny(uri, "{}") {
+			if match == dapi.EndpointMatchExact {
+				return p, nil
+			}
+			p.expr = regexp.QuoteMeta(uri) + "(/.*)?"
+			if strings.HasSuffix(uri, "/") {
+				p.expr = regexp.QuoteMeta(uri) + ".*"
+			}
+			break
+		}
+		var parts []string
+		for _, segment := range segments(uri) {
+			m := paramSegment.FindStringSubmatch(segment)
+			if m == nil {
+				if strings.ContainsAny(segment, "{}") {
+					return nil, errors.Errorf("invalid uri %s: parameters must be whole segments", uri)
+				}
+				parts = append(parts, regexp.QuoteMeta(segment))
+				continue
+			}
+			for _, param := range p.params {
+				if param == m[1] {
+					return nil, errors.Errorf("invalid uri %s: duplicate parameter %s", uri, param)
+				}
+			}
+			p.params = append(p.params, m[1])
+			parts = append(parts, "[^/]+")
+		}
+		p.expr = "/" + strings.Join(parts, "/")
+		if match == dapi.EndpointMatchPrefix {
+			p.expr += "(/.*)?"
+		}
+	default:
+		return nil, errors.Errorf("unsupported match %s", match)
+	}
+
+	// Parameters are captured to be passed to the function
+	expr := p.expr
+	if len(p.params) > 0 {
+		expr = strings.Replace(expr, "[^/]+", "([^/]+)", -1)
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid uri %s", uri)
+	}
+	p.regexp = re
+	return p, nil
+}
+
+// Templated tells whether the uri has parameters
+func (p *Pattern) Templated() bool {
+	return len(p.params) > 0
+}
+
+// Regex returns the regular expression fully matching the paths of a prefix, template or regex uri
+func (p *Pattern) Regex() string {
+	return p.expr
+}
+
+// MatchPath matches a path, returning the parameters of templates
+func (p *Pattern) MatchPath(path string) (map[string]string, bool) {
+	if p.regexp == nil {
+		return nil, path == p.URI
+	}
+	m := p.regexp.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+	if !p.Templated() {
+		return nil, true
+	}
+	params := make(map[string]string)
+	for i, param := range p.params {
+		params[param] = m[i+1]
+	}
+	return params, true
+}
+
+// Rewrite returns the path a matched request is forwarded to.  The uri (or uri prefix) is replaced with rewrite, in
+// which the parameters of templates are substituted.
+func (p *Pattern) Rewrite(rewrite, path string, params map[string]string) string {
+	if rewrite == "" {
+		return path
+	}
+	switch {
+	case p.Templated():
+		for param, value := range params {
+			rewrite = strings.Replace(rewrite, "{"+param+"}", value, -1)
+		}
+		if p.Match == dapi.EndpointMatchPrefix {
+			m := p.regexp.FindStringSubmatch(path)
+			rewrite = strings.TrimSuffix(rewrite, "/") + m[len(m)-1]
+		}
+		return rewrite
+	case p.Match == dapi.EndpointMatchPrefix:
+		return rewrite + strings.TrimPrefix(path, p.URI)
+	}
+	return rewrite
+}
+
+// matchRanks orders the kinds of uris from the most specific
+var matchRanks = map[string]int{
+	dapi.EndpointMatchExact:  0,
+	dapi.EndpointMatchPrefix: 1,
+	dapi.EndpointMatchRegex:  2,
+}
+
+// MoreSpecific tells whether a is tried before b when matching requests: exact uris before prefixes before regexes,
+// then uris with more segments, with fewer parameters, and longer ones.  Equally specific uris are ordered by uri.
+func MoreSpecific(a, b *Pattern) bool {
+	if ra, rb := matchRanks[a.Match], matchRanks[b.Match]; ra != rb {
+		return ra < rb
+	}
+	if sa, sb := len(segments(a.URI)), len(segments(b.URI)); sa != sb {
+		return sa > sb
+	}
+	if len(a.params) != len(b.params) {
+		return len(a.params) < len(b.params)
+	}
+	if len(a.URI) != len(b.URI) {
+		return len(a.URI) > len(b.URI)
+	}
+	return a.URI < b.URI
+}
+
+// Sort sorts patterns from the most specific, the order in which requests are matched against them
+func Sort(patterns []*Pattern) {
+	sort.SliceStable(patterns, func(i, j int) bool { return MoreSpecific(patterns[i], patterns[j]) })
+}
+
+// Overlaps tells whether some path is matched by both patterns.  It is exact for exact and prefix uris, and for
+// regex uris best effort: a regex overlaps another uri if it matches an instance of it.
+func Overlaps(a, b *Pattern) bool {
+	if a.Match == dapi.EndpointMatchRegex && b.Match == dapi.EndpointMatchRegex {
+		return a.URI == b.URI
+	}
+	if a.Match == dapi.EndpointMatchRegex {
+		_, ok := a.MatchPath(b.sample())
+		return ok
+	}
+	if b.Match == dapi.EndpointMatchRegex {
+		_, ok := b.MatchPath(a.sample())
+		return ok
+	}
+
+	aPrefix, bPrefix := a.Match == dapi.EndpointMatchPrefix, b.Match == dapi.EndpointMatchPrefix
+	switch {
+	case aPrefix && bPrefix:
+		return covers(a, b) || covers(b, a)
+	case aPrefix:
+		return covers(a, b)
+	case bPrefix:
+		return covers(b, a)
+	}
+	as, bs := segments(a.URI), segments(b.URI)
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if !compatible(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// covers tells whether the prefix pattern p matches some path of q, or of the paths starting with q if a prefix
+func covers(p, q *Pattern) bool {
+	ps, qs := segments(p.URI), segments(q.URI)
+	if len(ps) > len(qs) {
+		return false
+	}
+	last := len(ps) - 1
+	for i := 0; i < last; i++ {
+		if !compatible(ps[i], qs[i]) {
+			return false
+		}
+	}
+	// A prefix ending with / matches any segment after it
+	return ps[last] == "" || compatible(ps[last], qs[last])
+}
+
+// sample returns a path matched by the pattern
+func (p *Pattern) sample() string {
+	var parts []string
+	for _, segment := range segments(p.URI) {
+		if isParam(segment) {
+			segment = "x"
+		}
+		parts = append(parts, segment)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func segments(uri string) []string {
+	return strings.Split(strings.TrimPrefix(uri, "/"), "/")
+}
+
+func isParam(segment string) bool {
+	return paramSegment.MatchString(segment)
+}
+
+func compatible(a, b string) bool {
+	return a == b || isParam(a) || isParam(b)
+}
+
+// Conflict describes two endpoints serving the same requests
+type Conflict struct {
+	Endpoint string
+	Host     string
+	URI      string
+	Method   string
+}
+
+func (c *Conflict) Error() string {
+	return fmt.Sprintf("%s %s%s is already served by endpoint %s", c.Method, c.Host, c.URI, c.Endpoint)
+}
+
+// DefaultHost is the host serving the endpoints of a project which don't name any, <project>.<org>.<dispatchHost>
+func DefaultHost(endpoint *dapi.Endpoint, dispatchHost string) string {
+	return fmt.Sprintf("%s.%s.%s", endpoint.Project, endpoint.Org, dispatchHost)
+}
+
+// Hosts returns the hosts serving an endpoint, the default host of its project if it doesn't name any
+func Hosts(endpoint *dapi.Endpoint, dispatchHost string) []string {
+	if len(endpoint.Hosts) == 0 {
+		return []string{DefaultHost(endpoint, dispatchHost)}
+	}
+	return endpoint.Hosts
+}
+
+// FindConflict returns the first endpoint serving requests of endpoint, nil if none.  Endpoints without hosts are
+// compared on the default host of their project.
+func FindConflict(endpoint *dapi.Endpoint, others []*dapi.Endpoint, dispatchHost string) (*Conflict, error) {
+	patterns, err := CompileAll(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	hosts := Hosts(endpoint, dispatchHost)
+	for _, other := range others {
+		if other.Name == endpoint.Name {
+			continue
+		}
+		host, ok := intersect(hosts, Hosts(other, dispatchHost))
+		if !ok {
+			continue
+		}
+		method, ok := intersect(endpoint.Methods, other.Methods)
+		if !ok {
+			continue
+		}
+		otherPatterns, err := CompileAll(other)
+		if err != nil {
+			// Endpoints created before their uris were validated can't be compared
+			continue
+		}
+		for _, p := range patterns {
+			for _, q := range otherPatterns {
+				if Overlaps(p, q) {
+					return &Conflict{Endpoint: other.Name, Host: host, URI: p.URI, Method: strings.ToUpper(method)}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CompileAll compiles the patterns of the uris of an endpoint
+func CompileAll(endpoint *dapi.Endpoint) ([]*Pattern, error) {
+	var patterns []*Pattern
+	for _, uri := range endpoint.Uris {
+		p, err := Compile(endpoint.Match, uri)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// intersect returns a value in both lists, ignoring case
+func intersect(a, b []string) (string, bool) {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return x, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,214 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+func compile(t *testing.T, match, uri string) *Pattern {
+	p, err := Compile(match, uri)
+	require.NoError(t, err)
+	return p
+}
+
+func TestCompile(t *testing.T) {
+	p := compile(t, "", "/hello")
+	assert.Equal(t, dapi.EndpointMatchExact, p.Match)
+	assert.False(t, p.Templated())
+
+	p = compile(t, dapi.EndpointMatchExact, "/users/{id}/orders/{order}")
+	assert.True(t, p.Templated())
+	assert.Equal(t, "/users/[^/]+/orders/[^/]+", p.Regex())
+
+	for _, invalid := range []struct{ match, uri string }{
+		{dapi.EndpointMatchExact, "hello"},
+		{dapi.EndpointMatchExact, "/users/id-{id}"},
+		{dapi.EndpointMatchExact, "/users/{id}/{id}"},
+		{dapi.EndpointMatchPrefix, "/users/{}"},
+		{dapi.EndpointMatchRegex, "/users/[0-9"},
+		{"glob", "/users/*"},
+	} {
+		_, err := Compile(invalid.match, invalid.uri)
+		assert.Error(t, err, "%s %s", invalid.match, invalid.uri)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	p := compile(t, dapi.EndpointMatchExact, "/hello")
+	_, ok := p.MatchPath("/hello")
+	assert.True(t, ok)
+	_, ok = p.MatchPath("/hello/world")
+	assert.False(t, ok)
+
+	// Prefixes match whole segments
+	p = compile(t, dapi.EndpointMatchPrefix, "/users")
+	_, ok = p.MatchPath("/users")
+	assert.True(t, ok)
+	_, ok = p.MatchPath("/users/me")
+	assert.True(t, ok)
+	_, ok = p.MatchPath("/usersX")
+	assert.False(t, ok)
+	p = compile(t, dapi.EndpointMatchPrefix, "/users/")
+	_, ok = p.MatchPath("/users/me")
+	assert.True(t, ok)
+	_, ok = p.MatchPath("/users")
+	assert.False(t, ok)
+	p = compile(t, dapi.EndpointMatchPrefix, "/")
+	_, ok = p.MatchPath("/hello/world")
+	assert.True(t, ok)
+
+	p = compile(t, dapi.EndpointMatchExact, "/users/{id}/orders/{order}")
+	params, ok := p.MatchPath("/users/alice/orders/42")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "alice", "order": "42"}, params)
+	_, ok = p.MatchPath("/users/alice/orders")
+	assert.False(t, ok)
+	_, ok = p.MatchPath("/users/alice/bob/orders/42")
+	assert.False(t, ok)
+
+	p = compile(t, dapi.EndpointMatchPrefix, "/users/{id}")
+	params, ok = p.MatchPath("/users/alice/orders")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "alice"}, params)
+
+	p = compile(t, dapi.EndpointMatchRegex, "/items/[0-9]+")
+	_, ok = p.MatchPath("/items/42")
+	assert.True(t, ok)
+	_, ok = p.MatchPath("/items/42/x")
+	assert.False(t, ok)
+}
+
+func TestRewrite(t *testing.T) {
+	p := compile(t, dapi.EndpointMatchExact, "/hello")
+	assert.Equal(t, "/hello", p.Rewrite("", "/hello", nil))
+	assert.Equal(t, "/", p.Rewrite("/", "/hello", nil))
+
+	p = compile(t, dapi.EndpointMatchPrefix, "/api")
+	assert.Equal(t, "/v1/users", p.Rewrite("/v1", "/api/users", nil))
+
+	p = compile(t, dapi.EndpointMatchPrefix, "/users/{id}")
+	params, _ := p.MatchPath("/users/alice/orders")
+	assert.Equal(t, "/accounts/alice/orders", p.Rewrite("/accounts/{id}", "/users/alice/orders", params))
+	params, _ = p.MatchPath("/users/alice")
+	assert.Equal(t, "/accounts/alice", p.Rewrite("/accounts/{id}", "/users/alice", params))
+}
+
+func TestOverlaps(t *testing.T) {
+	for _, c := range []struct {
+		aMatch, a, bMatch, b string
+		overlaps             bool
+	}{
+		{dapi.EndpointMatchExact, "/hello", dapi.EndpointMatchExact, "/hello", true},
+		{dapi.EndpointMatchExact, "/hello", dapi.EndpointMatchExact, "/world", false},
+		{dapi.EndpointMatchExact, "/users/{id}", dapi.EndpointMatchExact, "/users/me", true},
+		{dapi.EndpointMatchExact, "/users/{id}", dapi.EndpointMatchExact, "/users/me/orders", false},
+		{dapi.EndpointMatchExact, "/users/{id}/orders", dapi.EndpointMatchExact, "/users/{name}/orders", true},
+		{dapi.EndpointMatchPrefix, "/api", dapi.EndpointMatchExact, "/api/users", true},
+		{dapi.EndpointMatchPrefix, "/api/users", dapi.EndpointMatchExact, "/api", false},
+		{dapi.EndpointMatchPrefix, "/api", dapi.EndpointMatchPrefix, "/api/v1", true},
+		{dapi.EndpointMatchPrefix, "/api/v1", dapi.EndpointMatchPrefix, "/api/v2", false},
+		{dapi.EndpointMatchPrefix, "/users", dapi.EndpointMatchExact, "/usersX", false},
+		{dapi.EndpointMatchPrefix, "/users", dapi.EndpointMatchPrefix, "/usersX", false},
+		{dapi.EndpointMatchPrefix, "/users/", dapi.EndpointMatchExact, "/users/me", true},
+		{dapi.EndpointMatchPrefix, "/", dapi.EndpointMatchPrefix, "/users/{id}", true},
+		{dapi.EndpointMatchPrefix, "/users/{id}", dapi.EndpointMatchExact, "/users/me/orders", true},
+		{dapi.EndpointMatchRegex, "/items/[0-9]+", dapi.EndpointMatchExact, "/items/42", true},
+		{dapi.EndpointMatchRegex, "/items/[0-9]+", dapi.EndpointMatchExact, "/items/all", false},
+		{dapi.EndpointMatchExact, "/items/{id}", dapi.EndpointMatchRegex, "/items/.*", true},
+		{dapi.EndpointMatchRegex, "/items/.*", dapi.EndpointMatchRegex, "/items/.*", true},
+	} {
+		a, b := compile(t, c.aMatch, c.a), compile(t, c.bMatch, c.b)
+		assert.Equal(t, c.overlaps, Overlaps(a, b), "%s %s, %s %s", c.aMatch, c.a, c.bMatch, c.b)
+		assert.Equal(t, c.overlaps, Overlaps(b, a), "%s %s, %s %s", c.bMatch, c.b, c.aMatch, c.a)
+	}
+}
+
+func TestSort(t *testing.T) {
+	patterns := []*Pattern{
+		compile(t, dapi.EndpointMatchRegex, "/api/.*"),
+		compile(t, dapi.EndpointMatchPrefix, "/api"),
+		compile(t, dapi.EndpointMatchExact, "/api/{version}"),
+		compile(t, dapi.EndpointMatchPrefix, "/api/v1"),
+		compile(t, dapi.EndpointMatchExact, "/api/v1"),
+		compile(t, dapi.EndpointMatchExact, "/api/v1/users"),
+		compile(t, dapi.EndpointMatchExact, "/api/v2"),
+		compile(t, dapi.EndpointMatchPrefix, "/api-v1"),
+	}
+	Sort(patterns)
+	var sorted []string
+	for _, p := range patterns {
+		sorted = append(sorted, p.Match+" "+p.URI)
+	}
+	assert.Equal(t, []string{
+		"exact /api/v1/users",
+		"exact /api/v1",
+		"exact /api/v2",
+		"exact /api/{version}",
+		"prefix /api/v1",
+		"prefix /api-v1",
+		"prefix /api",
+		"regex /api/.*",
+	}, sorted)
+}
+
+func TestFindConflict(t *testing.T) {
+	endpoint := func(name string, hosts, uris, methods []string) *dapi.Endpoint {
+		return &dapi.Endpoint{
+			Meta:    dapi.Meta{Name: name, Org: "dispatch", Project: "default"},
+			Hosts:   hosts,
+			Uris:    uris,
+			Methods: methods,
+		}
+	}
+	findConflict := func(e *dapi.Endpoint, others []*dapi.Endpoint) (*Conflict, error) {
+		return FindConflict(e, others, "dispatch.local")
+	}
+	others := []*dapi.Endpoint{
+		endpoint("users", []string{"api.example.com"}, []string{"/users/{id}"}, []string{"GET"}),
+		endpoint("hello", nil, []string{"/hello"}, []string{"GET", "POST"}),
+	}
+
+	conflict, err := findConflict(endpoint("me", []string{"api.example.com"}, []string{"/users/me"}, []string{"get"}), others)
+	require.NoError(t, err)
+	require.NotNil(t, conflict)
+	assert.Equal(t, "users", conflict.Endpoint)
+	assert.Equal(t, "GET api.example.com/users/me is already served by endpoint users", conflict.Error())
+
+	// Other hosts and methods don't conflict
+	conflict, err = findConflict(endpoint("me", []string{"www.example.com"}, []string{"/users/me"}, []string{"GET"}), others)
+	require.NoError(t, err)
+	assert.Nil(t, conflict)
+	conflict, err = findConflict(endpoint("me", []string{"api.example.com"}, []string{"/users/me"}, []string{"DELETE"}), others)
+	require.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	// Endpoints without hosts are served on the default host of their project
+	conflict, err = findConflict(endpoint("hi", []string{"www.example.com"}, []string{"/hello"}, []string{"POST"}), others)
+	require.NoError(t, err)
+	assert.Nil(t, conflict)
+	conflict, err = findConflict(endpoint("hi", []string{"default.dispatch.dispatch.local"}, []string{"/hello"}, []string{"POST"}), others)
+	require.NoError(t, err)
+	require.NotNil(t, conflict)
+	assert.Equal(t, "hello", conflict.Endpoint)
+	assert.Equal(t, "POST default.dispatch.dispatch.local/hello is already served by endpoint hello", conflict.Error())
+	conflict, err = findConflict(endpoint("users-default", nil, []string{"/users/me"}, []string{"GET"}), others)
+	require.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	// An endpoint doesn't conflict with its previous version
+	conflict, err = findConflict(endpoint("hello", nil, []string{"/hello"}, []string{"GET"}), others)
+	require.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	_, err = findConflict(endpoint("bad", nil, []string{"bad"}, []string{"GET"}), others)
+	assert.Error(t, err)
+}
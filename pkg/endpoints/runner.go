@@ -0,0 +1,80 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+)
+
+// FunctionsClients returns the functions client of a project
+type FunctionsClients func(project string) client.FunctionsClient
+
+// functionRunner runs the function of an endpoint with the request body as input, and responds with its output.
+// Functions run with the Content-Type and Accept of the request.  The HTTP context of the run also holds the Method,
+// the raw Query and the Params of templated uris, if any.
+type functionRunner struct {
+	functions FunctionsClients
+}
+
+func (fr *functionRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	matched := routeFromContext(r.Context())
+	endpoint := matched.endpoint
+	input, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "error reading the request body", http.StatusBadRequest)
+		return
+	}
+
+	httpContext := map[string]interface{}{"Method": r.Method}
+	if r.URL.RawQuery != "" {
+		httpContext["Query"] = r.URL.RawQuery
+	}
+	if len(matched.params) > 0 {
+		httpContext["Params"] = matched.params
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		httpContext["Content-Type"] = contentType
+	}
+	// Functions answer with the content type they are asked for, a wildcard is left to the default
+	if accept := r.Header.Get("Accept"); accept != "" && !strings.Contains(accept, "*") {
+		httpContext["Accept"] = accept
+	}
+	run := &dapi.Run{
+		Blocking:     true,
+		FunctionName: endpoint.Function,
+		InputBytes:   input,
+		HTTPContext:  httpContext,
+	}
+
+	result, err := fr.functions(endpoint.Project).RunFunction(r.Context(), endpoint.Org, run)
+	if err != nil {
+		code, message := http.StatusBadGateway, "error running the function"
+		if runErr, ok := err.(client.Error); ok {
+			message = runErr.Message()
+			// Inputs not matching the schema of the function are errors of the caller, other client errors (e.g. a
+			// missing function) are errors of the endpoint
+			switch c := runErr.Code(); {
+			case c == http.StatusBadRequest || c == http.StatusUnprocessableEntity || c >= http.StatusInternalServerError:
+				code = c
+			}
+		}
+		log.Debugf("error running function %s of endpoint %s: %v", endpoint.Function, endpoint.Name, err)
+		http.Error(w, message, code)
+		return
+	}
+
+	if contentType, ok := result.HTTPContext["Content-Type"].(string); ok && contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Write(result.OutputBytes)
+}
@@ -0,0 +1,124 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package endpoints
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+	"github.com/vmware/dispatch/pkg/endpoints/backend"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+func TestGatewayRunProxy(t *testing.T) {
+	fnClient := &mocks.FunctionsClient{}
+	var projects []string
+	clients := func(project string) client.FunctionsClient {
+		projects = append(projects, project)
+		return fnClient
+	}
+
+	gateway, err := backend.Gateway(backend.GatewayConfig{DispatchHost: "dispatch.local"}, helpers.MakeEntityStore(t))
+	require.NoError(t, err)
+	_, err = gateway.Add(context.Background(), &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "hello", Org: "dispatch", Project: "default"},
+		Function: "hello-fn",
+		Uris:     []string{"/hello"},
+		Methods:  []string{"POST"},
+		Enabled:  true,
+		Auth:     &dapi.EndpointAuth{Type: dapi.EndpointAuthBasic, Secret: "users"},
+	})
+	require.NoError(t, err)
+	_, err = gateway.Add(context.Background(), &dapi.Endpoint{
+		Meta:     dapi.Meta{Name: "greet", Org: "dispatch", Project: "default"},
+		Function: "greet-fn",
+		Uris:     []string{"/greet/{name}"},
+		Methods:  []string{"POST"},
+		Enabled:  true,
+		Auth:     &dapi.EndpointAuth{Type: dapi.EndpointAuthPublic},
+	})
+	require.NoError(t, err)
+	proxy := NewRunProxy(gateway, auth.NewAuthenticator(&testSecrets{}, nil), clients)
+	server := httptest.NewServer(gateway.Handler(proxy))
+	defer server.Close()
+
+	request := func(path string, authenticate bool) *http.Response {
+		r, err := http.NewRequest("POST", server.URL+path, strings.NewReader(`{"name":"alice"}`))
+		require.NoError(t, err)
+		r.Host = "default.dispatch.dispatch.local"
+		r.Header.Set("Content-Type", "application/json")
+		if authenticate {
+			r.SetBasicAuth("alice", "secret")
+		}
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		return resp
+	}
+
+	fnClient.On("RunFunction", mock.Anything, "dispatch", mock.MatchedBy(func(run *dapi.Run) bool {
+		return run.FunctionName == "hello-fn" && run.Blocking && string(run.InputBytes) == `{"name":"alice"}` &&
+			run.HTTPContext["Content-Type"] == "application/json" && run.HTTPContext["Method"] == "POST" &&
+			run.HTTPContext["Query"] == nil && run.HTTPContext["Params"] == nil
+	})).Return(&dapi.Run{
+		OutputBytes: []byte(`{"hello":"alice"}`),
+		HTTPContext: map[string]interface{}{"Content-Type": "application/json"},
+	}, nil).Once()
+	resp := request("/hello", true)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"hello":"alice"}`, string(body))
+	assert.Equal(t, []string{"default"}, projects)
+
+	// The method, query and path parameters of the request are passed in the HTTP context
+	fnClient.On("RunFunction", mock.Anything, "dispatch", mock.MatchedBy(func(run *dapi.Run) bool {
+		params, _ := run.HTTPContext["Params"].(map[string]string)
+		return run.FunctionName == "greet-fn" && run.HTTPContext["Method"] == "POST" &&
+			run.HTTPContext["Query"] == "lang=fr&x=1" && params["name"] == "bob"
+	})).Return(&dapi.Run{OutputBytes: []byte(`{"hello":"bob"}`)}, nil).Once()
+	resp = request("/greet/bob?lang=fr&x=1", false)
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"hello":"bob"}`, string(body))
+
+	resp = request("/hello", false)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = request("/other", true)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// A missing function is an error of the endpoint, a function error is passed on
+	fnClient.On("RunFunction", mock.Anything, "dispatch", mock.Anything).Return(
+		nil, client.NewErrorNotFound(&dapi.Error{Code: http.StatusNotFound, Message: swag.String("not found")})).Once()
+	resp = request("/hello", true)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	fnClient.On("RunFunction", mock.Anything, "dispatch", mock.Anything).Return(
+		nil, client.NewErrorServerUnknownError(&dapi.Error{Code: http.StatusGatewayTimeout, Message: swag.String("timeout")})).Once()
+	resp = request("/hello", true)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+
+	fnClient.AssertExpectations(t)
+}
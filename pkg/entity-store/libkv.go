@@ -18,16 +18,29 @@ import (
 )
 
 type libkvEntityStore struct {
-	kv store.Store
+	kv      store.Store
+	changes *broadcaster
 }
 
 // newLibkv is the EntityStore constructor
 func newLibkv(kv store.Store) EntityStore {
 	return &libkvEntityStore{
-		kv: kv,
+		kv:      kv,
+		changes: newBroadcaster(),
 	}
 }
 
+func (es *libkvEntityStore) publish(eventType WatchEventType, entity Entity, value []byte, revision uint64) {
+	es.changes.publish(WatchEvent{
+		Type:           eventType,
+		DataType:       getDataType(entity),
+		OrganizationID: entity.GetOrganizationID(),
+		Name:           entity.GetName(),
+		value:          value,
+		entityRevision: revision,
+	})
+}
+
 func (es *libkvEntityStore) UpdateWithError(ctx context.Context, e Entity, err error) {
 	if err != nil {
 		e.SetStatus(StatusERROR)
@@ -83,6 +96,7 @@ func (es *libkvEntityStore) Add(ctx context.Context, entity Entity) (id string,
 		return "", err
 	}
 	entity.setRevision(resp.LastIndex)
+	es.publish(WatchEventAdded, entity, data, resp.LastIndex)
 	return id, nil
 }
 
@@ -116,6 +130,7 @@ func (es *libkvEntityStore) Update(ctx context.Context, lastRevision uint64, ent
 		return 0, err
 	}
 	entity.setRevision(kv.LastIndex)
+	es.publish(WatchEventUpdated, entity, data, kv.LastIndex)
 	return int64(kv.LastIndex), nil
 }
 
@@ -126,7 +141,24 @@ func (es *libkvEntityStore) Delete(ctx context.Context, organizationID string, n
 		return errors.Errorf("organizationID cannot be empty")
 	}
 	key := buildKey(getDataType(entity), organizationID, name)
-	return es.kv.Delete(key)
+	kv, err := es.kv.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := es.kv.Delete(key); err != nil {
+		return err
+	}
+	deleted := reflect.New(reflect.TypeOf(entity).Elem()).Interface().(Entity)
+	if err := json.Unmarshal(kv.Value, deleted); err != nil {
+		return errors.Wrap(err, "deserialization error, while deleting")
+	}
+	es.publish(WatchEventDeleted, deleted, kv.Value, kv.LastIndex)
+	return nil
+}
+
+// Watch streams the changes of entities of a single data type, kept in process as BoltDB is not shared
+func (es *libkvEntityStore) Watch(ctx context.Context, dataType DataType, organizationID string, fromRevision uint64) (<-chan WatchEvent, error) {
+	return es.changes.watch(ctx, dataType, organizationID, fromRevision)
 }
 
 // SoftDelete marks a single entity for deletion
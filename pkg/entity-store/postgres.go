@@ -11,7 +11,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -24,8 +26,38 @@ import (
 	"github.com/vmware/dispatch/pkg/trace"
 )
 
+const (
+	// eventChannel is the channel notified of the changes of the entity table
+	eventChannel = "entity_event"
+	// eventRetention is how many changes of the entity table are kept for watchers to resume from
+	eventRetention = 10000
+	// eventPruneInterval is how often the changes beyond the retention are deleted
+	eventPruneInterval = time.Minute
+	// maxWatermarkCandidates bounds the revisions waiting for their transactions to finish, older ones are dropped
+	// which only delays the watermark
+	maxWatermarkCandidates = 100
+	// watchPollInterval is how often watchers look for changes they could have missed a notification of
+	watchPollInterval = 30 * time.Second
+)
+
 type postgresEntityStore struct {
 	db *sqlx.DB
+
+	connStr   string
+	listening sync.Mutex
+	listener  *pq.Listener
+	notified  *notifier
+	committed *watermark
+}
+
+type dbEvent struct {
+	Revision       uint64         `db:"revision"`
+	Event          string         `db:"event"`
+	Type           string         `db:"type"`
+	OrganizationID string         `db:"organization_id"`
+	Name           string         `db:"name"`
+	EntityRevision uint64         `db:"entity_revision"`
+	Value          types.JSONText `db:"value"`
 }
 
 type dbEntity struct {
@@ -122,12 +154,58 @@ func (p *postgresEntityStore) createTable() error {
 	if err != nil {
 		return errors.Wrap(err, "fail to create the entity table")
 	}
+	return p.createEventTable()
+}
+
+// createEventTable creates the change feed of the entity table, filled by a trigger which notifies the watchers.
+// Revisions come from the sequence of the table, and may be committed out of order: watchers only read up to the
+// committed watermark, so they cannot skip a change committed late.
+func (p *postgresEntityStore) createEventTable() error {
+	sql := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS entity_event (
+		revision 		BIGSERIAL PRIMARY KEY,
+		event 			TEXT,
+		type			TEXT,
+		organization_id TEXT,
+		name 			TEXT,
+		entity_revision BIGINT,
+		value 			JSONB
+	);
+
+	CREATE OR REPLACE FUNCTION entity_event_notify() RETURNS TRIGGER AS $$
+	DECLARE
+		rev BIGINT;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			INSERT INTO entity_event (event, type, organization_id, name, entity_revision, value)
+			VALUES ('%s', OLD.type, OLD.organization_id, OLD.name, OLD.revision, OLD.value)
+			RETURNING revision INTO rev;
+		ELSE
+			INSERT INTO entity_event (event, type, organization_id, name, entity_revision, value)
+			VALUES (CASE TG_OP WHEN 'INSERT' THEN '%s' ELSE '%s' END,
+				NEW.type, NEW.organization_id, NEW.name, NEW.revision, NEW.value)
+			RETURNING revision INTO rev;
+		END IF;
+		PERFORM pg_notify('%s', rev::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS entity_event ON entity;
+	CREATE TRIGGER entity_event AFTER INSERT OR UPDATE OR DELETE ON entity
+		FOR EACH ROW EXECUTE PROCEDURE entity_event_notify();
+	`, WatchEventDeleted, WatchEventAdded, WatchEventUpdated, eventChannel)
+	_, err := p.db.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, "fail to create the entity event table")
+	}
 	return nil
 }
 
 func (p *postgresEntityStore) dropTable() error {
 	sql := `
-	DROP TABLE IF EXISTS entity`
+	DROP TABLE IF EXISTS entity;
+	DROP TABLE IF EXISTS entity_event`
 	_, err := p.db.Exec(sql)
 	if err != nil {
 		log.Debug(err)
@@ -154,16 +232,28 @@ func newPostgres(config BackendConfig) (EntityStore, error) {
 		log.Debugf("error connecting to postgresql DB")
 		return nil, errors.Wrap(err, "Unable to connect to the postgres db server")
 	}
-	store := &postgresEntityStore{db: db}
+	store := &postgresEntityStore{db: db, connStr: opts, notified: newNotifier(), committed: &watermark{}}
 
 	// create tables if not exists
 	err = store.createTable()
 	if err != nil {
 		return nil, err
 	}
+	go store.pruneEvents()
 	return store, nil
 }
 
+// pruneEvents deletes the changes of the entity table beyond the retention, in the background so writes don't pay for
+// it
+func (p *postgresEntityStore) pruneEvents() {
+	for range time.Tick(eventPruneInterval) {
+		_, err := p.db.Exec(`DELETE FROM entity_event WHERE revision <= (SELECT MAX(revision) FROM entity_event) - $1`, eventRetention)
+		if err != nil {
+			log.Warnf("error pruning entity events: %s", err)
+		}
+	}
+}
+
 func dbToEntity(row dbEntity, entity Entity) error {
 
 	err := row.Value.Unmarshal(entity)
@@ -549,3 +639,271 @@ func (p *postgresEntityStore) UpdateWithError(ctx context.Context, e Entity, err
 	}
 	return
 }
+
+// notifier wakes the watchers of the store up when a change is notified
+type notifier struct {
+	sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+func newNotifier() *notifier {
+	return &notifier{subscribers: map[chan struct{}]struct{}{}}
+}
+
+func (n *notifier) subscribe() chan struct{} {
+	n.Lock()
+	defer n.Unlock()
+	c := make(chan struct{}, 1)
+	n.subscribers[c] = struct{}{}
+	return c
+}
+
+func (n *notifier) unsubscribe(c chan struct{}) {
+	n.Lock()
+	defer n.Unlock()
+	delete(n.subscribers, c)
+}
+
+func (n *notifier) notify() {
+	n.Lock()
+	defer n.Unlock()
+	for c := range n.subscribers {
+		select {
+		case c <- struct{}{}:
+		default:
+			// already woken up, the watcher reads every change since its last revision anyway
+		}
+	}
+}
+
+// listen starts listening to the notifications of the entity event table, once for all the watchers of the store
+func (p *postgresEntityStore) listen() error {
+	p.listening.Lock()
+	defer p.listening.Unlock()
+	if p.listener != nil {
+		return nil
+	}
+
+	listener := pq.NewListener(p.connStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warnf("entity event listener: %s", err)
+		}
+	})
+	if err := listener.Listen(eventChannel); err != nil {
+		listener.Close()
+		return errors.Wrap(err, "error listening to entity events")
+	}
+	p.listener = listener
+
+	go func() {
+		// A nil notification is sent after a reconnection, when notifications may have been lost
+		for range listener.Notify {
+			p.notified.notify()
+		}
+	}()
+	return nil
+}
+
+// txSnapshot is a snapshot of the transactions of the database, as returned by txid_current_snapshot()
+type txSnapshot struct {
+	xmin uint64
+	xmax uint64
+	xip  []uint64
+}
+
+func parseTxSnapshot(snapshot string) (*txSnapshot, error) {
+	parts := strings.Split(snapshot, ":")
+	if len(parts) != 3 {
+		return nil, errors.Errorf("invalid transaction snapshot '%s'", snapshot)
+	}
+	s := new(txSnapshot)
+	var err error
+	if s.xmin, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return nil, errors.Wrapf(err, "invalid transaction snapshot '%s'", snapshot)
+	}
+	if s.xmax, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return nil, errors.Wrapf(err, "invalid transaction snapshot '%s'", snapshot)
+	}
+	if parts[2] == "" {
+		return s, nil
+	}
+	for _, part := range strings.Split(parts[2], ",") {
+		txid, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction snapshot '%s'", snapshot)
+		}
+		s.xip = append(s.xip, txid)
+	}
+	return s, nil
+}
+
+// finished tells whether a transaction was over when the snapshot was taken
+func (s *txSnapshot) finished(txid uint64) bool {
+	if txid < s.xmin {
+		return true
+	}
+	if txid >= s.xmax {
+		return false
+	}
+	for _, running := range s.xip {
+		if running == txid {
+			return false
+		}
+	}
+	return true
+}
+
+// watermarkCandidate is a revision of the sequence, with the transactions which were running when it was read. Only
+// those transactions can hold a revision up to it which is not committed yet: a transaction taking a revision later
+// gets one above it.
+type watermarkCandidate struct {
+	revision uint64
+	running  []uint64
+}
+
+// watermark tracks the committed watermark of the event table, the revision up to which every change is either
+// committed or rolled back
+type watermark struct {
+	sync.Mutex
+	revision   uint64
+	candidates []watermarkCandidate
+}
+
+// advance records the revision last taken from the sequence along with a snapshot taken after it, and returns the
+// committed watermark
+func (w *watermark) advance(last uint64, snapshot *txSnapshot) uint64 {
+	w.Lock()
+	defer w.Unlock()
+	w.candidates = append(w.candidates, watermarkCandidate{revision: last, running: snapshot.xip})
+	if len(w.candidates) > maxWatermarkCandidates {
+		w.candidates = w.candidates[len(w.candidates)-maxWatermarkCandidates:]
+	}
+	pending := w.candidates[:0]
+	for _, candidate := range w.candidates {
+		done := true
+		for _, txid := range candidate.running {
+			if !snapshot.finished(txid) {
+				done = false
+				break
+			}
+		}
+		if done {
+			if candidate.revision > w.revision {
+				w.revision = candidate.revision
+			}
+		} else if candidate.revision > w.revision {
+			pending = append(pending, candidate)
+		}
+	}
+	w.candidates = pending
+	return w.revision
+}
+
+// committedRevision returns the committed watermark of the event table. The sequence is read before the snapshot, so
+// the transactions holding a revision up to the one read are finished or running in the snapshot.
+func (p *postgresEntityStore) committedRevision() (uint64, error) {
+	var last uint64
+	var called bool
+	if err := p.db.QueryRow(`SELECT last_value, is_called FROM entity_event_revision_seq`).Scan(&last, &called); err != nil {
+		return 0, errors.Wrap(err, "error reading the entity event sequence")
+	}
+	if !called {
+		last = 0
+	}
+	var current string
+	if err := p.db.QueryRow(`SELECT txid_current_snapshot()::TEXT`).Scan(&current); err != nil {
+		return 0, errors.Wrap(err, "error reading the transaction snapshot")
+	}
+	snapshot, err := parseTxSnapshot(current)
+	if err != nil {
+		return 0, err
+	}
+	return p.committed.advance(last, snapshot), nil
+}
+
+func (p *postgresEntityStore) eventsAfter(dataType DataType, organizationID string, revision uint64) ([]WatchEvent, error) {
+	committed, err := p.committedRevision()
+	if err != nil {
+		return nil, err
+	}
+	if committed <= revision {
+		return nil, nil
+	}
+	sql := `SELECT * FROM entity_event WHERE revision > $1 AND revision <= $4 AND type = $2 AND ($3 = '' OR organization_id = $3) ORDER BY revision`
+	var rows []dbEvent
+	if err := p.db.Select(&rows, sql, revision, string(dataType), organizationID, committed); err != nil {
+		return nil, errors.Wrap(err, "error reading entity events")
+	}
+	events := make([]WatchEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, WatchEvent{
+			Type:           WatchEventType(row.Event),
+			Revision:       row.Revision,
+			DataType:       DataType(row.Type),
+			OrganizationID: row.OrganizationID,
+			Name:           row.Name,
+			value:          row.Value,
+			entityRevision: row.EntityRevision,
+		})
+	}
+	return events, nil
+}
+
+// Watch streams the changes of entities of a single data type from the entity event table, reading it again
+// whenever a change is notified
+func (p *postgresEntityStore) Watch(ctx context.Context, dataType DataType, organizationID string, fromRevision uint64) (<-chan WatchEvent, error) {
+	if err := p.listen(); err != nil {
+		return nil, err
+	}
+	// Subscribe first, so a change made while looking for the revision to start from is not missed
+	wakeUp := p.notified.subscribe()
+
+	var oldest uint64
+	err := p.db.QueryRow(`SELECT COALESCE(MIN(revision), 0) FROM entity_event`).Scan(&oldest)
+	if err != nil {
+		p.notified.unsubscribe(wakeUp)
+		return nil, errors.Wrap(err, "error reading entity events")
+	}
+	if fromRevision == 0 {
+		if fromRevision, err = p.committedRevision(); err != nil {
+			p.notified.unsubscribe(wakeUp)
+			return nil, err
+		}
+	} else if oldest > fromRevision+1 {
+		p.notified.unsubscribe(wakeUp)
+		return nil, ErrCompacted
+	}
+
+	events := make(chan WatchEvent, watchBufferSize)
+	go func() {
+		defer close(events)
+		defer p.notified.unsubscribe(wakeUp)
+		poll := time.NewTicker(watchPollInterval)
+		defer poll.Stop()
+
+		last := fromRevision
+		for {
+			changes, err := p.eventsAfter(dataType, organizationID, last)
+			if err != nil {
+				// The watcher watches again from the last revision it got
+				log.Errorf("error watching %s entities: %s", dataType, err)
+				return
+			}
+			for _, event := range changes {
+				select {
+				case events <- event:
+					last = event.Revision
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-wakeUp:
+			case <-poll.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -285,6 +285,10 @@ type EntityStore interface {
 	// UpdateWithError is used by entity handlers to save changes and/or error status
 	// e.g. `defer func() { h.store.UpdateWithError(e, err) }()`
 	UpdateWithError(ctx context.Context, e Entity, err error)
+	// Watch streams the changes of entities of a single data type, in an organization or all of them if empty,
+	// after fromRevision (0 for changes from now on). The channel is closed when ctx is done or the watcher falls
+	// behind, the watcher can then watch again from the revision of the last event it got.
+	Watch(ctx context.Context, dataType DataType, organizationID string, fromRevision uint64) (<-chan WatchEvent, error)
 }
 
 type uniqueViolation interface {
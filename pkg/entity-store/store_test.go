@@ -72,6 +72,7 @@ func TestPostgresEntityStore(t *testing.T) {
 	testDelete(t, es)
 	testInvalidNames(t, es)
 	testMixedTypes(t, es)
+	testWatch(t, es)
 }
 
 func TestLibkvEntityStore(t *testing.T) {
@@ -98,6 +99,7 @@ func TestLibkvEntityStore(t *testing.T) {
 	testDelete(t, es)
 	testInvalidNames(t, es)
 	testMixedTypes(t, es)
+	testWatch(t, es)
 
 	os.Remove(file.Name())
 }
@@ -569,3 +571,119 @@ func testDelete(t *testing.T, es EntityStore) {
 	err = es.Get(context.Background(), "testOrg", "testEntityDelete", Options{}, &retreived)
 	assert.Error(t, err)
 }
+
+func nextEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watch closed")
+		return event
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no watch event")
+	}
+	return WatchEvent{}
+}
+
+func testWatch(t *testing.T, es EntityStore) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := es.Watch(ctx, "testEntity", "testOrg", 0)
+	require.NoError(t, err)
+
+	// Neither another type nor another organization is streamed
+	other := &otherEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testWatchOther"}}
+	_, err = es.Add(ctx, other)
+	require.NoError(t, err)
+	defer es.Delete(ctx, "testOrg", "testWatchOther", other)
+	elsewhere := &testEntity{BaseEntity: BaseEntity{OrganizationID: "otherOrg", Name: "testWatch"}}
+	_, err = es.Add(ctx, elsewhere)
+	require.NoError(t, err)
+	defer es.Delete(ctx, "otherOrg", "testWatch", elsewhere)
+
+	e := &testEntity{BaseEntity: BaseEntity{OrganizationID: "testOrg", Name: "testWatch"}, Value: "added"}
+	_, err = es.Add(ctx, e)
+	require.NoError(t, err)
+	added := nextEvent(t, events)
+	assert.Equal(t, WatchEventAdded, added.Type)
+	assert.Equal(t, "testWatch", added.Name)
+
+	e.Value = "updated"
+	_, err = es.Update(ctx, e.Revision, e)
+	require.NoError(t, err)
+	updated := nextEvent(t, events)
+	assert.Equal(t, WatchEventUpdated, updated.Type)
+	assert.True(t, updated.Revision > added.Revision)
+	var decoded testEntity
+	require.NoError(t, updated.Decode(&decoded))
+	assert.Equal(t, "updated", decoded.Value)
+	assert.Equal(t, e.Revision, decoded.Revision)
+
+	require.NoError(t, es.Delete(ctx, "testOrg", "testWatch", e))
+	deleted := nextEvent(t, events)
+	assert.Equal(t, WatchEventDeleted, deleted.Type)
+	require.NoError(t, deleted.Decode(&decoded))
+	assert.Equal(t, "testWatch", decoded.Name)
+
+	// Watching again from a revision replays the changes after it, across organizations when none is given
+	replay, err := es.Watch(ctx, "testEntity", "", added.Revision)
+	require.NoError(t, err)
+	assert.Equal(t, WatchEventUpdated, nextEvent(t, replay).Type)
+	assert.Equal(t, WatchEventDeleted, nextEvent(t, replay).Type)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestBroadcasterCompacted(t *testing.T) {
+	b := newBroadcaster()
+	for i := 0; i < watchHistorySize+2; i++ {
+		b.publish(WatchEvent{Type: WatchEventAdded, DataType: "testEntity", OrganizationID: "testOrg"})
+	}
+	_, err := b.watch(context.Background(), "testEntity", "", 1)
+	assert.Equal(t, ErrCompacted, err)
+
+	events, err := b.watch(context.Background(), "testEntity", "", 2)
+	require.NoError(t, err)
+	assert.Len(t, events, watchHistorySize)
+}
+
+func TestBroadcasterDropsSlowWatcher(t *testing.T) {
+	b := newBroadcaster()
+	events, err := b.watch(context.Background(), "testEntity", "", 0)
+	require.NoError(t, err)
+	for i := 0; i < watchBufferSize+1; i++ {
+		b.publish(WatchEvent{Type: WatchEventAdded, DataType: "testEntity", OrganizationID: "testOrg"})
+	}
+	var received int
+	for range events {
+		received++
+	}
+	assert.Equal(t, watchBufferSize, received)
+}
+
+func TestPostgresWatermark(t *testing.T) {
+	snapshot := func(s string) *txSnapshot {
+		parsed, err := parseTxSnapshot(s)
+		require.NoError(t, err)
+		return parsed
+	}
+	parsed := snapshot("10:15:10,12")
+	assert.Equal(t, &txSnapshot{xmin: 10, xmax: 15, xip: []uint64{10, 12}}, parsed)
+	assert.True(t, parsed.finished(9))
+	assert.True(t, parsed.finished(11))
+	assert.False(t, parsed.finished(12))
+	assert.False(t, parsed.finished(15))
+	_, err := parseTxSnapshot("10:15")
+	assert.Error(t, err)
+
+	w := &watermark{}
+	// Nothing is running, every revision taken is committed or rolled back
+	assert.EqualValues(t, 3, w.advance(3, snapshot("20:20:")))
+	// Transaction 20 may hold a revision up to 7 which is not committed yet
+	assert.EqualValues(t, 3, w.advance(7, snapshot("20:22:20")))
+	assert.EqualValues(t, 3, w.advance(9, snapshot("20:23:20,21,22")))
+	// Once 20 is over, 7 is the watermark, 9 waits for 21 and 22
+	assert.EqualValues(t, 7, w.advance(9, snapshot("21:23:21")))
+	assert.EqualValues(t, 9, w.advance(12, snapshot("23:24:23")))
+}
@@ -0,0 +1,143 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entitystore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// WatchEventType is the kind of change of a watch event
+type WatchEventType string
+
+const (
+	// WatchEventAdded is sent when an entity is added
+	WatchEventAdded WatchEventType = "ADDED"
+	// WatchEventUpdated is sent when an entity is updated, including soft deletes
+	WatchEventUpdated WatchEventType = "UPDATED"
+	// WatchEventDeleted is sent when an entity is deleted from the store
+	WatchEventDeleted WatchEventType = "DELETED"
+)
+
+const (
+	// watchBufferSize is how many events a watcher can fall behind before it is dropped
+	watchBufferSize = 128
+	// watchHistorySize is how many past events the in-process broadcaster keeps to replay
+	watchHistorySize = 1024
+)
+
+// ErrCompacted is returned by Watch when the events after fromRevision are not kept anymore,
+// the watcher should list the entities again and watch from the current revision (0)
+var ErrCompacted = errors.New("watch revision has been compacted")
+
+// WatchEvent is a change of an entity in the store
+type WatchEvent struct {
+	Type WatchEventType
+	// Revision is the position of the event in the change feed of the store, pass it to Watch to resume after it
+	Revision       uint64
+	DataType       DataType
+	OrganizationID string
+	Name           string
+
+	value          []byte
+	entityRevision uint64
+}
+
+// Decode decodes the entity of the event, for a delete it is the entity as it was last stored
+func (e *WatchEvent) Decode(entity Entity) error {
+	if err := json.Unmarshal(e.value, entity); err != nil {
+		return errors.Wrap(err, "deserialization error, while watching")
+	}
+	entity.setRevision(e.entityRevision)
+	return nil
+}
+
+func (e *WatchEvent) matches(dataType DataType, organizationID string) bool {
+	return e.DataType == dataType && (organizationID == "" || e.OrganizationID == organizationID)
+}
+
+type watcher struct {
+	dataType       DataType
+	organizationID string
+	events         chan WatchEvent
+}
+
+// broadcaster fans the changes of an entity store out to its watchers within the process
+type broadcaster struct {
+	sync.Mutex
+	revision uint64
+	history  []WatchEvent
+	watchers map[*watcher]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{watchers: map[*watcher]struct{}{}}
+}
+
+func (b *broadcaster) publish(event WatchEvent) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.revision++
+	event.Revision = b.revision
+	b.history = append(b.history, event)
+	if len(b.history) > watchHistorySize {
+		b.history = b.history[len(b.history)-watchHistorySize:]
+	}
+	for w := range b.watchers {
+		if !event.matches(w.dataType, w.organizationID) {
+			continue
+		}
+		select {
+		case w.events <- event:
+		default:
+			// The watcher is too slow, it has to watch again from the last revision it got
+			delete(b.watchers, w)
+			close(w.events)
+		}
+	}
+}
+
+func (b *broadcaster) watch(ctx context.Context, dataType DataType, organizationID string, fromRevision uint64) (<-chan WatchEvent, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	var replay []WatchEvent
+	if fromRevision > 0 && fromRevision < b.revision {
+		if len(b.history) == 0 || b.history[0].Revision > fromRevision+1 {
+			return nil, ErrCompacted
+		}
+		for _, event := range b.history {
+			if event.Revision > fromRevision && event.matches(dataType, organizationID) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	w := &watcher{
+		dataType:       dataType,
+		organizationID: organizationID,
+		events:         make(chan WatchEvent, len(replay)+watchBufferSize),
+	}
+	for _, event := range replay {
+		w.events <- event
+	}
+	b.watchers[w] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.Lock()
+		defer b.Unlock()
+		if _, ok := b.watchers[w]; ok {
+			delete(b.watchers, w)
+			close(w.events)
+		}
+	}()
+	return w.events, nil
+}
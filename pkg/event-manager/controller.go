@@ -24,6 +24,8 @@ const (
 type EventControllerConfig struct {
 	ResyncPeriod time.Duration
 	WorkerNumber int
+	// LeaderElector elects the replica reconciling the entities, all of them do if nil
+	LeaderElector controller.LeaderElector
 }
 
 // NewEventController creates a new controller to manage the reconciliation of event manager entities
@@ -37,9 +39,11 @@ func NewEventController(manager subscriptions.Manager, backend drivers.Backend,
 	}
 
 	c := controller.NewController(controller.Options{
-		ResyncPeriod: config.ResyncPeriod,
-		Workers:      config.WorkerNumber,
-		ServiceName:  "events",
+		ResyncPeriod:  config.ResyncPeriod,
+		Workers:       config.WorkerNumber,
+		ServiceName:   "events",
+		Store:         store,
+		LeaderElector: config.LeaderElector,
 	})
 
 	c.AddEntityHandler(drivers.NewEntityHandler(store, backend))
@@ -0,0 +1,45 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package entities
+
+import (
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/event-manager/helpers"
+	"github.com/vmware/dispatch/pkg/events"
+)
+
+// NO TESTS
+
+// DeadLetter struct represents an event which a subscription failed to deliver
+type DeadLetter struct {
+	entitystore.BaseEntity
+	Subscription string            `json:"subscription"`
+	Function     string            `json:"function"`
+	Attempts     int64             `json:"attempts"`
+	Event        events.CloudEvent `json:"event"`
+}
+
+// ToModel converts dead letter to swagger model
+func (d *DeadLetter) ToModel() *v1.DeadLetter {
+	var reason string
+	if len(d.Reason) > 0 {
+		reason = d.Reason[0]
+	}
+	return &v1.DeadLetter{
+		ID:           strfmt.UUID(d.ID),
+		Name:         d.Name,
+		Kind:         v1.DeadLetterKind,
+		Subscription: d.Subscription,
+		Function:     d.Function,
+		Attempts:     d.Attempts,
+		Reason:       reason,
+		Event:        helpers.CloudEventToAPI(&d.Event),
+		CreatedTime:  d.CreatedTime.Unix(),
+	}
+}
@@ -0,0 +1,168 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package deadletters
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/event-manager/deadletters/entities"
+	"github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations"
+	deadlettersapi "github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations/deadletters"
+	"github.com/vmware/dispatch/pkg/events"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// Handlers is a base struct for dead letter API handlers.
+type Handlers struct {
+	store     entitystore.EntityStore
+	transport events.Transport
+}
+
+// NewHandlers Creates new instance of dead letter handlers
+func NewHandlers(store entitystore.EntityStore, transport events.Transport) *Handlers {
+	return &Handlers{
+		store:     store,
+		transport: transport,
+	}
+}
+
+// ConfigureHandlers configures API handlers for Dead Letter endpoints
+func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
+	a, ok := api.(*operations.EventManagerAPI)
+	if !ok {
+		panic("Cannot configure api")
+	}
+
+	a.DeadlettersGetDeadLetterHandler = deadlettersapi.GetDeadLetterHandlerFunc(h.getDeadLetter)
+	a.DeadlettersGetDeadLettersHandler = deadlettersapi.GetDeadLettersHandlerFunc(h.getDeadLetters)
+	a.DeadlettersReplayDeadLetterHandler = deadlettersapi.ReplayDeadLetterHandlerFunc(h.replayDeadLetter)
+}
+
+// getDeadLetter handles retrieval of single Dead Letter
+func (h *Handlers) getDeadLetter(params deadlettersapi.GetDeadLetterParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "getDeadLetter")
+	defer span.Finish()
+
+	d := entities.DeadLetter{}
+	var err error
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterEverything(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("%+v", err)
+		return deadlettersapi.NewGetDeadLetterBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	if err = h.store.Get(ctx, params.XDispatchOrg, params.DeadLetterName, opts, &d); err != nil {
+		log.Debugf("store error when getting dead letter: %+v", err)
+		return deadlettersapi.NewGetDeadLetterNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("dead letter", params.DeadLetterName),
+			})
+	}
+	return deadlettersapi.NewGetDeadLetterOK().WithPayload(d.ToModel())
+}
+
+// getDeadLetters handles retrieval of Dead Letter list
+func (h *Handlers) getDeadLetters(params deadlettersapi.GetDeadLettersParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "getDeadLetters")
+	defer span.Finish()
+
+	var deadLetters []*entities.DeadLetter
+	var err error
+	opts := entitystore.Options{
+		Filter: entitystore.FilterEverything(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("%+v", err)
+		return deadlettersapi.NewGetDeadLettersBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+
+	if err = h.store.List(ctx, params.XDispatchOrg, opts, &deadLetters); err != nil {
+		log.Errorf("store error when listing dead letters: %+v", err)
+		return deadlettersapi.NewGetDeadLettersDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: swag.String("internal server error when getting dead letters"),
+			})
+	}
+	deadLetterModels := []*v1.DeadLetter{}
+	for _, d := range deadLetters {
+		deadLetterModels = append(deadLetterModels, d.ToModel())
+	}
+	return deadlettersapi.NewGetDeadLettersOK().WithPayload(deadLetterModels)
+}
+
+// replayDeadLetter publishes the event of a Dead Letter again for the subscription which failed to deliver it only,
+// and removes the Dead Letter
+func (h *Handlers) replayDeadLetter(params deadlettersapi.ReplayDeadLetterParams, principal interface{}) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "replayDeadLetter")
+	defer span.Finish()
+
+	d := &entities.DeadLetter{}
+	var err error
+
+	opts := entitystore.Options{
+		Filter: entitystore.FilterEverything(),
+	}
+	opts.Filter, err = utils.ParseTags(opts.Filter, params.Tags)
+	if err != nil {
+		log.Errorf("%+v", err)
+		return deadlettersapi.NewReplayDeadLetterBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+	if err = h.store.Get(ctx, params.XDispatchOrg, params.DeadLetterName, opts, d); err != nil {
+		log.Debugf("store error when getting dead letter: %+v", err)
+		return deadlettersapi.NewReplayDeadLetterNotFound().WithPayload(
+			&v1.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("dead letter", params.DeadLetterName),
+			})
+	}
+
+	if d.Event.Extensions == nil {
+		d.Event.Extensions = events.CloudEventExtensions{}
+	}
+	d.Event.Extensions[events.ReplaySubscriptionExtension] = d.Subscription
+	if err = h.transport.Publish(ctx, &d.Event, d.Event.DefaultTopic(), d.OrganizationID); err != nil {
+		log.Errorf("error when replaying dead letter %s: %+v", d.Name, err)
+		return deadlettersapi.NewReplayDeadLetterDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: utils.ErrorMsgInternalError("dead letter", d.Name),
+			})
+	}
+	if err = h.store.Delete(ctx, d.OrganizationID, d.Name, d); err != nil {
+		log.Errorf("store error when deleting replayed dead letter %s: %+v", d.Name, err)
+		return deadlettersapi.NewReplayDeadLetterDefault(http.StatusInternalServerError).WithPayload(
+			&v1.Error{
+				Code:    http.StatusInternalServerError,
+				Message: utils.ErrorMsgInternalError("dead letter", d.Name),
+			})
+	}
+	return deadlettersapi.NewReplayDeadLetterOK().WithPayload(d.ToModel())
+}
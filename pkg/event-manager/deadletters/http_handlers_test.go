@@ -0,0 +1,112 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package deadletters
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/event-manager/deadletters/entities"
+	"github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations/deadletters"
+	"github.com/vmware/dispatch/pkg/events"
+	eventsmocks "github.com/vmware/dispatch/pkg/events/mocks"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+const testOrgID = "testOrg"
+
+func addDeadLetterEntity(t *testing.T, es entitystore.EntityStore, name string) *entities.DeadLetter {
+	d := &entities.DeadLetter{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           name,
+			OrganizationID: testOrgID,
+			Status:         entitystore.StatusREADY,
+			Reason:         []string{"testerror"},
+		},
+		Subscription: "testSubscription",
+		Function:     "testFunction",
+		Attempts:     3,
+		Event:        events.NewCloudEventWithDefaults("test.event"),
+	}
+	_, err := es.Add(context.Background(), d)
+	require.NoError(t, err)
+	return d
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	api := operations.NewEventManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := NewHandlers(es, nil)
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	d := addDeadLetterEntity(t, es, "dl1")
+	addDeadLetterEntity(t, es, "dl2")
+
+	r := httptest.NewRequest("GET", "/v1/event/deadletters", nil)
+	responder := api.DeadlettersGetDeadLettersHandler.Handle(deadletters.GetDeadLettersParams{
+		HTTPRequest:  r,
+		XDispatchOrg: testOrgID,
+	}, "testCookie")
+	var list []v1.DeadLetter
+	helpers.HandlerRequest(t, responder, &list, 200)
+	assert.Len(t, list, 2)
+
+	r = httptest.NewRequest("GET", "/v1/event/deadletters/dl1", nil)
+	responder = api.DeadlettersGetDeadLetterHandler.Handle(deadletters.GetDeadLetterParams{
+		HTTPRequest:    r,
+		XDispatchOrg:   testOrgID,
+		DeadLetterName: "dl1",
+	}, "testCookie")
+	var respBody v1.DeadLetter
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	assert.Equal(t, "testSubscription", respBody.Subscription)
+	assert.Equal(t, "testerror", respBody.Reason)
+	assert.Equal(t, int64(3), respBody.Attempts)
+	assert.Equal(t, d.Event.EventID, respBody.Event.EventID)
+
+	responder = api.DeadlettersGetDeadLetterHandler.Handle(deadletters.GetDeadLetterParams{
+		HTTPRequest:    r,
+		XDispatchOrg:   testOrgID,
+		DeadLetterName: "missing",
+	}, "testCookie")
+	var errBody v1.Error
+	helpers.HandlerRequest(t, responder, &errBody, 404)
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	api := operations.NewEventManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	transport := &eventsmocks.Transport{}
+	h := NewHandlers(es, transport)
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	d := addDeadLetterEntity(t, es, "dl1")
+	transport.On("Publish", mock.Anything, mock.MatchedBy(func(e *events.CloudEvent) bool {
+		return e.EventID == d.Event.EventID && e.Extensions[events.ReplaySubscriptionExtension] == d.Subscription
+	}), "test.event", testOrgID).Return(nil).Once()
+
+	r := httptest.NewRequest("POST", "/v1/event/deadletters/dl1/replay", nil)
+	responder := api.DeadlettersReplayDeadLetterHandler.Handle(deadletters.ReplayDeadLetterParams{
+		HTTPRequest:    r,
+		XDispatchOrg:   testOrgID,
+		DeadLetterName: "dl1",
+	}, "testCookie")
+	var respBody v1.DeadLetter
+	helpers.HandlerRequest(t, responder, &respBody, 200)
+	transport.AssertExpectations(t)
+
+	var deadLetters []*entities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	assert.Empty(t, deadLetters)
+}
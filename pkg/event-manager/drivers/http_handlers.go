@@ -124,7 +124,12 @@ func (h *Handlers) getDriver(params driverapi.GetDriverParams, principal interfa
 	}
 	opts := entitystore.Options{Filter: filter}
 
-	err = h.store.Get(ctx, params.XDispatchOrg, params.DriverName, opts, d)
+	err = utils.WaitForStatus(ctx, params.WaitFor, params.WaitTimeout, func() (v1.Status, error) {
+		if err := h.store.Get(ctx, params.XDispatchOrg, params.DriverName, opts, d); err != nil {
+			return "", err
+		}
+		return v1.Status(d.Status), nil
+	})
 	if err != nil {
 		log.Warnf("Received GET for non-existent driver %s", params.DriverName)
 		log.Debugf("store error when getting driver: %+v", err)
@@ -18,6 +18,7 @@ import (
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/controller"
 	"github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/event-manager/deadletters"
 	"github.com/vmware/dispatch/pkg/event-manager/drivers"
 	"github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations"
 	eventsapi "github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations/events"
@@ -36,6 +37,7 @@ type Handlers struct {
 	SecretsClient client.SecretsClient
 
 	subscriptions *subscriptions.Handlers
+	deadLetters   *deadletters.Handlers
 	drivers       *drivers.Handlers
 }
 
@@ -62,6 +64,9 @@ func (h *Handlers) ConfigureHandlers(api middleware.RoutableAPI) {
 	h.subscriptions = subscriptions.NewHandlers(h.Store, h.Watcher)
 	h.subscriptions.ConfigureHandlers(api)
 
+	h.deadLetters = deadletters.NewHandlers(h.Store, h.Transport)
+	h.deadLetters.ConfigureHandlers(api)
+
 	h.drivers = drivers.NewHandlers(h.Store, h.Watcher, h.SecretsClient)
 	h.drivers.ConfigureHandlers(api)
 
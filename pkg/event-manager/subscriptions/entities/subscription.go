@@ -17,8 +17,11 @@ import (
 type Subscription struct {
 	entitystore.BaseEntity
 	EventType string   `json:"eventType"`
+	Filter    string   `json:"filter,omitempty"`
 	Function  string   `json:"function"`
 	Secrets   []string `json:"secrets,omitempty"`
+
+	RetryPolicy *v1.RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 // ToModel converts subscription to swagger model
@@ -31,9 +34,11 @@ func (s *Subscription) ToModel() *v1.Subscription {
 		Name:         swag.String(s.Name),
 		Kind:         v1.SubscriptionKind,
 		EventType:    swag.String(s.EventType),
+		Filter:       s.Filter,
 		Function:     &s.Function,
 		Status:       v1.Status(s.Status),
 		Secrets:      s.Secrets,
+		RetryPolicy:  s.RetryPolicy,
 		CreatedTime:  s.CreatedTime.Unix(),
 		ModifiedTime: s.ModifiedTime.Unix(),
 		Tags:         tags,
@@ -52,6 +57,8 @@ func (s *Subscription) FromModel(m *v1.Subscription, orgID string) {
 	s.BaseEntity.Status = entitystore.Status(m.Status)
 	s.BaseEntity.Tags = tags
 	s.EventType = *m.EventType
+	s.Filter = m.Filter
 	s.Function = *m.Function
 	s.Secrets = m.Secrets
+	s.RetryPolicy = m.RetryPolicy
 }
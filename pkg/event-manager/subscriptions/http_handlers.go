@@ -20,6 +20,7 @@ import (
 	"github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations"
 	subscriptionsapi "github.com/vmware/dispatch/pkg/event-manager/gen/restapi/operations/subscriptions"
 	"github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
+	"github.com/vmware/dispatch/pkg/events"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
 )
@@ -67,6 +68,12 @@ func (h *Handlers) addSubscription(params subscriptionsapi.AddSubscriptionParams
 			Message: swag.String(fmt.Sprintf("error validating the payload: %s", err)),
 		})
 	}
+	if err := validateFilter(params.Body); err != nil {
+		return subscriptionsapi.NewAddSubscriptionBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
 
 	s := &entities.Subscription{}
 	s.FromModel(params.Body, params.XDispatchOrg)
@@ -197,6 +204,14 @@ func (h *Handlers) updateSubscription(params subscriptionsapi.UpdateSubscription
 			})
 	}
 
+	if err := validateFilter(params.Body); err != nil {
+		return subscriptionsapi.NewUpdateSubscriptionBadRequest().WithPayload(
+			&v1.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+	}
+
 	s.FromModel(params.Body, s.OrganizationID)
 	s.Status = entitystore.StatusUPDATING
 	if _, err = h.store.Update(ctx, s.Revision, s); err != nil {
@@ -261,3 +276,12 @@ func (h *Handlers) deleteSubscription(params subscriptionsapi.DeleteSubscription
 	h.watcher.OnAction(ctx, s)
 	return subscriptionsapi.NewDeleteSubscriptionOK().WithPayload(s.ToModel())
 }
+
+// validateFilter checks that the filter expression of the subscription, if any, can be evaluated
+func validateFilter(m *v1.Subscription) error {
+	if m.Filter == "" {
+		return nil
+	}
+	_, err := events.NewFilter(m.Filter)
+	return err
+}
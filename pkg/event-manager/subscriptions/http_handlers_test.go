@@ -75,6 +75,30 @@ func TestSubscriptionsAddSubscriptionHandler(t *testing.T) {
 	assert.Equal(t, "testfunction", *respBody.Function)
 }
 
+func TestSubscriptionsAddSubscriptionHandlerInvalidFilter(t *testing.T) {
+	api := operations.NewEventManagerAPI(nil)
+	es := helpers.MakeEntityStore(t)
+	h := Handlers{es, nil}
+	helpers.MakeAPI(t, h.ConfigureHandlers, api)
+
+	reqBody := &v1.Subscription{
+		Name:      swag.String("mysubscription"),
+		EventType: swag.String("vcenter.vm.*"),
+		Function:  swag.String("testfunction"),
+		Filter:    "[sauce] == 'vcenter1'",
+	}
+	r := httptest.NewRequest("POST", "/v1/event/subscriptions", nil)
+	params := subscriptions.AddSubscriptionParams{
+		HTTPRequest:  r,
+		Body:         reqBody,
+		XDispatchOrg: testOrgID,
+	}
+	responder := api.SubscriptionsAddSubscriptionHandler.Handle(params, "testCookie")
+	var respBody v1.Error
+	helpers.HandlerRequest(t, responder, &respBody, 400)
+	assert.Contains(t, *respBody.Message, "unknown attribute sauce")
+}
+
 func TestSubscriptionsGetSubscriptionHandler(t *testing.T) {
 	api := operations.NewEventManagerAPI(nil)
 	es := helpers.MakeEntityStore(t)
@@ -7,19 +7,34 @@ package subscriptions
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/entity-store"
 
 	"github.com/vmware/dispatch/pkg/client"
+	dlentities "github.com/vmware/dispatch/pkg/event-manager/deadletters/entities"
 	"github.com/vmware/dispatch/pkg/event-manager/helpers"
 	"github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
 	"github.com/vmware/dispatch/pkg/events"
 	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+const (
+	// defaultBackoff is the time to wait before the first retry of retry policies without a backoff
+	defaultBackoff = time.Second
+	// runPollInterval is how often the status of a run is checked until it finishes
+	runPollInterval = time.Second
+	// maxDeadLetters is the number of dead letters kept per organization, the oldest are deleted first
+	maxDeadLetters = 1000
 )
 
 // Manager defines the subscription manager interface
@@ -33,16 +48,21 @@ type Manager interface {
 type defaultManager struct {
 	queue    events.Transport
 	fnClient client.FunctionsClient
+	store    entitystore.EntityStore
 
 	sync.RWMutex
 	activeSubs map[string]events.Subscription
+
+	// deliveries tracks the events being delivered
+	deliveries sync.WaitGroup
 }
 
 // NewManager creates a new subscription manager
-func NewManager(mq events.Transport, fnClient client.FunctionsClient) (Manager, error) {
+func NewManager(mq events.Transport, fnClient client.FunctionsClient, store entitystore.EntityStore) (Manager, error) {
 	ec := defaultManager{
 		queue:      mq,
 		fnClient:   fnClient,
+		store:      store,
 		activeSubs: make(map[string]events.Subscription),
 	}
 
@@ -120,9 +140,23 @@ func (m *defaultManager) Update(ctx context.Context, sub *entities.Subscription)
 }
 
 func (m *defaultManager) createSubscription(ctx context.Context, sub *entities.Subscription) (events.Subscription, error) {
+	var filter *events.Filter
+	var err error
+	if sub.Filter != "" {
+		if filter, err = events.NewFilter(sub.Filter); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+	}
 	topic := sub.EventType
-	// subscribe
-	eventSub, err := m.queue.Subscribe(ctx, topic, sub.OrganizationID, m.handler(ctx, sub))
+	handler := m.handler(ctx, sub, filter)
+	// subscribe, durable transports resume after the last event the subscription handled
+	var eventSub events.Subscription
+	if durable, ok := m.queue.(events.DurableTransport); ok {
+		eventSub, err = durable.SubscribeDurable(ctx, topic, sub.OrganizationID, sub.Name, time.Time{}, handler)
+	} else {
+		eventSub, err = m.queue.Subscribe(ctx, topic, sub.OrganizationID, handler)
+	}
 	if err != nil {
 		err = errors.Wrapf(err, "unable to create a subscription for event %s and function %s", sub.EventType, sub.Function)
 
@@ -147,6 +181,12 @@ func (m *defaultManager) Delete(ctx context.Context, sub *entities.Subscription)
 		eventSub.Unsubscribe()
 		delete(m.activeSubs, sub.ID)
 	}
+	if durable, ok := m.queue.(events.DurableTransport); ok {
+		if err := durable.RemoveConsumer(ctx, sub.OrganizationID, sub.Name); err != nil {
+			span.LogKV("error", err)
+			log.Errorf("error when removing the consumer of subscription %s: %+v", sub.Name, err)
+		}
+	}
 	log.Debugf("Deleting subscription topic=%s id=%s revision=%d", sub.EventType, sub.Name, sub.Revision)
 	return nil
 }
@@ -162,7 +202,9 @@ func (m *defaultManager) Shutdown() {
 }
 
 // handler creates a function to handle the incoming event. it takes name of the function to be invoked as an argument.
-func (m *defaultManager) handler(ctx context.Context, sub *entities.Subscription) func(context.Context, *events.CloudEvent) {
+// Events not matching the filter of the subscription, if any, and replays of dead letters of other subscriptions are
+// dropped. Events are delivered in the background, so that runs and their retries don't hold up the transport.
+func (m *defaultManager) handler(ctx context.Context, sub *entities.Subscription, filter *events.Filter) func(context.Context, *events.CloudEvent) {
 	span, _ := trace.Trace(ctx, "")
 	defer span.Finish()
 
@@ -175,20 +217,145 @@ func (m *defaultManager) handler(ctx context.Context, sub *entities.Subscription
 		span.SetTag("eventType", sub.EventType)
 		span.SetTag("functionName", sub.Function)
 
-		m.runFunction(ctx, sub.OrganizationID, sub.Function, event, sub.Secrets)
+		if filter != nil {
+			match, err := filter.Match(event)
+			if err != nil {
+				span.LogKV("error", err)
+				log.Warnf("dropping event %s of subscription %s: %v", event.EventID, sub.Name, err)
+				return
+			}
+			if !match {
+				log.Debugf("event %s does not match the filter of subscription %s", event.EventID, sub.Name)
+				return
+			}
+		}
+
+		if target, ok := event.Extensions[events.ReplaySubscriptionExtension]; ok && target != sub.Name {
+			log.Debugf("event %s is replayed for subscription %v, not %s", event.EventID, target, sub.Name)
+			return
+		}
+
+		m.deliveries.Add(1)
+		go func() {
+			defer m.deliveries.Done()
+			m.deliver(context.Background(), sub, event)
+		}()
+	}
+}
+
+// deliver runs the function of the subscription following its retry policy, events which are never delivered
+// become dead letters.
+func (m *defaultManager) deliver(ctx context.Context, sub *entities.Subscription, event *events.CloudEvent) {
+	policy := sub.RetryPolicy
+	if policy == nil {
+		policy = &v1.RetryPolicy{}
+	}
+	maxAttempts := int(policy.MaxAttempts)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var timeout time.Duration
+	if policy.MaxAge > 0 {
+		emitted := event.EventTime
+		if emitted.IsZero() {
+			emitted = time.Now()
+		}
+		timeout = time.Until(emitted.Add(time.Duration(policy.MaxAge) * time.Millisecond))
+		if timeout <= 0 {
+			m.deadLetter(ctx, sub, event, 0, errors.New("event is older than the max age of the retry policy"))
+			return
+		}
+	}
+
+	backoff := time.Duration(policy.Backoff) * time.Millisecond
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	attempts, err := utils.BackoffAttempts(maxAttempts, backoff, timeout, func() error {
+		return m.runFunction(ctx, sub.OrganizationID, sub.Function, event, sub.Secrets)
+	})
+	if err != nil {
+		m.deadLetter(ctx, sub, event, attempts, err)
+	}
+}
+
+// deadLetter stores an event the subscription failed to deliver and publishes it to the dead letter topic of the
+// organization
+func (m *defaultManager) deadLetter(ctx context.Context, sub *entities.Subscription, event *events.CloudEvent, attempts int, cause error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	span.SetTag("eventType", event.EventType)
+	span.SetTag("functionName", sub.Function)
+
+	log.Errorf("giving up on event %s of subscription %s after %d attempts: %v", event.EventID, sub.Name, attempts, cause)
+
+	d := &dlentities.DeadLetter{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           uuid.NewV4().String(),
+			OrganizationID: sub.OrganizationID,
+			Status:         entitystore.StatusREADY,
+			Reason:         []string{cause.Error()},
+		},
+		Subscription: sub.Name,
+		Function:     sub.Function,
+		Attempts:     int64(attempts),
+		Event:        *event,
+	}
+	if _, err := m.store.Add(ctx, d); err != nil {
+		span.LogKV("error", err)
+		log.Errorf("error when storing dead letter of event %s: %+v", event.EventID, err)
+	}
+	m.pruneDeadLetters(ctx, sub.OrganizationID, maxDeadLetters)
+
+	// Dead letters of dead letters are only stored, publishing them could loop forever
+	if event.EventType == events.DeadLetterEventType {
+		return
+	}
+	data, err := json.Marshal(d.ToModel())
+	if err != nil {
+		log.Errorf("error when encoding dead letter of event %s: %+v", event.EventID, err)
+		return
+	}
+	deadLetterEvent := events.NewCloudEventWithDefaults(events.DeadLetterEventType)
+	deadLetterEvent.ContentType = "application/json"
+	deadLetterEvent.Data = data
+	if err := m.queue.Publish(ctx, &deadLetterEvent, deadLetterEvent.DefaultTopic(), sub.OrganizationID); err != nil {
+		span.LogKV("error", err)
+		log.Errorf("error when publishing dead letter of event %s: %+v", event.EventID, err)
+	}
+}
+
+// pruneDeadLetters deletes the oldest dead letters of an organization, keeping the latest ones
+func (m *defaultManager) pruneDeadLetters(ctx context.Context, organizationID string, keep int) {
+	var deadLetters []*dlentities.DeadLetter
+	if err := m.store.List(ctx, organizationID, entitystore.Options{}, &deadLetters); err != nil {
+		log.Errorf("error when listing dead letters to prune: %+v", err)
+		return
+	}
+	if len(deadLetters) <= keep {
+		return
+	}
+	sort.Slice(deadLetters, func(i, j int) bool { return deadLetters[i].CreatedTime.Before(deadLetters[j].CreatedTime) })
+	for _, d := range deadLetters[:len(deadLetters)-keep] {
+		if err := m.store.Delete(ctx, organizationID, d.Name, d); err != nil {
+			log.Errorf("error when deleting dead letter %s: %+v", d.Name, err)
+		}
 	}
 }
 
 // executes a function by connecting to function manager
-func (m *defaultManager) runFunction(ctx context.Context, organizationID string, fnName string, event *events.CloudEvent, secrets []string) {
+func (m *defaultManager) runFunction(ctx context.Context, organizationID string, fnName string, event *events.CloudEvent, secrets []string) error {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
 
 	span.SetTag("eventType", event.EventType)
 	span.SetTag("functionName", fnName)
 
+	// The run is asynchronous, its outcome is polled so that failures of the function itself are retried and dead
+	// lettered
 	run := v1.Run{
-		Blocking:     false,
 		FunctionName: fnName,
 		Input:        event.Data,
 	}
@@ -201,11 +368,41 @@ func (m *defaultManager) runFunction(ctx context.Context, organizationID string,
 		errorMsg := fmt.Sprintf("Unable to run function %s, error from function manager: %+v", fnName, err)
 		span.LogKV("error", errorMsg)
 		log.Error(errorMsg)
-		return
+		return errors.Wrapf(err, "error from function manager when running function %s", fnName)
+	}
+	if result, err = m.waitRun(ctx, organizationID, fnName, result); err != nil {
+		span.LogKV("error", err)
+		return err
+	}
+	if result.Status == v1.StatusERROR {
+		reason := "unknown error"
+		if result.Error != nil && result.Error.Message != nil {
+			reason = *result.Error.Message
+		}
+		span.LogKV("error", reason)
+		return errors.Errorf("run of function %s failed: %s", fnName, reason)
 	}
 	span.LogKV("functionName", result.FunctionName,
 		"functionResult", result.Output)
 	log.Debugf("Function %s returned %+v", result.FunctionName, result.Output)
 
-	return
+	return nil
+}
+
+// waitRun polls a run until it is finished
+func (m *defaultManager) waitRun(ctx context.Context, organizationID string, fnName string, run *v1.Run) (*v1.Run, error) {
+	for run.Status != v1.StatusREADY && run.Status != v1.StatusERROR {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "waiting for run %s of function %s", run.Name, fnName)
+		case <-time.After(runPollInterval):
+		}
+		runName := run.Name.String()
+		var err error
+		run, err = m.fnClient.GetFunctionRun(ctx, organizationID, client.FunctionOpts{FunctionName: &fnName, RunName: &runName})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error from function manager when getting run %s of function %s", runName, fnName)
+		}
+	}
+	return run, nil
 }
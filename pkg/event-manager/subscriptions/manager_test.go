@@ -8,21 +8,34 @@ package subscriptions
 import (
 	"context"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/client"
 	clientmocks "github.com/vmware/dispatch/pkg/client/mocks"
+	"github.com/vmware/dispatch/pkg/entity-store"
+	dlentities "github.com/vmware/dispatch/pkg/event-manager/deadletters/entities"
+	"github.com/vmware/dispatch/pkg/event-manager/subscriptions/entities"
 	"github.com/vmware/dispatch/pkg/events"
 	eventsmocks "github.com/vmware/dispatch/pkg/events/mocks"
+	"github.com/vmware/dispatch/pkg/events/transport"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
 )
 
-func mockSubscriptionManager(queue events.Transport, fnClient client.FunctionsClient) *defaultManager {
+func mockSubscriptionManager(queue events.Transport, fnClient client.FunctionsClient, store entitystore.EntityStore) *defaultManager {
 	return &defaultManager{
 		queue:      queue,
 		fnClient:   fnClient,
+		store:      store,
 		activeSubs: make(map[string]events.Subscription),
 	}
 }
@@ -30,12 +43,235 @@ func mockSubscriptionManager(queue events.Transport, fnClient client.FunctionsCl
 func TestRunFunction(t *testing.T) {
 	fnClient := &clientmocks.FunctionsClient{}
 	queue := &eventsmocks.Transport{}
-	manager := mockSubscriptionManager(queue, fnClient)
+	manager := mockSubscriptionManager(queue, fnClient, nil)
 	ev := &events.CloudEvent{}
-	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{}, nil).Once()
-	manager.runFunction(context.Background(), testOrgID, "testFunction", ev, []string{"secret1", "secret2"})
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{Status: v1.StatusREADY}, nil).Once()
+	assert.NoError(t, manager.runFunction(context.Background(), testOrgID, "testFunction", ev, []string{"secret1", "secret2"}))
 
 	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{}, errors.New("testerror")).Once()
-	manager.runFunction(context.Background(), testOrgID, "testFunction", ev, nil)
+	assert.Error(t, manager.runFunction(context.Background(), testOrgID, "testFunction", ev, nil))
 	fnClient.AssertNumberOfCalls(t, "RunFunction", 2)
 }
+
+func TestRunFunctionFailedRun(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	manager := mockSubscriptionManager(queue, fnClient, nil)
+	ev := &events.CloudEvent{}
+	async := mock.MatchedBy(func(run *v1.Run) bool { return !run.Blocking })
+
+	// Runs are asynchronous, their outcome is polled
+	accepted := &v1.Run{Name: "run1", Status: v1.StatusINITIALIZED}
+	failed := &v1.Run{Name: "run1", Status: v1.StatusERROR, Error: &v1.InvocationError{Message: swag.String("handler raised")}}
+	fnClient.On("RunFunction", mock.Anything, testOrgID, async).Return(accepted, nil).Once()
+	fnClient.On("GetFunctionRun", mock.Anything, testOrgID, mock.MatchedBy(func(opts client.FunctionOpts) bool {
+		return *opts.FunctionName == "testFunction" && *opts.RunName == "run1"
+	})).Return(failed, nil).Once()
+	err := manager.runFunction(context.Background(), testOrgID, "testFunction", ev, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handler raised")
+
+	fnClient.On("RunFunction", mock.Anything, testOrgID, async).Return(failed, nil).Once()
+	err = manager.runFunction(context.Background(), testOrgID, "testFunction", ev, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handler raised")
+	fnClient.AssertExpectations(t)
+}
+
+func TestDeliverFailedRunDeadLetter(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(queue, fnClient, es)
+
+	failed := &v1.Run{Status: v1.StatusERROR, Error: &v1.InvocationError{Message: swag.String("handler raised")}}
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(failed, nil)
+	queue.On("Publish", mock.Anything, mock.AnythingOfType("*events.CloudEvent"), events.DeadLetterEventType, testOrgID).Return(nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	manager.deliver(context.Background(), testSubscription(&v1.RetryPolicy{MaxAttempts: 2, Backoff: 1}), &ev)
+
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 2)
+	queue.AssertExpectations(t)
+	var deadLetters []*dlentities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	require.Len(t, deadLetters, 1)
+	assert.Contains(t, deadLetters[0].Reason[0], "handler raised")
+}
+
+func testSubscription(policy *v1.RetryPolicy) *entities.Subscription {
+	return &entities.Subscription{
+		BaseEntity: entitystore.BaseEntity{
+			Name:           "testSubscription",
+			OrganizationID: testOrgID,
+		},
+		EventType:   "test.event",
+		Function:    "testFunction",
+		RetryPolicy: policy,
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(queue, fnClient, es)
+
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(nil, errors.New("testerror")).Once()
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{Status: v1.StatusREADY}, nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	manager.deliver(context.Background(), testSubscription(&v1.RetryPolicy{MaxAttempts: 3, Backoff: 1}), &ev)
+
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 2)
+	var deadLetters []*dlentities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	assert.Empty(t, deadLetters)
+}
+
+func TestDeliverDeadLetter(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(queue, fnClient, es)
+
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(nil, errors.New("testerror"))
+	queue.On("Publish", mock.Anything, mock.AnythingOfType("*events.CloudEvent"), events.DeadLetterEventType, testOrgID).Return(nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	manager.deliver(context.Background(), testSubscription(&v1.RetryPolicy{MaxAttempts: 2, Backoff: 1}), &ev)
+
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 2)
+	queue.AssertExpectations(t)
+	var deadLetters []*dlentities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "testSubscription", deadLetters[0].Subscription)
+	assert.Equal(t, int64(2), deadLetters[0].Attempts)
+	assert.Equal(t, ev.EventID, deadLetters[0].Event.EventID)
+}
+
+func TestDeliverMaxAge(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(queue, fnClient, es)
+
+	queue.On("Publish", mock.Anything, mock.AnythingOfType("*events.CloudEvent"), events.DeadLetterEventType, testOrgID).Return(nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	ev.EventTime = time.Now().Add(-time.Hour)
+	manager.deliver(context.Background(), testSubscription(&v1.RetryPolicy{MaxAge: 1000}), &ev)
+
+	fnClient.AssertNotCalled(t, "RunFunction", mock.Anything, mock.Anything, mock.Anything)
+	queue.AssertExpectations(t)
+}
+
+func TestDeliverDeadLetterOfDeadLetter(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(queue, fnClient, es)
+
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(nil, errors.New("testerror"))
+	ev := events.NewCloudEventWithDefaults(events.DeadLetterEventType)
+	manager.deliver(context.Background(), testSubscription(nil), &ev)
+
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 1)
+	queue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	var deadLetters []*dlentities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	assert.Len(t, deadLetters, 1)
+}
+
+func TestHandlerFilter(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	manager := mockSubscriptionManager(queue, fnClient, nil)
+
+	filter, err := events.NewFilter("source == 'vcenter1' && [data.vm.cpus] > 2")
+	require.NoError(t, err)
+	handler := manager.handler(context.Background(), testSubscription(nil), filter)
+
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{Status: v1.StatusREADY}, nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	ev.Source = "vcenter1"
+	ev.Data = []byte(`{"vm": {"cpus": 4}}`)
+	handler(context.Background(), &ev)
+
+	ev.Data = []byte(`{"vm": {"cpus": 1}}`)
+	handler(context.Background(), &ev)
+
+	ev.Data = []byte(`not json`)
+	handler(context.Background(), &ev)
+
+	manager.deliveries.Wait()
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 1)
+}
+
+func TestHandlerReplay(t *testing.T) {
+	fnClient := &clientmocks.FunctionsClient{}
+	queue := &eventsmocks.Transport{}
+	manager := mockSubscriptionManager(queue, fnClient, nil)
+	handler := manager.handler(context.Background(), testSubscription(nil), nil)
+
+	// Replayed dead letters are only delivered to the subscription which failed to deliver them
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{Status: v1.StatusREADY}, nil).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	ev.Extensions = events.CloudEventExtensions{events.ReplaySubscriptionExtension: "otherSubscription"}
+	handler(context.Background(), &ev)
+	ev.Extensions[events.ReplaySubscriptionExtension] = "testSubscription"
+	handler(context.Background(), &ev)
+
+	manager.deliveries.Wait()
+	fnClient.AssertNumberOfCalls(t, "RunFunction", 1)
+}
+
+func TestPruneDeadLetters(t *testing.T) {
+	es := helpers.MakeEntityStore(t)
+	manager := mockSubscriptionManager(nil, nil, es)
+
+	for _, name := range []string{"dl1", "dl2", "dl3"} {
+		_, err := es.Add(context.Background(), &dlentities.DeadLetter{
+			BaseEntity: entitystore.BaseEntity{Name: name, OrganizationID: testOrgID},
+		})
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	manager.pruneDeadLetters(context.Background(), testOrgID, 2)
+
+	var deadLetters []*dlentities.DeadLetter
+	require.NoError(t, es.List(context.Background(), testOrgID, entitystore.Options{}, &deadLetters))
+	var names []string
+	for _, d := range deadLetters {
+		names = append(names, d.Name)
+	}
+	assert.Len(t, names, 2)
+	assert.NotContains(t, names, "dl1")
+}
+
+func TestDurableSubscription(t *testing.T) {
+	dir, err := ioutil.TempDir("", "subscriptions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	queue, err := transport.NewBolt(filepath.Join(dir, "events.db"))
+	require.NoError(t, err)
+	defer queue.Close()
+
+	fnClient := &clientmocks.FunctionsClient{}
+	manager := mockSubscriptionManager(queue, fnClient, nil)
+	sub := testSubscription(nil)
+	require.NoError(t, manager.Create(context.Background(), sub))
+	manager.Shutdown()
+
+	// Events published while the subscription is inactive are delivered once it is created again
+	ran := make(chan struct{})
+	fnClient.On("RunFunction", mock.Anything, testOrgID, mock.AnythingOfType("*v1.Run")).Return(&v1.Run{Status: v1.StatusREADY}, nil).Run(func(mock.Arguments) {
+		close(ran)
+	}).Once()
+	ev := events.NewCloudEventWithDefaults("test.event")
+	require.NoError(t, queue.Publish(context.Background(), &ev, ev.DefaultTopic(), testOrgID))
+	require.NoError(t, manager.Create(context.Background(), sub))
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("function was not run")
+	}
+	require.NoError(t, manager.Delete(context.Background(), sub))
+}
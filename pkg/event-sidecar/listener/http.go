@@ -83,31 +83,11 @@ func (l *HTTPListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer serverSpan.Finish()
 	spCtx := opentracing.ContextWithSpan(r.Context(), serverSpan)
 
-	evs, err := l.parser.Parse(r.Body)
+	code, err := l.publish(spCtx, r.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error parsing input: %s", err), http.StatusBadRequest)
+		http.Error(w, err.Error(), code)
 		return
 	}
 
-	if len(evs) == 0 {
-		http.Error(w, "No events parsed", http.StatusBadRequest)
-		return
-	}
-
-	for _, ev := range evs {
-		log.Debugf("Validating event %+v", ev)
-		err = l.validator.Validate(&ev)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Error validating event with ID %s: %s", ev.EventID, err), http.StatusBadRequest)
-			return
-		}
-		log.Debugf("Pushing event %+v using topic %s and organization %s", ev, ev.DefaultTopic(), l.organization)
-		err = l.transport.Publish(spCtx, &ev, ev.DefaultTopic(), l.organization)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Error publishing event with ID %s: %s", ev.EventID, err), http.StatusInternalServerError)
-			return
-		}
-	}
-
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(code)
 }
@@ -6,63 +6,136 @@
 package listener
 
 import (
-	"bufio"
-	"errors"
-	"log"
+	"bytes"
+	"context"
+	"encoding/json"
 	"os"
+	"sync"
 	"syscall"
 
-	"github.com/vmware/dispatch/pkg/events"
-)
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
-// Event sidecar pipe files
-const (
-	EventPipe    = "/pipes/events"
-	ResponsePipe = "/pipes/response"
+	"github.com/vmware/dispatch/pkg/events"
 )
 
-// PipeListener type for event pipe channels
+// PipeListener implements EventListener using a pair of named pipes, see events.WriteFrame for the protocol
 type PipeListener struct {
+	SharedListener
+	eventPipe    string
+	responsePipe string
+
+	mu        sync.Mutex
+	events    *os.File
+	responses *events.FrameWriter
+	closed    bool
 }
 
-// NewPipe creates a new PipeListener - TODO: finish me
-func NewPipe() (*PipeListener, error) {
-	log.Print("Creating new pipe listener")
-	err := syscall.Mkfifo(EventPipe, 0666)
-	if err != nil {
-		log.Printf("error creating input pipe: %v", err)
-		err = os.Remove(EventPipe)
-		if err != nil {
-			log.Printf("error removing input pipe: %v", err)
-			return nil, nil
+// NewPipe creates new Pipe Listener, creating the named pipes if needed
+func NewPipe(shared SharedListener, eventPipe, responsePipe string) (*PipeListener, error) {
+	for _, path := range []string{eventPipe, responsePipe} {
+		if err := mkfifo(path); err != nil {
+			return nil, err
 		}
-		err = syscall.Mkfifo(EventPipe, 0666)
-		if err != nil {
-			return nil, nil
+	}
+	return &PipeListener{
+		SharedListener: shared,
+		eventPipe:      eventPipe,
+		responsePipe:   responsePipe,
+	}, nil
+}
+
+// mkfifo creates a named pipe at path, replacing any other kind of file
+func mkfifo(path string) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeNamedPipe != 0 {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return errors.Wrapf(err, "error removing %s", path)
 		}
-	} else {
-		log.Printf("Created %v\n", EventPipe)
 	}
+	if err := syscall.Mkfifo(path, 0666); err != nil {
+		return errors.Wrapf(err, "error creating named pipe %s", path)
+	}
+	log.Printf("Created %s", path)
+	return nil
+}
 
-	infile, err := os.OpenFile(EventPipe, os.O_RDWR, os.ModeNamedPipe)
+// Serve starts serving loop. Blocks until Shutdown() is called.
+func (l *PipeListener) Serve() error {
+	// Both pipes are opened for reading and writing, so that opening them doesn't wait for the driver and drivers
+	// can reopen them at will.
+	var err error
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.events, err = os.OpenFile(l.eventPipe, os.O_RDWR, os.ModeNamedPipe); err != nil {
+		l.mu.Unlock()
+		return errors.Wrapf(err, "error opening %s", l.eventPipe)
+	}
+	responses, err := os.OpenFile(l.responsePipe, os.O_RDWR, os.ModeNamedPipe)
 	if err != nil {
-		panic(err)
+		l.events.Close()
+		l.mu.Unlock()
+		return errors.Wrapf(err, "error opening %s", l.responsePipe)
 	}
-	reader := bufio.NewReader(infile)
+	l.responses = events.NewFrameWriter(responses)
+	l.mu.Unlock()
 
-	go func() {
-		for {
-			_, err := readMessage(reader)
-			if err != nil {
-				log.Printf("error reading message: %v", err)
-				continue
+	log.Printf("Listening on %s\n", l.eventPipe)
+	for {
+		payload, err := events.ReadFrame(l.events)
+		if err != nil {
+			l.mu.Lock()
+			closed := l.closed
+			l.mu.Unlock()
+			if closed {
+				return nil
 			}
+			return errors.Wrapf(err, "error reading from %s", l.eventPipe)
 		}
-	}()
+		if err := l.respond(l.handle(payload)); err != nil {
+			return errors.Wrapf(err, "error writing to %s", l.responsePipe)
+		}
+	}
+}
+
+// Shutdown closes the pipes, the pipe files are left in place for the driver.
+func (l *PipeListener) Shutdown() error {
+	log.Printf("Shutting down...")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.events != nil {
+		l.events.Close()
+	}
+	if l.responses != nil {
+		l.responses.Close()
+	}
+	return nil
+}
 
-	return nil, nil
+func (l *PipeListener) handle(payload []byte) *events.PipeResponse {
+	span := opentracing.StartSpan("EventSidecar.ServePipe")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	code, err := l.publish(ctx, bytes.NewReader(payload))
+	response := &events.PipeResponse{Code: code}
+	if err != nil {
+		response.Message = err.Error()
+	}
+	return response
 }
 
-func readMessage(reader *bufio.Reader) (*events.CloudEvent, error) {
-	return nil, errors.New("not implemented")
+func (l *PipeListener) respond(response *events.PipeResponse) error {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return l.responses.WriteFrame(payload)
 }
@@ -0,0 +1,63 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package listener
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/events"
+	"github.com/vmware/dispatch/pkg/events/driverclient"
+	"github.com/vmware/dispatch/pkg/events/mocks"
+	"github.com/vmware/dispatch/pkg/events/parser"
+	"github.com/vmware/dispatch/pkg/events/validator"
+)
+
+func TestPipeListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	eventPipe := filepath.Join(dir, "events")
+	responsePipe := filepath.Join(dir, "response")
+
+	m := mockSharedListener()
+	m.parser = &parser.JSONEventParser{}
+	m.validator = validator.NewDefaultValidator()
+	transport := m.transport.(*mocks.Transport)
+	transport.On("Publish", mock.Anything, mock.Anything, testEvent1.EventType, mock.Anything).Return(nil).Twice()
+	transport.On("Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error publishing")).Once()
+
+	listener, err := NewPipe(m, eventPipe, responsePipe)
+	require.NoError(t, err)
+	served := make(chan error)
+	go func() {
+		served <- listener.Serve()
+	}()
+
+	client, err := driverclient.NewPipeClient(driverclient.WithPipes(eventPipe, responsePipe))
+	require.NoError(t, err)
+	defer client.(*driverclient.PipeClient).Close()
+
+	assert.NoError(t, client.SendOne(&testEvent1))
+	assert.NoError(t, client.Send([]events.CloudEvent{testEvent1}))
+
+	other := testEvent1
+	other.EventType = "other.event"
+	err = client.SendOne(&other)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error publishing")
+	transport.AssertExpectations(t)
+
+	require.NoError(t, listener.Shutdown())
+	assert.NoError(t, <-served)
+}
@@ -5,7 +5,16 @@
 
 package listener
 
-import "github.com/vmware/dispatch/pkg/events"
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/events"
+)
 
 // SharedListener serves as a simple DI container for Listener.
 type SharedListener struct {
@@ -35,3 +44,31 @@ func NewSharedListener(transport events.Transport, parser events.StreamParser, v
 		driverType:   driverType,
 	}
 }
+
+// publish parses the events read from input, validates and publishes them. The returned code follows HTTP status
+// codes, and comes with an error unless all events were published.
+func (l *SharedListener) publish(ctx context.Context, input io.Reader) (int, error) {
+	evs, err := l.parser.Parse(input)
+	if err != nil {
+		return http.StatusBadRequest, errors.Errorf("Error parsing input: %s", err)
+	}
+
+	if len(evs) == 0 {
+		return http.StatusBadRequest, errors.New("No events parsed")
+	}
+
+	for _, ev := range evs {
+		log.Debugf("Validating event %+v", ev)
+		err = l.validator.Validate(&ev)
+		if err != nil {
+			return http.StatusBadRequest, errors.Errorf("Error validating event with ID %s: %s", ev.EventID, err)
+		}
+		log.Debugf("Pushing event %+v using topic %s and organization %s", ev, ev.DefaultTopic(), l.organization)
+		err = l.transport.Publish(ctx, &ev, ev.DefaultTopic(), l.organization)
+		if err != nil {
+			return http.StatusInternalServerError, errors.Errorf("Error publishing event with ID %s: %s", ev.EventID, err)
+		}
+	}
+
+	return http.StatusCreated, nil
+}
@@ -18,6 +18,11 @@ import (
 const (
 	// CloudEventsVersion defines version of CloudEvent specification used in Dispatch
 	CloudEventsVersion = "0.1"
+
+	// DeadLetterEventType is the type of the events published when a subscription gives up on delivering an event
+	DeadLetterEventType = "dispatch.deadletter"
+	// ReplaySubscriptionExtension names the only subscription a replayed dead letter event is delivered to
+	ReplaySubscriptionExtension = "dispatch-replay-subscription"
 )
 
 // NewCloudEventWithDefaults creates new copy of CloudEvent struct, using reasonable defaults for all
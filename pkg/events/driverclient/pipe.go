@@ -5,28 +5,155 @@
 
 package driverclient
 
-import "github.com/vmware/dispatch/pkg/events"
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/events"
+	"github.com/vmware/dispatch/pkg/events/validator"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// PipeClientOpt allows customization of PipeClient
+type PipeClientOpt func(client *PipeClient) error
+
+// WithPipes sets the paths of the event and response pipes
+func WithPipes(eventPipe, responsePipe string) PipeClientOpt {
+	return func(client *PipeClient) error {
+		client.eventPipe = eventPipe
+		client.responsePipe = responsePipe
+		return nil
+	}
+}
 
 // PipeClient implements Event Driver client using named pipes.
 type PipeClient struct {
+	eventPipe    string
+	responsePipe string
+	validator    events.Validator
+
+	// mu pairs every frame written with the response read for it
+	mu        sync.Mutex
+	events    *events.FrameWriter
+	responses *os.File
 }
 
-// Send sends slice of events to event driver sidecar
-func (PipeClient) Send(event []events.CloudEvent) error {
-	panic("implement me")
+// NewPipeClient returns new instance of driverclient.Client using PipeClient implementation. It waits for the
+// sidecar to create the pipes.
+func NewPipeClient(opts ...PipeClientOpt) (Client, error) {
+	c := &PipeClient{
+		eventPipe:    events.EventPipe,
+		responsePipe: events.ResponsePipe,
+		validator:    validator.NewDefaultValidator(),
+	}
+
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.waitForPipes(); err != nil {
+		return nil, err
+	}
+	eventPipe, err := os.OpenFile(c.eventPipe, os.O_WRONLY, os.ModeNamedPipe)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening %s", c.eventPipe)
+	}
+	if c.responses, err = os.OpenFile(c.responsePipe, os.O_RDONLY, os.ModeNamedPipe); err != nil {
+		eventPipe.Close()
+		return nil, errors.Wrapf(err, "error opening %s", c.responsePipe)
+	}
+	c.events = events.NewFrameWriter(eventPipe)
+	return c, nil
 }
 
-// SendOne sends single event to event driver sidecar
-func (PipeClient) SendOne(event *events.CloudEvent) error {
-	panic("implement me")
+// Send sends slice of events to event driver sidecar. It runs Validate() first.
+func (c *PipeClient) Send(evs []events.CloudEvent) error {
+	if err := c.Validate(evs); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(evs)
+	if err != nil {
+		return err
+	}
+	return c.send(payload)
+}
+
+// SendOne sends single event to event driver sidecar. It runs Validate() first.
+func (c *PipeClient) SendOne(event *events.CloudEvent) error {
+	if err := c.ValidateOne(event); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return c.send(payload)
 }
 
 // Validate validates list of events without sending them.
-func (PipeClient) Validate(event []events.CloudEvent) error {
-	panic("implement me")
+func (c *PipeClient) Validate(evs []events.CloudEvent) error {
+	for _, e := range evs {
+		if err := c.validator.Validate(&e); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ValidateOne validates single event without sending it.
-func (PipeClient) ValidateOne(event *events.CloudEvent) error {
-	panic("implement me")
+func (c *PipeClient) ValidateOne(event *events.CloudEvent) error {
+	return c.validator.Validate(event)
+}
+
+// Close closes the pipes
+func (c *PipeClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses.Close()
+	return c.events.Close()
+}
+
+// send writes a frame to the event pipe and waits for its response
+func (c *PipeClient) send(payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.events.WriteFrame(payload); err != nil {
+		return errors.Wrapf(err, "error writing to %s", c.eventPipe)
+	}
+	frame, err := events.ReadFrame(c.responses)
+	if err != nil {
+		return errors.Wrapf(err, "error reading from %s", c.responsePipe)
+	}
+	var response events.PipeResponse
+	if err := json.Unmarshal(frame, &response); err != nil {
+		return errors.Wrap(err, "error decoding the response of the sidecar")
+	}
+	if response.Code >= 300 {
+		return errors.Errorf("sidecar responded with code %d: %s", response.Code, response.Message)
+	}
+	return nil
+}
+
+func (c *PipeClient) waitForPipes() error {
+	return utils.Backoff(30*time.Second, func() error {
+		for _, path := range []string{c.eventPipe, c.responsePipe} {
+			log.Printf("checking pipe %s", path)
+			if _, err := os.Stat(path); err != nil {
+				log.Printf("pipe not ready: %s", err)
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -0,0 +1,164 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package events
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/pkg/errors"
+)
+
+const (
+	// WildcardWord matches exactly one word of a dot separated event type
+	WildcardWord = "*"
+	// WildcardWords matches zero or more words of a dot separated event type
+	WildcardWords = "#"
+)
+
+// IsWildcard returns true if the event type contains wildcards.
+func IsWildcard(eventType string) bool {
+	return strings.ContainsAny(eventType, WildcardWord+WildcardWords)
+}
+
+// MatchEventType returns true if the event type matches the pattern. Patterns follow AMQP topic semantics,
+// e.g. "vcenter.vm.*" matches "vcenter.vm.created" and "vcenter.#" matches "vcenter" and "vcenter.vm.created".
+func MatchEventType(pattern, eventType string) bool {
+	if !IsWildcard(pattern) {
+		return pattern == eventType
+	}
+	return matchWords(strings.Split(pattern, "."), strings.Split(eventType, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case WildcardWords:
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case WildcardWord:
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
+
+// Filter is a boolean expression evaluated against CloudEvents. Expressions can refer to the attributes of the
+// event (e.g. source, eventTypeVersion), to its extensions (e.g. [extensions.region]) and to its JSON payload
+// (e.g. [data.vm.name], [data.disks.0.size]). Names containing dots must be enclosed in brackets.
+type Filter struct {
+	expression *govaluate.EvaluableExpression
+}
+
+// NewFilter parses the filter expression
+func NewFilter(expression string) (*Filter, error) {
+	e, err := govaluate.NewEvaluableExpression(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid filter expression %q", expression)
+	}
+	for _, v := range e.Vars() {
+		if err := validateFilterVar(v); err != nil {
+			return nil, errors.Wrapf(err, "invalid filter expression %q", expression)
+		}
+	}
+	return &Filter{expression: e}, nil
+}
+
+// Match returns true if the event satisfies the filter. Attributes missing from the event evaluate to nil.
+func (f *Filter) Match(event *CloudEvent) (bool, error) {
+	result, err := f.expression.Eval(&filterParameters{event: event})
+	if err != nil {
+		return false, errors.Wrapf(err, "error evaluating filter against event %s", event.EventID)
+	}
+	match, ok := result.(bool)
+	if !ok {
+		return false, errors.Errorf("filter evaluated to %v, expected a boolean", result)
+	}
+	return match, nil
+}
+
+func validateFilterVar(name string) error {
+	root := strings.SplitN(name, ".", 2)[0]
+	switch root {
+	case "eventType", "eventTypeVersion", "cloudEventsVersion", "source", "eventID", "schemaURL", "contentType":
+		if root != name {
+			return errors.Errorf("attribute %s has no fields", root)
+		}
+	case "extensions", "data":
+	default:
+		return errors.Errorf("unknown attribute %s", name)
+	}
+	return nil
+}
+
+// filterParameters exposes a CloudEvent to filter expressions, the payload is decoded only when referred to
+type filterParameters struct {
+	event *CloudEvent
+
+	decoded bool
+	data    interface{}
+}
+
+// Get implements govaluate.Parameters
+func (p *filterParameters) Get(name string) (interface{}, error) {
+	path := strings.Split(name, ".")
+	switch path[0] {
+	case "eventType":
+		return p.event.EventType, nil
+	case "eventTypeVersion":
+		return p.event.EventTypeVersion, nil
+	case "cloudEventsVersion":
+		return p.event.CloudEventsVersion, nil
+	case "source":
+		return p.event.Source, nil
+	case "eventID":
+		return p.event.EventID, nil
+	case "schemaURL":
+		return p.event.SchemaURL, nil
+	case "contentType":
+		return p.event.ContentType, nil
+	case "extensions":
+		return lookup(map[string]interface{}(p.event.Extensions), path[1:]), nil
+	case "data":
+		if !p.decoded {
+			p.decoded = true
+			if len(p.event.Data) > 0 {
+				if err := json.Unmarshal(p.event.Data, &p.data); err != nil {
+					return nil, errors.Wrap(err, "event data is not valid JSON")
+				}
+			}
+		}
+		return lookup(p.data, path[1:]), nil
+	}
+	return nil, errors.Errorf("unknown attribute %s", name)
+}
+
+// lookup walks a decoded JSON value, numeric path elements index arrays
+func lookup(value interface{}, path []string) interface{} {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchEventType(t *testing.T) {
+	cases := []struct {
+		pattern   string
+		eventType string
+		match     bool
+	}{
+		{"vcenter.vm.created", "vcenter.vm.created", true},
+		{"vcenter.vm.created", "vcenter.vm.deleted", false},
+		{"vcenter.vm.*", "vcenter.vm.created", true},
+		{"vcenter.vm.*", "vcenter.vm", false},
+		{"vcenter.vm.*", "vcenter.vm.disk.added", false},
+		{"vcenter.*.created", "vcenter.host.created", true},
+		{"vcenter.#", "vcenter", true},
+		{"vcenter.#", "vcenter.vm.disk.added", true},
+		{"#.created", "vcenter.vm.created", true},
+		{"#", "anything.at.all", true},
+		{"vcenter.#.added", "vcenter.added", true},
+		{"vcenter.#.added", "vcenter.vm.created", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, MatchEventType(c.pattern, c.eventType), "%s ~ %s", c.pattern, c.eventType)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	event := NewCloudEventWithDefaults("vcenter.vm.created")
+	event.Source = "vcenter1"
+	event.EventTypeVersion = "6.5"
+	event.Extensions = CloudEventExtensions{"region": "us-west"}
+	event.Data = []byte(`{"vm": {"name": "web-1", "cpus": 4}, "disks": [{"size": 10}]}`)
+
+	cases := []struct {
+		expression string
+		match      bool
+	}{
+		{"source == 'vcenter1'", true},
+		{"source == 'vcenter2'", false},
+		{"eventTypeVersion == '6.5' && [extensions.region] == 'us-west'", true},
+		{"[extensions.zone] == 'a'", false},
+		{"[data.vm.name] =~ '^web-'", true},
+		{"[data.vm.cpus] > 2", true},
+		{"[data.disks.0.size] >= 20", false},
+		{"[data.vm.missing] != 'web-1'", true},
+	}
+	for _, c := range cases {
+		f, err := NewFilter(c.expression)
+		require.NoError(t, err, c.expression)
+		match, err := f.Match(&event)
+		require.NoError(t, err, c.expression)
+		assert.Equal(t, c.match, match, c.expression)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	_, err := NewFilter("source ==")
+	assert.Error(t, err)
+
+	_, err = NewFilter("[sauce] == 'vcenter1'")
+	assert.Error(t, err)
+
+	_, err = NewFilter("[source.name] == 'vcenter1'")
+	assert.Error(t, err)
+
+	f, err := NewFilter("source")
+	require.NoError(t, err)
+	event := NewCloudEventWithDefaults("test.event")
+	_, err = f.Match(&event)
+	assert.Error(t, err)
+
+	f, err = NewFilter("[data.name] == 'x'")
+	require.NoError(t, err)
+	event.Data = []byte("not json")
+	_, err = f.Match(&event)
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package events
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Event drivers send events to the sidecar through named pipes. Every message is a frame made of its length as a
+// 4 bytes big-endian unsigned integer followed by the message. The driver writes frames holding JSON CloudEvents
+// (a single event or a list) to the event pipe, and reads a frame holding a JSON PipeResponse from the response pipe
+// for each of them.  Writes of frames larger than PIPE_BUF are not atomic, so a pipe must have a single writer process,
+// which serializes its frames with a FrameWriter.
+const (
+	// EventPipe is the default path of the pipe events are written to
+	EventPipe = "/pipes/events"
+	// ResponsePipe is the default path of the pipe responses are read from
+	ResponsePipe = "/pipes/response"
+
+	// MaxFrameSize is the maximum size of a frame
+	MaxFrameSize = 4 << 20
+)
+
+// PipeResponse acknowledges a frame written to the event pipe. Code follows HTTP status codes, e.g. 201 when the
+// events were published.
+type PipeResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// WriteFrame writes a length-prefixed frame holding payload.  After an error the stream may hold part of the frame.
+func WriteFrame(w io.Writer, payload []byte) error {
+	if len(payload) > MaxFrameSize {
+		return errors.Errorf("frame of %d bytes exceeds the maximum size of %d bytes", len(payload), MaxFrameSize)
+	}
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+	n, err := w.Write(frame)
+	if err == nil && n < len(frame) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
+
+// FrameWriter writes frames to a stream shared by goroutines, one frame at a time.  A failed write leaves part of a
+// frame in the stream, which the reader can't tell from the next frame, so the stream is closed and later writes fail.
+type FrameWriter struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+	// err is the error which broke the stream
+	err error
+}
+
+// NewFrameWriter creates a FrameWriter writing to w
+func NewFrameWriter(w io.WriteCloser) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// WriteFrame writes a length-prefixed frame holding payload
+func (fw *FrameWriter) WriteFrame(payload []byte) error {
+	if len(payload) > MaxFrameSize {
+		return errors.Errorf("frame of %d bytes exceeds the maximum size of %d bytes", len(payload), MaxFrameSize)
+	}
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.err != nil {
+		return errors.Wrap(fw.err, "stream closed after a failed write")
+	}
+	if err := WriteFrame(fw.w, payload); err != nil {
+		fw.err = err
+		fw.w.Close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the stream
+func (fw *FrameWriter) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.err != nil {
+		return nil
+	}
+	fw.err = errors.New("stream closed")
+	return fw.w.Close()
+}
+
+// ReadFrame reads a length-prefixed frame and returns its payload
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return nil, errors.Errorf("frame of %d bytes exceeds the maximum size of %d bytes", size, MaxFrameSize)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errors.Wrap(err, "error reading frame payload")
+	}
+	return payload, nil
+}
@@ -0,0 +1,110 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package events
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteFrame(buf, []byte(`{"eventType":"test.event"}`)))
+	require.NoError(t, WriteFrame(buf, []byte{}))
+
+	payload, err := ReadFrame(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `{"eventType":"test.event"}`, string(payload))
+	payload, err = ReadFrame(buf)
+	require.NoError(t, err)
+	assert.Empty(t, payload)
+	_, err = ReadFrame(buf)
+	assert.Error(t, err)
+}
+
+func TestFrameErrors(t *testing.T) {
+	assert.Error(t, WriteFrame(&bytes.Buffer{}, make([]byte, MaxFrameSize+1)))
+
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, MaxFrameSize+1)
+	_, err := ReadFrame(bytes.NewReader(header))
+	assert.Error(t, err)
+
+	binary.BigEndian.PutUint32(header, 10)
+	_, err = ReadFrame(bytes.NewReader(append(header, []byte("short")...)))
+	assert.Error(t, err)
+}
+
+// chunkWriter writes a few bytes at a time, yielding in between like a pipe does with frames larger than PIPE_BUF.  It
+// fails once limit bytes were written.
+type chunkWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	limit  int
+	closed bool
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		w.mu.Lock()
+		if w.limit > 0 && w.buf.Len() >= w.limit {
+			w.mu.Unlock()
+			return written, io.ErrClosedPipe
+		}
+		n := 3
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf.Write(p[:n])
+		w.mu.Unlock()
+		written += n
+		p = p[n:]
+		runtime.Gosched()
+	}
+	return written, nil
+}
+
+func (w *chunkWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestFrameWriter(t *testing.T) {
+	w := &chunkWriter{}
+	fw := NewFrameWriter(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, fw.WriteFrame(bytes.Repeat([]byte{byte('a' + i)}, 100)))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		payload, err := ReadFrame(&w.buf)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat(payload[:1], 100), payload)
+	}
+}
+
+func TestFrameWriterFailedWrite(t *testing.T) {
+	w := &chunkWriter{limit: 10}
+	fw := NewFrameWriter(w)
+	assert.Error(t, fw.WriteFrame(make([]byte, 100)))
+	assert.True(t, w.closed)
+	assert.Error(t, fw.WriteFrame([]byte("next")))
+	assert.Equal(t, 12, w.buf.Len())
+}
@@ -0,0 +1,436 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/events"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+const (
+	boltBatchSize          = 100
+	boltCompactionInterval = time.Minute
+)
+
+var (
+	boltEventsBucket  = []byte("events")
+	boltOffsetsBucket = []byte("offsets")
+)
+
+// Bolt provides event transport backed by an append-only log stored in a local BoltDB file. Every subscription
+// reads the log on its own, so all subscriptions of a topic receive its events. Durable subscriptions store their
+// offset after handling each batch of events, which gives at-least-once delivery across restarts.
+type Bolt struct {
+	db        *bolt.DB
+	retention time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*boltSubscriber]struct{}
+	done        chan struct{}
+}
+
+// boltRecord is an event stored in the log
+type boltRecord struct {
+	Topic string            `json:"topic"`
+	Time  time.Time         `json:"time"`
+	Event events.CloudEvent `json:"event"`
+
+	seq uint64
+}
+
+// boltSubscriber is the state of an active subscription
+type boltSubscriber struct {
+	topic        string
+	organization string
+	consumer     string
+	offset       uint64
+	committed    uint64
+	wake         chan struct{}
+	done         chan struct{}
+	// removed is closed when the consumer is removed, stopped once the subscriber is gone
+	removed    chan struct{}
+	removeOnce sync.Once
+	stopped    chan struct{}
+}
+
+// OptBoltRetention sets how long events are kept in the log, 0 keeps them forever.
+func OptBoltRetention(age time.Duration) func(b *Bolt) error {
+	return func(b *Bolt) error {
+		b.retention = age
+		return nil
+	}
+}
+
+// NewBolt creates an instance of transport storing events in the BoltDB file at path.
+func NewBolt(path string, options ...func(b *Bolt) error) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening event log %s", path)
+	}
+	b := &Bolt{
+		db:          db,
+		subscribers: make(map[*boltSubscriber]struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, option := range options {
+		if err := option(b); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if b.retention > 0 {
+		go b.compactLoop()
+	}
+	return b, nil
+}
+
+// Publish appends the event to the log of the organization
+func (b *Bolt) Publish(ctx context.Context, event *events.CloudEvent, topic string, organization string) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if organization == "" {
+		return errors.New("organization cannot be empty")
+	}
+
+	if topic == "" {
+		return errors.New("topic cannot be empty")
+	}
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := createOrgBucket(tx, boltEventsBucket, organization)
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(boltRecord{Topic: topic, Time: time.Now(), Event: *event})
+		if err != nil {
+			return err
+		}
+		return bucket.Put(itob(seq), data)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error when appending event to the log, topic: %s, organization: %s", topic, organization)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		if s.organization != organization {
+			continue
+		}
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe invokes handler for every event published on topic from now on, topic can contain wildcards.
+func (b *Bolt) Subscribe(ctx context.Context, topic string, organization string, handler events.Handler) (events.Subscription, error) {
+	return b.SubscribeDurable(ctx, topic, organization, "", time.Time{}, handler)
+}
+
+// SubscribeDurable invokes handler for every event published on topic, starting after the last event handled by
+// the consumer. An empty consumer is not durable. Only one subscription per consumer should be active at a time.
+func (b *Bolt) SubscribeDurable(ctx context.Context, topic string, organization string, consumer string, from time.Time, handler events.Handler) (events.Subscription, error) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	if organization == "" {
+		return nil, errors.New("organization cannot be empty")
+	}
+
+	if topic == "" {
+		return nil, errors.New("topic cannot be empty")
+	}
+
+	s := &boltSubscriber{
+		topic:        topic,
+		organization: organization,
+		consumer:     consumer,
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+		removed:      make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		var found bool
+		if from.IsZero() && consumer != "" {
+			s.offset, found = readOffset(tx, organization, consumer)
+		}
+		if !found {
+			s.offset = startOffset(tx, organization, from)
+		}
+		if consumer == "" {
+			return nil
+		}
+		s.committed = s.offset
+		return writeOffset(tx, organization, consumer, s.offset)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error when subscribing to topic %s in organization %s", topic, organization)
+	}
+
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+
+	go b.consume(s, handler)
+
+	return &subscription{done: s.done, topic: topic, organization: organization}, nil
+}
+
+// RemoveConsumer stops the subscriptions of the consumer and forgets its offset
+func (b *Bolt) RemoveConsumer(ctx context.Context, organization string, consumer string) error {
+	var removed []*boltSubscriber
+	b.mu.Lock()
+	for s := range b.subscribers {
+		if s.organization == organization && s.consumer == consumer {
+			s.removeOnce.Do(func() { close(s.removed) })
+			removed = append(removed, s)
+		}
+	}
+	b.mu.Unlock()
+	// A running subscriber would store its offset again
+	for _, s := range removed {
+		<-s.stopped
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := orgBucket(tx, boltOffsetsBucket, organization)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(consumer))
+	})
+}
+
+// Close stops all subscriptions and closes the log, once the subscribers stored their offset.
+func (b *Bolt) Close() {
+	close(b.done)
+	b.mu.Lock()
+	var subscribers []*boltSubscriber
+	for s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	b.mu.Unlock()
+	for _, s := range subscribers {
+		<-s.stopped
+	}
+	if err := b.db.Close(); err != nil {
+		log.Warnf("error when closing the event log: %+v", err)
+	}
+}
+
+// consume reads the log after the offset of the subscriber and runs the handler for every event of its topic
+func (b *Bolt) consume(s *boltSubscriber, handler events.Handler) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.subscribers, s)
+		b.mu.Unlock()
+		close(s.stopped)
+	}()
+	for {
+		records, err := b.read(s.organization, s.offset)
+		if err != nil {
+			log.Errorf("error when reading the event log of organization %s: %+v", s.organization, err)
+		}
+		for _, r := range records {
+			select {
+			case <-s.done:
+				b.commit(s)
+				return
+			case <-s.removed:
+				return
+			case <-b.done:
+				b.commit(s)
+				return
+			default:
+			}
+			s.offset = r.seq
+			if !events.MatchEventType(s.topic, r.Topic) {
+				continue
+			}
+			b.handle(s, r, handler)
+		}
+		if len(records) > 0 {
+			b.commit(s)
+		}
+		if len(records) == boltBatchSize {
+			continue
+		}
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		case <-s.removed:
+			return
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *Bolt) handle(s *boltSubscriber, r *boltRecord, handler events.Handler) {
+	spSub := opentracing.StartSpan("Bolt.SubscriptionHandler")
+	defer spSub.Finish()
+
+	// Update the context with the span for the subsequent reference.
+	ctx := opentracing.ContextWithSpan(context.Background(), spSub)
+	log.Debugf("Got an event %s on topic %s", r.Event.EventID, r.Topic)
+	handler(ctx, &r.Event)
+}
+
+// commit stores the offset of durable subscribers, once per batch of events
+func (b *Bolt) commit(s *boltSubscriber) {
+	if s.consumer == "" || s.offset == s.committed {
+		return
+	}
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return writeOffset(tx, s.organization, s.consumer, s.offset)
+	})
+	if err != nil {
+		log.Errorf("error when storing the offset of consumer %s: %+v", s.consumer, err)
+		return
+	}
+	s.committed = s.offset
+}
+
+// read returns the next batch of records after offset
+func (b *Bolt) read(organization string, offset uint64) ([]*boltRecord, error) {
+	var records []*boltRecord
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := orgBucket(tx, boltEventsBucket, organization)
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Seek(itob(offset + 1)); k != nil && len(records) < boltBatchSize; k, v = c.Next() {
+			r := &boltRecord{seq: binary.BigEndian.Uint64(k)}
+			if err := json.Unmarshal(v, r); err != nil {
+				log.Errorf("skipping malformed record %d in the event log of organization %s: %+v", r.seq, organization, err)
+			}
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (b *Bolt) compactLoop() {
+	ticker := time.NewTicker(boltCompactionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.compact(time.Now().Add(-b.retention)); err != nil {
+				log.Errorf("error when compacting the event log: %+v", err)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// compact deletes the records published before the given time
+func (b *Bolt) compact(before time.Time) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(boltEventsBucket)
+		if root == nil {
+			return nil
+		}
+		return root.ForEach(func(organization, _ []byte) error {
+			c := root.Bucket(organization).Cursor()
+			for k, v := c.First(); k != nil; k, v = c.Next() {
+				var r boltRecord
+				if err := json.Unmarshal(v, &r); err == nil && !r.Time.Before(before) {
+					return nil
+				}
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// startOffset returns the offset of the last record published before from, or of the last record if from is zero
+func startOffset(tx *bolt.Tx, organization string, from time.Time) uint64 {
+	bucket := orgBucket(tx, boltEventsBucket, organization)
+	if bucket == nil {
+		return 0
+	}
+	if from.IsZero() {
+		return bucket.Sequence()
+	}
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var r boltRecord
+		if err := json.Unmarshal(v, &r); err == nil && !r.Time.Before(from) {
+			return binary.BigEndian.Uint64(k) - 1
+		}
+	}
+	return bucket.Sequence()
+}
+
+func readOffset(tx *bolt.Tx, organization, consumer string) (uint64, bool) {
+	bucket := orgBucket(tx, boltOffsetsBucket, organization)
+	if bucket == nil {
+		return 0, false
+	}
+	v := bucket.Get([]byte(consumer))
+	if v == nil {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(v), true
+}
+
+func writeOffset(tx *bolt.Tx, organization, consumer string, offset uint64) error {
+	bucket, err := createOrgBucket(tx, boltOffsetsBucket, organization)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(consumer), itob(offset))
+}
+
+func orgBucket(tx *bolt.Tx, name []byte, organization string) *bolt.Bucket {
+	root := tx.Bucket(name)
+	if root == nil {
+		return nil
+	}
+	return root.Bucket([]byte(organization))
+}
+
+func createOrgBucket(tx *bolt.Tx, name []byte, organization string) (*bolt.Bucket, error) {
+	root, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return root.CreateBucketIfNotExists([]byte(organization))
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,178 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package transport
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/events"
+)
+
+func makeBolt(t *testing.T) (*Bolt, string) {
+	dir, err := ioutil.TempDir("", "bolt-transport")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "events.db")
+	b, err := NewBolt(path)
+	require.NoError(t, err)
+	return b, path
+}
+
+func receive(t *testing.T, received chan *events.CloudEvent) *events.CloudEvent {
+	select {
+	case e := <-received:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return nil
+}
+
+func assertNothingReceived(t *testing.T, received chan *events.CloudEvent) {
+	select {
+	case e := <-received:
+		t.Fatalf("unexpected event %s", e.EventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func channelHandler(received chan *events.CloudEvent) events.Handler {
+	return func(ctx context.Context, e *events.CloudEvent) {
+		received <- e
+	}
+}
+
+func publish(t *testing.T, b *Bolt, eventType string) events.CloudEvent {
+	event := events.NewCloudEventWithDefaults(eventType)
+	require.NoError(t, b.Publish(context.Background(), &event, event.DefaultTopic(), testOrg))
+	return event
+}
+
+func TestBoltFanOut(t *testing.T) {
+	b, path := makeBolt(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer b.Close()
+
+	first := make(chan *events.CloudEvent, 10)
+	second := make(chan *events.CloudEvent, 10)
+	wildcard := make(chan *events.CloudEvent, 10)
+	_, err := b.Subscribe(context.Background(), "vcenter.vm.created", testOrg, channelHandler(first))
+	require.NoError(t, err)
+	_, err = b.Subscribe(context.Background(), "vcenter.vm.created", testOrg, channelHandler(second))
+	require.NoError(t, err)
+	sub, err := b.Subscribe(context.Background(), "vcenter.#", testOrg, channelHandler(wildcard))
+	require.NoError(t, err)
+
+	created := publish(t, b, "vcenter.vm.created")
+	deleted := publish(t, b, "vcenter.vm.deleted")
+
+	assert.Equal(t, created.EventID, receive(t, first).EventID)
+	assert.Equal(t, created.EventID, receive(t, second).EventID)
+	assert.Equal(t, created.EventID, receive(t, wildcard).EventID)
+	assert.Equal(t, deleted.EventID, receive(t, wildcard).EventID)
+	assertNothingReceived(t, first)
+
+	require.NoError(t, sub.Unsubscribe())
+	publish(t, b, "vcenter.vm.deleted")
+	assertNothingReceived(t, wildcard)
+}
+
+func TestBoltDurable(t *testing.T) {
+	b, path := makeBolt(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	received := make(chan *events.CloudEvent, 10)
+	sub, err := b.SubscribeDurable(context.Background(), testTopic, testOrg, "consumer", time.Time{}, channelHandler(received))
+	require.NoError(t, err)
+	first := publish(t, b, testTopic)
+	assert.Equal(t, first.EventID, receive(t, received).EventID)
+	require.NoError(t, sub.Unsubscribe())
+
+	// Events published while the consumer is away are delivered when it comes back, also after a restart
+	second := publish(t, b, testTopic)
+	b.Close()
+	b, err = NewBolt(path)
+	require.NoError(t, err)
+	defer b.Close()
+
+	_, err = b.SubscribeDurable(context.Background(), testTopic, testOrg, "consumer", time.Time{}, channelHandler(received))
+	require.NoError(t, err)
+	assert.Equal(t, second.EventID, receive(t, received).EventID)
+	assertNothingReceived(t, received)
+
+	// Removed consumers start from the next published event
+	require.NoError(t, b.RemoveConsumer(context.Background(), testOrg, "other"))
+	late := make(chan *events.CloudEvent, 10)
+	_, err = b.SubscribeDurable(context.Background(), testTopic, testOrg, "other", time.Time{}, channelHandler(late))
+	require.NoError(t, err)
+	assertNothingReceived(t, late)
+}
+
+func TestBoltRemoveConsumer(t *testing.T) {
+	b, path := makeBolt(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer b.Close()
+
+	received := make(chan *events.CloudEvent, 10)
+	_, err := b.SubscribeDurable(context.Background(), testTopic, testOrg, "consumer", time.Time{}, channelHandler(received))
+	require.NoError(t, err)
+	first := publish(t, b, testTopic)
+	assert.Equal(t, first.EventID, receive(t, received).EventID)
+
+	// The subscription stops, and doesn't store its offset again
+	require.NoError(t, b.RemoveConsumer(context.Background(), testOrg, "consumer"))
+	publish(t, b, testTopic)
+	assertNothingReceived(t, received)
+	require.NoError(t, b.db.View(func(tx *bolt.Tx) error {
+		_, found := readOffset(tx, testOrg, "consumer")
+		assert.False(t, found)
+		return nil
+	}))
+}
+
+func TestBoltReplayFrom(t *testing.T) {
+	b, path := makeBolt(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer b.Close()
+
+	publish(t, b, testTopic)
+	time.Sleep(10 * time.Millisecond)
+	from := time.Now()
+	second := publish(t, b, testTopic)
+	third := publish(t, b, testTopic)
+
+	received := make(chan *events.CloudEvent, 10)
+	_, err := b.SubscribeDurable(context.Background(), testTopic, testOrg, "replay", from, channelHandler(received))
+	require.NoError(t, err)
+	assert.Equal(t, second.EventID, receive(t, received).EventID)
+	assert.Equal(t, third.EventID, receive(t, received).EventID)
+	assertNothingReceived(t, received)
+}
+
+func TestBoltCompact(t *testing.T) {
+	b, path := makeBolt(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer b.Close()
+
+	publish(t, b, testTopic)
+	time.Sleep(10 * time.Millisecond)
+	before := time.Now()
+	kept := publish(t, b, testTopic)
+	require.NoError(t, b.compact(before))
+
+	records, err := b.read(testOrg, 0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, kept.EventID, records[0].Event.EventID)
+}
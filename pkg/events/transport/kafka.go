@@ -104,6 +104,10 @@ func (k *Kafka) Subscribe(ctx context.Context, topic string, organization string
 		return nil, errors.New("topic cannot be empty")
 	}
 
+	if events.IsWildcard(topic) {
+		return nil, errors.Errorf("wildcard topic %s is not supported by the kafka transport", topic)
+	}
+
 	topicWithOrg := organization + "." + topic
 
 	doneChan := make(chan struct{})
@@ -42,16 +42,16 @@ func (m *InMemory) Publish(ctx context.Context, event *events.CloudEvent, topic
 	if !ok || exchange == nil {
 		return nil
 	}
-	queue, ok := exchange[topic]
-	if !ok || queue == nil {
-		return nil
+	for pattern, queue := range exchange {
+		if queue != nil && events.MatchEventType(pattern, topic) {
+			queue <- *event
+		}
 	}
-	queue <- *event
 
 	return nil
 }
 
-// Subscribe implements Transport interface subscribe method
+// Subscribe implements Transport interface subscribe method, topic can contain wildcards
 func (m *InMemory) Subscribe(ctx context.Context, topic string, organization string, handler events.Handler) (events.Subscription, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -40,3 +40,23 @@ func TestInMemorySubscribe(t *testing.T) {
 	<-done
 
 }
+
+func TestInMemorySubscribeWildcard(t *testing.T) {
+	event := events.NewCloudEventWithDefaults("vcenter.vm.created")
+	memory := NewInMemory()
+
+	done := make(chan struct{})
+	_, err := memory.Subscribe(context.Background(), "vcenter.vm.*", testOrg, func(ctx context.Context, e *events.CloudEvent) {
+		assert.Equal(t, event.EventID, e.EventID)
+		done <- struct{}{}
+	})
+	assert.NoError(t, err)
+
+	other := events.NewCloudEventWithDefaults("vcenter.host.created")
+	err = memory.Publish(context.Background(), &other, other.DefaultTopic(), testOrg)
+	assert.NoError(t, err)
+	err = memory.Publish(context.Background(), &event, event.DefaultTopic(), testOrg)
+	assert.NoError(t, err)
+
+	<-done
+}
@@ -157,7 +157,7 @@ func (mq *RabbitMQ) Publish(ctx context.Context, event *events.CloudEvent, topic
 }
 
 // Subscribe creates an active subscription on specified topic, and invokes handler function
-// for every event received on given topic.
+// for every event received on given topic. Wildcards in the topic are matched by the topic exchange.
 func (mq *RabbitMQ) Subscribe(ctx context.Context, topic string, organization string, handler events.Handler) (events.Subscription, error) {
 	span, ctx := trace.Trace(ctx, "")
 	defer span.Finish()
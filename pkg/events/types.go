@@ -8,6 +8,7 @@ package events
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // NO TESTS
@@ -23,6 +24,20 @@ type Transport interface {
 	Close()
 }
 
+// DurableTransport is a Transport keeping published events. Durable subscriptions are identified by a consumer
+// name and resume after the last event they handled, so events published while no subscription is active are
+// still delivered.
+type DurableTransport interface {
+	Transport
+
+	// SubscribeDurable subscribes the consumer to topic. Events are replayed from the given time if it is not zero,
+	// otherwise from the last event handled by the consumer, or from the next published event for new consumers.
+	SubscribeDurable(ctx context.Context, topic string, organization string, consumer string, from time.Time, handler Handler) (Subscription, error)
+
+	// RemoveConsumer forgets the position of the consumer
+	RemoveConsumer(ctx context.Context, organization string, consumer string) error
+}
+
 // Handler is a callback function used to handle received event
 type Handler func(context.Context, *CloudEvent)
 
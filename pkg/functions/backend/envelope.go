@@ -0,0 +1,34 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package backend
+
+import (
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// Dispatch asks function servers for an envelope by setting EnvelopeHeader to "true" on the run request. Servers
+// which support it respond with EnvelopeContentType and an Envelope holding the function output along with what the
+// function wrote to stdout and stderr during the invocation. Plain responses are still accepted, without logs.
+// Requests without the header (e.g. from endpoints routed straight to the function) get plain responses.  The local
+// backend wraps the responses of the function processes it runs itself.
+const (
+	// EnvelopeHeader is the request header asking for an envelope
+	EnvelopeHeader = "X-Dispatch-Envelope"
+	// EnvelopeContentType is the content type of envelope responses
+	EnvelopeContentType = "application/vnd.dispatch.envelope+json"
+)
+
+// Envelope is the response of a function server wrapping the result of an invocation
+type Envelope struct {
+	// ContentType is the content type of Output
+	ContentType string `json:"contentType,omitempty"`
+	// Output is the output of the function, base64 encoded in JSON
+	Output []byte `json:"output,omitempty"`
+	// Logs holds the lines written by the function to stdout and stderr
+	Logs *dapi.Logs `json:"logs,omitempty"`
+	// Error is set if the function failed
+	Error *dapi.InvocationError `json:"error,omitempty"`
+}
@@ -9,7 +9,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/knative/serving/pkg/apis/serving"
+	knserve "github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	knclientset "github.com/knative/serving/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -65,6 +68,13 @@ func Knative(kubeconfPath, ingressGateway, buildImage string, storageConfig *con
 
 func (h *knative) Add(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
 	log.Debugf("Creating function with destination: %s", function.FunctionImageURL)
+	// A new function has no revisions to split traffic between yet
+	if err := validateTraffic(function.Traffic, nil); err != nil {
+		return nil, ValidationError{err}
+	}
+	if err := validateScaling(function); err != nil {
+		return nil, ValidationError{err}
+	}
 	service := FromFunction(h.buildConfig, function)
 	if err := service.Validate(); err != nil {
 		fmt.Println(err.Message)
@@ -99,7 +109,26 @@ func (h *knative) Get(ctx context.Context, meta *dapi.Meta) (*dapi.Function, err
 		return nil, errors.Wrapf(err, "getting knative service '%s'", serviceName)
 	}
 
-	return ToFunction(service), nil
+	function := ToFunction(service)
+	function.Revisions, err = h.revisions(service)
+	if err != nil {
+		return nil, err
+	}
+	return function, nil
+}
+
+// revisions lists the revisions of the function backed by the service
+func (h *knative) revisions(service *knserve.Service) ([]*dapi.FunctionRevision, error) {
+	revisions := h.knClient.ServingV1alpha1().Revisions(service.Namespace)
+	revisionList, err := revisions.List(v1.ListOptions{
+		LabelSelector: knaming.ToLabelSelector(map[string]string{
+			serving.ConfigurationLabelKey: service.Name,
+		}),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing knative revisions of '%s'", service.Name)
+	}
+	return toRevisions(revisionList.Items, service.Status.Traffic), nil
 }
 
 func (h *knative) Delete(ctx context.Context, meta *dapi.Meta) error {
@@ -144,14 +173,47 @@ func (h *knative) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Function,
 }
 
 func (h *knative) Update(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
+	services := h.knClient.ServingV1alpha1().Services(function.Meta.Org)
+
+	serviceName := knaming.FunctionName(function.Meta)
+	existing, err := services.Get(serviceName, v1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, NotFound{err}
+		}
+		return nil, errors.Wrapf(err, "getting knative service '%s'", serviceName)
+	}
+	// Keep splitting traffic between revisions unless the update says otherwise
+	if function.Traffic == nil {
+		function.Traffic = ToFunction(existing).Traffic
+	}
+	revisions, err := h.revisions(existing)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTraffic(function.Traffic, revisions); err != nil {
+		return nil, ValidationError{err}
+	}
+	if err := validateScaling(function); err != nil {
+		return nil, ValidationError{err}
+	}
+	if len(activeTraffic(function.Traffic)) == 0 {
+		function.Traffic = nil
+	}
+	if function.Meta.Revision == "" {
+		function.Meta.Revision = existing.ResourceVersion
+	}
+
 	service := FromFunction(h.buildConfig, function)
+	// Keep the last roll of the function, otherwise updates which don't change the function start a new revision
+	if rolled := revisionAnnotation(existing, knaming.RolledAnnotation); rolled != "" {
+		setRevisionAnnotation(service, knaming.RolledAnnotation, rolled)
+	}
 
 	if err := service.Validate(); err != nil {
 		return nil, ValidationError{err}
 	}
 
-	services := h.knClient.ServingV1alpha1().Services(function.Meta.Org)
-
 	updatedService, err := services.Update(service)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -160,7 +222,75 @@ func (h *knative) Update(ctx context.Context, function *dapi.Function) (*dapi.Fu
 		return nil, errors.Wrap(err, "updating knative service")
 	}
 
-	return ToFunction(updatedService), nil
+	updatedFunction := ToFunction(updatedService)
+	updatedFunction.Revisions = revisions
+	return updatedFunction, nil
+}
+
+// RollConfig starts a new revision of the functions using the configuration, so that they get its current values.
+// Functions splitting traffic between revisions move all their traffic to the new revision, a release would never
+// route to it.
+func (h *knative) RollConfig(ctx context.Context, meta *dapi.Meta) error {
+	return h.roll(ctx, meta, func(function *dapi.Function) bool { return usesConfig(function, meta.Name) })
+}
+
+// RollService starts a new revision of the functions using the service instance, so that they get its binding
+// credentials
+func (h *knative) RollService(ctx context.Context, meta *dapi.Meta) error {
+	return h.roll(ctx, meta, func(function *dapi.Function) bool { return usesService(function, meta.Name) })
+}
+
+// roll starts a new revision of the functions of the project of meta which uses tells about
+func (h *knative) roll(ctx context.Context, meta *dapi.Meta, uses func(*dapi.Function) bool) error {
+	functions, err := h.List(ctx, meta)
+	if err != nil {
+		return err
+	}
+	services := h.knClient.ServingV1alpha1().Services(meta.Org)
+	rolled := time.Now().UTC().Format(time.RFC3339Nano)
+	for _, function := range functions {
+		if !uses(function) {
+			continue
+		}
+		service := function.BackingObject.(*knserve.Service).DeepCopy()
+		if service.Spec.Release != nil {
+			if err := runLatest(service); err != nil {
+				return err
+			}
+			log.Infof("function %s rolled for %s no longer splits traffic", function.Name, meta.Name)
+		}
+		setRevisionAnnotation(service, knaming.RolledAnnotation, rolled)
+		if _, err := services.Update(service); err != nil {
+			return errors.Wrapf(err, "rolling knative service '%s'", service.Name)
+		}
+		log.Debugf("rolled function %s using %s", function.Name, meta.Name)
+	}
+	return nil
+}
+
+// ConfigUsers returns the names of the functions using the configuration
+func (h *knative) ConfigUsers(ctx context.Context, meta *dapi.Meta) ([]string, error) {
+	return h.users(ctx, meta, func(function *dapi.Function) bool { return usesConfig(function, meta.Name) })
+}
+
+// ServiceUsers returns the names of the functions using the service instance
+func (h *knative) ServiceUsers(ctx context.Context, meta *dapi.Meta) ([]string, error) {
+	return h.users(ctx, meta, func(function *dapi.Function) bool { return usesService(function, meta.Name) })
+}
+
+// users returns the names of the functions of the project of meta which uses tells about
+func (h *knative) users(ctx context.Context, meta *dapi.Meta, uses func(*dapi.Function) bool) ([]string, error) {
+	functions, err := h.List(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	for _, function := range functions {
+		if uses(function) {
+			users = append(users, function.Name)
+		}
+	}
+	return users, nil
 }
 
 func (h *knative) RunEndpoint(ctx context.Context, meta *dapi.Meta) (string, string, error) {
@@ -10,11 +10,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-openapi/swag"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving"
+	knserve "github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/client/clientset/versioned/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/functions/config"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
 )
 
 const (
@@ -138,6 +146,165 @@ func TestKnative_Update(t *testing.T) {
 	assert.Equal(t, newImage, function.FunctionImageURL)
 }
 
+func TestKnative_RollConfig(t *testing.T) {
+	be := testBackend()
+	uses := f1()
+	uses.Configs = []string{"settings"}
+	_, err := be.Add(context.TODO(), uses)
+	require.NoError(t, err)
+	_, err = be.Add(context.TODO(), f2())
+	require.NoError(t, err)
+
+	rolled := func(name string) string {
+		function, err := be.Get(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: name})
+		require.NoError(t, err)
+		return revisionAnnotation(function.BackingObject.(*knserve.Service), knaming.RolledAnnotation)
+	}
+
+	require.NoError(t, be.RollConfig(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "settings"}))
+	first := rolled(fn1)
+	assert.NotEmpty(t, first)
+	assert.Empty(t, rolled(fn2))
+
+	// Updating the function keeps the last roll
+	_, err = be.Update(context.TODO(), uses)
+	require.NoError(t, err)
+	assert.Equal(t, first, rolled(fn1))
+
+	users, err := be.ConfigUsers(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "settings"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fn1}, users)
+	users, err = be.ConfigUsers(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "limits"})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestKnative_RollService(t *testing.T) {
+	be := testBackend()
+	uses := f1()
+	uses.Services = []string{"orders"}
+	_, err := be.Add(context.TODO(), uses)
+	require.NoError(t, err)
+	_, err = be.Add(context.TODO(), f2())
+	require.NoError(t, err)
+
+	rolled := func(name string) string {
+		function, err := be.Get(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: name})
+		require.NoError(t, err)
+		return revisionAnnotation(function.BackingObject.(*knserve.Service), knaming.RolledAnnotation)
+	}
+
+	require.NoError(t, be.RollService(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "orders"}))
+	assert.NotEmpty(t, rolled(fn1))
+	assert.Empty(t, rolled(fn2))
+
+	users, err := be.ServiceUsers(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{fn1}, users)
+	users, err = be.ServiceUsers(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "payments"})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestKnative_RollConfigTraffic(t *testing.T) {
+	be := testBackend()
+	uses := f2()
+	uses.Configs = []string{"settings"}
+	_, err := be.Add(context.TODO(), uses)
+	require.NoError(t, err)
+	addRevision(t, be, uses, "rev-00001", true)
+	addRevision(t, be, uses, "rev-00002", true)
+
+	uses.Traffic = []*v1.TrafficTarget{
+		{Revision: swag.String("rev-00001"), Percent: 90},
+		{Revision: swag.String("rev-00002"), Percent: 10},
+	}
+	_, err = be.Update(context.TODO(), uses)
+	require.NoError(t, err)
+
+	// The new revision would get no traffic from the release, all traffic moves to it instead
+	require.NoError(t, be.RollConfig(context.TODO(), &v1.Meta{Org: testOrg, Project: testProject, Name: "settings"}))
+	function, err := be.Get(context.TODO(), &uses.Meta)
+	require.NoError(t, err)
+	spec := function.BackingObject.(*knserve.Service).Spec
+	assert.Nil(t, spec.Release)
+	require.NotNil(t, spec.RunLatest)
+	assert.NotEmpty(t, spec.RunLatest.Configuration.RevisionTemplate.Annotations[knaming.RolledAnnotation])
+	assert.Empty(t, function.Traffic)
+	assert.Equal(t, []string{"settings"}, function.Configs)
+}
+
+func addRevision(t *testing.T, be *knative, function *v1.Function, name string, ready bool) {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	revision := &knserve.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: function.Org,
+			Labels:    map[string]string{serving.ConfigurationLabelKey: knaming.FunctionName(function.Meta)},
+		},
+		Status: knserve.RevisionStatus{
+			Conditions: duckv1alpha1.Conditions{{Type: knserve.RevisionConditionReady, Status: status}},
+		},
+	}
+	_, err := be.knClient.ServingV1alpha1().Revisions(function.Org).Create(revision)
+	require.NoError(t, err)
+}
+
+func TestKnative_Traffic(t *testing.T) {
+	be := testBackend()
+	_, err := be.Add(context.TODO(), f2())
+	require.NoError(t, err)
+	addRevision(t, be, f2(), "rev-00001", true)
+	addRevision(t, be, f2(), "rev-00002", false)
+
+	function, err := be.Get(context.TODO(), &f2().Meta)
+	require.NoError(t, err)
+	require.Len(t, function.Revisions, 2)
+	assert.Equal(t, v1.StatusREADY, function.Revisions[0].Status)
+	assert.Equal(t, v1.StatusERROR, function.Revisions[1].Status)
+
+	update := f2()
+	update.Traffic = []*v1.TrafficTarget{
+		{Revision: swag.String("rev-00001"), Percent: 90},
+		{Revision: swag.String("rev-00002"), Percent: 10},
+	}
+	_, err = be.Update(context.TODO(), update)
+	require.NoError(t, err)
+	function, err = be.Get(context.TODO(), &f2().Meta)
+	require.NoError(t, err)
+	release := function.BackingObject.(*knserve.Service).Spec.Release
+	require.NotNil(t, release)
+	assert.Equal(t, []string{"rev-00001", "rev-00002"}, release.Revisions)
+	assert.Equal(t, 10, release.RolloutPercent)
+
+	// Updates without traffic keep the traffic split
+	_, err = be.Update(context.TODO(), f2())
+	require.NoError(t, err)
+	function, err = be.Get(context.TODO(), &f2().Meta)
+	require.NoError(t, err)
+	assert.NotNil(t, function.BackingObject.(*knserve.Service).Spec.Release)
+	assert.Len(t, function.Traffic, 2)
+
+	update.Traffic = []*v1.TrafficTarget{{Revision: swag.String("rev-00003"), Percent: 100}}
+	_, err = be.Update(context.TODO(), update)
+	assert.IsType(t, ValidationError{}, err)
+
+	update.Traffic = []*v1.TrafficTarget{{Revision: swag.String("rev-00001"), Percent: 50}}
+	_, err = be.Update(context.TODO(), update)
+	assert.IsType(t, ValidationError{}, err)
+
+	update.Traffic = []*v1.TrafficTarget{{Revision: swag.String(v1.LatestRevision), Percent: 100}}
+	_, err = be.Update(context.TODO(), update)
+	require.NoError(t, err)
+	function, err = be.Get(context.TODO(), &f2().Meta)
+	require.NoError(t, err)
+	assert.NotNil(t, function.BackingObject.(*knserve.Service).Spec.RunLatest)
+	assert.Empty(t, function.Traffic)
+}
+
 func TestKnative_Delete(t *testing.T) {
 	be := setup(t)
 
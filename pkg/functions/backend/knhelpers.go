@@ -6,15 +6,19 @@
 package backend
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	knbuild "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	knserve "github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	dapi "github.com/vmware/dispatch/pkg/api/v1"
@@ -22,6 +26,10 @@ import (
 	"github.com/vmware/dispatch/pkg/utils/knaming"
 )
 
+// defaultContainerConcurrency is the number of concurrent requests served by an instance of functions which don't
+// set it
+const defaultContainerConcurrency = 1
+
 //FromFunction produces a Knative Service from a Dispatch Function
 func FromFunction(buildCfg *BuildConfig, function *dapi.Function) *knserve.Service {
 	if function == nil {
@@ -35,12 +43,17 @@ func FromFunction(buildCfg *BuildConfig, function *dapi.Function) *knserve.Servi
 		},
 		InitialDelaySeconds: 0,
 	}
+	bindingEnvVars, bindings := fromServices(function.Services, function.Meta)
+	secrets := append(append([]string{}, function.Secrets...), bindings...)
 	envVars := append(
-		fromSecrets(function.Secrets, function.Meta),
+		append(fromSecrets(function.Secrets, function.Meta), bindingEnvVars...),
 		corev1.EnvVar{Name: "SERVERS", Value: "1"},
-		corev1.EnvVar{Name: "SECRETS", Value: strings.Join(function.Secrets, ",")},
+		corev1.EnvVar{Name: "SECRETS", Value: strings.Join(secrets, ",")},
 		corev1.EnvVar{Name: "TIMEOUT", Value: strconv.FormatInt(function.Timeout, 10)},
 	)
+	for _, env := range function.Env {
+		envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: env.Value})
+	}
 	source := &knbuild.SourceSpec{
 		Custom: &corev1.Container{
 			Image:           buildCfg.BuildImage,
@@ -94,36 +107,69 @@ func FromFunction(buildCfg *BuildConfig, function *dapi.Function) *knserve.Servi
 		}
 	}
 
-	return &knserve.Service{
-		ObjectMeta: knaming.ToObjectMeta(function.Meta, *function),
-		Spec: knserve.ServiceSpec{
-			RunLatest: &knserve.RunLatestType{
-				Configuration: knserve.ConfigurationSpec{
-					Build: &knserve.RawExtension{
-						BuildSpec: build,
-					},
-					RevisionTemplate: knserve.RevisionTemplateSpec{
-						Spec: knserve.RevisionSpec{
-							Container: corev1.Container{
-								Image:          function.FunctionImageURL,
-								Env:            envVars,
-								LivenessProbe:  probe,
-								ReadinessProbe: probe,
-								// Resources: corev1.ResourceRequirements{
-								// 	Requests: corev1.ResourceList{
-								// 		corev1.ResourceCPU: resource.MustParse("100m"),
-								// 	},
-								// },
-							},
-							ContainerConcurrency: 1,
-							// TODO define a service account per function
-							ServiceAccountName: buildCfg.ServiceAccount,
-						},
-					},
+	var revisionMeta metav1.ObjectMeta
+	annotations := make(map[string]string)
+	// Revisions remember their source so that it is kept while any revision may still serve the function
+	if function.SourceURL != "" {
+		annotations[knaming.SourceURLAnnotation] = function.SourceURL
+	}
+	if function.MinScale > 0 {
+		annotations[autoscaling.MinScaleAnnotationKey] = strconv.FormatInt(function.MinScale, 10)
+	}
+	if function.MaxScale > 0 {
+		annotations[autoscaling.MaxScaleAnnotationKey] = strconv.FormatInt(function.MaxScale, 10)
+	}
+	if len(annotations) > 0 {
+		revisionMeta.Annotations = annotations
+	}
+
+	containerConcurrency := int64(defaultContainerConcurrency)
+	if function.ContainerConcurrency != nil {
+		containerConcurrency = *function.ContainerConcurrency
+	}
+
+	configuration := knserve.ConfigurationSpec{
+		Build: &knserve.RawExtension{
+			BuildSpec: build,
+		},
+		RevisionTemplate: knserve.RevisionTemplateSpec{
+			ObjectMeta: revisionMeta,
+			Spec: knserve.RevisionSpec{
+				Container: corev1.Container{
+					Image:          function.FunctionImageURL,
+					Env:            envVars,
+					EnvFrom:        fromConfigs(function.Configs, function.Meta),
+					LivenessProbe:  probe,
+					ReadinessProbe: probe,
+					Resources:      fromResources(function.Resources),
 				},
+				ContainerConcurrency: knserve.RevisionContainerConcurrencyType(containerConcurrency),
+				// TODO define a service account per function
+				ServiceAccountName: buildCfg.ServiceAccount,
 			},
 		},
 	}
+
+	// Revisions are read only, they are looked up whenever the function is fetched
+	initialObject := *function
+	initialObject.Revisions = nil
+	service := &knserve.Service{
+		ObjectMeta: knaming.ToObjectMeta(function.Meta, initialObject),
+	}
+	traffic := activeTraffic(function.Traffic)
+	if len(traffic) == 0 {
+		service.Spec.RunLatest = &knserve.RunLatestType{Configuration: configuration}
+		return service
+	}
+	release := &knserve.ReleaseType{Configuration: configuration}
+	for _, target := range traffic {
+		release.Revisions = append(release.Revisions, *target.Revision)
+	}
+	if len(traffic) > 1 {
+		release.RolloutPercent = int(traffic[1].Percent)
+	}
+	service.Spec.Release = release
+	return service
 }
 
 func fromSecrets(secrets []string, meta dapi.Meta) []corev1.EnvVar {
@@ -146,6 +192,180 @@ func fromSecrets(secrets []string, meta dapi.Meta) []corev1.EnvVar {
 	return r
 }
 
+// fromServices produces the env vars holding the binding credentials of the service instances a function uses, along
+// with the names of the binding secrets. They are optional, so that functions start before their instances are bound,
+// the service manager rolls the functions once they are.
+func fromServices(services []string, meta dapi.Meta) ([]corev1.EnvVar, []string) {
+	var secrets []string
+	for _, service := range services {
+		secrets = append(secrets, knaming.BindingSecretName(service))
+	}
+	envVars := fromSecrets(secrets, meta)
+	for i := range envVars {
+		envVars[i].ValueFrom.SecretKeyRef.Optional = swag.Bool(true)
+	}
+	return envVars, secrets
+}
+
+// fromResources produces the resource requirements of a function container, quantities must have been checked with
+// validateScaling
+func fromResources(resources *dapi.FunctionResources) corev1.ResourceRequirements {
+	var r corev1.ResourceRequirements
+	if resources == nil {
+		return r
+	}
+	r.Requests = fromResourceList(resources.Requests)
+	r.Limits = fromResourceList(resources.Limits)
+	return r
+}
+
+func fromResourceList(list *dapi.ResourceList) corev1.ResourceList {
+	if list == nil {
+		return nil
+	}
+	r := make(corev1.ResourceList)
+	for name, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: list.CPU, corev1.ResourceMemory: list.Memory} {
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			r[name] = quantity
+		}
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
+
+func toResources(requirements corev1.ResourceRequirements) *dapi.FunctionResources {
+	if len(requirements.Requests) == 0 && len(requirements.Limits) == 0 {
+		return nil
+	}
+	return &dapi.FunctionResources{
+		Requests: toResourceList(requirements.Requests),
+		Limits:   toResourceList(requirements.Limits),
+	}
+}
+
+func toResourceList(list corev1.ResourceList) *dapi.ResourceList {
+	if len(list) == 0 {
+		return nil
+	}
+	r := new(dapi.ResourceList)
+	if quantity, ok := list[corev1.ResourceCPU]; ok {
+		r.CPU = quantity.String()
+	}
+	if quantity, ok := list[corev1.ResourceMemory]; ok {
+		r.Memory = quantity.String()
+	}
+	return r
+}
+
+// validateScaling checks the resource quantities and scale bounds of a function
+func validateScaling(function *dapi.Function) error {
+	if function.MaxScale > 0 && function.MinScale > function.MaxScale {
+		return errors.Errorf("minimum scale %d is greater than the maximum scale %d", function.MinScale, function.MaxScale)
+	}
+	if function.Resources == nil {
+		return nil
+	}
+	for kind, list := range map[string]*dapi.ResourceList{"request": function.Resources.Requests, "limit": function.Resources.Limits} {
+		if list == nil {
+			continue
+		}
+		for name, value := range map[string]string{"CPU": list.CPU, "memory": list.Memory} {
+			if value == "" {
+				continue
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return errors.Errorf("invalid %s %s '%s': %s", name, kind, value, err)
+			}
+		}
+	}
+	return nil
+}
+
+// serviceConfiguration returns the configuration of the revisions of a service
+func serviceConfiguration(service *knserve.Service) *knserve.ConfigurationSpec {
+	switch {
+	case service.Spec.RunLatest != nil:
+		return &service.Spec.RunLatest.Configuration
+	case service.Spec.Release != nil:
+		return &service.Spec.Release.Configuration
+	}
+	return nil
+}
+
+// fromConfigs sets the values of the configurations as environment variables, later configurations take precedence
+func fromConfigs(configs []string, meta dapi.Meta) []corev1.EnvFromSource {
+	var r []corev1.EnvFromSource
+	for _, config := range configs {
+		meta := meta
+		meta.Name = config
+		r = append(r, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: knaming.ConfigName(meta),
+				},
+			},
+		})
+	}
+	return r
+}
+
+// usesConfig tells whether the function gets the values of the configuration
+func usesConfig(function *dapi.Function, config string) bool {
+	for _, name := range function.Configs {
+		if name == config {
+			return true
+		}
+	}
+	return false
+}
+
+// usesService tells whether the function gets the binding credentials of the service instance
+func usesService(function *dapi.Function, service string) bool {
+	for _, name := range function.Services {
+		if name == service {
+			return true
+		}
+	}
+	return false
+}
+
+// runLatest routes all traffic of the service to its latest revision, also dropping the traffic split of the function
+func runLatest(service *knserve.Service) error {
+	var function dapi.Function
+	if err := knaming.FromJSONString(service.Annotations[knaming.InitialObjectAnnotation], &function); err != nil {
+		return errors.Wrapf(err, "decoding the function of knative service '%s'", service.Name)
+	}
+	function.Traffic = nil
+	service.Annotations[knaming.InitialObjectAnnotation] = knaming.ToJSONString(function)
+	service.Spec.RunLatest = &knserve.RunLatestType{Configuration: service.Spec.Release.Configuration}
+	service.Spec.Release = nil
+	return nil
+}
+
+// revisionAnnotation returns an annotation of the revision template of a service
+func revisionAnnotation(service *knserve.Service, key string) string {
+	configuration := serviceConfiguration(service)
+	if configuration == nil {
+		return ""
+	}
+	return configuration.RevisionTemplate.Annotations[key]
+}
+
+// setRevisionAnnotation sets an annotation of the revision template of a service, changing the template starts a new
+// revision
+func setRevisionAnnotation(service *knserve.Service, key, value string) {
+	configuration := serviceConfiguration(service)
+	if configuration == nil {
+		return
+	}
+	if configuration.RevisionTemplate.Annotations == nil {
+		configuration.RevisionTemplate.Annotations = make(map[string]string)
+	}
+	configuration.RevisionTemplate.Annotations[key] = value
+}
+
 //ToFunction produces a Dispatch Function from a Knative Service
 func ToFunction(service *knserve.Service) *dapi.Function {
 	if service == nil {
@@ -158,14 +378,31 @@ func ToFunction(service *knserve.Service) *dapi.Function {
 		panic(errors.Wrap(err, "decoding into function"))
 	}
 	function.CreatedTime = service.CreationTimestamp.Unix()
+	// Resources and scaling come from the revision template, which is what Knative runs
+	if configuration := serviceConfiguration(service); configuration != nil {
+		template := &configuration.RevisionTemplate
+		function.Resources = toResources(template.Spec.Container.Resources)
+		containerConcurrency := int64(template.Spec.ContainerConcurrency)
+		if function.ContainerConcurrency != nil || containerConcurrency != defaultContainerConcurrency {
+			function.ContainerConcurrency = swag.Int64(containerConcurrency)
+		}
+		function.MinScale, _ = strconv.ParseInt(template.Annotations[autoscaling.MinScaleAnnotationKey], 10, 64)
+		function.MaxScale, _ = strconv.ParseInt(template.Annotations[autoscaling.MaxScaleAnnotationKey], 10, 64)
+	}
+	function.Revision = objMeta.ResourceVersion
 
 	function.Kind = dapi.FunctionKind
 	function.ID = strfmt.UUID(objMeta.UID)
 	function.ModifiedTime = objMeta.CreationTimestamp.Unix()
 	function.Status = dapi.StatusINITIALIZED
+	var routesReady bool
+	configurationsReady := corev1.ConditionUnknown
 	for _, cond := range service.Status.Conditions {
-		if cond.Type == knserve.ServiceConditionRoutesReady && cond.Status == "True" {
-			function.Status = dapi.StatusREADY
+		switch cond.Type {
+		case knserve.ServiceConditionRoutesReady:
+			routesReady = cond.Status == corev1.ConditionTrue
+		case knserve.ServiceConditionConfigurationsReady:
+			configurationsReady = cond.Status
 		}
 		if cond.LastTransitionTime.Inner.Unix() > function.ModifiedTime {
 			function.ModifiedTime = cond.LastTransitionTime.Inner.Unix()
@@ -174,6 +411,93 @@ func ToFunction(service *knserve.Service) *dapi.Function {
 			function.Reason = append(function.Reason, cond.Reason)
 		}
 	}
+	// Until an update is rolled out, the routes keep serving the previous revision
+	switch {
+	case routesReady && configurationsReady == corev1.ConditionFalse:
+		function.Status = dapi.StatusERROR
+	case routesReady && (configurationsReady == corev1.ConditionUnknown || service.Status.ObservedGeneration < service.Generation):
+		function.Status = dapi.StatusUPDATING
+	case routesReady:
+		function.Status = dapi.StatusREADY
+	}
 	function.BackingObject = service
 	return &function
 }
+
+// activeTraffic returns the traffic targets which receive traffic, no targets means all traffic goes to the latest
+// revision
+func activeTraffic(traffic []*dapi.TrafficTarget) []*dapi.TrafficTarget {
+	var active []*dapi.TrafficTarget
+	for _, target := range traffic {
+		if target.Percent > 0 && *target.Revision != dapi.LatestRevision {
+			active = append(active, target)
+		}
+	}
+	return active
+}
+
+// validateTraffic checks that traffic is split between (at most two) of the given revisions
+func validateTraffic(traffic []*dapi.TrafficTarget, revisions []*dapi.FunctionRevision) error {
+	if len(traffic) == 0 {
+		return nil
+	}
+	if len(traffic) == 1 && *traffic[0].Revision == dapi.LatestRevision {
+		return nil
+	}
+	if len(activeTraffic(traffic)) > 2 {
+		return errors.New("traffic can be split between at most two revisions")
+	}
+	known := make(map[string]bool)
+	for _, revision := range revisions {
+		known[revision.Name] = true
+	}
+	var total int64
+	seen := make(map[string]bool)
+	for _, target := range traffic {
+		name := *target.Revision
+		if !known[name] {
+			return errors.Errorf("unknown revision '%s'", name)
+		}
+		if seen[name] {
+			return errors.Errorf("revision '%s' is listed more than once", name)
+		}
+		seen[name] = true
+		total += target.Percent
+	}
+	if total != 100 {
+		return errors.Errorf("traffic percentages add up to %d instead of 100", total)
+	}
+	return nil
+}
+
+// toRevisions produces the revisions of a function from Knative revisions and the traffic the service routes to them
+func toRevisions(revisions []knserve.Revision, traffic []knserve.TrafficTarget) []*dapi.FunctionRevision {
+	percents := make(map[string]int64)
+	for _, target := range traffic {
+		percents[target.RevisionName] += int64(target.Percent)
+	}
+	var r []*dapi.FunctionRevision
+	for i := range revisions {
+		revision := &revisions[i]
+		status := dapi.StatusINITIALIZED
+		if revision.Status.IsReady() {
+			status = dapi.StatusREADY
+		} else if cond := revision.Status.GetCondition(knserve.RevisionConditionReady); cond != nil && cond.Status == corev1.ConditionFalse {
+			status = dapi.StatusERROR
+		}
+		r = append(r, &dapi.FunctionRevision{
+			Name:        revision.Name,
+			CreatedTime: revision.CreationTimestamp.Unix(),
+			Percent:     percents[revision.Name],
+			SourceURL:   revision.Annotations[knaming.SourceURLAnnotation],
+			Status:      status,
+		})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].CreatedTime != r[j].CreatedTime {
+			return r[i].CreatedTime < r[j].CreatedTime
+		}
+		return r[i].Name < r[j].Name
+	})
+	return r
+}
@@ -5,4 +5,164 @@
 
 package backend
 
-//TODO add tests
+import (
+	"testing"
+
+	"github.com/go-openapi/swag"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	knserve "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
+)
+
+func TestFromFunctionTimeout(t *testing.T) {
+	buildCfg := testBackend().buildConfig
+
+	function := f1()
+	service := FromFunction(buildCfg, function)
+	assert.Empty(t, service.Spec.RunLatest.Configuration.RevisionTemplate.Annotations)
+
+	function.Timeout = 1500
+	service = FromFunction(buildCfg, function)
+	template := service.Spec.RunLatest.Configuration.RevisionTemplate
+	assert.Empty(t, template.Annotations)
+	assert.Contains(t, template.Spec.Container.Env, corev1.EnvVar{Name: "TIMEOUT", Value: "1500"})
+}
+
+func TestFromFunctionScaling(t *testing.T) {
+	buildCfg := testBackend().buildConfig
+
+	function := f1()
+	service := FromFunction(buildCfg, function)
+	template := service.Spec.RunLatest.Configuration.RevisionTemplate
+	assert.EqualValues(t, 1, template.Spec.ContainerConcurrency)
+	assert.Empty(t, template.Spec.Container.Resources.Requests)
+	assert.Equal(t, f1(), withoutServiceMeta(ToFunction(service)))
+
+	function.Resources = &v1.FunctionResources{
+		Requests: &v1.ResourceList{CPU: "250m", Memory: "64Mi"},
+		Limits:   &v1.ResourceList{Memory: "1Gi"},
+	}
+	function.ContainerConcurrency = swag.Int64(0)
+	function.MinScale = 1
+	function.MaxScale = 10
+	service = FromFunction(buildCfg, function)
+	template = service.Spec.RunLatest.Configuration.RevisionTemplate
+	assert.EqualValues(t, 0, template.Spec.ContainerConcurrency)
+	assert.Equal(t, "250m", template.Spec.Container.Resources.Requests.Cpu().String())
+	assert.Equal(t, "1Gi", template.Spec.Container.Resources.Limits.Memory().String())
+	assert.Equal(t, "1", template.Annotations[autoscaling.MinScaleAnnotationKey])
+	assert.Equal(t, "10", template.Annotations[autoscaling.MaxScaleAnnotationKey])
+	assert.Equal(t, function, withoutServiceMeta(ToFunction(service)))
+}
+
+func TestValidateScaling(t *testing.T) {
+	function := f1()
+	assert.NoError(t, validateScaling(function))
+
+	function.MinScale = 3
+	function.MaxScale = 2
+	assert.Error(t, validateScaling(function))
+
+	function.MaxScale = 0
+	assert.NoError(t, validateScaling(function))
+
+	function.Resources = &v1.FunctionResources{Limits: &v1.ResourceList{CPU: "lots"}}
+	err := validateScaling(function)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid CPU limit 'lots'")
+}
+
+func TestFromFunctionEnv(t *testing.T) {
+	buildCfg := testBackend().buildConfig
+
+	function := f1()
+	function.Env = []*v1.EnvVar{{Name: swag.String("LOG_LEVEL"), Value: "debug"}}
+	function.Configs = []string{"settings"}
+	service := FromFunction(buildCfg, function)
+	container := service.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"})
+	require.Len(t, container.EnvFrom, 1)
+	assert.Equal(t, knaming.ConfigName(v1.Meta{Org: testOrg, Project: testProject, Name: "settings"}), container.EnvFrom[0].ConfigMapRef.Name)
+	assert.Equal(t, function, withoutServiceMeta(ToFunction(service)))
+}
+
+func TestFromFunctionServices(t *testing.T) {
+	buildCfg := testBackend().buildConfig
+
+	function := f1()
+	function.Services = []string{"db"}
+	service := FromFunction(buildCfg, function)
+	container := service.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container
+	binding := knaming.BindingSecretName("db")
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name: knaming.SecretEnvVarName(binding),
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: knaming.SecretName(v1.Meta{Org: testOrg, Project: testProject, Name: binding}),
+				},
+				Key:      knaming.TheSecretKey,
+				Optional: swag.Bool(true),
+			},
+		},
+	})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "SECRETS", Value: secret1 + "," + secret2 + "," + binding})
+	assert.Equal(t, []string{secret1, secret2}, function.Secrets)
+	assert.Equal(t, function, withoutServiceMeta(ToFunction(service)))
+}
+
+// withoutServiceMeta clears the fields ToFunction sets from the service metadata
+func withoutServiceMeta(function *v1.Function) *v1.Function {
+	function.BackingObject = nil
+	function.Kind = v1.FunctionKind
+	function.ID = ""
+	function.CreatedTime = 0
+	function.ModifiedTime = 0
+	function.Status = ""
+	return function
+}
+
+func TestRevisionSourceURL(t *testing.T) {
+	buildCfg := testBackend().buildConfig
+
+	function := f1()
+	function.SourceURL = "file:///store/source.tgz"
+	service := FromFunction(buildCfg, function)
+	template := service.Spec.RunLatest.Configuration.RevisionTemplate
+	assert.Equal(t, function.SourceURL, template.Annotations[knaming.SourceURLAnnotation])
+
+	revision := knserve.Revision{ObjectMeta: template.ObjectMeta}
+	revision.Name = "rev-00001"
+	revisions := toRevisions([]knserve.Revision{revision}, nil)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, function.SourceURL, revisions[0].SourceURL)
+}
+
+func TestToFunctionStatus(t *testing.T) {
+	service := FromFunction(testBackend().buildConfig, f1())
+	assert.Equal(t, v1.StatusINITIALIZED, ToFunction(service).Status)
+
+	service.Generation = 2
+	service.Status.ObservedGeneration = 2
+	service.Status.Conditions = duckv1alpha1.Conditions{
+		{Type: knserve.ServiceConditionRoutesReady, Status: corev1.ConditionTrue},
+		{Type: knserve.ServiceConditionConfigurationsReady, Status: corev1.ConditionTrue},
+	}
+	assert.Equal(t, v1.StatusREADY, ToFunction(service).Status)
+
+	// An update the controller did not see yet, or a revision not ready yet, is still rolling out
+	service.Generation = 3
+	assert.Equal(t, v1.StatusUPDATING, ToFunction(service).Status)
+	service.Status.ObservedGeneration = 3
+	service.Status.Conditions[1].Status = corev1.ConditionUnknown
+	assert.Equal(t, v1.StatusUPDATING, ToFunction(service).Status)
+
+	service.Status.Conditions[1].Status = corev1.ConditionFalse
+	assert.Equal(t, v1.StatusERROR, ToFunction(service).Status)
+}
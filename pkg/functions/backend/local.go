@@ -0,0 +1,535 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/functions/sourcestore"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+const (
+	localReadyTimeout = time.Minute
+	// localLogsTimeout bounds the wait for the output a function process wrote during a run
+	localLogsTimeout = time.Second
+)
+
+// LocalConfig contains the settings of the local backend
+type LocalConfig struct {
+	// WorkDir is where function sources are unpacked
+	WorkDir string
+	// Commands serve functions over HTTP on $PORT by the language of the function image, the command of the empty
+	// language serves functions of any other language.  Commands run in the function source directory with the
+	// function handler in $HANDLER and the function image in $IMAGE
+	Commands map[string][]string
+}
+
+type localFunction struct {
+	function *dapi.Function
+	dir      string
+	// port serves the proxy to the function process, which listens on processPort
+	port        int
+	processPort int
+	cmd         *exec.Cmd
+	server      *http.Server
+	proxy       *httputil.ReverseProxy
+	stdout      *processLog
+	stderr      *processLog
+	// runLock serves runs asking for an envelope one at a time, so that the output of the process is theirs
+	runLock sync.Mutex
+	// exited is closed once the function process is gone
+	exited chan struct{}
+}
+
+type local struct {
+	config  *LocalConfig
+	sources sourcestore.SourceStore
+	images  client.ImagesClient
+
+	sync.Mutex
+	functions map[string]*localFunction
+}
+
+// Local returns a functions backend which runs functions as processes on the local machine, no cluster required
+func Local(config *LocalConfig, sources sourcestore.SourceStore, images client.ImagesClient) Backend {
+	return &local{
+		config:    config,
+		sources:   sources,
+		images:    images,
+		functions: make(map[string]*localFunction),
+	}
+}
+
+// ParseLocalCommands parses the commands of the local backend, given as [LANGUAGE=]COMMAND
+func ParseLocalCommands(values []string) (map[string][]string, error) {
+	if len(values) == 0 {
+		return nil, errors.New("the local backend needs a command serving functions")
+	}
+	commands := make(map[string][]string)
+	for _, value := range values {
+		var language string
+		// The command itself may set environment variables, a language is a single word before the first '='
+		if i := strings.Index(value, "="); i > 0 && !strings.ContainsAny(value[:i], " \t") {
+			language, value = value[:i], value[i+1:]
+		}
+		command := strings.Fields(value)
+		if len(command) == 0 {
+			return nil, errors.Errorf("empty local command for language '%s'", language)
+		}
+		if _, ok := commands[language]; ok {
+			return nil, errors.Errorf("more than one local command for language '%s'", language)
+		}
+		commands[language] = command
+	}
+	return commands, nil
+}
+
+func localKey(meta *dapi.Meta) string {
+	return fmt.Sprintf("%s/%s/%s", meta.Org, meta.Project, meta.Name)
+}
+
+func (h *local) Add(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
+	if len(activeTraffic(function.Traffic)) > 0 {
+		return nil, ValidationError{errors.New("the local backend does not support traffic splitting")}
+	}
+	command, err := h.command(ctx, function)
+	if err != nil {
+		return nil, err
+	}
+	h.Lock()
+	defer h.Unlock()
+
+	key := localKey(&function.Meta)
+	if _, ok := h.functions[key]; ok {
+		return nil, AlreadyExists{errors.Errorf("function '%s' already exists", key)}
+	}
+	function.Kind = dapi.FunctionKind
+	function.ID = strfmt.UUID(uuid.NewV4().String())
+	function.CreatedTime = time.Now().Unix()
+	return h.start(ctx, function, command)
+}
+
+func (h *local) Get(ctx context.Context, meta *dapi.Meta) (*dapi.Function, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	fn, ok := h.functions[localKey(meta)]
+	if !ok {
+		return nil, NotFound{errors.Errorf("function '%s' not found", localKey(meta))}
+	}
+	function := *fn.function
+	return &function, nil
+}
+
+func (h *local) Delete(ctx context.Context, meta *dapi.Meta) error {
+	h.Lock()
+	defer h.Unlock()
+
+	key := localKey(meta)
+	fn, ok := h.functions[key]
+	if !ok {
+		return NotFound{errors.Errorf("function '%s' not found", key)}
+	}
+	delete(h.functions, key)
+	h.stop(fn)
+	return nil
+}
+
+func (h *local) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Function, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	var functions []*dapi.Function
+	for _, fn := range h.functions {
+		if fn.function.Org == meta.Org && fn.function.Project == meta.Project {
+			function := *fn.function
+			functions = append(functions, &function)
+		}
+	}
+	return functions, nil
+}
+
+func (h *local) Update(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
+	if len(activeTraffic(function.Traffic)) > 0 {
+		return nil, ValidationError{errors.New("the local backend does not support traffic splitting")}
+	}
+	command, err := h.command(ctx, function)
+	if err != nil {
+		return nil, err
+	}
+	h.Lock()
+	defer h.Unlock()
+
+	key := localKey(&function.Meta)
+	fn, ok := h.functions[key]
+	if !ok {
+		return nil, NotFound{errors.Errorf("function '%s' not found", key)}
+	}
+	h.stop(fn)
+	function.Kind = dapi.FunctionKind
+	function.ID = fn.function.ID
+	function.CreatedTime = fn.function.CreatedTime
+	if function.SourceURL == "" {
+		function.SourceURL = fn.function.SourceURL
+	}
+	return h.start(ctx, function, command)
+}
+
+func (h *local) RunEndpoint(ctx context.Context, meta *dapi.Meta) (string, string, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	fn, ok := h.functions[localKey(meta)]
+	if !ok {
+		return "", "", NotFound{errors.Errorf("function '%s' not found", localKey(meta))}
+	}
+	host := fmt.Sprintf("127.0.0.1:%d", fn.port)
+	return "http://" + host, host, nil
+}
+
+// command returns the command serving a function, chosen by the language of the function image
+func (h *local) command(ctx context.Context, function *dapi.Function) ([]string, error) {
+	image, err := h.images.GetImage(ctx, function.Org, function.Image)
+	if err != nil {
+		if _, ok := err.(*client.ErrorNotFound); ok {
+			return nil, ValidationError{errors.Errorf("image '%s' not found", function.Image)}
+		}
+		return nil, errors.Wrapf(err, "fetching image of function '%s'", function.Name)
+	}
+	if command, ok := h.config.Commands[image.Language]; ok {
+		return command, nil
+	}
+	if command, ok := h.config.Commands[""]; ok {
+		return command, nil
+	}
+	return nil, ValidationError{errors.Errorf("the local backend has no command for functions in %s", image.Language)}
+}
+
+// start unpacks the function source and starts serving the function, the caller must hold the lock
+func (h *local) start(ctx context.Context, function *dapi.Function, command []string) (*dapi.Function, error) {
+	source, err := h.sources.Get(ctx, function.SourceURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching source of function '%s'", function.Name)
+	}
+	dir := filepath.Join(h.config.WorkDir, function.Org, function.Project, function.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, errors.Wrapf(err, "cleaning up source directory of function '%s'", function.Name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "creating source directory of function '%s'", function.Name)
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(source))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading source of function '%s'", function.Name)
+	}
+	if err := utils.Untar(dir, "", gr); err != nil {
+		return nil, errors.Wrapf(err, "unpacking source of function '%s'", function.Name)
+	}
+
+	processPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrapf(err, "listening for runs of function '%s'", function.Name)
+	}
+	stdout, err := newProcessLog(os.Stdout)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	stderr, err := newProcessLog(os.Stderr)
+	if err != nil {
+		listener.Close()
+		stdout.close()
+		return nil, err
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout.w
+	cmd.Stderr = stderr.w
+	cmd.Env = append(os.Environ(),
+		"PORT="+strconv.Itoa(processPort),
+		"HANDLER="+function.Handler,
+		"IMAGE="+function.ImageURL,
+		"SERVERS=1",
+		"SECRETS="+strings.Join(function.Secrets, ","),
+		"TIMEOUT="+strconv.FormatInt(function.Timeout, 10),
+	)
+	for _, env := range function.Env {
+		cmd.Env = append(cmd.Env, *env.Name+"="+env.Value)
+	}
+	if err := cmd.Start(); err != nil {
+		listener.Close()
+		stdout.close()
+		stderr.close()
+		return nil, errors.Wrapf(err, "starting function '%s'", function.Name)
+	}
+
+	stored := *function
+	stored.Source = nil
+	stored.Status = dapi.StatusINITIALIZED
+	stored.Reason = nil
+	stored.ModifiedTime = time.Now().Unix()
+	fn := &localFunction{
+		function:    &stored,
+		dir:         dir,
+		port:        listener.Addr().(*net.TCPAddr).Port,
+		processPort: processPort,
+		cmd:         cmd,
+		stdout:      stdout,
+		stderr:      stderr,
+		exited:      make(chan struct{}),
+	}
+	fn.proxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", processPort)})
+	fn.proxy.ModifyResponse = fn.wrapResponse
+	fn.server = &http.Server{Handler: fn}
+	go fn.server.Serve(listener)
+	h.functions[localKey(&function.Meta)] = fn
+	go h.watch(fn)
+
+	created := stored
+	return &created, nil
+}
+
+// watch tracks the status of a function process
+func (h *local) watch(fn *localFunction) {
+	go func() {
+		err := fn.cmd.Wait()
+		fn.stdout.close()
+		fn.stderr.close()
+		close(fn.exited)
+		h.setStatus(fn, dapi.StatusERROR, fmt.Sprintf("function process exited: %v", err))
+	}()
+
+	address := fmt.Sprintf("127.0.0.1:%d", fn.processPort)
+	deadline := time.After(localReadyTimeout)
+	for {
+		select {
+		case <-fn.exited:
+			return
+		case <-deadline:
+			h.setStatus(fn, dapi.StatusERROR, "function did not start listening in time")
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			h.setStatus(fn, dapi.StatusREADY, "")
+			return
+		}
+	}
+}
+
+func (h *local) setStatus(fn *localFunction, status dapi.Status, reason string) {
+	h.Lock()
+	defer h.Unlock()
+
+	// Status changes of stopped processes no longer matter
+	if h.functions[localKey(&fn.function.Meta)] != fn {
+		return
+	}
+	fn.function.Status = status
+	fn.function.ModifiedTime = time.Now().Unix()
+	if reason != "" {
+		log.Errorf("function %s: %s", localKey(&fn.function.Meta), reason)
+		fn.function.Reason = []string{reason}
+	}
+}
+
+// stop kills the function process and removes its source directory
+func (h *local) stop(fn *localFunction) {
+	if err := fn.cmd.Process.Kill(); err != nil {
+		log.Debugf("killing function %s: %v", fn.function.Name, err)
+	}
+	<-fn.exited
+	fn.server.Close()
+	if err := os.RemoveAll(fn.dir); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "removing source directory of function '%s'", fn.function.Name))
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, errors.Wrap(err, "finding a free port")
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// ServeHTTP proxies requests to the function process.  Responses to runs asking for an envelope are wrapped in one,
+// with the lines the process wrote to stdout and stderr during the run, unless the process wrapped them itself.
+func (fn *localFunction) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(EnvelopeHeader) == "true" {
+		fn.runLock.Lock()
+		defer fn.runLock.Unlock()
+		fn.stdout.capture()
+		fn.stderr.capture()
+		// Stops capturing if the response was not wrapped
+		defer fn.stdout.lines()
+		defer fn.stderr.lines()
+	}
+	fn.proxy.ServeHTTP(w, r)
+}
+
+func (fn *localFunction) wrapResponse(response *http.Response) error {
+	if response.Request.Header.Get(EnvelopeHeader) != "true" {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type")); err == nil && mediaType == EnvelopeContentType {
+		return nil
+	}
+	output, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return errors.Wrapf(err, "reading output of function '%s'", fn.function.Name)
+	}
+	body, err := json.Marshal(&Envelope{
+		ContentType: response.Header.Get("Content-Type"),
+		Output:      output,
+		Logs:        &dapi.Logs{Stdout: fn.stdout.lines(), Stderr: fn.stderr.lines()},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "wrapping output of function '%s'", fn.function.Name)
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	response.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	response.Header.Set("Content-Type", EnvelopeContentType)
+	return nil
+}
+
+// processLog passes the output of a function process on to the output of the server, and captures the lines written
+// during a run.  The server holds the pipe open too, to write a marker after the run: once it reads the marker back, it
+// has read all the process wrote before responding.
+type processLog struct {
+	out    io.Writer
+	r, w   *os.File
+	marker string
+	synced chan struct{}
+
+	sync.Mutex
+	capturing bool
+	captured  []string
+}
+
+func newProcessLog(out io.Writer) (*processLog, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating pipe for function output")
+	}
+	l := &processLog{
+		out:    out,
+		r:      r,
+		w:      w,
+		marker: "dispatch-run-" + uuid.NewV4().String(),
+		synced: make(chan struct{}, 1),
+	}
+	go l.copy()
+	return l, nil
+}
+
+func (l *processLog) copy() {
+	defer l.r.Close()
+	reader := bufio.NewReader(l.r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			// The marker follows a partial line, if the process wrote one
+			if strings.HasSuffix(line, l.marker) {
+				if line = strings.TrimSuffix(line, l.marker); line != "" {
+					l.write(line)
+				}
+				select {
+				case l.synced <- struct{}{}:
+				default:
+				}
+			} else {
+				l.write(line)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (l *processLog) write(line string) {
+	fmt.Fprintln(l.out, line)
+	l.Lock()
+	defer l.Unlock()
+	if l.capturing {
+		l.captured = append(l.captured, line)
+	}
+}
+
+// capture starts capturing the lines of a run
+func (l *processLog) capture() {
+	l.Lock()
+	defer l.Unlock()
+	l.capturing = true
+	l.captured = []string{}
+}
+
+// lines stops capturing and returns the lines of the run, once the process output up to now is read
+func (l *processLog) lines() []string {
+	l.Lock()
+	capturing := l.capturing
+	l.Unlock()
+	if !capturing {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(l.w, l.marker); err == nil {
+		select {
+		case <-l.synced:
+		case <-time.After(localLogsTimeout):
+			log.Debugf("timed out waiting for function output")
+		}
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	lines := l.captured
+	l.capturing = false
+	l.captured = nil
+	return lines
+}
+
+// close closes the pipe once the process is gone, output still in the pipe is passed on
+func (l *processLog) close() {
+	l.w.Close()
+}
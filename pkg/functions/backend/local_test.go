@@ -0,0 +1,262 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+	"github.com/vmware/dispatch/pkg/functions/config"
+	"github.com/vmware/dispatch/pkg/functions/sourcestore"
+	"github.com/vmware/dispatch/pkg/testing/dev"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// TestLocalHelperProcess is the function process started by the local backend in tests
+func TestLocalHelperProcess(t *testing.T) {
+	if os.Getenv("DISPATCH_LOCAL_HELPER") != "1" {
+		return
+	}
+	source, _ := ioutil.ReadFile("handler.txt")
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("running %s\n", os.Getenv("HANDLER"))
+		fmt.Fprintf(os.Stderr, "source %s\n", source)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s:%s", os.Getenv("HANDLER"), source)
+	})
+	http.ListenAndServe("127.0.0.1:"+os.Getenv("PORT"), nil)
+	os.Exit(0)
+}
+
+func putLocalSource(t *testing.T, store sourcestore.SourceStore, content string) string {
+	dir, err := ioutil.TempDir("", "local-source")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "handler.txt"), []byte(content), 0644))
+	return putLocalDir(t, store, dir)
+}
+
+func putLocalDir(t *testing.T, store sourcestore.SourceStore, dir string) string {
+	source, err := utils.TarGzBytes(dir)
+	require.NoError(t, err)
+	url, err := store.Put(context.Background(), testOrg, testProject, source)
+	require.NoError(t, err)
+	return url
+}
+
+func waitLocalStatus(t *testing.T, b Backend, meta *v1.Meta, status v1.Status) {
+	for i := 0; i < 100; i++ {
+		function, err := b.Get(context.Background(), meta)
+		require.NoError(t, err)
+		if function.Status == status {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("function %s did not become %s", meta.Name, status)
+}
+
+func callLocal(t *testing.T, b Backend, meta *v1.Meta) string {
+	url, _, err := b.RunEndpoint(context.Background(), meta)
+	require.NoError(t, err)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func callLocalEnvelope(t *testing.T, b Backend, meta *v1.Meta) *Envelope {
+	url, _, err := b.RunEndpoint(context.Background(), meta)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", url, nil)
+	require.NoError(t, err)
+	req.Header.Set(EnvelopeHeader, "true")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, EnvelopeContentType, resp.Header.Get("Content-Type"))
+	envelope := new(Envelope)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(envelope))
+	return envelope
+}
+
+// localImages returns an images client knowing images by language, named after it
+func localImages(languages ...string) *mocks.ImagesClient {
+	images := &mocks.ImagesClient{}
+	for _, language := range languages {
+		images.On("GetImage", mock.Anything, testOrg, language).Return(&v1.Image{Meta: v1.Meta{Name: language}, Language: language}, nil)
+	}
+	images.On("GetImage", mock.Anything, testOrg, mock.Anything).Return(nil, client.NewErrorNotFound(&v1.Error{Code: 404}))
+	return images
+}
+
+func TestLocal(t *testing.T) {
+	root, err := ioutil.TempDir("", "local-backend")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	ctx := context.Background()
+	store := sourcestore.NewFile(&config.StorageFileConfig{SourceRootPath: filepath.Join(root, "sources")})
+	b := Local(&LocalConfig{
+		WorkDir: filepath.Join(root, "functions"),
+		Commands: map[string][]string{
+			"go": {"env", "DISPATCH_LOCAL_HELPER=1", os.Args[0], "-test.run=TestLocalHelperProcess"},
+			"":   {"false"},
+		},
+	}, store, localImages("go"))
+
+	function := f1()
+	function.Image = "go"
+	function.Handler = "main"
+	function.SourceURL = putLocalSource(t, store, "v1")
+	created, err := b.Add(ctx, function)
+	require.NoError(t, err)
+	assert.Equal(t, v1.StatusINITIALIZED, created.Status)
+
+	_, err = b.Add(ctx, function)
+	assert.IsType(t, AlreadyExists{}, err)
+
+	meta := &function.Meta
+	waitLocalStatus(t, b, meta, v1.StatusREADY)
+	assert.Equal(t, "main:v1", callLocal(t, b, meta))
+
+	// Runs get the output of the process during the run
+	for i := 0; i < 3; i++ {
+		envelope := callLocalEnvelope(t, b, meta)
+		assert.Equal(t, "text/plain", envelope.ContentType)
+		assert.Equal(t, "main:v1", string(envelope.Output))
+		require.NotNil(t, envelope.Logs)
+		assert.Equal(t, []string{"running main"}, envelope.Logs.Stdout)
+		assert.Equal(t, []string{"source v1"}, envelope.Logs.Stderr)
+	}
+
+	functions, err := b.List(ctx, &v1.Meta{Org: testOrg, Project: testProject})
+	require.NoError(t, err)
+	assert.Len(t, functions, 1)
+
+	function.SourceURL = putLocalSource(t, store, "v2")
+	updated, err := b.Update(ctx, function)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	waitLocalStatus(t, b, meta, v1.StatusREADY)
+	assert.Equal(t, "main:v2", callLocal(t, b, meta))
+
+	require.NoError(t, b.Delete(ctx, meta))
+	_, err = b.Get(ctx, meta)
+	assert.IsType(t, NotFound{}, err)
+	_, err = os.Stat(filepath.Join(root, "functions", testOrg, testProject, function.Name))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLocalRejectsTraffic(t *testing.T) {
+	b := Local(&LocalConfig{}, nil, localImages())
+	function := f1()
+	function.Traffic = []*v1.TrafficTarget{
+		{Revision: &function.Name, Percent: 50},
+		{Revision: &function.Name, Percent: 50},
+	}
+	_, err := b.Add(context.Background(), function)
+	assert.IsType(t, ValidationError{}, err)
+}
+
+func TestLocalCommand(t *testing.T) {
+	b := Local(&LocalConfig{
+		Commands: map[string][]string{"python3": {"python3", "server.py"}, "": {"serve"}},
+	}, nil, localImages("python3", "nodejs")).(*local)
+
+	function := f1()
+	function.Image = "python3"
+	command, err := b.command(context.Background(), function)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python3", "server.py"}, command)
+
+	function.Image = "nodejs"
+	command, err = b.command(context.Background(), function)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"serve"}, command)
+
+	delete(b.config.Commands, "")
+	_, err = b.command(context.Background(), function)
+	assert.IsType(t, ValidationError{}, err)
+
+	function.Image = "missing"
+	_, err = b.command(context.Background(), function)
+	assert.IsType(t, ValidationError{}, err)
+}
+
+func TestParseLocalCommands(t *testing.T) {
+	commands, err := ParseLocalCommands([]string{
+		"python3=python3 /opt/runtime/server.py",
+		"env PORT_FILE=port serve",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"python3": {"python3", "/opt/runtime/server.py"},
+		"":        {"env", "PORT_FILE=port", "serve"},
+	}, commands)
+
+	_, err = ParseLocalCommands(nil)
+	assert.Error(t, err)
+	_, err = ParseLocalCommands([]string{"nodejs="})
+	assert.Error(t, err)
+	_, err = ParseLocalCommands([]string{"serve", "serve --other"})
+	assert.Error(t, err)
+}
+
+// TestLocalExamples runs the example functions with the commands of the local backend in DEVLOCALCOMMANDS
+func TestLocalExamples(t *testing.T) {
+	dev.EnsureLocal(t)
+	if dev.FunctionsBackend() != "local" {
+		t.Skip("run with DEVFUNCTIONSBACKEND=local")
+	}
+	commands, err := ParseLocalCommands(dev.LocalCommands())
+	require.NoError(t, err)
+
+	root, err := ioutil.TempDir("", "local-backend")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	ctx := context.Background()
+	store := sourcestore.NewFile(&config.StorageFileConfig{SourceRootPath: filepath.Join(root, "sources")})
+	b := Local(&LocalConfig{WorkDir: filepath.Join(root, "functions"), Commands: commands}, store, localImages("nodejs", "python3"))
+
+	for language, handler := range map[string]string{"nodejs": "hello.js", "python3": "hello.handle"} {
+		function := f1()
+		function.Name = "hello-" + language
+		function.Image = language
+		function.Handler = handler
+		function.SourceURL = putLocalDir(t, store, filepath.Join("..", "..", "..", "examples", language))
+		_, err := b.Add(ctx, function)
+		require.NoError(t, err)
+		waitLocalStatus(t, b, &function.Meta, v1.StatusREADY)
+		url, _, err := b.RunEndpoint(ctx, &function.Meta)
+		require.NoError(t, err)
+		resp, err := http.Post(url, "application/json", strings.NewReader(`{"name": "Jon", "place": "Winterfell"}`))
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "Hello, Jon from Winterfell", language)
+		require.NoError(t, b.Delete(ctx, &function.Meta))
+	}
+}
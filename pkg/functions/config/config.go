@@ -5,6 +5,8 @@
 
 package config
 
+import "time"
+
 // NO TESTS
 
 // StorageType is a storage label
@@ -41,3 +43,21 @@ type StorageConfig struct {
 	File    *StorageFileConfig
 	Minio   *StorageMinioConfig
 }
+
+// RunRetentionConfig contains the limits for keeping function run history
+type RunRetentionConfig struct {
+	// MaxAge is how long a run is kept after it was executed, 0 keeps runs forever
+	MaxAge time.Duration
+	// MaxCount is the number of most recent runs kept per function, 0 keeps all runs
+	MaxCount int
+}
+
+// RunnerConfig contains the settings for executing runs
+type RunnerConfig struct {
+	// Workers is the number of non-blocking runs executed concurrently
+	Workers int
+	// QueueSize is the number of non-blocking runs waiting for a worker before new runs are rejected
+	QueueSize int
+	// ValidateOutput checks run outputs against the output schema of their function
+	ValidateOutput bool
+}
@@ -0,0 +1,85 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// FnRun is the function run entity type
+type FnRun struct {
+	entitystore.BaseEntity
+	Project      string                 `json:"project"`
+	FunctionName string                 `json:"functionName"`
+	FunctionID   string                 `json:"functionID"`
+	Blocking     bool                   `json:"blocking"`
+	HTTPContext  map[string]interface{} `json:"httpContext,omitempty"`
+	InputSize    int64                  `json:"inputSize"`
+	Output       []byte                 `json:"output,omitempty"`
+	OutputSize   int64                  `json:"outputSize"`
+	Secrets      []string               `json:"secrets,omitempty"`
+	Event        *dapi.CloudEvent       `json:"event,omitempty"`
+	Logs         *dapi.Logs             `json:"logs,omitempty"`
+	Error        *dapi.InvocationError  `json:"error,omitempty"`
+	ExecutedTime time.Time              `json:"executedTime"`
+	FinishedTime time.Time              `json:"finishedTime,omitempty"`
+}
+
+// ToModel converts a run entity into the API model
+func (r *FnRun) ToModel() *dapi.Run {
+	var tags []*dapi.Tag
+	for k, v := range r.Tags {
+		tags = append(tags, &dapi.Tag{Key: k, Value: v})
+	}
+	m := dapi.Run{
+		Name:         strfmt.UUID(r.Name),
+		FunctionName: r.FunctionName,
+		FunctionID:   r.FunctionID,
+		Blocking:     r.Blocking,
+		HTTPContext:  r.HTTPContext,
+		InputSize:    r.InputSize,
+		OutputBytes:  r.Output,
+		OutputSize:   r.OutputSize,
+		Secrets:      r.Secrets,
+		Event:        r.Event,
+		Logs:         r.Logs,
+		Error:        r.Error,
+		Status:       dapi.Status(r.Status),
+		Reason:       r.Reason,
+		ExecutedTime: r.ExecutedTime.Unix(),
+		Tags:         tags,
+	}
+	if !r.FinishedTime.IsZero() {
+		m.FinishedTime = r.FinishedTime.Unix()
+		m.Duration = int64(r.FinishedTime.Sub(r.ExecutedTime) / time.Millisecond)
+	}
+	return &m
+}
+
+// FromModel populates a run entity from the API model
+func (r *FnRun) FromModel(m *dapi.Run, orgID, project string) {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	r.BaseEntity.OrganizationID = orgID
+	r.BaseEntity.Name = m.Name.String()
+	r.BaseEntity.Status = entitystore.Status(m.Status)
+	r.BaseEntity.Tags = tags
+	r.Project = project
+	r.FunctionName = m.FunctionName
+	r.FunctionID = m.FunctionID
+	r.Blocking = m.Blocking
+	r.HTTPContext = m.HTTPContext
+	r.InputSize = int64(len(m.InputBytes))
+	r.Secrets = m.Secrets
+	r.Event = m.Event
+}
@@ -0,0 +1,30 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+import (
+	"encoding/json"
+	"mime"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/functions/backend"
+)
+
+// isEnvelope tells if a response of the given content type is an envelope
+func isEnvelope(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == backend.EnvelopeContentType
+}
+
+// unwrapEnvelope decodes an envelope response
+func unwrapEnvelope(body []byte) (*backend.Envelope, error) {
+	envelope := new(backend.Envelope)
+	if err := json.Unmarshal(body, envelope); err != nil {
+		return nil, errors.Wrap(err, "decoding function response envelope")
+	}
+	return envelope, nil
+}
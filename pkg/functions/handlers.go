@@ -7,32 +7,39 @@ package functions
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/url"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
-	minio "github.com/minio/minio-go"
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 
 	dapi "github.com/vmware/dispatch/pkg/api/v1"
 	"github.com/vmware/dispatch/pkg/client"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/functions/backend"
 	"github.com/vmware/dispatch/pkg/functions/config"
 	"github.com/vmware/dispatch/pkg/functions/gen/restapi/operations"
 	fnrunner "github.com/vmware/dispatch/pkg/functions/gen/restapi/operations/runner"
 	fnstore "github.com/vmware/dispatch/pkg/functions/gen/restapi/operations/store"
+	"github.com/vmware/dispatch/pkg/functions/sourcestore"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
 )
 
+// defaultRunTimeout limits runs of functions which have no timeout of their own
+const defaultRunTimeout = 60 * time.Second
+
 //Handlers interface declares methods needed to implement functions API
 type Handlers interface {
 	addFunction(params fnstore.AddFunctionParams) middleware.Responder
@@ -65,75 +72,35 @@ func ConfigureHandlers(api middleware.RoutableAPI, h Handlers) {
 
 type defaultHandlers struct {
 	backend       backend.Backend
+	store         entitystore.EntityStore
 	httpClient    *http.Client
 	namespace     string
 	imageRegistry string
-	storageConfig *config.StorageConfig
+	sourceStore   sourcestore.SourceStore
 	imagesClient  client.ImagesClient
+	workers       *workerPool
+	// validateOutput checks function outputs against the output schema of the function
+	validateOutput bool
+	sourceLocks    sourceLocks
 }
 
 // NewHandlers is the constructor for the function manager API knHandlers
-func NewHandlers(kubeconfPath, namespace, imageRegistry, ingressGateway, buildImage string, storageConfig *config.StorageConfig, imagesClient client.ImagesClient) Handlers {
+func NewHandlers(namespace, imageRegistry string, functionsBackend backend.Backend, sourceStore sourcestore.SourceStore, imagesClient client.ImagesClient, store entitystore.EntityStore, runnerConfig *config.RunnerConfig) Handlers {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
 
 	return &defaultHandlers{
-		backend:       backend.Knative(kubeconfPath, ingressGateway, buildImage, storageConfig),
+		backend:       functionsBackend,
+		store:         store,
 		httpClient:    &http.Client{Transport: tr},
 		namespace:     namespace,
 		imageRegistry: imageRegistry,
 		imagesClient:  imagesClient,
-		storageConfig: storageConfig,
-	}
-}
-
-func (h *defaultHandlers) writeSource(sourceID, org, project string, source []byte) (*url.URL, error) {
-	name := fmt.Sprintf("%s.tgz", sourceID)
-	switch h.storageConfig.Storage {
-	case config.Minio:
-		minioClient, err := minio.New(
-			h.storageConfig.Minio.MinioAddress, h.storageConfig.Minio.Username, h.storageConfig.Minio.Password, false)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create minio client")
-		}
-		contentType := "application/tar+gz"
-		bucketName := fmt.Sprintf("%s-%s", org, project)
-
-		err = minioClient.MakeBucket(bucketName, string(h.storageConfig.Minio.Location))
-		if err != nil {
-			_, err := minioClient.BucketExists(bucketName)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to create minio bucket")
-			}
-		}
+		sourceStore:   sourceStore,
+		workers:       newWorkerPool(runnerConfig.Workers, runnerConfig.QueueSize),
 
-		tmpfile, err := ioutil.TempFile("", name)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create temp file")
-		}
-		defer os.Remove(tmpfile.Name())
-
-		err = ioutil.WriteFile(tmpfile.Name(), source, 0600)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to write temp file")
-		}
-		_, err = minioClient.FPutObject(bucketName, name, tmpfile.Name(), minio.PutObjectOptions{ContentType: contentType})
-		if err != nil {
-			return nil, errors.Wrapf(err, "error putting file to minio bucket")
-		}
-		return url.Parse(fmt.Sprintf("minio://%s/%s/%s", h.storageConfig.Minio.MinioAddress, bucketName, name))
-	case config.File:
-		sourceDir := fmt.Sprintf("%s/%s/%s", h.storageConfig.File.SourceRootPath, org, project)
-		sourcePath := fmt.Sprintf("%s/%s", sourceDir, name)
-		os.MkdirAll(sourceDir, 0700)
-		err := ioutil.WriteFile(sourcePath, source, 0600)
-		if err != nil {
-			return nil, errors.Wrapf(err, "error writing file")
-		}
-		return url.Parse(fmt.Sprintf("file://%s/%s", sourcePath, name))
-	default:
-		return nil, fmt.Errorf("unknown source URL scheme: %s", h.storageConfig.Storage)
+		validateOutput: runnerConfig.ValidateOutput,
 	}
 }
 
@@ -164,31 +131,39 @@ func (h *defaultHandlers) addFunction(params fnstore.AddFunctionParams) middlewa
 	function.ImageURL = img.ImageURL
 	log.Debugf("fetched image url %s for image %s and function %s", img.ImageURL, function.Image, function.Name)
 
-	sourceID := uuid.NewV4().String()
-	u, err := h.writeSource(sourceID, org, project, function.Source)
+	unlock := h.sourceLocks.lock(sourceKey(sourcestore.ObjectName(function.Source)))
+	sourceURL, err := h.sourceStore.Put(ctx, org, project, function.Source)
 	if err != nil {
+		unlock()
 		log.Errorf("%+v", errors.Wrap(err, "writing function source"))
 		return fnstore.NewAddFunctionDefault(500).WithPayload(&dapi.Error{
 			Code:    http.StatusInternalServerError,
 			Message: utils.ErrorMsgInternalError("function", function.Meta.Name),
 		})
 	}
-	sourceURL := u.String()
 	// Once saved, unset source as we don't need it anymore
 	// What is the best way to transmit source?  Probably not through JSON like
 	// we are doing.
 	function.Source = nil
 	function.SourceURL = sourceURL
-	function.FunctionImageURL = fmt.Sprintf("%s/%s", h.imageRegistry, sourceID)
+	function.FunctionImageURL = fmt.Sprintf("%s/%s", h.imageRegistry, uuid.NewV4().String())
 
 	createdFunction, err := h.backend.Add(ctx, function)
+	unlock()
 	if err != nil {
+		h.discardSource(ctx, &function.Meta, sourceURL)
 		if _, ok := err.(backend.AlreadyExists); ok {
 			return fnstore.NewAddFunctionConflict().WithPayload(&dapi.Error{
 				Code:    http.StatusConflict,
 				Message: utils.ErrorMsgAlreadyExists("function", function.Meta.Name),
 			})
 		}
+		if _, ok := err.(backend.ValidationError); ok {
+			return fnstore.NewAddFunctionBadRequest().WithPayload(&dapi.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+		}
 		log.Errorf("%+v", errors.Wrap(err, "creating a function"))
 		return fnstore.NewAddFunctionDefault(500).WithPayload(&dapi.Error{
 			Code:    http.StatusInternalServerError,
@@ -209,7 +184,15 @@ func (h *defaultHandlers) getFunction(params fnstore.GetFunctionParams) middlewa
 	name := params.FunctionName
 	log.Debugf("getting function %s in %s:%s", name, org, project)
 
-	function, err := h.backend.Get(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+	var function *dapi.Function
+	err := utils.WaitForStatus(ctx, params.WaitFor, params.WaitTimeout, func() (dapi.Status, error) {
+		var err error
+		function, err = h.backend.Get(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+		if err != nil {
+			return "", err
+		}
+		return function.Status, nil
+	})
 	if err != nil {
 		if _, ok := err.(backend.NotFound); ok {
 			return fnstore.NewGetFunctionNotFound().WithPayload(&dapi.Error{
@@ -217,7 +200,11 @@ func (h *defaultHandlers) getFunction(params fnstore.GetFunctionParams) middlewa
 				Message: utils.ErrorMsgNotFound("function", name),
 			})
 		}
-		errors.Wrapf(err, "getting function '%s'", name)
+		log.Errorf("%+v", errors.Wrapf(err, "getting function '%s'", name))
+		return fnstore.NewGetFunctionDefault(500).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("function", name),
+		})
 	}
 
 	return fnstore.NewGetFunctionOK().WithPayload(function)
@@ -231,8 +218,12 @@ func (h *defaultHandlers) deleteFunction(params fnstore.DeleteFunctionParams) mi
 	project := *params.XDispatchProject
 
 	name := params.FunctionName
+	meta := &dapi.Meta{Name: name, Org: org, Project: project}
 
-	err := h.backend.Delete(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+	function, err := h.backend.Get(ctx, meta)
+	if err == nil {
+		err = h.backend.Delete(ctx, meta)
+	}
 	if err != nil {
 		if _, ok := err.(backend.NotFound); ok {
 			return fnstore.NewDeleteFunctionNotFound().WithPayload(&dapi.Error{
@@ -240,12 +231,72 @@ func (h *defaultHandlers) deleteFunction(params fnstore.DeleteFunctionParams) mi
 				Message: utils.ErrorMsgNotFound("function", name),
 			})
 		}
-		errors.Wrapf(err, "deleting function '%s'", name)
+		log.Errorf("%+v", errors.Wrapf(err, "deleting function '%s'", name))
+		return fnstore.NewDeleteFunctionDefault(500).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("function", name),
+		})
+	}
+
+	sourceURLs := []string{function.SourceURL}
+	for _, revision := range function.Revisions {
+		sourceURLs = append(sourceURLs, revision.SourceURL)
+	}
+	if err := h.collectSources(ctx, meta, sourceURLs); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "collecting sources of function '%s'", name))
 	}
 
 	return fnstore.NewDeleteFunctionOK()
 }
 
+// discardSource deletes the source archive stored for a function which the backend failed to create or update, unless
+// another function of the project, or the existing function, uses it
+func (h *defaultHandlers) discardSource(ctx context.Context, meta *dapi.Meta, sourceURL string) {
+	if sourceURL == "" {
+		return
+	}
+	if err := h.collectSources(ctx, &dapi.Meta{Org: meta.Org, Project: meta.Project}, []string{sourceURL}); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "collecting source of function '%s'", meta.Name))
+	}
+}
+
+// collectSources deletes the source archives of a deleted function unless another function of the project, or one of
+// its revisions, still uses them. When meta has no name, the archives are kept if any function of the project uses them.
+func (h *defaultHandlers) collectSources(ctx context.Context, meta *dapi.Meta, sourceURLs []string) error {
+	var keys []string
+	for _, sourceURL := range sourceURLs {
+		if sourceURL != "" {
+			keys = append(keys, sourceKey(sourceURL))
+		}
+	}
+	defer h.sourceLocks.lock(keys...)()
+
+	functions, err := h.backend.List(ctx, &dapi.Meta{Org: meta.Org, Project: meta.Project})
+	if err != nil {
+		return errors.Wrap(err, "listing functions")
+	}
+	used := make(map[string]bool)
+	for _, function := range functions {
+		if function.Name == meta.Name {
+			continue
+		}
+		used[function.SourceURL] = true
+		for _, revision := range function.Revisions {
+			used[revision.SourceURL] = true
+		}
+	}
+	for _, sourceURL := range sourceURLs {
+		if sourceURL == "" || used[sourceURL] {
+			continue
+		}
+		used[sourceURL] = true
+		if err := h.sourceStore.Delete(ctx, sourceURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *defaultHandlers) getFunctions(params fnstore.GetFunctionsParams) middleware.Responder {
 	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
 	defer span.Finish()
@@ -275,14 +326,89 @@ func (h *defaultHandlers) updateFunction(params fnstore.UpdateFunctionParams) mi
 	function := params.Body
 	utils.AdjustMeta(&function.Meta, dapi.Meta{Org: org, Project: project})
 
+	existing, err := h.backend.Get(ctx, &function.Meta)
+	if err != nil {
+		if _, ok := err.(backend.NotFound); ok {
+			return fnstore.NewUpdateFunctionNotFound().WithPayload(&dapi.Error{
+				Code:    http.StatusNotFound,
+				Message: utils.ErrorMsgNotFound("function", function.Meta.Name),
+			})
+		}
+		log.Errorf("%+v", errors.Wrap(err, "getting function to update"))
+		return fnstore.NewUpdateFunctionDefault(500).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("function", function.Meta.Name),
+		})
+	}
+
+	// A function is rebuilt when its image or its source changes, otherwise it keeps its built image
+	rebuild := false
+	if function.Image == "" {
+		function.Image = existing.Image
+	}
+	if function.Image == existing.Image {
+		function.ImageURL = existing.ImageURL
+	} else {
+		img, err := h.imagesClient.GetImage(ctx, org, function.Image)
+		if err != nil {
+			if err, ok := err.(client.Error); ok {
+				return fnstore.NewUpdateFunctionDefault(err.Code()).WithPayload(&dapi.Error{
+					Code:    int64(err.Code()),
+					Message: swag.String(err.Message()),
+				})
+			}
+			log.Errorf("%+v", errors.Wrap(err, "fetching image for function"))
+			return fnstore.NewUpdateFunctionDefault(500).WithPayload(&dapi.Error{
+				Code:    http.StatusInternalServerError,
+				Message: utils.ErrorMsgInternalError("function", function.Meta.Name),
+			})
+		}
+		function.ImageURL = img.ImageURL
+		rebuild = true
+	}
+
+	newSourceURL := ""
+	unlock := func() {}
+	if function.Source != nil {
+		unlock = h.sourceLocks.lock(sourceKey(sourcestore.ObjectName(function.Source)))
+		sourceURL, err := h.sourceStore.Put(ctx, org, project, function.Source)
+		if err != nil {
+			unlock()
+			log.Errorf("%+v", errors.Wrap(err, "writing function source"))
+			return fnstore.NewUpdateFunctionDefault(500).WithPayload(&dapi.Error{
+				Code:    http.StatusInternalServerError,
+				Message: utils.ErrorMsgInternalError("function", function.Meta.Name),
+			})
+		}
+		function.Source = nil
+		function.SourceURL = sourceURL
+		newSourceURL = sourceURL
+		rebuild = true
+	} else if function.SourceURL == "" {
+		function.SourceURL = existing.SourceURL
+	}
+	if rebuild {
+		function.FunctionImageURL = fmt.Sprintf("%s/%s", h.imageRegistry, uuid.NewV4().String())
+	} else if function.FunctionImageURL == "" {
+		function.FunctionImageURL = existing.FunctionImageURL
+	}
+
 	updatedFunction, err := h.backend.Update(ctx, function)
+	unlock()
 	if err != nil {
+		h.discardSource(ctx, &function.Meta, newSourceURL)
 		if _, ok := err.(backend.NotFound); ok {
 			return fnstore.NewUpdateFunctionNotFound().WithPayload(&dapi.Error{
 				Code:    http.StatusNotFound,
 				Message: utils.ErrorMsgNotFound("function", function.Meta.Name),
 			})
 		}
+		if _, ok := err.(backend.ValidationError); ok {
+			return fnstore.NewUpdateFunctionBadRequest().WithPayload(&dapi.Error{
+				Code:    http.StatusBadRequest,
+				Message: swag.String(err.Error()),
+			})
+		}
 		log.Errorf("%+v", errors.Wrap(err, "updating a function"))
 		return fnstore.NewUpdateFunctionDefault(500).WithPayload(&dapi.Error{
 			Code:    http.StatusInternalServerError,
@@ -301,73 +427,417 @@ func (h *defaultHandlers) runFunction(params fnrunner.RunFunctionParams) middlew
 	project := *params.XDispatchProject
 	name := *params.FunctionName
 
-	contentType := params.Body.HTTPContext["Content-Type"].(string)
-	accept := params.Body.HTTPContext["Accept"].(string)
-	inBytes := params.Body.InputBytes
+	run := params.Body
+	run.Name = strfmt.UUID(uuid.NewV4().String())
+	run.FunctionName = name
+	run.Status = dapi.StatusINITIALIZED
+	if err := prepareInput(run); err != nil {
+		run.Status = dapi.StatusERROR
+		run.Error = &dapi.InvocationError{
+			Type:    dapi.ErrorTypeInputError,
+			Message: swag.String(err.Error()),
+		}
+		return fnrunner.NewRunFunctionBadRequest().WithPayload(run)
+	}
+
+	runEntity := new(FnRun)
+	runEntity.FromModel(run, org, project)
+	runEntity.ExecutedTime = time.Now()
+	if _, err := h.store.Add(ctx, runEntity); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "recording run of function '%s'", name))
+	}
 
 	log.Debugf("running function %s:%s:%s", org, project, name)
-	runHost, runEndpoint, err := h.backend.RunEndpoint(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
-	if err != nil {
-		if _, ok := err.(backend.NotFound); ok {
-			return fnrunner.NewRunFunctionNotFound().WithPayload(&dapi.Error{
-				Code:    http.StatusNotFound,
-				Message: utils.ErrorMsgNotFound("function", name),
-			})
+	endpoint, runErr := h.runEndpoint(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+	if runErr == nil {
+		runErr = checkInput(name, endpoint, run)
+	}
+	if runErr == nil && !run.Blocking {
+		// The request context is done once we respond, so the run continues in a context of its own
+		asyncCtx := opentracing.ContextWithSpan(context.Background(), span)
+		accepted := runEntity.ToModel()
+		submitted := h.workers.submit(func() {
+			span, ctx := trace.Trace(asyncCtx, "Async Run")
+			defer span.Finish()
+			out, runErr := h.invoke(ctx, name, endpoint, run)
+			h.finishRun(ctx, runEntity, out, runErr)
+		})
+		if submitted {
+			return fnrunner.NewRunFunctionAccepted().WithPayload(accepted)
+		}
+		runErr = &runError{
+			error:   errors.Errorf("run queue is full, rejecting run of function '%s'", name),
+			code:    http.StatusServiceUnavailable,
+			errType: dapi.ErrorTypeSystemError,
+			message: swag.String("too many function runs in progress, try again later"),
+		}
+	}
+
+	var out *runOutput
+	if runErr == nil {
+		out, runErr = h.invoke(ctx, name, endpoint, run)
+	}
+	h.finishRun(ctx, runEntity, out, runErr)
+	if runErr != nil {
+		if runErr.code == http.StatusBadRequest {
+			// The rejected run has the field errors of the input
+			return fnrunner.NewRunFunctionBadRequest().WithPayload(runEntity.ToModel())
 		}
-		errors.Wrapf(err, "getting function '%s'", name)
+		if runErr.code == http.StatusNotFound {
+			return fnrunner.NewRunFunctionNotFound().WithPayload(runErr.payload())
+		}
+		if runErr.code == http.StatusBadGateway {
+			// The failed run keeps what the function logged before failing
+			return fnrunner.NewRunFunctionBadGateway().WithPayload(runEntity.ToModel())
+		}
+		return fnrunner.NewRunFunctionDefault(runErr.code).WithPayload(runErr.payload())
+	}
+
+	return fnrunner.NewRunFunctionOK().WithPayload(runEntity.ToModel())
+}
+
+// runError describes a failed invocation and the response code it maps to
+type runError struct {
+	error
+	code    int
+	errType dapi.ErrorType
+	message *string
+	// fieldErrors are the schema violations of the input or output
+	fieldErrors []string
+	// stacktrace is the stack trace of a function error, if the function reported one
+	stacktrace []string
+}
+
+// runOutput is what a function returned
+type runOutput struct {
+	contentType string
+	output      []byte
+	logs        *dapi.Logs
+}
+
+func (e *runError) payload() *dapi.Error {
+	return &dapi.Error{
+		Code:    int64(e.code),
+		Message: e.message,
+	}
+}
+
+func timeoutError(name string, timeout time.Duration) *runError {
+	return &runError{
+		error:   errors.Errorf("function '%s' did not finish within %s", name, timeout),
+		code:    http.StatusGatewayTimeout,
+		errType: dapi.ErrorTypeTimeoutError,
+		message: swag.String(fmt.Sprintf("function %s timed out after %s", name, timeout)),
 	}
-	req, err := http.NewRequest("POST", runHost, bytes.NewReader(inBytes))
+}
+
+// prepareInput fills in the raw input and HTTP context of a run, encoding the input object as JSON if no raw input
+// was given (e.g. for runs triggered by events)
+func prepareInput(run *dapi.Run) error {
+	if run.HTTPContext == nil {
+		run.HTTPContext = make(map[string]interface{})
+	}
+	for _, header := range []string{"Content-Type", "Accept"} {
+		if _, ok := run.HTTPContext[header].(string); !ok {
+			run.HTTPContext[header] = "application/json"
+		}
+	}
+	if run.InputBytes == nil && run.Input != nil {
+		inBytes, err := json.Marshal(run.Input)
+		if err != nil {
+			return errors.Wrap(err, "encoding run input")
+		}
+		run.InputBytes = inBytes
+	}
+	return nil
+}
+
+// functionEndpoint describes where a function is run, how long a run may take and what it takes and returns
+type functionEndpoint struct {
+	// host is the address requests are sent to
+	host string
+	// hostHeader routes requests to the function
+	hostHeader string
+	timeout    time.Duration
+	schema     *dapi.Schema
+}
+
+// runEndpoint resolves the function and the endpoint to send function requests to
+func (h *defaultHandlers) runEndpoint(ctx context.Context, meta *dapi.Meta) (*functionEndpoint, *runError) {
+	name := meta.Name
+	function, err := h.backend.Get(ctx, meta)
+	if err == nil {
+		endpoint := &functionEndpoint{timeout: defaultRunTimeout, schema: function.Schema}
+		if function.Timeout > 0 {
+			endpoint.timeout = time.Duration(function.Timeout) * time.Millisecond
+		}
+		endpoint.host, endpoint.hostHeader, err = h.backend.RunEndpoint(ctx, meta)
+		if err == nil {
+			return endpoint, nil
+		}
+	}
+	if _, ok := err.(backend.NotFound); ok {
+		return nil, &runError{
+			error:   errors.Errorf("function '%s' not found", name),
+			code:    http.StatusNotFound,
+			errType: dapi.ErrorTypeInputError,
+			message: utils.ErrorMsgNotFound("function", name),
+		}
+	}
+	return nil, &runError{
+		error:   errors.Wrapf(err, "getting function '%s'", name),
+		code:    http.StatusInternalServerError,
+		errType: dapi.ErrorTypeSystemError,
+		message: utils.ErrorMsgInternalError("function", name),
+	}
+}
+
+// invoke sends the run input to the function and returns its output. The output holds the logs of the function, if
+// any, also when the function failed.
+func (h *defaultHandlers) invoke(ctx context.Context, name string, endpoint *functionEndpoint, run *dapi.Run) (*runOutput, *runError) {
+	ctx, cancel := context.WithTimeout(ctx, endpoint.timeout)
+	defer cancel()
+
+	req, err := http.NewRequest("POST", endpoint.host, bytes.NewReader(run.InputBytes))
 	if err != nil {
-		log.Errorf("%+v", errors.Wrap(err, "building http request"))
-		return fnrunner.NewRunFunctionDefault(500).WithPayload(&dapi.Error{
-			Code:    http.StatusInternalServerError,
-			Message: utils.ErrorMsgInternalError("building http request to run function", name),
-		})
+		return nil, &runError{
+			error:   errors.Wrap(err, "building http request"),
+			code:    http.StatusInternalServerError,
+			errType: dapi.ErrorTypeSystemError,
+			message: utils.ErrorMsgInternalError("building http request to run function", name),
+		}
 	}
-	req.Host = runEndpoint
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", accept)
-	// TODO: Make timeout configurable
-	h.httpClient.Timeout = 60 * time.Second
+	req = req.WithContext(ctx)
+	req.Host = endpoint.hostHeader
+	req.Header.Set("Content-Type", run.HTTPContext["Content-Type"].(string))
+	req.Header.Set("Accept", run.HTTPContext["Accept"].(string))
+	req.Header.Set(backend.EnvelopeHeader, "true")
 	// TODO: Add Dispatch context via header (X-Dispatch-Context)
 	response, err := h.httpClient.Do(req)
 	if err != nil {
-		log.Errorf("%+v", errors.Wrap(err, "performing http request"))
-		return fnrunner.NewRunFunctionDefault(502).WithPayload(&dapi.Error{
-			Code:    http.StatusBadGateway,
-			Message: utils.ErrorMsgInternalError("performing http request to run function", name),
-		})
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, timeoutError(name, endpoint.timeout)
+		}
+		return nil, &runError{
+			error:   errors.Wrap(err, "performing http request"),
+			code:    http.StatusBadGateway,
+			errType: dapi.ErrorTypeSystemError,
+			message: utils.ErrorMsgInternalError("performing http request to run function", name),
+		}
 	}
 	defer response.Body.Close()
 
-	outContentType := response.Header.Get("Content-Type")
 	outBytes, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		log.Errorf("%+v", errors.Wrap(err, "reading http response body"))
-		return fnrunner.NewRunFunctionDefault(502).WithPayload(&dapi.Error{
-			Code:    http.StatusBadGateway,
-			Message: utils.ErrorMsgInternalError("reading http response body running function", name),
-		})
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, timeoutError(name, endpoint.timeout)
+		}
+		return nil, &runError{
+			error:   errors.Wrap(err, "reading http response body"),
+			code:    http.StatusBadGateway,
+			errType: dapi.ErrorTypeSystemError,
+			message: utils.ErrorMsgInternalError("reading http response body running function", name),
+		}
 	}
 	// Technically, this shouldn't happen... but it will.
 	if response.StatusCode == http.StatusNotFound {
-		return fnrunner.NewRunFunctionNotFound().WithPayload(&dapi.Error{
-			Code:    http.StatusNotFound,
-			Message: utils.ErrorMsgInternalError("function not found", name),
-		})
+		return nil, &runError{
+			error:   errors.Errorf("function '%s' endpoint responded with not found", name),
+			code:    http.StatusNotFound,
+			errType: dapi.ErrorTypeSystemError,
+			message: utils.ErrorMsgInternalError("function not found", name),
+		}
+	}
+
+	out := &runOutput{contentType: response.Header.Get("Content-Type"), output: outBytes}
+	if isEnvelope(out.contentType) {
+		envelope, err := unwrapEnvelope(outBytes)
+		if err != nil {
+			return nil, &runError{
+				error:   errors.Wrapf(err, "running function '%s'", name),
+				code:    http.StatusBadGateway,
+				errType: dapi.ErrorTypeSystemError,
+				message: utils.ErrorMsgInternalError("decoding response of function", name),
+			}
+		}
+		out = &runOutput{contentType: envelope.ContentType, output: envelope.Output, logs: envelope.Logs}
+		if envelope.Error != nil {
+			return out, functionError(name, envelope.Error)
+		}
+	}
+
+	if h.validateOutput {
+		if runErr := checkOutput(name, endpoint, out.contentType, out.output); runErr != nil {
+			return out, runErr
+		}
+	}
+
+	return out, nil
+}
+
+// functionError describes an error reported by the function itself
+func functionError(name string, e *dapi.InvocationError) *runError {
+	errType := e.Type
+	if errType == "" {
+		errType = dapi.ErrorTypeFunctionError
+	}
+	message := e.Message
+	if message == nil {
+		message = swag.String(fmt.Sprintf("function %s failed", name))
+	}
+	return &runError{
+		error:       errors.Errorf("function '%s' failed: %s", name, *message),
+		code:        http.StatusBadGateway,
+		errType:     errType,
+		message:     message,
+		fieldErrors: e.Errors,
+		stacktrace:  e.Stacktrace,
+	}
+}
+
+// checkInput validates the run input against the input schema of the function, if any
+func checkInput(name string, endpoint *functionEndpoint, run *dapi.Run) *runError {
+	if endpoint.schema == nil || endpoint.schema.In == nil {
+		return nil
+	}
+	// Schemas describe JSON documents, other inputs are left to the function
+	if contentType, _ := run.HTTPContext["Content-Type"].(string); !isJSON(contentType) {
+		return nil
 	}
+	fieldErrors, err := validateSchema("input", endpoint.schema.In, run.InputBytes)
+	if err != nil {
+		return &runError{
+			error:   errors.Wrapf(err, "validating input of function '%s'", name),
+			code:    http.StatusInternalServerError,
+			errType: dapi.ErrorTypeSystemError,
+			message: swag.String(fmt.Sprintf("invalid input schema of function %s: %s", name, err)),
+		}
+	}
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return &runError{
+		error:       errors.Errorf("input of function '%s' does not match its schema: %s", name, strings.Join(fieldErrors, ", ")),
+		code:        http.StatusBadRequest,
+		errType:     dapi.ErrorTypeInputError,
+		message:     swag.String(fmt.Sprintf("input does not match the schema of function %s", name)),
+		fieldErrors: fieldErrors,
+	}
+}
+
+// checkOutput validates the JSON output of a function against its output schema, if any. Outputs without a content
+// type are taken as JSON. Violations are errors of the function.
+func checkOutput(name string, endpoint *functionEndpoint, contentType string, output []byte) *runError {
+	if endpoint.schema == nil || endpoint.schema.Out == nil || (contentType != "" && !isJSON(contentType)) {
+		return nil
+	}
+	fieldErrors, err := validateSchema("output", endpoint.schema.Out, output)
+	if err == nil && len(fieldErrors) == 0 {
+		return nil
+	}
+	if err != nil {
+		fieldErrors = []string{err.Error()}
+	}
+	return &runError{
+		error:       errors.Errorf("output of function '%s' does not match its schema: %s", name, strings.Join(fieldErrors, ", ")),
+		code:        http.StatusBadGateway,
+		errType:     dapi.ErrorTypeFunctionError,
+		message:     swag.String(fmt.Sprintf("output does not match the schema of function %s", name)),
+		fieldErrors: fieldErrors,
+	}
+}
 
-	run := &dapi.Run{
-		HTTPContext: map[string]interface{}{"Content-Type": outContentType},
-		OutputBytes: outBytes,
+// finishRun records the outcome of an invocation in the run history, out is nil if the function was not reached
+func (h *defaultHandlers) finishRun(ctx context.Context, run *FnRun, out *runOutput, runErr *runError) {
+	if out == nil {
+		out = new(runOutput)
+	}
+	run.FinishedTime = time.Now()
+	run.HTTPContext = map[string]interface{}{"Content-Type": out.contentType}
+	run.Output = out.output
+	run.OutputSize = int64(len(out.output))
+	run.Logs = out.logs
+	run.Status = entitystore.StatusREADY
+	if runErr != nil {
+		log.Errorf("%+v", runErr.error)
+		run.Status = entitystore.StatusERROR
+		run.Reason = []string{runErr.Error()}
+		run.Error = &dapi.InvocationError{
+			Type:       runErr.errType,
+			Message:    runErr.message,
+			Errors:     runErr.fieldErrors,
+			Stacktrace: runErr.stacktrace,
+		}
+	}
+	if _, err := h.store.Update(ctx, run.Revision, run); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "recording result of run '%s'", run.Name))
 	}
-	return fnrunner.NewRunFunctionOK().WithPayload(run)
 }
 
-func (*defaultHandlers) getRun(params fnrunner.GetRunParams) middleware.Responder {
-	panic("implement me")
+func (h *defaultHandlers) getRun(params fnrunner.GetRunParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := h.namespace
+	project := *params.XDispatchProject
+	name := params.RunName.String()
+
+	filter := runFilter(project, params.FunctionName, nil, params.Since, nil)
+	filter, err := utils.ParseTags(filter, params.Tags)
+	if err != nil {
+		return fnrunner.NewGetRunBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	run := FnRun{}
+	found, err := h.store.Find(ctx, org, name, entitystore.Options{Filter: filter}, &run)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting run '%s'", name))
+		return fnrunner.NewGetRunDefault(500).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("run", name),
+		})
+	}
+	if !found {
+		return fnrunner.NewGetRunNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("run", name),
+		})
+	}
+
+	return fnrunner.NewGetRunOK().WithPayload(run.ToModel())
 }
 
-func (*defaultHandlers) getRuns(params fnrunner.GetRunsParams) middleware.Responder {
-	panic("implement me")
+func (h *defaultHandlers) getRuns(params fnrunner.GetRunsParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := h.namespace
+	project := *params.XDispatchProject
+
+	filter := runFilter(project, params.FunctionName, params.Status, params.Since, params.Before)
+	filter, err := utils.ParseTags(filter, params.Tags)
+	if err != nil {
+		return fnrunner.NewGetRunsBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	var runs []*FnRun
+	if err := h.store.List(ctx, org, entitystore.Options{Filter: filter}, &runs); err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "listing runs"))
+		return fnrunner.NewGetRunsDefault(500).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	models := []*dapi.Run{}
+	for _, run := range runs {
+		models = append(models, run.ToModel())
+	}
+	return fnrunner.NewGetRunsOK().WithPayload(models)
 }
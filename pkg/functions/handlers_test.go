@@ -5,4 +5,613 @@
 
 package functions
 
-//TODO add tests
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	"github.com/vmware/dispatch/pkg/client/mocks"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/functions/backend"
+	"github.com/vmware/dispatch/pkg/functions/config"
+	fnrunner "github.com/vmware/dispatch/pkg/functions/gen/restapi/operations/runner"
+	fnstore "github.com/vmware/dispatch/pkg/functions/gen/restapi/operations/store"
+	"github.com/vmware/dispatch/pkg/functions/sourcestore"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+const (
+	testOrg     = "testorg"
+	testProject = "testproject"
+)
+
+// fakeBackend routes every function to a single endpoint, functions which were not added are made up on the fly
+type fakeBackend struct {
+	backend.Backend
+	runHost   string
+	timeout   int64
+	functions map[string]*dapi.Function
+}
+
+func (b *fakeBackend) Get(ctx context.Context, meta *dapi.Meta) (*dapi.Function, error) {
+	if function, ok := b.functions[meta.Name]; ok {
+		return function, nil
+	}
+	if meta.Name == "missing" {
+		return nil, backend.NotFound{}
+	}
+	return &dapi.Function{Meta: *meta, Timeout: b.timeout}, nil
+}
+
+func (b *fakeBackend) List(ctx context.Context, meta *dapi.Meta) ([]*dapi.Function, error) {
+	var functions []*dapi.Function
+	for _, function := range b.functions {
+		functions = append(functions, function)
+	}
+	return functions, nil
+}
+
+func (b *fakeBackend) Add(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
+	if _, ok := b.functions[function.Name]; ok {
+		return nil, backend.AlreadyExists{}
+	}
+	b.functions[function.Name] = function
+	return function, nil
+}
+
+func (b *fakeBackend) Update(ctx context.Context, function *dapi.Function) (*dapi.Function, error) {
+	if _, ok := b.functions[function.Name]; !ok {
+		return nil, backend.NotFound{}
+	}
+	b.functions[function.Name] = function
+	return function, nil
+}
+
+func (b *fakeBackend) Delete(ctx context.Context, meta *dapi.Meta) error {
+	delete(b.functions, meta.Name)
+	return nil
+}
+
+func (b *fakeBackend) RunEndpoint(ctx context.Context, meta *dapi.Meta) (string, string, error) {
+	if meta.Name == "missing" {
+		return "", "", backend.NotFound{}
+	}
+	return b.runHost, meta.Name, nil
+}
+
+func newTestHandlers(t *testing.T, fn http.HandlerFunc) (*defaultHandlers, func()) {
+	server := httptest.NewServer(fn)
+	h := &defaultHandlers{
+		backend:    &fakeBackend{runHost: server.URL},
+		store:      helpers.MakeEntityStore(t),
+		httpClient: &http.Client{},
+		namespace:  testOrg,
+		workers:    newWorkerPool(1, 1),
+	}
+	return h, server.Close
+}
+
+func runTestFunction(t *testing.T, h *defaultHandlers, name string, input string, statusCode int) *dapi.Run {
+	return runTestFunctionWithBlocking(t, h, name, input, true, statusCode)
+}
+
+func runTestFunctionWithBlocking(t *testing.T, h *defaultHandlers, name string, input string, blocking bool, statusCode int) *dapi.Run {
+	params := fnrunner.NewRunFunctionParams()
+	params.HTTPRequest = httptest.NewRequest("POST", "/v1/runs", nil)
+	params.FunctionName = swag.String(name)
+	params.Body = &dapi.Run{
+		Blocking:    blocking,
+		InputBytes:  []byte(input),
+		HTTPContext: map[string]interface{}{"Content-Type": "text/plain", "Accept": "text/plain"},
+	}
+	var run dapi.Run
+	helpers.HandlerRequest(t, h.runFunction(params), &run, statusCode)
+	return &run
+}
+
+func TestRunFunctionRecordsRun(t *testing.T) {
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "hello %s", body)
+	})
+	defer done()
+
+	run := runTestFunction(t, h, "hello", "world", http.StatusOK)
+	assert.Equal(t, "hello world", string(run.OutputBytes))
+	assert.Equal(t, dapi.StatusREADY, run.Status)
+	assert.NotEmpty(t, run.Name)
+
+	stored := getTestRun(t, h, run.Name)
+	assert.Equal(t, "hello", stored.FunctionName)
+	assert.Equal(t, dapi.StatusREADY, stored.Status)
+	assert.Equal(t, int64(5), stored.InputSize)
+	assert.Equal(t, int64(11), stored.OutputSize)
+	assert.NotZero(t, stored.ExecutedTime)
+	assert.NotZero(t, stored.FinishedTime)
+}
+
+func TestRunFunctionNotFound(t *testing.T) {
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	runTestFunction(t, h, "missing", "", http.StatusNotFound)
+
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	params.Status = swag.String(string(dapi.StatusERROR))
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "missing", runs[0].FunctionName)
+	require.NotNil(t, runs[0].Error)
+	assert.Equal(t, dapi.ErrorTypeInputError, runs[0].Error.Type)
+}
+
+func getTestRun(t *testing.T, h *defaultHandlers, name strfmt.UUID) *dapi.Run {
+	params := fnrunner.NewGetRunParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs/"+name.String(), nil)
+	params.RunName = name
+	var run dapi.Run
+	helpers.HandlerRequest(t, h.getRun(params), &run, http.StatusOK)
+	return &run
+}
+
+func TestRunFunctionNonBlocking(t *testing.T) {
+	received := make(chan struct{}, 3)
+	release := make(chan struct{})
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		w.Write([]byte("done"))
+	})
+	defer done()
+
+	run := runTestFunctionWithBlocking(t, h, "slow", "", false, http.StatusAccepted)
+	assert.Equal(t, dapi.StatusINITIALIZED, run.Status)
+	<-received
+
+	// The only worker is busy, so one run gets queued and the next one rejected
+	queued := runTestFunctionWithBlocking(t, h, "slow", "", false, http.StatusAccepted)
+	runTestFunctionWithBlocking(t, h, "slow", "", false, http.StatusServiceUnavailable)
+	assert.Equal(t, dapi.StatusINITIALIZED, getTestRun(t, h, run.Name).Status)
+
+	close(release)
+	for _, name := range []strfmt.UUID{run.Name, queued.Name} {
+		var finished *dapi.Run
+		for i := 0; i < 50; i++ {
+			finished = getTestRun(t, h, name)
+			if finished.Status != dapi.StatusINITIALIZED {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.Equal(t, dapi.StatusREADY, finished.Status)
+		assert.Equal(t, "done", string(finished.OutputBytes))
+	}
+}
+
+func TestRunFunctionTimeout(t *testing.T) {
+	release := make(chan struct{})
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer done()
+	defer close(release)
+	h.backend.(*fakeBackend).timeout = 100
+
+	runTestFunction(t, h, "slow", "", http.StatusGatewayTimeout)
+
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	require.Len(t, runs, 1)
+	assert.Equal(t, dapi.StatusERROR, runs[0].Status)
+	require.NotNil(t, runs[0].Error)
+	assert.Equal(t, dapi.ErrorTypeTimeoutError, runs[0].Error.Type)
+}
+
+func TestDeleteFunctionCollectsSource(t *testing.T) {
+	root, err := ioutil.TempDir("", "sources")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ctx := context.Background()
+	h.sourceStore = sourcestore.NewFile(&config.StorageFileConfig{SourceRootPath: root})
+	shared, err := h.sourceStore.Put(ctx, testOrg, testProject, []byte("shared"))
+	require.NoError(t, err)
+	own, err := h.sourceStore.Put(ctx, testOrg, testProject, []byte("own"))
+	require.NoError(t, err)
+	previous, err := h.sourceStore.Put(ctx, testOrg, testProject, []byte("previous"))
+	require.NoError(t, err)
+	rolledBack, err := h.sourceStore.Put(ctx, testOrg, testProject, []byte("rolled back"))
+	require.NoError(t, err)
+
+	fb := h.backend.(*fakeBackend)
+	fb.functions = make(map[string]*dapi.Function)
+	for name, sourceURL := range map[string]string{"f1": shared, "f2": shared, "f3": own} {
+		fb.functions[name] = &dapi.Function{Meta: dapi.Meta{Name: name}, SourceURL: sourceURL}
+	}
+	// f3 has an older revision, f4 was rolled back to a revision built from the shared source
+	fb.functions["f3"].Revisions = []*dapi.FunctionRevision{{Name: "f3-1", SourceURL: previous}, {Name: "f3-2", SourceURL: own}}
+	fb.functions["f4"] = &dapi.Function{
+		Meta:      dapi.Meta{Name: "f4"},
+		SourceURL: rolledBack,
+		Revisions: []*dapi.FunctionRevision{{Name: "f4-1", SourceURL: shared}, {Name: "f4-2", SourceURL: rolledBack}},
+	}
+
+	deleteFunction := func(name string) {
+		params := fnstore.NewDeleteFunctionParams()
+		params.HTTPRequest = httptest.NewRequest("DELETE", "/v1/function/"+name, nil)
+		params.XDispatchProject = swag.String(testProject)
+		params.FunctionName = name
+		helpers.HandlerRequest(t, h.deleteFunction(params), nil, http.StatusOK)
+	}
+
+	deleteFunction("f1")
+	deleteFunction("f3")
+	sources, err := h.sourceStore.List(ctx, testOrg, testProject)
+	require.NoError(t, err)
+	assert.Equal(t, sortedStrings(shared, rolledBack), sortedStrings(sources...))
+
+	deleteFunction("f2")
+	sources, err = h.sourceStore.List(ctx, testOrg, testProject)
+	require.NoError(t, err)
+	assert.Equal(t, sortedStrings(shared, rolledBack), sortedStrings(sources...))
+
+	deleteFunction("f4")
+	sources, err = h.sourceStore.List(ctx, testOrg, testProject)
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+}
+
+func TestAddFunctionDiscardsSource(t *testing.T) {
+	root, err := ioutil.TempDir("", "sources")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ctx := context.Background()
+	h.sourceStore = sourcestore.NewFile(&config.StorageFileConfig{SourceRootPath: root})
+	images := &mocks.ImagesClient{}
+	images.On("GetImage", mock.Anything, testOrg, "nodejs").Return(&dapi.Image{ImageURL: "nodejs-url"}, nil)
+	h.imagesClient = images
+	shared, err := h.sourceStore.Put(ctx, testOrg, testProject, []byte("shared"))
+	require.NoError(t, err)
+	h.backend.(*fakeBackend).functions = map[string]*dapi.Function{
+		"hello": {Meta: dapi.Meta{Name: "hello"}, SourceURL: shared},
+	}
+
+	addFunction := func(source string, statusCode int) {
+		params := fnstore.NewAddFunctionParams()
+		params.HTTPRequest = httptest.NewRequest("POST", "/v1/function", nil)
+		params.XDispatchProject = swag.String(testProject)
+		params.Body = &dapi.Function{Meta: dapi.Meta{Name: "hello"}, Image: "nodejs", Source: []byte(source)}
+		helpers.HandlerRequest(t, h.addFunction(params), &dapi.Error{}, statusCode)
+	}
+
+	// The source of a function which failed to be created is deleted, unless another function uses it
+	addFunction("new", http.StatusConflict)
+	addFunction("shared", http.StatusConflict)
+	sources, err := h.sourceStore.List(ctx, testOrg, testProject)
+	require.NoError(t, err)
+	assert.Equal(t, []string{shared}, sources)
+}
+
+func TestUpdateFunctionBuildsSource(t *testing.T) {
+	root, err := ioutil.TempDir("", "sources")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	h.imageRegistry = "registry"
+	h.sourceStore = sourcestore.NewFile(&config.StorageFileConfig{SourceRootPath: root})
+	images := &mocks.ImagesClient{}
+	images.On("GetImage", mock.Anything, testOrg, "python3").Return(&dapi.Image{ImageURL: "python3-url"}, nil)
+	h.imagesClient = images
+
+	live := &dapi.Function{
+		Meta:             dapi.Meta{Name: "hello", Org: testOrg, Project: testProject},
+		Image:            "nodejs",
+		ImageURL:         "nodejs-url",
+		SourceURL:        "file:///old",
+		FunctionImageURL: "registry/old",
+	}
+	h.backend.(*fakeBackend).functions = map[string]*dapi.Function{"hello": live}
+
+	updateFunction := func(function *dapi.Function) *dapi.Function {
+		params := fnstore.NewUpdateFunctionParams()
+		params.HTTPRequest = httptest.NewRequest("PUT", "/v1/function/hello", nil)
+		params.XDispatchProject = swag.String(testProject)
+		params.FunctionName = "hello"
+		params.Body = function
+		var updated dapi.Function
+		helpers.HandlerRequest(t, h.updateFunction(params), &updated, http.StatusOK)
+		return &updated
+	}
+
+	// Changing neither image nor source keeps the built function
+	updated := updateFunction(&dapi.Function{Meta: dapi.Meta{Name: "hello"}, Timeout: 10})
+	assert.Equal(t, "nodejs", updated.Image)
+	assert.Equal(t, "nodejs-url", h.backend.(*fakeBackend).functions["hello"].ImageURL)
+	assert.Equal(t, "file:///old", updated.SourceURL)
+	assert.Equal(t, "registry/old", updated.FunctionImageURL)
+	assert.Equal(t, int64(10), updated.Timeout)
+
+	// A new source and image are stored and resolved, and the function is rebuilt
+	updated = updateFunction(&dapi.Function{Meta: dapi.Meta{Name: "hello"}, Image: "python3", Source: []byte("new source")})
+	assert.Nil(t, updated.Source)
+	assert.Equal(t, "python3-url", h.backend.(*fakeBackend).functions["hello"].ImageURL)
+	assert.NotEqual(t, "registry/old", updated.FunctionImageURL)
+	assert.Contains(t, updated.FunctionImageURL, "registry/")
+	sources, err := h.sourceStore.List(context.Background(), testOrg, testProject)
+	require.NoError(t, err)
+	assert.Equal(t, []string{updated.SourceURL}, sources)
+	images.AssertExpectations(t)
+
+	params := fnstore.NewUpdateFunctionParams()
+	params.HTTPRequest = httptest.NewRequest("PUT", "/v1/function/missing", nil)
+	params.XDispatchProject = swag.String(testProject)
+	params.FunctionName = "missing"
+	params.Body = &dapi.Function{Meta: dapi.Meta{Name: "missing"}}
+	helpers.HandlerRequest(t, h.updateFunction(params), &dapi.Error{}, http.StatusNotFound)
+}
+
+func sortedStrings(values ...string) []string {
+	sort.Strings(values)
+	return values
+}
+
+func TestGetRunsFilter(t *testing.T) {
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	runTestFunction(t, h, "first", "", http.StatusOK)
+	runTestFunction(t, h, "first", "", http.StatusOK)
+	runTestFunction(t, h, "second", "", http.StatusOK)
+
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 3)
+
+	params.FunctionName = swag.String("first")
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 2)
+
+	params.FunctionName = nil
+	params.Before = swag.Int64(time.Now().Add(-time.Hour).Unix())
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 0)
+
+	params.Before = nil
+	params.Since = swag.Int64(time.Now().Add(-time.Hour).Unix())
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 3)
+
+	params.Since = swag.Int64(time.Now().Add(time.Hour).Unix())
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 0)
+
+	params.Since = nil
+	params.Status = swag.String(string(dapi.StatusERROR))
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	assert.Len(t, runs, 0)
+}
+
+func TestRunReaper(t *testing.T) {
+	store := helpers.MakeEntityStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		run := &FnRun{
+			BaseEntity: entitystore.BaseEntity{
+				OrganizationID: testOrg,
+				Name:           fmt.Sprintf("run-%d", i),
+			},
+			Project:      "default",
+			FunctionName: "hello",
+			ExecutedTime: now.Add(-time.Duration(i) * time.Hour),
+		}
+		_, err := store.Add(ctx, run)
+		require.NoError(t, err)
+	}
+
+	reaper := NewRunReaper(store, &config.RunRetentionConfig{MaxAge: 150 * time.Minute, MaxCount: 2})
+	require.NoError(t, reaper.Reap(ctx))
+
+	var runs []*FnRun
+	require.NoError(t, store.List(ctx, testOrg, entitystore.Options{}, &runs))
+	names := make(map[string]bool)
+	for _, run := range runs {
+		names[run.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"run-0": true, "run-1": true}, names)
+
+	reaper = NewRunReaper(store, &config.RunRetentionConfig{MaxAge: 30 * time.Minute})
+	require.NoError(t, reaper.Reap(ctx))
+	require.NoError(t, store.List(ctx, testOrg, entitystore.Options{}, &runs))
+	assert.Len(t, runs, 1)
+}
+
+func schemaFunctions(h *defaultHandlers) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+		},
+	}
+	h.backend.(*fakeBackend).functions = map[string]*dapi.Function{
+		"hello": {Meta: dapi.Meta{Name: "hello"}, Schema: &dapi.Schema{In: schema, Out: schema}},
+	}
+}
+
+// runJSONFunction runs a function with a JSON input and decodes the response into out
+func runJSONFunction(t *testing.T, h *defaultHandlers, name string, input string, statusCode int, out interface{}) {
+	params := fnrunner.NewRunFunctionParams()
+	params.HTTPRequest = httptest.NewRequest("POST", "/v1/runs", nil)
+	params.FunctionName = swag.String(name)
+	params.Body = &dapi.Run{
+		Blocking:    true,
+		InputBytes:  []byte(input),
+		HTTPContext: map[string]interface{}{"Content-Type": "application/json; charset=utf-8"},
+	}
+	helpers.HandlerRequest(t, h.runFunction(params), out, statusCode)
+}
+
+func TestRunFunctionInputSchema(t *testing.T) {
+	invoked := 0
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {
+		invoked++
+		w.Write([]byte(`{"name": "world"}`))
+	})
+	defer done()
+	schemaFunctions(h)
+
+	// The rejected run has the field errors
+	var rejected dapi.Run
+	runJSONFunction(t, h, "hello", `{"name": 1}`, http.StatusBadRequest, &rejected)
+	assert.Equal(t, 0, invoked)
+	assert.NotEmpty(t, rejected.Name)
+	assert.Equal(t, dapi.StatusERROR, rejected.Status)
+	require.NotNil(t, rejected.Error)
+	assert.Equal(t, dapi.ErrorTypeInputError, rejected.Error.Type)
+	require.Len(t, rejected.Error.Errors, 1)
+	assert.Contains(t, rejected.Error.Errors[0], "input.name")
+
+	// The run records them too
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	require.Len(t, runs, 1)
+	assert.Equal(t, dapi.StatusERROR, runs[0].Status)
+	require.NotNil(t, runs[0].Error)
+	assert.Equal(t, dapi.ErrorTypeInputError, runs[0].Error.Type)
+	require.Len(t, runs[0].Error.Errors, 1)
+	assert.Contains(t, runs[0].Error.Errors[0], "input.name")
+
+	rejected = dapi.Run{}
+	runJSONFunction(t, h, "hello", `{}`, http.StatusBadRequest, &rejected)
+	require.NotNil(t, rejected.Error)
+	require.Len(t, rejected.Error.Errors, 1)
+	assert.Contains(t, rejected.Error.Errors[0], "input.name in body is required")
+
+	var run dapi.Run
+	runJSONFunction(t, h, "hello", `{"name": "world"}`, http.StatusOK, &run)
+	assert.Equal(t, 1, invoked)
+
+	// Schemas only apply to JSON inputs
+	runTestFunction(t, h, "hello", "world", http.StatusOK)
+	assert.Equal(t, 2, invoked)
+}
+
+func TestRunFunctionOutputSchema(t *testing.T) {
+	h, done := newTestHandlers(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	defer done()
+	schemaFunctions(h)
+
+	// Outputs are only checked if enabled
+	var run dapi.Run
+	runJSONFunction(t, h, "hello", `{"name": "world"}`, http.StatusOK, &run)
+
+	h.validateOutput = true
+	var failed dapi.Error
+	runJSONFunction(t, h, "hello", `{"name": "world"}`, http.StatusBadGateway, &failed)
+
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	params.Status = swag.String(string(dapi.StatusERROR))
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	require.Len(t, runs, 1)
+	require.NotNil(t, runs[0].Error)
+	assert.Equal(t, dapi.ErrorTypeFunctionError, runs[0].Error.Type)
+	assert.Equal(t, []string{"output.name in body is required"}, runs[0].Error.Errors)
+}
+
+func envelopeHandler(envelope *backend.Envelope) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(backend.EnvelopeHeader) != "true" {
+			w.Write(envelope.Output)
+			return
+		}
+		w.Header().Set("Content-Type", backend.EnvelopeContentType)
+		json.NewEncoder(w).Encode(envelope)
+	}
+}
+
+func TestRunFunctionLogs(t *testing.T) {
+	h, done := newTestHandlers(t, envelopeHandler(&backend.Envelope{
+		ContentType: "text/plain",
+		Output:      []byte("hello world"),
+		Logs:        &dapi.Logs{Stdout: []string{"saying hello"}, Stderr: []string{}},
+	}))
+	defer done()
+
+	run := runTestFunction(t, h, "hello", "world", http.StatusOK)
+	assert.Equal(t, "hello world", string(run.OutputBytes))
+	require.NotNil(t, run.Logs)
+	assert.Equal(t, []string{"saying hello"}, run.Logs.Stdout)
+
+	stored := getTestRun(t, h, run.Name)
+	assert.Equal(t, "text/plain", stored.HTTPContext["Content-Type"])
+	require.NotNil(t, stored.Logs)
+	assert.Equal(t, []string{"saying hello"}, stored.Logs.Stdout)
+}
+
+func TestRunFunctionEnvelopeError(t *testing.T) {
+	h, done := newTestHandlers(t, envelopeHandler(&backend.Envelope{
+		Logs: &dapi.Logs{Stdout: []string{}, Stderr: []string{"about to fail"}},
+		Error: &dapi.InvocationError{
+			Message:    swag.String("division by zero"),
+			Stacktrace: []string{"hello.py:3"},
+		},
+	}))
+	defer done()
+
+	// The failed run is returned with the logs of the function
+	failed := runTestFunction(t, h, "hello", "world", http.StatusBadGateway)
+	assert.Equal(t, dapi.StatusERROR, failed.Status)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, "division by zero", *failed.Error.Message)
+	require.NotNil(t, failed.Logs)
+	assert.Equal(t, []string{"about to fail"}, failed.Logs.Stderr)
+
+	params := fnrunner.NewGetRunsParams()
+	params.HTTPRequest = httptest.NewRequest("GET", "/v1/runs", nil)
+	var runs []*dapi.Run
+	helpers.HandlerRequest(t, h.getRuns(params), &runs, http.StatusOK)
+	require.Len(t, runs, 1)
+	assert.Equal(t, dapi.StatusERROR, runs[0].Status)
+	require.NotNil(t, runs[0].Error)
+	assert.Equal(t, dapi.ErrorTypeFunctionError, runs[0].Error.Type)
+	assert.Equal(t, "division by zero", *runs[0].Error.Message)
+	assert.Equal(t, []string{"hello.py:3"}, runs[0].Error.Stacktrace)
+	require.NotNil(t, runs[0].Logs)
+	assert.Equal(t, []string{"about to fail"}, runs[0].Logs.Stderr)
+}
@@ -0,0 +1,161 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/functions/config"
+	"github.com/vmware/dispatch/pkg/trace"
+)
+
+// RunReaper removes function runs which exceed the run retention limits
+type RunReaper struct {
+	store     entitystore.EntityStore
+	retention *config.RunRetentionConfig
+}
+
+// NewRunReaper is the constructor for the RunReaper
+func NewRunReaper(store entitystore.EntityStore, retention *config.RunRetentionConfig) *RunReaper {
+	return &RunReaper{
+		store:     store,
+		retention: retention,
+	}
+}
+
+// Run reaps runs every period until the context is done
+func (r *RunReaper) Run(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		if err := r.Reap(ctx); err != nil {
+			log.Errorf("%+v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap deletes runs older than the maximum age, and all but the most recent runs of every function
+func (r *RunReaper) Reap(ctx context.Context) error {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	now := time.Now()
+	if r.retention.MaxAge > 0 {
+		var runs []*FnRun
+		if err := r.list(ctx, entitystore.FilterVerbBefore, now.Add(-r.retention.MaxAge), &runs); err != nil {
+			return err
+		}
+		for _, run := range runs {
+			r.delete(ctx, run)
+		}
+	}
+
+	if r.retention.MaxCount > 0 {
+		// Only runs which are not expired are left to count
+		var runs []*FnRun
+		var err error
+		if r.retention.MaxAge > 0 {
+			err = r.list(ctx, entitystore.FilterVerbAfter, now.Add(-r.retention.MaxAge), &runs)
+		} else {
+			err = r.store.ListGlobal(ctx, entitystore.Options{Filter: entitystore.FilterEverything()}, &runs)
+		}
+		if err != nil {
+			return errors.Wrap(err, "listing runs to reap")
+		}
+		sort.Slice(runs, func(i, j int) bool {
+			return runs[i].ExecutedTime.After(runs[j].ExecutedTime)
+		})
+		counts := make(map[string]int)
+		for _, run := range runs {
+			key := runFunctionKey(run)
+			counts[key]++
+			if counts[key] > r.retention.MaxCount {
+				r.delete(ctx, run)
+			}
+		}
+	}
+	return nil
+}
+
+// list returns the runs of all organizations which were executed before or after the given time
+func (r *RunReaper) list(ctx context.Context, verb entitystore.Verb, t time.Time, runs *[]*FnRun) error {
+	filter := entitystore.FilterEverything().Add(entitystore.FilterStat{
+		Scope:   entitystore.FilterScopeExtra,
+		Subject: "ExecutedTime",
+		Verb:    verb,
+		Object:  t,
+	})
+	if err := r.store.ListGlobal(ctx, entitystore.Options{Filter: filter}, runs); err != nil {
+		return errors.Wrap(err, "listing runs to reap")
+	}
+	return nil
+}
+
+func (r *RunReaper) delete(ctx context.Context, run *FnRun) {
+	log.Debugf("reaping run %s of function %s", run.Name, runFunctionKey(run))
+	if err := r.store.Delete(ctx, run.OrganizationID, run.Name, run); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "deleting run '%s'", run.Name))
+	}
+}
+
+func runFunctionKey(run *FnRun) string {
+	return fmt.Sprintf("%s/%s/%s", run.OrganizationID, run.Project, run.FunctionName)
+}
+
+// runFilter builds an entity store filter from the run query parameters
+func runFilter(project string, functionName, status *string, since, before *int64) entitystore.Filter {
+	filter := entitystore.FilterEverything().Add(entitystore.FilterStat{
+		Scope:   entitystore.FilterScopeExtra,
+		Subject: "Project",
+		Verb:    entitystore.FilterVerbEqual,
+		Object:  project,
+	})
+	if functionName != nil {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "FunctionName",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  *functionName,
+		})
+	}
+	if status != nil {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeField,
+			Subject: "Status",
+			Verb:    entitystore.FilterVerbEqual,
+			Object:  entitystore.Status(*status),
+		})
+	}
+	if since != nil {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "ExecutedTime",
+			Verb:    entitystore.FilterVerbAfter,
+			Object:  time.Unix(*since, 0),
+		})
+	}
+	if before != nil {
+		filter.Add(entitystore.FilterStat{
+			Scope:   entitystore.FilterScopeExtra,
+			Subject: "ExecutedTime",
+			Verb:    entitystore.FilterVerbBefore,
+			Object:  time.Unix(*before, 0),
+		})
+	}
+	return filter
+}
@@ -0,0 +1,59 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/pkg/errors"
+)
+
+// validateSchema validates the JSON document against the JSON schema and returns an error message per invalid
+// field, with field names starting with root. An error is returned if the schema itself can't be used.
+func validateSchema(root string, schema interface{}, document []byte) (fieldErrors []string, err error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding schema")
+	}
+	s := new(spec.Schema)
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, errors.Wrap(err, "decoding schema")
+	}
+
+	var data interface{}
+	if len(document) > 0 {
+		if err := json.Unmarshal(document, &data); err != nil {
+			return []string{fmt.Sprintf("%s is not valid JSON: %s", root, err)}, nil
+		}
+	}
+
+	// The validator panics on schemas with unresolvable references
+	defer func() {
+		if r := recover(); r != nil {
+			fieldErrors, err = nil, errors.Errorf("invalid schema: %v", r)
+		}
+	}()
+	result := validate.NewSchemaValidator(s, nil, root, strfmt.Default).Validate(data)
+	for _, e := range result.Errors {
+		fieldErrors = append(fieldErrors, e.Error())
+	}
+	return fieldErrors, nil
+}
+
+// isJSON returns whether a content type is JSON, application/json or a +json type
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,70 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+import (
+	"path"
+	"sort"
+	"sync"
+)
+
+// sourceLocks are locks on source archives, keyed by the object name (content address) of the archive. A lock is held
+// from storing an archive until a function refers to it, and while collecting the archive, so that an archive being
+// shared by a new function is not collected in between.
+type sourceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sourceLock
+}
+
+type sourceLock struct {
+	sync.Mutex
+	refs int
+}
+
+// sourceKey is the lock key of the archive stored at a source URL
+func sourceKey(sourceURL string) string {
+	return path.Base(sourceURL)
+}
+
+// lock locks the archives with the given keys, and returns the function unlocking them. Keys are locked in order, so
+// that locking several archives at once does not deadlock.
+func (l *sourceLocks) lock(keys ...string) func() {
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+	var locked []*sourceLock
+	var lockedKeys []string
+	for i, key := range keys {
+		if i > 0 && key == keys[i-1] {
+			continue
+		}
+		l.mu.Lock()
+		if l.locks == nil {
+			l.locks = make(map[string]*sourceLock)
+		}
+		lock, ok := l.locks[key]
+		if !ok {
+			lock = &sourceLock{}
+			l.locks[key] = lock
+		}
+		lock.refs++
+		l.mu.Unlock()
+
+		lock.Lock()
+		locked = append(locked, lock)
+		lockedKeys = append(lockedKeys, key)
+	}
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, lock := range locked {
+			lock.Unlock()
+			lock.refs--
+			if lock.refs == 0 {
+				delete(l.locks, lockedKeys[i])
+			}
+		}
+	}
+}
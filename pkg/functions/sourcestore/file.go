@@ -0,0 +1,114 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package sourcestore
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/functions/config"
+)
+
+type fileStore struct {
+	rootPath string
+}
+
+// NewFile creates a source store keeping archives in a (shared) file system
+func NewFile(fileConfig *config.StorageFileConfig) SourceStore {
+	return &fileStore{rootPath: fileConfig.SourceRootPath}
+}
+
+func (s *fileStore) Put(ctx context.Context, org, project string, source []byte) (string, error) {
+	sourceDir := filepath.Join(s.rootPath, org, project)
+	sourcePath := filepath.Join(sourceDir, ObjectName(source))
+	if _, err := os.Stat(sourcePath); err == nil {
+		return fileURL(sourcePath), nil
+	}
+	if err := os.MkdirAll(sourceDir, 0700); err != nil {
+		return "", errors.Wrapf(err, "error creating source directory")
+	}
+	// Write to a temporary file first, so concurrent uploads of the same source never expose a partial archive
+	tmpfile, err := ioutil.TempFile(sourceDir, ".upload-")
+	if err != nil {
+		return "", errors.Wrapf(err, "error creating temp file")
+	}
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.Write(source)
+	if closeErr := tmpfile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "error writing file")
+	}
+	if err := os.Rename(tmpfile.Name(), sourcePath); err != nil {
+		return "", errors.Wrapf(err, "error writing file")
+	}
+	return fileURL(sourcePath), nil
+}
+
+func (s *fileStore) Get(ctx context.Context, sourceURL string) ([]byte, error) {
+	sourcePath, err := s.path(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	source, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NotFound{err}
+		}
+		return nil, errors.Wrapf(err, "error reading file")
+	}
+	return source, nil
+}
+
+func (s *fileStore) Delete(ctx context.Context, sourceURL string) error {
+	sourcePath, err := s.path(sourceURL)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(sourcePath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error removing file")
+	}
+	return nil
+}
+
+func (s *fileStore) List(ctx context.Context, org, project string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.rootPath, org, project, "*.tgz"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing files")
+	}
+	urls := make([]string, 0, len(paths))
+	for _, path := range paths {
+		urls = append(urls, fileURL(path))
+	}
+	return urls, nil
+}
+
+// path returns the file path of a source URL, making sure it points into the source root
+func (s *fileStore) path(sourceURL string) (string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "error parsing source URL")
+	}
+	if u.Scheme != "file" {
+		return "", errors.Errorf("unexpected source URL scheme: %s", u.Scheme)
+	}
+	sourcePath := filepath.Clean(u.Path)
+	if !strings.HasPrefix(sourcePath, filepath.Clean(s.rootPath)+string(filepath.Separator)) {
+		return "", errors.Errorf("source URL %s is outside of the source root", sourceURL)
+	}
+	return sourcePath, nil
+}
+
+func fileURL(path string) string {
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,55 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package sourcestore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/dispatch/pkg/functions/config"
+)
+
+func TestFileStore(t *testing.T) {
+	root, err := ioutil.TempDir("", "sourcestore")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	ctx := context.Background()
+	store := NewFile(&config.StorageFileConfig{SourceRootPath: root})
+
+	u1, err := store.Put(ctx, "org", "project", []byte("source1"))
+	require.NoError(t, err)
+	u2, err := store.Put(ctx, "org", "project", []byte("source1"))
+	require.NoError(t, err)
+	assert.Equal(t, u1, u2)
+	u3, err := store.Put(ctx, "org", "project", []byte("source2"))
+	require.NoError(t, err)
+	assert.NotEqual(t, u1, u3)
+
+	urls, err := store.List(ctx, "org", "project")
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+
+	source, err := store.Get(ctx, u1)
+	require.NoError(t, err)
+	assert.Equal(t, "source1", string(source))
+
+	require.NoError(t, store.Delete(ctx, u1))
+	require.NoError(t, store.Delete(ctx, u1))
+	_, err = store.Get(ctx, u1)
+	assert.IsType(t, NotFound{}, err)
+
+	urls, err = store.List(ctx, "org", "project")
+	require.NoError(t, err)
+	assert.Equal(t, []string{u3}, urls)
+
+	assert.Error(t, store.Delete(ctx, "file:///etc/passwd"))
+}
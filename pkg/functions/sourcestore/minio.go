@@ -0,0 +1,133 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package sourcestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	minio "github.com/minio/minio-go"
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/functions/config"
+)
+
+const minioContentType = "application/tar+gz"
+
+type minioStore struct {
+	client   *minio.Client
+	address  string
+	location string
+}
+
+// NewMinio creates a source store keeping archives in minio (or any other S3 compatible storage)
+func NewMinio(minioConfig *config.StorageMinioConfig) (SourceStore, error) {
+	client, err := minio.New(minioConfig.MinioAddress, minioConfig.Username, minioConfig.Password, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create minio client")
+	}
+	return &minioStore{
+		client:   client,
+		address:  minioConfig.MinioAddress,
+		location: string(minioConfig.Location),
+	}, nil
+}
+
+func (s *minioStore) Put(ctx context.Context, org, project string, source []byte) (string, error) {
+	bucketName := bucketName(org, project)
+	name := ObjectName(source)
+	if _, err := s.client.StatObject(bucketName, name, minio.StatObjectOptions{}); err == nil {
+		return s.url(bucketName, name), nil
+	}
+
+	if err := s.client.MakeBucket(bucketName, s.location); err != nil {
+		if _, err := s.client.BucketExists(bucketName); err != nil {
+			return "", errors.Wrapf(err, "failed to create minio bucket")
+		}
+	}
+	opts := minio.PutObjectOptions{ContentType: minioContentType}
+	if _, err := s.client.PutObject(bucketName, name, bytes.NewReader(source), int64(len(source)), opts); err != nil {
+		return "", errors.Wrapf(err, "error putting file to minio bucket")
+	}
+	return s.url(bucketName, name), nil
+}
+
+func (s *minioStore) Get(ctx context.Context, sourceURL string) ([]byte, error) {
+	bucketName, name, err := s.parse(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(bucketName, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting file from minio bucket")
+	}
+	defer object.Close()
+	source, err := ioutil.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, NotFound{err}
+		}
+		return nil, errors.Wrapf(err, "error reading file from minio bucket")
+	}
+	return source, nil
+}
+
+func (s *minioStore) Delete(ctx context.Context, sourceURL string) error {
+	bucketName, name, err := s.parse(sourceURL)
+	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(bucketName, name); err != nil {
+		return errors.Wrapf(err, "error removing file from minio bucket")
+	}
+	return nil
+}
+
+func (s *minioStore) List(ctx context.Context, org, project string) ([]string, error) {
+	bucketName := bucketName(org, project)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var urls []string
+	for object := range s.client.ListObjectsV2(bucketName, "", false, doneCh) {
+		if object.Err != nil {
+			if minio.ToErrorResponse(object.Err).Code == "NoSuchBucket" {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(object.Err, "error listing files in minio bucket")
+		}
+		urls = append(urls, s.url(bucketName, object.Key))
+	}
+	return urls, nil
+}
+
+func (s *minioStore) url(bucketName, name string) string {
+	return fmt.Sprintf("minio://%s/%s/%s", s.address, bucketName, name)
+}
+
+// parse returns the bucket and object name of a source URL
+func (s *minioStore) parse(sourceURL string) (string, string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "error parsing source URL")
+	}
+	if u.Scheme != "minio" {
+		return "", "", errors.Errorf("unexpected source URL scheme: %s", u.Scheme)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid source URL: %s", sourceURL)
+	}
+	return parts[0], parts[1], nil
+}
+
+func bucketName(org, project string) string {
+	return fmt.Sprintf("%s-%s", org, project)
+}
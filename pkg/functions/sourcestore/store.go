@@ -0,0 +1,58 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package sourcestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware/dispatch/pkg/functions/config"
+)
+
+// SourceStore persists function source archives. Archives are addressed by the SHA-256 digest of their content,
+// so storing identical sources twice yields the same URL.
+type SourceStore interface {
+	// Put stores a source archive and returns the URL the archive is fetched from
+	Put(ctx context.Context, org, project string, source []byte) (string, error)
+	// Get returns the source archive stored at the URL
+	Get(ctx context.Context, sourceURL string) ([]byte, error)
+	// Delete removes the source archive stored at the URL, deleting a missing archive is not an error
+	Delete(ctx context.Context, sourceURL string) error
+	// List returns the URLs of all source archives of a project
+	List(ctx context.Context, org, project string) ([]string, error)
+}
+
+// NotFound is a typed error meaning that the requested source archive was not found
+type NotFound struct {
+	error
+}
+
+// Cause returns the parent error
+func (nf NotFound) Cause() error {
+	return nf.error
+}
+
+// New creates the source store for the storage configuration
+func New(storageConfig *config.StorageConfig) (SourceStore, error) {
+	switch storageConfig.Storage {
+	case config.Minio:
+		return NewMinio(storageConfig.Minio)
+	case config.File:
+		return NewFile(storageConfig.File), nil
+	default:
+		return nil, errors.Errorf("unknown storage type: %s", storageConfig.Storage)
+	}
+}
+
+// ObjectName is the content address of a source archive, it is the last element of the URL the archive is stored at
+func ObjectName(source []byte) string {
+	digest := sha256.Sum256(source)
+	return fmt.Sprintf("%s.tgz", hex.EncodeToString(digest[:]))
+}
@@ -0,0 +1,35 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package functions
+
+// workerPool executes jobs on a fixed number of goroutines
+type workerPool struct {
+	jobs chan func()
+}
+
+func newWorkerPool(workers, queueSize int) *workerPool {
+	p := &workerPool{
+		jobs: make(chan func(), queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// submit queues a job for execution, it returns false if the queue is full
+func (p *workerPool) submit(job func()) bool {
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
@@ -19,11 +19,13 @@ type AllInOneRouter struct {
 	EventsHandler     http.Handler
 	FunctionsHandler  http.Handler
 	SecretsHandler    http.Handler
+	ConfigsHandler    http.Handler
 	BaseImagesHandler http.Handler
 	ImagesHandler     http.Handler
 	IdentityHandler   http.Handler
 	ServicesHandler   http.Handler
 	EndpointsHandler  http.Handler
+	AppsHandler       http.Handler
 }
 
 // ServeHTTP implements the http.Handler interface
@@ -48,6 +50,8 @@ func (d *AllInOneRouter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		d.FunctionsHandler.ServeHTTP(rw, r)
 	case "secret":
 		d.SecretsHandler.ServeHTTP(rw, r)
+	case "config":
+		d.ConfigsHandler.ServeHTTP(rw, r)
 	case "baseimage":
 		d.BaseImagesHandler.ServeHTTP(rw, r)
 	case "image":
@@ -56,7 +60,11 @@ func (d *AllInOneRouter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		d.EventsHandler.ServeHTTP(rw, r)
 	case "endpoint":
 		d.EndpointsHandler.ServeHTTP(rw, r)
-	case "iam", "eventdrivers", "application", "serviceclass", "serviceinstance":
+	case "serviceclass", "serviceinstance":
+		d.ServicesHandler.ServeHTTP(rw, r)
+	case "application":
+		d.AppsHandler.ServeHTTP(rw, r)
+	case "iam", "eventdrivers":
 		rw.Header().Add("Content-type", "application/json")
 		rw.WriteHeader(http.StatusNotImplemented)
 		rw.Write(notImplementedError())
@@ -75,6 +75,26 @@ func TestAllInOneRouter(t *testing.T) {
 			expectedCode: http.StatusOK,
 			expectedBody: "secretsHandler",
 		},
+		{
+			url:          "http://localhost:8080/v1/config",
+			expectedCode: http.StatusOK,
+			expectedBody: "configsHandler",
+		},
+		{
+			url:          "http://localhost:8080/v1/serviceclass",
+			expectedCode: http.StatusOK,
+			expectedBody: "servicesHandler",
+		},
+		{
+			url:          "http://localhost:8080/v1/serviceinstance/db",
+			expectedCode: http.StatusOK,
+			expectedBody: "servicesHandler",
+		},
+		{
+			url:          "http://localhost:8080/v1/application/shop",
+			expectedCode: http.StatusOK,
+			expectedBody: "appsHandler",
+		},
 	}
 
 	for _, c := range cases {
@@ -118,9 +138,18 @@ func createRouter() *AllInOneRouter {
 	secretsMock := &mocks.HandlerMock{}
 	secretsMock.On("ServeHTTP", mock.Anything, mock.Anything).Run(testHandler("secretsHandler"))
 	r.SecretsHandler = secretsMock
+	configsMock := &mocks.HandlerMock{}
+	configsMock.On("ServeHTTP", mock.Anything, mock.Anything).Run(testHandler("configsHandler"))
+	r.ConfigsHandler = configsMock
 	endpointsMock := &mocks.HandlerMock{}
 	endpointsMock.On("ServeHTTP", mock.Anything, mock.Anything).Run(testHandler("endpointHandler"))
 	r.EndpointsHandler = endpointsMock
+	servicesMock := &mocks.HandlerMock{}
+	servicesMock.On("ServeHTTP", mock.Anything, mock.Anything).Run(testHandler("servicesHandler"))
+	r.ServicesHandler = servicesMock
+	appsMock := &mocks.HandlerMock{}
+	appsMock.On("ServeHTTP", mock.Anything, mock.Anything).Run(testHandler("appsHandler"))
+	r.AppsHandler = appsMock
 	return r
 }
 
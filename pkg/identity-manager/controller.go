@@ -14,12 +14,14 @@ import (
 	"github.com/vmware/dispatch/pkg/entity-store"
 )
 
-// NewIdentityController creates a new controller to manage the reconciliation of policy entities
-func NewIdentityController(store entitystore.EntityStore, enforcer *casbin.SyncedEnforcer, resync time.Duration, zookeeper string) controller.Controller {
+// NewIdentityController creates a new controller to manage the reconciliation of policy entities, elector is optional
+// and elects the replica reconciling them
+func NewIdentityController(store entitystore.EntityStore, enforcer *casbin.SyncedEnforcer, resync time.Duration, elector controller.LeaderElector) controller.Controller {
 	c := controller.NewController(controller.Options{
-		ResyncPeriod:      resync,
-		Workers:           5, // TODO: make this configurable
-		ZookeeperLocation: zookeeper,
+		ResyncPeriod:  resync,
+		Workers:       5, // TODO: make this configurable
+		Store:         store,
+		LeaderElector: elector,
 	})
 
 	c.AddEntityHandler(&policyEntityHandler{store: store, enforcer: enforcer})
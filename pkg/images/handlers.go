@@ -136,7 +136,15 @@ func (h *defaultHandlers) getImage(params image.GetImageByNameParams, principal
 	org := h.namespace
 	project := *params.XDispatchProject
 	log.Debugf("getting image %s in %s:%s", name, org, project)
-	img, err := h.backend.GetImage(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+	var img *dapi.Image
+	err := utils.WaitForStatus(ctx, params.WaitFor, params.WaitTimeout, func() (dapi.Status, error) {
+		var err error
+		img, err = h.backend.GetImage(ctx, &dapi.Meta{Name: name, Org: org, Project: project})
+		if err != nil {
+			return "", err
+		}
+		return img.Status, nil
+	})
 	if err != nil {
 		if derrors.IsObjectNotFound(err) {
 			log.Debugf("image %s in %s:%s not found", name, org, project)
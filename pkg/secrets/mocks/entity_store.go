@@ -148,3 +148,26 @@ func (_m *EntityStore) Update(ctx context.Context, lastRevision uint64, entity e
 func (_m *EntityStore) UpdateWithError(ctx context.Context, e entitystore.Entity, err error) {
 	_m.Called(ctx, e, err)
 }
+
+// Watch provides a mock function with given fields: ctx, dataType, organizationID, fromRevision
+func (_m *EntityStore) Watch(ctx context.Context, dataType entitystore.DataType, organizationID string, fromRevision uint64) (<-chan entitystore.WatchEvent, error) {
+	ret := _m.Called(ctx, dataType, organizationID, fromRevision)
+
+	var r0 <-chan entitystore.WatchEvent
+	if rf, ok := ret.Get(0).(func(context.Context, entitystore.DataType, string, uint64) <-chan entitystore.WatchEvent); ok {
+		r0 = rf(ctx, dataType, organizationID, fromRevision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan entitystore.WatchEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, entitystore.DataType, string, uint64) error); ok {
+		r1 = rf(ctx, dataType, organizationID, fromRevision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -9,9 +9,11 @@ package web
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
 
 	log "github.com/sirupsen/logrus"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/vmware/dispatch/pkg/secrets/service"
 	"github.com/vmware/dispatch/pkg/trace"
 	"github.com/vmware/dispatch/pkg/utils"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
 )
 
 // Handlers encapsulates the secret store handlers
@@ -61,6 +64,14 @@ func (h *Handlers) addSecret(params secret.AddSecretParams) middleware.Responder
 
 	utils.AdjustMeta(&params.Secret.Meta, v1.Meta{Org: org, Project: project})
 
+	// Binding credentials of service instances are stored under reserved names
+	if strings.HasPrefix(*params.Secret.Name, knaming.BindingSecretPrefix) {
+		return secret.NewAddSecretBadRequest().WithPayload(&v1.Error{
+			Code:    http.StatusBadRequest,
+			Message: utils.ErrorMsgBadRequest("secret", *params.Secret.Name, errors.Errorf("names starting with %s are reserved", knaming.BindingSecretPrefix)),
+		})
+	}
+
 	vmwSecret, err := h.secretsService.AddSecret(ctx, params.Secret)
 	if err != nil {
 		if entitystore.IsUniqueViolation(err) {
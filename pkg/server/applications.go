@@ -0,0 +1,31 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package dispatchserver
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/loads"
+	log "github.com/sirupsen/logrus"
+
+	applicationmanager "github.com/vmware/dispatch/pkg/application-manager"
+	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi"
+	"github.com/vmware/dispatch/pkg/application-manager/gen/restapi/operations"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+func initApplications(config *serverConfig, store entitystore.EntityStore) http.Handler {
+	swaggerSpec, err := loads.Analyzed(restapi.FlatSwaggerJSON, "")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	api := operations.NewApplicationManagerAPI(swaggerSpec)
+	handlers := applicationmanager.NewHandlers(config.Namespace, store)
+	applicationmanager.ConfigureHandlers(api, handlers)
+
+	return api.Serve(nil)
+}
@@ -6,6 +6,8 @@
 package dispatchserver
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
@@ -23,12 +25,37 @@ type serverConfig struct {
 	MinioPassword  string `mapstructure:"minio-password" json:"minio-password,omitempty"`
 	MinioAddress   string `mapstructure:"minio-address" json:"minio-address,omitempty"`
 
+	// Functions backend [knative/local (no cluster, for development)]
+	FunctionsBackend string   `mapstructure:"functions-backend" json:"functions-backend"`
+	LocalWorkDir     string   `mapstructure:"local-work-dir" json:"local-work-dir,omitempty"`
+	LocalCommands    []string `mapstructure:"local-command" json:"local-command,omitempty"`
+
+	DatabaseBackend  string `mapstructure:"db-backend" json:"db-backend"`
+	DatabaseAddress  string `mapstructure:"db-address" json:"db-address"`
+	DatabaseUsername string `mapstructure:"db-username" json:"db-username,omitempty"`
+	DatabasePassword string `mapstructure:"db-password" json:"db-password,omitempty"`
+	DatabaseName     string `mapstructure:"db-database" json:"db-database"`
+
+	RunRetentionAge   time.Duration `mapstructure:"run-retention-age" json:"run-retention-age"`
+	RunRetentionCount int           `mapstructure:"run-retention-count" json:"run-retention-count"`
+	RunWorkers        int           `mapstructure:"run-workers" json:"run-workers"`
+	RunQueueSize      int           `mapstructure:"run-queue-size" json:"run-queue-size"`
+	ValidateOutput    bool          `mapstructure:"validate-output" json:"validate-output"`
+
+	ServiceBrokers      []string      `mapstructure:"service-brokers" json:"service-brokers,omitempty"`
+	ServicePollInterval time.Duration `mapstructure:"service-poll-interval" json:"service-poll-interval"`
+
 	BuildImage       string `mapstructure:"build-image" json:"build-image"`
 	IngressGatewayIP string `mapstructure:"ingress-gateway-ip" json:"ingress-gateway-ip"`
 	InternalGateway  string `mapstructure:"internal-gateway" json:"internal-gateway"`
 	SharedGateway    string `mapstructure:"shared-gateway" json:"shared-gateway"`
 	DispatchHost     string `mapstructure:"dispatch-host" json:"dispatch-host"`
 
+	EndpointsBackend     string `mapstructure:"endpoints-backend" json:"endpoints-backend"`
+	EndpointsProxyHost   string `mapstructure:"endpoints-proxy-host" json:"endpoints-proxy-host"`
+	EndpointsProxyPort   int    `mapstructure:"endpoints-proxy-port" json:"endpoints-proxy-port"`
+	EndpointsGatewayPort int    `mapstructure:"endpoints-gateway-port" json:"endpoints-gateway-port"`
+
 	Host              string `mapstructure:"host" json:"host"`
 	Port              int    `mapstructure:"port" json:"port"`
 	DisableHTTP       bool   `mapstructure:"disable-http" json:"disable-http"`
@@ -56,11 +83,34 @@ func configGlobalFlags(flags *pflag.FlagSet) {
 	flags.String("minio-password", "", "Minio password")
 	flags.String("minio-address", "minio.minio.svc.cluster.local:9000", "Minio address (host:port)")
 
+	flags.String("functions-backend", "knative", "Functions backend [knative|local]")
+	flags.String("local-work-dir", "./functions", "Directory function sources are unpacked to by the local backend")
+	flags.StringArray("local-command", nil, "Command serving functions on $PORT as [LANGUAGE=]COMMAND, run by the local backend in the function source directory, without a language it serves functions of other languages")
+
+	flags.String("db-backend", "boltdb", "Database backend [boltdb|postgres]")
+	flags.String("db-address", "./db.bolt", "Database address (file path for boltdb, host:port for postgres)")
+	flags.String("db-username", "", "Database username")
+	flags.String("db-password", "", "Database password")
+	flags.String("db-database", "dispatch", "Database name (bucket for boltdb)")
+
+	flags.Duration("run-retention-age", 7*24*time.Hour, "How long function runs are kept (0 keeps runs forever)")
+	flags.Int("run-retention-count", 100, "Number of most recent runs kept per function (0 keeps all runs)")
+	flags.Int("run-workers", 10, "Number of non-blocking function runs executed concurrently")
+	flags.Int("run-queue-size", 100, "Number of non-blocking function runs queued before new runs are rejected")
+	flags.Bool("validate-output", false, "Check function outputs against the output schema of the function")
+
+	flags.StringSlice("service-brokers", nil, "Open Service Broker brokers as name=URL, credentials in the URL are used for basic authentication")
+	flags.Duration("service-poll-interval", 5*time.Second, "How often asynchronous service broker operations are polled")
+
 	flags.String("build-image", defaultBuildImage, "Docker image for building functions")
 	flags.String("ingress-gateway-ip", "", "IP of knative ingress gateway (default is empty)")
 	flags.String("internal-gateway", "knative-ingressgateway.istio-system.svc.cluster.local", "Knative/Istio internal gateway")
 	flags.String("shared-gateway", "knative-shared-gateway.knative-serving.svc.cluster.local", "Knative/Istio shared gateway")
 	flags.String("dispatch-host", "dispatch.local", "Dispatch host DNS name")
+	flags.String("endpoints-backend", "knative", "Endpoints backend [knative|gateway]")
+	flags.String("endpoints-proxy-host", "dispatch-server.dispatch.svc.cluster.local", "Host the gateway reaches the endpoints proxy at, which authenticates the callers of non-public endpoints")
+	flags.Int("endpoints-proxy-port", 8082, "Port the endpoints proxy listens on")
+	flags.Int("endpoints-gateway-port", 8083, "Port the built-in endpoints gateway listens on, for the gateway backend")
 
 	flags.String("host", "127.0.0.1", "Host/IP to listen on")
 	flags.Int("port", 8080, "HTTP port to listen on")
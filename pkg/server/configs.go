@@ -0,0 +1,42 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package dispatchserver
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/loads"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/vmware/dispatch/pkg/configs/gen/restapi"
+	"github.com/vmware/dispatch/pkg/configs/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/configs/service"
+	"github.com/vmware/dispatch/pkg/configs/web"
+	"github.com/vmware/dispatch/pkg/functions/backend"
+)
+
+func initConfigs(config *serverConfig, functionsBackend backend.Backend) http.Handler {
+	swaggerSpec, err := loads.Analyzed(restapi.FlatSwaggerJSON, "")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	api := operations.NewConfigsAPI(swaggerSpec)
+
+	configsService := &service.K8sConfigsService{
+		K8sAPI: k8sClient(config.K8sConfig).CoreV1(),
+	}
+	// Functions are rolled by backends which support it
+	if roller, ok := functionsBackend.(service.FunctionRoller); ok {
+		configsService.Functions = roller
+	}
+
+	handlers := web.NewHandlers(configsService)
+
+	web.ConfigureHandlers(api, handlers)
+
+	return api.Serve(nil)
+}
@@ -13,19 +13,29 @@ import (
 
 func runDispatch(config *serverConfig) {
 
-	functionsHandler := initFunctions(config)
+	store := entityStore(config)
+
+	functionsHandler, functionsBackend := initFunctions(config, store)
 	secretsHandler := initSecrets(config)
+	configsHandler := initConfigs(config, functionsBackend)
 	baseImagesHandler := initBaseImages(config)
 	imagesHandler := initImages(config)
-	endpointsHandler := initEndpoints(config)
+	endpointsHandler, endpointsServer, endpointsPort := initEndpoints(config, store, functionsHandler)
+	servicesHandler := initServices(config, store, functionsBackend)
+	appsHandler := initApplications(config, store)
 
 	dispatchHandler := &http.AllInOneRouter{
 		FunctionsHandler:  functionsHandler,
 		SecretsHandler:    secretsHandler,
+		ConfigsHandler:    configsHandler,
 		BaseImagesHandler: baseImagesHandler,
 		ImagesHandler:     imagesHandler,
 		EndpointsHandler:  endpointsHandler,
+		ServicesHandler:   servicesHandler,
+		AppsHandler:       appsHandler,
 	}
+	go serveEndpoints(config, endpointsServer, endpointsPort)
+
 	handler := addMiddleware(dispatchHandler)
 	server := httpServer(config)
 	server.SetHandler(handler)
@@ -6,25 +6,94 @@
 package dispatchserver
 
 import (
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/go-openapi/loads"
 	log "github.com/sirupsen/logrus"
+	"github.com/vmware/dispatch/pkg/client"
 	"github.com/vmware/dispatch/pkg/endpoints"
+	"github.com/vmware/dispatch/pkg/endpoints/auth"
+	"github.com/vmware/dispatch/pkg/endpoints/backend"
 	"github.com/vmware/dispatch/pkg/endpoints/gen/restapi"
 	"github.com/vmware/dispatch/pkg/endpoints/gen/restapi/operations"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	functionsclient "github.com/vmware/dispatch/pkg/functions/gen/client"
+	secretsservice "github.com/vmware/dispatch/pkg/secrets/service"
 )
 
-func initEndpoints(config *serverConfig) http.Handler {
+// initEndpoints returns the handler of the endpoints API, and the handler of the requests to endpoints with the port
+// it is served on: the endpoints proxy behind the Knative gateway, or the built-in gateway running functions with
+// functionsHandler and keeping endpoints in store
+func initEndpoints(config *serverConfig, store entitystore.EntityStore, functionsHandler http.Handler) (http.Handler, http.Handler, int) {
 	swaggerSpec, err := loads.Analyzed(restapi.FlatSwaggerJSON, "2.0")
 	if err != nil {
 		log.Fatalln(err)
 	}
 	api := operations.NewEndpointsAPI(swaggerSpec)
-	handlers := endpoints.NewHandlers(
-		config.K8sConfig, config.Namespace, config.InternalGateway,
-		config.SharedGateway, config.DispatchHost)
+
+	// Basic authentication and API keys are kept in secrets
+	secretsService := &secretsservice.K8sSecretsService{
+		K8sAPI: k8sClient(config.K8sConfig).CoreV1(),
+	}
+	authenticator := auth.NewAuthenticator(secretsService, nil)
+
+	var endpointsBackend backend.Backend
+	var handler http.Handler
+	var port int
+	switch config.EndpointsBackend {
+	case "knative":
+		endpointsBackend = backend.Knative(config.K8sConfig, backend.KnativeConfig{
+			InternalGateway: config.InternalGateway,
+			SharedGateway:   config.SharedGateway,
+			DispatchHost:    config.DispatchHost,
+			ProxyHost:       config.EndpointsProxyHost,
+			ProxyPort:       config.EndpointsProxyPort,
+		})
+		handler = endpoints.NewProxy(endpointsBackend, authenticator, config.InternalGateway)
+		port = config.EndpointsProxyPort
+	case "gateway":
+		router, err := backend.Gateway(backend.GatewayConfig{DispatchHost: config.DispatchHost}, store)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		proxy := endpoints.NewRunProxy(router, authenticator, functionsClients(config, functionsHandler))
+		endpointsBackend, handler, port = router, router.Handler(proxy), config.EndpointsGatewayPort
+	default:
+		log.Fatalf("incompatible endpoints backend %s", config.EndpointsBackend)
+	}
+
+	handlers := endpoints.NewHandlers(config.Namespace, config.DispatchHost, endpointsBackend)
 	endpoints.ConfigureHandlers(api, handlers)
 
-	return api.Serve(nil)
+	return api.Serve(nil), handler, port
+}
+
+// functionsClients returns the clients of the functions API of dispatch-server, one per project.  They call the
+// functions handler in process, so that runs neither need credentials nor depend on the listeners of the server.
+func functionsClients(config *serverConfig, functionsHandler http.Handler) endpoints.FunctionsClients {
+	transport := client.HandlerHTTPClient(functionsHandler, functionsclient.DefaultBasePath)
+
+	var lock sync.Mutex
+	clients := make(map[string]client.FunctionsClient)
+	return func(project string) client.FunctionsClient {
+		lock.Lock()
+		defer lock.Unlock()
+		if c, ok := clients[project]; ok {
+			return c
+		}
+		c := client.NewFunctionsClientWithTransport(transport, nil, config.Namespace, project)
+		clients[project] = c
+		return c
+	}
+}
+
+// serveEndpoints serves the requests to endpoints on their own port
+func serveEndpoints(config *serverConfig, handler http.Handler, port int) {
+	addr := fmt.Sprintf("%s:%d", config.Host, port)
+	log.Infof("Serving endpoints (%s backend) at http://%s", config.EndpointsBackend, addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Errorf("error serving endpoints: %v", err)
+	}
 }
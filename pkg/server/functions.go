@@ -6,8 +6,10 @@
 package dispatchserver
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-openapi/loads"
 	"github.com/go-openapi/loads/fmts"
@@ -15,19 +17,26 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/vmware/dispatch/pkg/client"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
 	"github.com/vmware/dispatch/pkg/functions"
+	"github.com/vmware/dispatch/pkg/functions/backend"
 	fconfig "github.com/vmware/dispatch/pkg/functions/config"
 	"github.com/vmware/dispatch/pkg/functions/gen/restapi"
 	"github.com/vmware/dispatch/pkg/functions/gen/restapi/operations"
+	"github.com/vmware/dispatch/pkg/functions/sourcestore"
 )
 
-const defaultBuildImage = "dispatchframework/dispatch-knative-builder:0.0.2"
+const (
+	defaultBuildImage = "dispatchframework/dispatch-knative-builder:0.0.2"
+	runReapPeriod     = time.Minute
+)
 
 func init() {
 	loads.AddLoader(fmts.YAMLMatcher, fmts.YAMLDoc)
 }
 
-func initFunctions(config *serverConfig) http.Handler {
+// initFunctions returns the handler of the functions API and the functions backend it uses
+func initFunctions(config *serverConfig, store entitystore.EntityStore) (http.Handler, backend.Backend) {
 	swaggerSpec, err := loads.Analyzed(restapi.FlatSwaggerJSON, "2.0")
 	if err != nil {
 		log.Fatalln(err)
@@ -62,15 +71,49 @@ func initFunctions(config *serverConfig) http.Handler {
 		log.Fatalf("incompatible storage config %s", config.Storage)
 	}
 
-	k8sClient := k8sClient(config.K8sConfig)
-	imageRegistryURL, err := registryURL(k8sClient, config.ImageRegistry, config.Namespace)
+	sourceStore, err := sourcestore.New(storageConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
+	var functionsBackend backend.Backend
+	imageRegistryURL := config.ImageRegistry
+	switch config.FunctionsBackend {
+	case "knative":
+		k8sClient := k8sClient(config.K8sConfig)
+		imageRegistryURL, err = registryURL(k8sClient, config.ImageRegistry, config.Namespace)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		functionsBackend = backend.Knative(config.K8sConfig, config.IngressGatewayIP, config.BuildImage, storageConfig)
+	case "local":
+		commands, err := backend.ParseLocalCommands(config.LocalCommands)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		functionsBackend = backend.Local(&backend.LocalConfig{
+			WorkDir:  config.LocalWorkDir,
+			Commands: commands,
+		}, sourceStore, imagesClient)
+	default:
+		log.Fatalf("incompatible functions backend %s", config.FunctionsBackend)
+	}
+
+	runnerConfig := &fconfig.RunnerConfig{
+		Workers:        config.RunWorkers,
+		QueueSize:      config.RunQueueSize,
+		ValidateOutput: config.ValidateOutput,
+	}
+
 	handlers := functions.NewHandlers(
-		config.K8sConfig, config.Namespace, imageRegistryURL, config.IngressGatewayIP, config.BuildImage, storageConfig, imagesClient)
+		config.Namespace, imageRegistryURL, functionsBackend, sourceStore, imagesClient, store, runnerConfig)
 	functions.ConfigureHandlers(api, handlers)
 
-	return api.Serve(nil)
+	reaper := functions.NewRunReaper(store, &fconfig.RunRetentionConfig{
+		MaxAge:   config.RunRetentionAge,
+		MaxCount: config.RunRetentionCount,
+	})
+	go reaper.Run(context.Background(), runReapPeriod)
+
+	return api.Serve(nil), functionsBackend
 }
@@ -0,0 +1,57 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package dispatchserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/loads"
+	log "github.com/sirupsen/logrus"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/functions/backend"
+	secretsservice "github.com/vmware/dispatch/pkg/secrets/service"
+	servicemanager "github.com/vmware/dispatch/pkg/service-manager"
+	"github.com/vmware/dispatch/pkg/service-manager/broker"
+	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi"
+	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations"
+)
+
+func initServices(config *serverConfig, store entitystore.EntityStore, functionsBackend backend.Backend) http.Handler {
+	swaggerSpec, err := loads.Analyzed(restapi.FlatSwaggerJSON, "")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	api := operations.NewServicesAPI(swaggerSpec)
+
+	brokers, err := broker.ParseBrokers(config.ServiceBrokers)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Binding credentials are kept in secrets
+	secretsService := &secretsservice.K8sSecretsService{
+		K8sAPI: k8sClient(config.K8sConfig).CoreV1(),
+	}
+
+	// Functions are rolled once their instances are bound by backends which support it
+	var roller servicemanager.FunctionRoller
+	if r, ok := functionsBackend.(servicemanager.FunctionRoller); ok {
+		roller = r
+	}
+
+	manager := servicemanager.NewManager(store, secretsService, brokers, config.ServicePollInterval, roller)
+	handlers := servicemanager.NewHandlers(config.Namespace, store, manager)
+	servicemanager.ConfigureHandlers(api, handlers)
+
+	if err := handlers.Resume(context.Background()); err != nil {
+		log.Errorf("error resuming service instance operations: %+v", err)
+	}
+
+	return api.Serve(nil)
+}
@@ -0,0 +1,26 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package dispatchserver
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+func entityStore(config *serverConfig) entitystore.EntityStore {
+	store, err := entitystore.NewFromBackend(entitystore.BackendConfig{
+		Backend:  config.DatabaseBackend,
+		Address:  config.DatabaseAddress,
+		Username: config.DatabaseUsername,
+		Password: config.DatabasePassword,
+		Bucket:   config.DatabaseName,
+	})
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return store
+}
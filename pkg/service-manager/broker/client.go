@@ -0,0 +1,224 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Client speaks the Open Service Broker API to a broker
+type Client struct {
+	name       string
+	url        *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the broker at rawURL, credentials in the URL are used for basic authentication
+func NewClient(name, rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the URL of broker %s", name)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("unsupported URL scheme %q for broker %s", u.Scheme, name)
+	}
+	c := &Client{
+		name:       name,
+		httpClient: http.DefaultClient,
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+		u.User = nil
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	c.url = u
+	return c, nil
+}
+
+// ParseBrokers creates clients from name=URL pairs
+func ParseBrokers(specs []string) ([]*Client, error) {
+	var clients []*Client
+	names := make(map[string]bool)
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid broker %q, expected name=URL", spec)
+		}
+		if names[parts[0]] {
+			return nil, errors.Errorf("broker %s is registered twice", parts[0])
+		}
+		names[parts[0]] = true
+		c, err := NewClient(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
+
+// Name returns the name the broker is registered with
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Catalog fetches the services offered by the broker
+func (c *Client) Catalog(ctx context.Context) (*Catalog, error) {
+	catalog := new(Catalog)
+	if _, err := c.do(ctx, "GET", "/v2/catalog", nil, nil, catalog, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Provision asks the broker to create an instance, the operation may complete asynchronously
+func (c *Client) Provision(ctx context.Context, instanceID string, request *ProvisionRequest) (*OperationResponse, error) {
+	query := url.Values{"accepts_incomplete": {"true"}}
+	response := new(OperationResponse)
+	status, err := c.do(ctx, "PUT", instancePath(instanceID), query, request, response,
+		http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	response.Async = status == http.StatusAccepted
+	return response, nil
+}
+
+// Deprovision asks the broker to delete an instance, the operation may complete asynchronously. Instances which are
+// already gone are deprovisioned.
+func (c *Client) Deprovision(ctx context.Context, instanceID, serviceID, planID string) (*OperationResponse, error) {
+	query := url.Values{
+		"accepts_incomplete": {"true"},
+		"service_id":         {serviceID},
+		"plan_id":            {planID},
+	}
+	response := new(OperationResponse)
+	status, err := c.do(ctx, "DELETE", instancePath(instanceID), query, nil, response,
+		http.StatusOK, http.StatusAccepted, http.StatusGone)
+	if err != nil {
+		return nil, err
+	}
+	response.Async = status == http.StatusAccepted
+	return response, nil
+}
+
+// LastOperation polls the state of an asynchronous operation on an instance
+func (c *Client) LastOperation(ctx context.Context, instanceID, serviceID, planID, operation string) (*LastOperationResponse, error) {
+	query := url.Values{
+		"service_id": {serviceID},
+		"plan_id":    {planID},
+	}
+	if operation != "" {
+		query.Set("operation", operation)
+	}
+	response := new(LastOperationResponse)
+	status, err := c.do(ctx, "GET", instancePath(instanceID)+"/last_operation", query, nil, response,
+		http.StatusOK, http.StatusGone)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusGone {
+		// The instance is gone, which is how deprovisioning succeeds
+		response.State = StateSucceeded
+	}
+	return response, nil
+}
+
+// Bind asks the broker to create a binding of an instance and returns its credentials
+func (c *Client) Bind(ctx context.Context, instanceID, bindingID string, request *BindRequest) (*BindResponse, error) {
+	response := new(BindResponse)
+	if _, err := c.do(ctx, "PUT", bindingPath(instanceID, bindingID), nil, request, response,
+		http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Unbind asks the broker to delete a binding, bindings which are already gone are unbound
+func (c *Client) Unbind(ctx context.Context, instanceID, bindingID, serviceID, planID string) error {
+	query := url.Values{
+		"service_id": {serviceID},
+		"plan_id":    {planID},
+	}
+	_, err := c.do(ctx, "DELETE", bindingPath(instanceID, bindingID), query, nil, nil,
+		http.StatusOK, http.StatusGone)
+	return err
+}
+
+func instancePath(instanceID string) string {
+	return "/v2/service_instances/" + url.PathEscape(instanceID)
+}
+
+func bindingPath(instanceID, bindingID string) string {
+	return instancePath(instanceID) + "/service_bindings/" + url.PathEscape(bindingID)
+}
+
+// do sends a request to the broker and decodes the response into out, the response status must be one of expected
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}, expected ...int) (int, error) {
+	u := *c.url
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return 0, errors.Wrap(err, "encoding broker request")
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating broker request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Broker-API-Version", APIVersion)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error calling broker %s", c.name)
+	}
+	defer resp.Body.Close()
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error reading the response of broker %s", c.name)
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out != nil && status != http.StatusGone && len(bytes.TrimSpace(payload)) > 0 {
+			if err := json.Unmarshal(payload, out); err != nil {
+				return 0, errors.Wrapf(err, "error decoding the response of broker %s", c.name)
+			}
+		}
+		return status, nil
+	}
+	brokerErr := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(payload, brokerErr); err != nil {
+		brokerErr.Description = strings.TrimSpace(string(payload))
+	}
+	return 0, brokerErr
+}
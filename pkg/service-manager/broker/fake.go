@@ -0,0 +1,254 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package broker
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// FakeInstance is an instance provisioned by the fake broker
+type FakeInstance struct {
+	ServiceID  string
+	PlanID     string
+	Parameters map[string]interface{}
+	Bindings   map[string]map[string]interface{}
+
+	// pending counts the polls left before the last operation succeeds
+	pending int
+	// deleted is set when the pending operation is a deprovision
+	deleted bool
+}
+
+// FakeBroker is an in-memory Open Service Broker, for tests and local development. Credentials of bindings hold
+// the instance and binding IDs along with the provision parameters.
+type FakeBroker struct {
+	// Services is the catalog of the broker
+	Services []Service
+	// Username and Password are required for basic authentication if set
+	Username string
+	Password string
+	// Polls is the number of last operation polls before asynchronous operations complete, operations complete
+	// synchronously if it's 0
+	Polls int
+	// FailProvision makes provisioning fail with the given description
+	FailProvision string
+
+	mu        sync.Mutex
+	instances map[string]*FakeInstance
+}
+
+// NewFakeBroker creates a fake broker offering services
+func NewFakeBroker(services ...Service) *FakeBroker {
+	return &FakeBroker{
+		Services:  services,
+		instances: make(map[string]*FakeInstance),
+	}
+}
+
+// Instance returns a copy of a provisioned instance
+func (b *FakeBroker) Instance(instanceID string) (FakeInstance, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i, ok := b.instances[instanceID]
+	if !ok || i.deleted {
+		return FakeInstance{}, false
+	}
+	return *i, true
+}
+
+// InstanceCount returns the number of provisioned instances
+func (b *FakeBroker) InstanceCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := 0
+	for _, i := range b.instances {
+		if !i.deleted {
+			count++
+		}
+	}
+	return count
+}
+
+// ServeHTTP implements http.Handler
+func (b *FakeBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Broker-API-Version") == "" {
+		b.respond(w, http.StatusPreconditionFailed, &Error{Description: "missing X-Broker-API-Version header"})
+		return
+	}
+	if b.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != b.Username || password != b.Password {
+			b.respond(w, http.StatusUnauthorized, &Error{Description: "unauthorized"})
+			return
+		}
+	}
+
+	// /v2/catalog, /v2/service_instances/:id[/last_operation|/service_bindings/:id]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && parts[1] == "catalog" && r.Method == "GET":
+		b.respond(w, http.StatusOK, &Catalog{Services: b.Services})
+	case len(parts) == 3 && parts[1] == "service_instances" && r.Method == "PUT":
+		b.provision(w, r, parts[2])
+	case len(parts) == 3 && parts[1] == "service_instances" && r.Method == "DELETE":
+		b.deprovision(w, parts[2])
+	case len(parts) == 4 && parts[3] == "last_operation" && r.Method == "GET":
+		b.lastOperation(w, parts[2])
+	case len(parts) == 5 && parts[3] == "service_bindings" && r.Method == "PUT":
+		b.bind(w, parts[2], parts[4])
+	case len(parts) == 5 && parts[3] == "service_bindings" && r.Method == "DELETE":
+		b.unbind(w, parts[2], parts[4])
+	default:
+		b.respond(w, http.StatusNotFound, &Error{Description: "not found"})
+	}
+}
+
+func (b *FakeBroker) provision(w http.ResponseWriter, r *http.Request, instanceID string) {
+	var request ProvisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		b.respond(w, http.StatusBadRequest, &Error{Description: err.Error()})
+		return
+	}
+	if b.plan(request.ServiceID, request.PlanID) == nil {
+		b.respond(w, http.StatusBadRequest, &Error{Description: "unknown service or plan"})
+		return
+	}
+	if b.FailProvision != "" && b.Polls == 0 {
+		b.respond(w, http.StatusInternalServerError, &Error{Description: b.FailProvision})
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i, ok := b.instances[instanceID]; ok && !i.deleted {
+		// Provisioning the same instance again is fine, it's a conflict if it differs
+		if i.ServiceID != request.ServiceID || i.PlanID != request.PlanID {
+			b.respond(w, http.StatusConflict, &Error{Description: "instance already exists"})
+			return
+		}
+		if i.pending > 0 {
+			b.respond(w, http.StatusAccepted, &OperationResponse{Operation: "provision"})
+			return
+		}
+		b.respond(w, http.StatusOK, &OperationResponse{})
+		return
+	}
+	b.instances[instanceID] = &FakeInstance{
+		ServiceID:  request.ServiceID,
+		PlanID:     request.PlanID,
+		Parameters: request.Parameters,
+		Bindings:   make(map[string]map[string]interface{}),
+		pending:    b.Polls,
+	}
+	if b.Polls > 0 {
+		b.respond(w, http.StatusAccepted, &OperationResponse{Operation: "provision"})
+		return
+	}
+	b.respond(w, http.StatusCreated, &OperationResponse{})
+}
+
+func (b *FakeBroker) deprovision(w http.ResponseWriter, instanceID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i, ok := b.instances[instanceID]
+	if !ok {
+		b.respond(w, http.StatusGone, struct{}{})
+		return
+	}
+	if b.Polls > 0 {
+		i.deleted = true
+		i.pending = b.Polls
+		b.respond(w, http.StatusAccepted, &OperationResponse{Operation: "deprovision"})
+		return
+	}
+	delete(b.instances, instanceID)
+	b.respond(w, http.StatusOK, struct{}{})
+}
+
+func (b *FakeBroker) lastOperation(w http.ResponseWriter, instanceID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i, ok := b.instances[instanceID]
+	if !ok {
+		b.respond(w, http.StatusGone, struct{}{})
+		return
+	}
+	if i.pending > 1 {
+		i.pending--
+		b.respond(w, http.StatusOK, &LastOperationResponse{State: StateInProgress})
+		return
+	}
+	if i.deleted {
+		delete(b.instances, instanceID)
+		b.respond(w, http.StatusGone, struct{}{})
+		return
+	}
+	i.pending = 0
+	if b.FailProvision != "" {
+		delete(b.instances, instanceID)
+		b.respond(w, http.StatusOK, &LastOperationResponse{State: StateFailed, Description: b.FailProvision})
+		return
+	}
+	b.respond(w, http.StatusOK, &LastOperationResponse{State: StateSucceeded})
+}
+
+func (b *FakeBroker) bind(w http.ResponseWriter, instanceID, bindingID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i, ok := b.instances[instanceID]
+	if !ok || i.deleted || i.pending > 0 {
+		b.respond(w, http.StatusNotFound, &Error{Description: "instance not found"})
+		return
+	}
+	credentials := map[string]interface{}{
+		"instance": instanceID,
+		"binding":  bindingID,
+	}
+	for k, v := range i.Parameters {
+		credentials[k] = v
+	}
+	i.Bindings[bindingID] = credentials
+	b.respond(w, http.StatusCreated, &BindResponse{Credentials: credentials})
+}
+
+func (b *FakeBroker) unbind(w http.ResponseWriter, instanceID, bindingID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i, ok := b.instances[instanceID]
+	if !ok {
+		b.respond(w, http.StatusGone, struct{}{})
+		return
+	}
+	if _, ok := i.Bindings[bindingID]; !ok {
+		b.respond(w, http.StatusGone, struct{}{})
+		return
+	}
+	delete(i.Bindings, bindingID)
+	b.respond(w, http.StatusOK, struct{}{})
+}
+
+func (b *FakeBroker) plan(serviceID, planID string) *Plan {
+	for _, service := range b.Services {
+		if service.ID != serviceID {
+			continue
+		}
+		for i := range service.Plans {
+			if service.Plans[i].ID == planID {
+				return &service.Plans[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (b *FakeBroker) respond(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,128 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package broker
+
+import (
+	"fmt"
+)
+
+// NO TESTS
+
+// APIVersion is the version of the Open Service Broker API spoken to brokers
+const APIVersion = "2.13"
+
+// Operation states reported by brokers for asynchronous operations
+const (
+	StateInProgress = "in progress"
+	StateSucceeded  = "succeeded"
+	StateFailed     = "failed"
+)
+
+// Catalog lists the services offered by a broker
+type Catalog struct {
+	Services []Service `json:"services"`
+}
+
+// Service is a service offered by a broker
+type Service struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Bindable    bool                   `json:"bindable"`
+	Tags        []string               `json:"tags,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Plans       []Plan                 `json:"plans"`
+}
+
+// Plan is a plan of a service, it overrides whether the service is bindable if set
+type Plan struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Free        *bool                  `json:"free,omitempty"`
+	Bindable    *bool                  `json:"bindable,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Schemas     *Schemas               `json:"schemas,omitempty"`
+}
+
+// IsBindable tells if instances of the plan of service can be bound
+func (p *Plan) IsBindable(service *Service) bool {
+	if p.Bindable != nil {
+		return *p.Bindable
+	}
+	return service.Bindable
+}
+
+// Schemas holds the JSON schemas of the parameters accepted by a plan
+type Schemas struct {
+	ServiceInstance *InstanceSchemas `json:"service_instance,omitempty"`
+	ServiceBinding  *BindingSchemas  `json:"service_binding,omitempty"`
+}
+
+// InstanceSchemas holds the schemas of the parameters accepted when creating and updating instances
+type InstanceSchemas struct {
+	Create *InputParameters `json:"create,omitempty"`
+	Update *InputParameters `json:"update,omitempty"`
+}
+
+// BindingSchemas holds the schema of the parameters accepted when binding instances
+type BindingSchemas struct {
+	Create *InputParameters `json:"create,omitempty"`
+}
+
+// InputParameters wraps the JSON schema of parameters
+type InputParameters struct {
+	Parameters interface{} `json:"parameters,omitempty"`
+}
+
+// ProvisionRequest is the body of provision requests
+type ProvisionRequest struct {
+	ServiceID        string                 `json:"service_id"`
+	PlanID           string                 `json:"plan_id"`
+	OrganizationGUID string                 `json:"organization_guid"`
+	SpaceGUID        string                 `json:"space_guid"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// OperationResponse is the response of provision and deprovision requests, Async is set if the broker accepted the
+// request and Operation should be polled until it completes
+type OperationResponse struct {
+	DashboardURL string `json:"dashboard_url,omitempty"`
+	Operation    string `json:"operation,omitempty"`
+	Async        bool   `json:"-"`
+}
+
+// LastOperationResponse is the state of an asynchronous operation
+type LastOperationResponse struct {
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+}
+
+// BindRequest is the body of bind requests
+type BindRequest struct {
+	ServiceID  string                 `json:"service_id"`
+	PlanID     string                 `json:"plan_id"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// BindResponse holds the credentials of a binding
+type BindResponse struct {
+	Credentials map[string]interface{} `json:"credentials,omitempty"`
+}
+
+// Error is an error response of a broker
+type Error struct {
+	StatusCode  int    `json:"-"`
+	ErrorCode   string `json:"error,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.ErrorCode != "" {
+		return fmt.Sprintf("broker responded with status %d (%s): %s", e.StatusCode, e.ErrorCode, e.Description)
+	}
+	return fmt.Sprintf("broker responded with status %d: %s", e.StatusCode, e.Description)
+}
@@ -0,0 +1,88 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package servicemanager
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+)
+
+// NO TESTS
+
+// ServiceInstance is the service instance entity type, its ID is the instance ID known to the broker
+type ServiceInstance struct {
+	entitystore.BaseEntity
+	Project          string                 `json:"project"`
+	ServiceClass     string                 `json:"serviceClass"`
+	ServicePlan      string                 `json:"servicePlan"`
+	Broker           string                 `json:"broker"`
+	ServiceID        string                 `json:"serviceID"`
+	PlanID           string                 `json:"planID"`
+	Bindable         bool                   `json:"bindable"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+	SecretParameters []string               `json:"secretParameters,omitempty"`
+	Binding          *ServiceBinding        `json:"binding,omitempty"`
+}
+
+// ServiceBinding is the binding of a service instance, its credentials are kept in a Dispatch secret
+type ServiceBinding struct {
+	ID            string             `json:"id"`
+	BindingSecret string             `json:"bindingSecret"`
+	Status        entitystore.Status `json:"status"`
+	Reason        []string           `json:"reason,omitempty"`
+}
+
+// ToModel converts a service instance entity into the API model
+func (s *ServiceInstance) ToModel() *dapi.ServiceInstance {
+	var tags []*dapi.Tag
+	for k, v := range s.Tags {
+		tags = append(tags, &dapi.Tag{Key: k, Value: v})
+	}
+	m := dapi.ServiceInstance{
+		ID:               strfmt.UUID(s.ID),
+		Kind:             dapi.ServiceInstanceKind,
+		Name:             swag.String(s.Name),
+		ServiceClass:     swag.String(s.ServiceClass),
+		ServicePlan:      swag.String(s.ServicePlan),
+		SecretParameters: s.SecretParameters,
+		Status:           dapi.Status(s.Status),
+		Reason:           s.Reason,
+		CreatedTime:      s.CreatedTime.Unix(),
+		Tags:             tags,
+	}
+	if s.Parameters != nil {
+		m.Parameters = s.Parameters
+	}
+	if s.Binding != nil {
+		m.Binding = &dapi.ServiceBinding{
+			BindingSecret: s.Binding.BindingSecret,
+			Status:        dapi.Status(s.Binding.Status),
+			Reason:        s.Binding.Reason,
+		}
+	}
+	return &m
+}
+
+// FromModel populates a service instance entity from the API model
+func (s *ServiceInstance) FromModel(m *dapi.ServiceInstance, orgID, project string) {
+	tags := make(map[string]string)
+	for _, t := range m.Tags {
+		tags[t.Key] = t.Value
+	}
+	s.BaseEntity.OrganizationID = orgID
+	s.BaseEntity.Name = *m.Name
+	s.BaseEntity.Tags = tags
+	s.Project = project
+	s.ServiceClass = *m.ServiceClass
+	s.ServicePlan = *m.ServicePlan
+	if parameters, ok := m.Parameters.(map[string]interface{}); ok {
+		s.Parameters = parameters
+	}
+	s.SecretParameters = m.SecretParameters
+}
@@ -0,0 +1,325 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package servicemanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations"
+	classapi "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_class"
+	instanceapi "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_instance"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils"
+)
+
+// operationTimeout limits the time spent provisioning or deprovisioning an instance
+const operationTimeout = 30 * time.Minute
+
+// Handlers implements the service manager API
+type Handlers struct {
+	manager   *Manager
+	store     entitystore.EntityStore
+	namespace string
+}
+
+// NewHandlers is the constructor for the service manager API handlers
+func NewHandlers(namespace string, store entitystore.EntityStore, manager *Manager) *Handlers {
+	return &Handlers{
+		manager:   manager,
+		store:     store,
+		namespace: namespace,
+	}
+}
+
+// ConfigureHandlers registers the service manager handlers to the API
+func ConfigureHandlers(api middleware.RoutableAPI, h *Handlers) {
+	a, ok := api.(*operations.ServicesAPI)
+	if !ok {
+		panic("Cannot configure api")
+	}
+
+	a.Logger = log.Printf
+	a.ServiceClassGetServiceClassesHandler = classapi.GetServiceClassesHandlerFunc(h.getServiceClasses)
+	a.ServiceClassGetServiceClassHandler = classapi.GetServiceClassHandlerFunc(h.getServiceClass)
+	a.ServiceInstanceAddServiceInstanceHandler = instanceapi.AddServiceInstanceHandlerFunc(h.addServiceInstance)
+	a.ServiceInstanceGetServiceInstancesHandler = instanceapi.GetServiceInstancesHandlerFunc(h.getServiceInstances)
+	a.ServiceInstanceGetServiceInstanceHandler = instanceapi.GetServiceInstanceHandlerFunc(h.getServiceInstance)
+	a.ServiceInstanceDeleteServiceInstanceHandler = instanceapi.DeleteServiceInstanceHandlerFunc(h.deleteServiceInstance)
+}
+
+func (h *Handlers) getServiceClasses(params classapi.GetServiceClassesParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	classes := []*dapi.ServiceClass{}
+	for _, class := range h.manager.Classes(ctx) {
+		if hasTags(class.Tags, params.Tags) {
+			classes = append(classes, class)
+		}
+	}
+	return classapi.NewGetServiceClassesOK().WithPayload(classes)
+}
+
+func (h *Handlers) getServiceClass(params classapi.GetServiceClassParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	class := h.manager.Class(ctx, params.ServiceClassName)
+	if class == nil {
+		return classapi.NewGetServiceClassNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("service class", params.ServiceClassName),
+		})
+	}
+	return classapi.NewGetServiceClassOK().WithPayload(class)
+}
+
+func (h *Handlers) addServiceInstance(params instanceapi.AddServiceInstanceParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := h.namespace
+	project := *params.XDispatchProject
+
+	model := params.ServiceInstance
+	if model == nil {
+		return instanceapi.NewAddServiceInstanceBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String("missing service instance"),
+		})
+	}
+	if err := model.Validate(strfmt.Default); err != nil {
+		return instanceapi.NewAddServiceInstanceBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("error validating the service instance: %s", err)),
+		})
+	}
+	if _, ok := model.Parameters.(map[string]interface{}); model.Parameters != nil && !ok {
+		return instanceapi.NewAddServiceInstanceBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: utils.ErrorMsgBadRequest("service instance", *model.Name, errors.New("parameters must be an object")),
+		})
+	}
+
+	instance := new(ServiceInstance)
+	instance.FromModel(model, org, project)
+	if err := h.manager.Resolve(ctx, instance); err != nil {
+		return instanceapi.NewAddServiceInstanceBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: utils.ErrorMsgBadRequest("service instance", instance.Name, err),
+		})
+	}
+	instance.Status = entitystore.StatusCREATING
+	if _, err := h.store.Add(ctx, instance); err != nil {
+		if entitystore.IsUniqueViolation(err) {
+			return instanceapi.NewAddServiceInstanceConflict().WithPayload(&dapi.Error{
+				Code:    http.StatusConflict,
+				Message: utils.ErrorMsgAlreadyExists("service instance", instance.Name),
+			})
+		}
+		log.Errorf("%+v", errors.Wrapf(err, "storing service instance %s", instance.Name))
+		return instanceapi.NewAddServiceInstanceDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("service instance", instance.Name),
+		})
+	}
+
+	h.provision(*instance)
+
+	return instanceapi.NewAddServiceInstanceCreated().WithPayload(instance.ToModel())
+}
+
+func (h *Handlers) getServiceInstances(params instanceapi.GetServiceInstancesParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	org := h.namespace
+	project := *params.XDispatchProject
+
+	filter, err := utils.ParseTags(projectFilter(project), params.Tags)
+	if err != nil {
+		return instanceapi.NewGetServiceInstancesBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(err.Error()),
+		})
+	}
+
+	var instances []*ServiceInstance
+	if err := h.store.List(ctx, org, entitystore.Options{Filter: filter}, &instances); err != nil {
+		log.Errorf("%+v", errors.Wrap(err, "listing service instances"))
+		return instanceapi.NewGetServiceInstancesDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: swag.String("internal server error when getting service instances"),
+		})
+	}
+	models := []*dapi.ServiceInstance{}
+	for _, instance := range instances {
+		models = append(models, instance.ToModel())
+	}
+	return instanceapi.NewGetServiceInstancesOK().WithPayload(models)
+}
+
+func (h *Handlers) getServiceInstance(params instanceapi.GetServiceInstanceParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.ServiceInstanceName
+	instance, err := h.find(ctx, *params.XDispatchProject, name)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting service instance %s", name))
+		return instanceapi.NewGetServiceInstanceDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("service instance", name),
+		})
+	}
+	if instance == nil {
+		return instanceapi.NewGetServiceInstanceNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("service instance", name),
+		})
+	}
+	return instanceapi.NewGetServiceInstanceOK().WithPayload(instance.ToModel())
+}
+
+func (h *Handlers) deleteServiceInstance(params instanceapi.DeleteServiceInstanceParams) middleware.Responder {
+	span, ctx := trace.Trace(params.HTTPRequest.Context(), "")
+	defer span.Finish()
+
+	name := params.ServiceInstanceName
+	instance, err := h.find(ctx, *params.XDispatchProject, name)
+	if err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "getting service instance %s", name))
+		return instanceapi.NewDeleteServiceInstanceDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("service instance", name),
+		})
+	}
+	if instance == nil {
+		return instanceapi.NewDeleteServiceInstanceNotFound().WithPayload(&dapi.Error{
+			Code:    http.StatusNotFound,
+			Message: utils.ErrorMsgNotFound("service instance", name),
+		})
+	}
+	if instance.Status == entitystore.StatusCREATING || instance.Status == entitystore.StatusDELETING {
+		return instanceapi.NewDeleteServiceInstanceBadRequest().WithPayload(&dapi.Error{
+			Code:    http.StatusBadRequest,
+			Message: swag.String(fmt.Sprintf("service instance %s is %s", name, instance.Status)),
+		})
+	}
+	users, err := h.manager.Users(ctx, instance)
+	if err != nil {
+		log.Errorf("%+v", err)
+		return instanceapi.NewDeleteServiceInstanceDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("service instance", name),
+		})
+	}
+	if len(users) > 0 {
+		return instanceapi.NewDeleteServiceInstanceConflict().WithPayload(&dapi.Error{
+			Code:    http.StatusConflict,
+			Message: swag.String(fmt.Sprintf("service instance %s is used by functions: %s", name, strings.Join(users, ", "))),
+		})
+	}
+
+	instance.Status = entitystore.StatusDELETING
+	if _, err := h.store.Update(ctx, instance.Revision, instance); err != nil {
+		log.Errorf("%+v", errors.Wrapf(err, "updating service instance %s", name))
+		return instanceapi.NewDeleteServiceInstanceDefault(http.StatusInternalServerError).WithPayload(&dapi.Error{
+			Code:    http.StatusInternalServerError,
+			Message: utils.ErrorMsgInternalError("service instance", name),
+		})
+	}
+
+	h.deprovision(*instance)
+
+	return instanceapi.NewDeleteServiceInstanceNoContent()
+}
+
+// provision provisions an instance in the background
+func (h *Handlers) provision(instance ServiceInstance) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+		defer cancel()
+		h.manager.Provision(ctx, &instance)
+	}()
+}
+
+// deprovision deprovisions an instance in the background
+func (h *Handlers) deprovision(instance ServiceInstance) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+		defer cancel()
+		h.manager.Deprovision(ctx, &instance)
+	}()
+}
+
+// Resume restarts the provisioning and deprovisioning of the instances left CREATING or DELETING, e.g. by a restart
+// of the server. Brokers treat provisioning an existing instance and deprovisioning a missing one as done.
+func (h *Handlers) Resume(ctx context.Context) error {
+	var instances []*ServiceInstance
+	if err := h.store.List(ctx, h.namespace, entitystore.Options{Filter: entitystore.FilterEverything()}, &instances); err != nil {
+		return errors.Wrap(err, "listing service instances")
+	}
+	for _, instance := range instances {
+		switch instance.Status {
+		case entitystore.StatusCREATING:
+			log.Infof("resuming the provisioning of service instance %s", instance.Name)
+			h.provision(*instance)
+		case entitystore.StatusDELETING:
+			log.Infof("resuming the deprovisioning of service instance %s", instance.Name)
+			h.deprovision(*instance)
+		}
+	}
+	return nil
+}
+
+// find gets a service instance of the project, it returns nil if there is none
+func (h *Handlers) find(ctx context.Context, project, name string) (*ServiceInstance, error) {
+	instance := new(ServiceInstance)
+	found, err := h.store.Find(ctx, h.namespace, name, entitystore.Options{Filter: projectFilter(project)}, instance)
+	if err != nil || !found {
+		return nil, err
+	}
+	return instance, nil
+}
+
+func projectFilter(project string) entitystore.Filter {
+	return entitystore.FilterEverything().Add(entitystore.FilterStat{
+		Scope:   entitystore.FilterScopeExtra,
+		Subject: "Project",
+		Verb:    entitystore.FilterVerbEqual,
+		Object:  project,
+	})
+}
+
+// hasTags tells if tags holds all the key=value filters
+func hasTags(tags []*dapi.Tag, filters []string) bool {
+	for _, filter := range filters {
+		found := false
+		for _, tag := range tags {
+			if tag.Key+"="+tag.Value == filter {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,353 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package servicemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretsservice "github.com/vmware/dispatch/pkg/secrets/service"
+	"github.com/vmware/dispatch/pkg/service-manager/broker"
+	"github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations"
+	classapi "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_class"
+	instanceapi "github.com/vmware/dispatch/pkg/service-manager/gen/restapi/operations/service_instance"
+	helpers "github.com/vmware/dispatch/pkg/testing/api"
+)
+
+const (
+	testNamespace = "dispatch"
+	testProject   = "default"
+)
+
+type testEnv struct {
+	api      *operations.ServicesAPI
+	handlers *Handlers
+	store    entitystore.EntityStore
+	secrets  secretsservice.SecretsService
+	broker   *broker.FakeBroker
+	roller   *fakeRoller
+}
+
+type fakeRoller struct {
+	mu     sync.Mutex
+	rolled []string
+	// users are the functions using each instance
+	users map[string][]string
+}
+
+func (r *fakeRoller) RollService(ctx context.Context, meta *dapi.Meta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rolled = append(r.rolled, meta.Name)
+	return nil
+}
+
+func (r *fakeRoller) ServiceUsers(ctx context.Context, meta *dapi.Meta) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users[meta.Name], nil
+}
+
+func (r *fakeRoller) instances() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.rolled...)
+}
+
+func newTestEnv(t *testing.T) (*testEnv, func()) {
+	fakeBroker := broker.NewFakeBroker(broker.Service{
+		ID:          "db-id",
+		Name:        "db",
+		Description: "a database",
+		Bindable:    true,
+		Tags:        []string{"sql"},
+		Plans: []broker.Plan{
+			{ID: "small-id", Name: "small"},
+			{ID: "unbound-id", Name: "unbound", Bindable: swag.Bool(false)},
+		},
+	})
+	server := httptest.NewServer(fakeBroker)
+	client, err := broker.NewClient("fake", server.URL)
+	require.NoError(t, err)
+
+	env := &testEnv{
+		api:     operations.NewServicesAPI(nil),
+		store:   helpers.MakeEntityStore(t),
+		secrets: &secretsservice.K8sSecretsService{K8sAPI: fake.NewSimpleClientset().CoreV1()},
+		broker:  fakeBroker,
+		roller:  &fakeRoller{},
+	}
+	manager := NewManager(env.store, env.secrets, []*broker.Client{client}, 10*time.Millisecond, env.roller)
+	env.handlers = NewHandlers(testNamespace, env.store, manager)
+	ConfigureHandlers(env.api, env.handlers)
+	return env, server.Close
+}
+
+func (env *testEnv) addInstance(t *testing.T, instance *dapi.ServiceInstance, status int) *dapi.ServiceInstance {
+	r := httptest.NewRequest("POST", "/v1/serviceinstance", nil)
+	responder := env.api.ServiceInstanceAddServiceInstanceHandler.Handle(instanceapi.AddServiceInstanceParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+		ServiceInstance:  instance,
+	})
+	var created dapi.ServiceInstance
+	helpers.HandlerRequest(t, responder, &created, status)
+	return &created
+}
+
+func (env *testEnv) getInstance(t *testing.T, name string, status int) *dapi.ServiceInstance {
+	r := httptest.NewRequest("GET", "/v1/serviceinstance/"+name, nil)
+	responder := env.api.ServiceInstanceGetServiceInstanceHandler.Handle(instanceapi.GetServiceInstanceParams{
+		HTTPRequest:         r,
+		XDispatchProject:    swag.String(testProject),
+		ServiceInstanceName: name,
+	})
+	var instance dapi.ServiceInstance
+	helpers.HandlerRequest(t, responder, &instance, status)
+	return &instance
+}
+
+// waitForStatus waits for the background provisioning of an instance to settle
+func (env *testEnv) waitForStatus(t *testing.T, name string, status dapi.Status) *dapi.ServiceInstance {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		instance := env.getInstance(t, name, http.StatusOK)
+		if instance.Status == status {
+			return instance
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("service instance %s is %s, expected %s", name, instance.Status, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func testInstance(name, plan string) *dapi.ServiceInstance {
+	return &dapi.ServiceInstance{
+		Name:         swag.String(name),
+		ServiceClass: swag.String("db"),
+		ServicePlan:  swag.String(plan),
+		Parameters:   map[string]interface{}{"size": "1G"},
+	}
+}
+
+func TestGetServiceClasses(t *testing.T) {
+	env, done := newTestEnv(t)
+	defer done()
+
+	r := httptest.NewRequest("GET", "/v1/serviceclass", nil)
+	responder := env.api.ServiceClassGetServiceClassesHandler.Handle(classapi.GetServiceClassesParams{
+		HTTPRequest: r,
+	})
+	var classes []dapi.ServiceClass
+	helpers.HandlerRequest(t, responder, &classes, http.StatusOK)
+	require.Len(t, classes, 1)
+	assert.Equal(t, "db", *classes[0].Name)
+	assert.Equal(t, "fake", *classes[0].Broker)
+	require.Len(t, classes[0].Plans, 2)
+	assert.True(t, classes[0].Plans[0].Bindable)
+	assert.False(t, classes[0].Plans[1].Bindable)
+
+	responder = env.api.ServiceClassGetServiceClassesHandler.Handle(classapi.GetServiceClassesParams{
+		HTTPRequest: r,
+		Tags:        []string{"tag=nosql"},
+	})
+	helpers.HandlerRequest(t, responder, &classes, http.StatusOK)
+	assert.Len(t, classes, 0)
+
+	r = httptest.NewRequest("GET", "/v1/serviceclass/db", nil)
+	responder = env.api.ServiceClassGetServiceClassHandler.Handle(classapi.GetServiceClassParams{
+		HTTPRequest:      r,
+		ServiceClassName: "queue",
+	})
+	helpers.HandlerRequest(t, responder, &dapi.Error{}, http.StatusNotFound)
+}
+
+func TestServiceInstanceLifecycle(t *testing.T) {
+	env, done := newTestEnv(t)
+	defer done()
+	ctx := context.Background()
+
+	_, err := env.secrets.AddSecret(ctx, &dapi.Secret{
+		Meta:    dapi.Meta{Org: testNamespace, Project: testProject, Name: "db-password"},
+		Name:    swag.String("db-password"),
+		Secrets: dapi.SecretValue{"password": "s3cr3t"},
+	})
+	require.NoError(t, err)
+
+	instance := testInstance("orders", "small")
+	instance.SecretParameters = []string{"db-password"}
+	created := env.addInstance(t, instance, http.StatusCreated)
+	assert.Equal(t, dapi.Status(entitystore.StatusCREATING), created.Status)
+	env.addInstance(t, testInstance("orders", "small"), http.StatusConflict)
+
+	ready := env.waitForStatus(t, "orders", dapi.StatusREADY)
+	require.NotNil(t, ready.Binding)
+	assert.Equal(t, "dispatch-binding-orders", ready.Binding.BindingSecret)
+	assert.Equal(t, dapi.StatusREADY, ready.Binding.Status)
+	// Functions using the instance are rolled to get the credentials
+	assert.Equal(t, []string{"orders"}, env.roller.instances())
+
+	// The broker got the secret parameters, which are not stored with the instance
+	brokerInstance, ok := env.broker.Instance(ready.ID.String())
+	require.True(t, ok)
+	assert.Equal(t, "s3cr3t", brokerInstance.Parameters["password"])
+	assert.Equal(t, map[string]interface{}{"size": "1G"}, ready.Parameters)
+
+	secret, err := env.secrets.GetSecret(ctx, &dapi.Meta{Org: testNamespace, Project: testProject, Name: "dispatch-binding-orders"})
+	require.NoError(t, err)
+	assert.Equal(t, ready.ID.String(), secret.Secrets["instance"])
+	assert.Equal(t, "1G", secret.Secrets["size"])
+
+	r := httptest.NewRequest("GET", "/v1/serviceinstance", nil)
+	responder := env.api.ServiceInstanceGetServiceInstancesHandler.Handle(instanceapi.GetServiceInstancesParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String(testProject),
+	})
+	var instances []dapi.ServiceInstance
+	helpers.HandlerRequest(t, responder, &instances, http.StatusOK)
+	assert.Len(t, instances, 1)
+	responder = env.api.ServiceInstanceGetServiceInstancesHandler.Handle(instanceapi.GetServiceInstancesParams{
+		HTTPRequest:      r,
+		XDispatchProject: swag.String("other"),
+	})
+	helpers.HandlerRequest(t, responder, &instances, http.StatusOK)
+	assert.Len(t, instances, 0)
+
+	// Instances used by functions are not deprovisioned
+	env.roller.mu.Lock()
+	env.roller.users = map[string][]string{"orders": {"checkout"}}
+	env.roller.mu.Unlock()
+	env.deleteInstance(t, "orders", http.StatusConflict)
+	env.waitForStatus(t, "orders", dapi.StatusREADY)
+	env.roller.mu.Lock()
+	env.roller.users = nil
+	env.roller.mu.Unlock()
+
+	env.deleteInstance(t, "orders", http.StatusNoContent)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for env.broker.InstanceCount() > 0 || env.getInstanceStatus(t, "orders") != http.StatusNotFound {
+		if time.Now().After(deadline) {
+			t.Fatal("service instance orders was not deprovisioned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, err = env.secrets.GetSecret(ctx, &dapi.Meta{Org: testNamespace, Project: testProject, Name: "dispatch-binding-orders"})
+	assert.IsType(t, secretsservice.SecretNotFound{}, err)
+}
+
+func (env *testEnv) deleteInstance(t *testing.T, name string, status int) {
+	r := httptest.NewRequest("DELETE", "/v1/serviceinstance/"+name, nil)
+	responder := env.api.ServiceInstanceDeleteServiceInstanceHandler.Handle(instanceapi.DeleteServiceInstanceParams{
+		HTTPRequest:         r,
+		XDispatchProject:    swag.String(testProject),
+		ServiceInstanceName: name,
+	})
+	if status == http.StatusNoContent {
+		helpers.HandlerRequest(t, responder, nil, status)
+		return
+	}
+	var e dapi.Error
+	helpers.HandlerRequest(t, responder, &e, status)
+}
+
+func (env *testEnv) getInstanceStatus(t *testing.T, name string) int {
+	r := httptest.NewRequest("GET", "/v1/serviceinstance/"+name, nil)
+	responder := env.api.ServiceInstanceGetServiceInstanceHandler.Handle(instanceapi.GetServiceInstanceParams{
+		HTTPRequest:         r,
+		XDispatchProject:    swag.String(testProject),
+		ServiceInstanceName: name,
+	})
+	w := httptest.NewRecorder()
+	responder.WriteResponse(w, runtime.JSONProducer())
+	return w.Code
+}
+
+func TestServiceInstanceNotBindable(t *testing.T) {
+	env, done := newTestEnv(t)
+	defer done()
+	env.broker.Polls = 2
+
+	env.addInstance(t, testInstance("archive", "unbound"), http.StatusCreated)
+	ready := env.waitForStatus(t, "archive", dapi.StatusREADY)
+	assert.Nil(t, ready.Binding)
+	assert.Empty(t, env.roller.instances())
+}
+
+func TestServiceInstanceResume(t *testing.T) {
+	env, done := newTestEnv(t)
+	defer done()
+	ctx := context.Background()
+
+	env.addInstance(t, testInstance("orders", "small"), http.StatusCreated)
+	env.waitForStatus(t, "orders", dapi.StatusREADY)
+
+	setStatus := func(status entitystore.Status) {
+		instance := new(ServiceInstance)
+		require.NoError(t, env.store.Get(ctx, testNamespace, "orders", entitystore.Options{}, instance))
+		instance.Status = status
+		_, err := env.store.Update(ctx, instance.Revision, instance)
+		require.NoError(t, err)
+	}
+
+	// A provisioning interrupted after storing the credentials provisions and binds again
+	setStatus(entitystore.StatusCREATING)
+	require.NoError(t, env.handlers.Resume(ctx))
+	ready := env.waitForStatus(t, "orders", dapi.StatusREADY)
+	require.NotNil(t, ready.Binding)
+	assert.Equal(t, 1, env.broker.InstanceCount())
+	secret, err := env.secrets.GetSecret(ctx, &dapi.Meta{Org: testNamespace, Project: testProject, Name: "dispatch-binding-orders"})
+	require.NoError(t, err)
+	brokerInstance, ok := env.broker.Instance(ready.ID.String())
+	require.True(t, ok)
+	assert.Contains(t, brokerInstance.Bindings, secret.Secrets["binding"])
+
+	setStatus(entitystore.StatusDELETING)
+	require.NoError(t, env.handlers.Resume(ctx))
+	deadline := time.Now().Add(5 * time.Second)
+	for env.broker.InstanceCount() > 0 || env.getInstanceStatus(t, "orders") != http.StatusNotFound {
+		if time.Now().After(deadline) {
+			t.Fatal("service instance orders was not deprovisioned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServiceInstanceErrors(t *testing.T) {
+	env, done := newTestEnv(t)
+	defer done()
+
+	env.addInstance(t, &dapi.ServiceInstance{
+		Name:         swag.String("orders"),
+		ServiceClass: swag.String("queue"),
+		ServicePlan:  swag.String("small"),
+	}, http.StatusBadRequest)
+	env.addInstance(t, testInstance("orders", "large"), http.StatusBadRequest)
+	instance := testInstance("orders", "small")
+	instance.Parameters = "size=1G"
+	env.addInstance(t, instance, http.StatusBadRequest)
+	env.getInstance(t, "orders", http.StatusNotFound)
+
+	env.broker.Polls = 1
+	env.broker.FailProvision = "out of capacity"
+	env.addInstance(t, testInstance("orders", "small"), http.StatusCreated)
+	failed := env.waitForStatus(t, "orders", dapi.StatusERROR)
+	require.Len(t, failed.Reason, 1)
+	assert.Contains(t, failed.Reason[0], "out of capacity")
+}
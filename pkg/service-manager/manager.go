@@ -0,0 +1,430 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package servicemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+	entitystore "github.com/vmware/dispatch/pkg/entity-store"
+	secretsservice "github.com/vmware/dispatch/pkg/secrets/service"
+	"github.com/vmware/dispatch/pkg/service-manager/broker"
+	"github.com/vmware/dispatch/pkg/trace"
+	"github.com/vmware/dispatch/pkg/utils/knaming"
+)
+
+// UnknownClassError is returned for instances of a class or plan which no broker offers
+type UnknownClassError struct {
+	Class string
+	Plan  string
+}
+
+func (e UnknownClassError) Error() string {
+	if e.Plan == "" {
+		return fmt.Sprintf("service class %s is not offered by any broker", e.Class)
+	}
+	return fmt.Sprintf("service class %s has no plan %s", e.Class, e.Plan)
+}
+
+// offering is a service of a broker
+type offering struct {
+	broker  *broker.Client
+	service *broker.Service
+}
+
+// FunctionRoller restarts the functions using a service instance, so that they get its binding credentials
+type FunctionRoller interface {
+	RollService(ctx context.Context, meta *dapi.Meta) error
+	// ServiceUsers returns the names of the functions using a service instance
+	ServiceUsers(ctx context.Context, meta *dapi.Meta) ([]string, error)
+}
+
+// Manager provisions service instances with the Open Service Broker brokers and binds them. Bindable instances get a
+// single binding when they are provisioned, bindings have no API of their own. Binding credentials are kept in
+// Dispatch secrets which functions using the instance get.
+type Manager struct {
+	store        entitystore.EntityStore
+	secrets      secretsservice.SecretsService
+	brokers      []*broker.Client
+	pollInterval time.Duration
+	// functions rolls the functions using an instance once it is bound, optional
+	functions FunctionRoller
+}
+
+// NewManager is the constructor for the Manager, asynchronous broker operations are polled every pollInterval.
+// Functions are rolled by functions once their instances are bound, it may be nil.
+func NewManager(store entitystore.EntityStore, secrets secretsservice.SecretsService, brokers []*broker.Client, pollInterval time.Duration, functions FunctionRoller) *Manager {
+	return &Manager{
+		store:        store,
+		secrets:      secrets,
+		brokers:      brokers,
+		pollInterval: pollInterval,
+		functions:    functions,
+	}
+}
+
+// offerings fetches the catalogs of the brokers. Brokers which fail are skipped, as are services whose name is
+// already offered by another broker.
+func (m *Manager) offerings(ctx context.Context) []offering {
+	var offerings []offering
+	names := make(map[string]string)
+	for _, b := range m.brokers {
+		catalog, err := b.Catalog(ctx)
+		if err != nil {
+			log.Errorf("error fetching the catalog of broker %s: %+v", b.Name(), err)
+			continue
+		}
+		for i := range catalog.Services {
+			service := &catalog.Services[i]
+			if other, ok := names[service.Name]; ok {
+				log.Warnf("skipping service %s of broker %s, already offered by broker %s", service.Name, b.Name(), other)
+				continue
+			}
+			names[service.Name] = b.Name()
+			offerings = append(offerings, offering{broker: b, service: service})
+		}
+	}
+	return offerings
+}
+
+// Classes returns the service classes offered by the brokers
+func (m *Manager) Classes(ctx context.Context) []*dapi.ServiceClass {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var classes []*dapi.ServiceClass
+	for _, o := range m.offerings(ctx) {
+		classes = append(classes, toServiceClass(o))
+	}
+	return classes
+}
+
+// Class returns the service class named name, or nil if no broker offers it
+func (m *Manager) Class(ctx context.Context, name string) *dapi.ServiceClass {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	for _, o := range m.offerings(ctx) {
+		if o.service.Name == name {
+			return toServiceClass(o)
+		}
+	}
+	return nil
+}
+
+// Resolve sets the broker, service and plan of an instance from its class and plan names
+func (m *Manager) Resolve(ctx context.Context, instance *ServiceInstance) error {
+	for _, o := range m.offerings(ctx) {
+		if o.service.Name != instance.ServiceClass {
+			continue
+		}
+		for i := range o.service.Plans {
+			plan := &o.service.Plans[i]
+			if plan.Name != instance.ServicePlan {
+				continue
+			}
+			instance.Broker = o.broker.Name()
+			instance.ServiceID = o.service.ID
+			instance.PlanID = plan.ID
+			instance.Bindable = plan.IsBindable(o.service)
+			return nil
+		}
+		return UnknownClassError{Class: instance.ServiceClass, Plan: instance.ServicePlan}
+	}
+	return UnknownClassError{Class: instance.ServiceClass}
+}
+
+// Provision provisions a stored instance and binds it if possible, the outcome is recorded in the status of the
+// instance
+func (m *Manager) Provision(ctx context.Context, instance *ServiceInstance) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var err error
+	defer func() { m.store.UpdateWithError(ctx, instance, err) }()
+
+	b, err := m.broker(instance.Broker)
+	if err != nil {
+		return
+	}
+	parameters, err := m.parameters(ctx, instance)
+	if err != nil {
+		return
+	}
+	op, err := b.Provision(ctx, instance.ID, &broker.ProvisionRequest{
+		ServiceID:        instance.ServiceID,
+		PlanID:           instance.PlanID,
+		OrganizationGUID: instance.OrganizationID,
+		SpaceGUID:        instance.Project,
+		Parameters:       parameters,
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "provisioning service instance %s", instance.Name)
+		return
+	}
+	if op.Async {
+		if err = m.wait(ctx, b, instance, op.Operation); err != nil {
+			err = errors.Wrapf(err, "provisioning service instance %s", instance.Name)
+			return
+		}
+	}
+	if instance.Bindable {
+		if err = m.bind(ctx, b, instance); err != nil {
+			return
+		}
+		m.rollFunctions(ctx, instance)
+	}
+	instance.Status = entitystore.StatusREADY
+	instance.Reason = nil
+	log.Infof("service instance %s of class %s provisioned", instance.Name, instance.ServiceClass)
+}
+
+// rollFunctions restarts the functions using an instance, they start without credentials until it is bound
+func (m *Manager) rollFunctions(ctx context.Context, instance *ServiceInstance) {
+	if m.functions == nil {
+		return
+	}
+	meta := &dapi.Meta{Org: instance.OrganizationID, Project: instance.Project, Name: instance.Name}
+	if err := m.functions.RollService(ctx, meta); err != nil {
+		log.Errorf("error when rolling the functions using service instance %s: %+v", instance.Name, err)
+	}
+}
+
+// Users returns the names of the functions using an instance, none if functions can't be looked up
+func (m *Manager) Users(ctx context.Context, instance *ServiceInstance) ([]string, error) {
+	if m.functions == nil {
+		return nil, nil
+	}
+	meta := &dapi.Meta{Org: instance.OrganizationID, Project: instance.Project, Name: instance.Name}
+	users, err := m.functions.ServiceUsers(ctx, meta)
+	return users, errors.Wrapf(err, "looking up the functions using service instance %s", instance.Name)
+}
+
+// Deprovision unbinds and deprovisions an instance, then removes it from the store
+func (m *Manager) Deprovision(ctx context.Context, instance *ServiceInstance) {
+	span, ctx := trace.Trace(ctx, "")
+	defer span.Finish()
+
+	var err error
+	b, err := m.broker(instance.Broker)
+	if err == nil && instance.Binding != nil {
+		err = m.unbind(ctx, b, instance)
+	}
+	if err == nil {
+		err = m.deprovision(ctx, b, instance)
+	}
+	if err != nil {
+		m.store.UpdateWithError(ctx, instance, err)
+		return
+	}
+	if err := m.store.Delete(ctx, instance.OrganizationID, instance.Name, instance); err != nil {
+		log.Errorf("error deleting service instance %s: %+v", instance.Name, err)
+		return
+	}
+	log.Infof("service instance %s of class %s deprovisioned", instance.Name, instance.ServiceClass)
+}
+
+func (m *Manager) deprovision(ctx context.Context, b *broker.Client, instance *ServiceInstance) error {
+	op, err := b.Deprovision(ctx, instance.ID, instance.ServiceID, instance.PlanID)
+	if err != nil {
+		return errors.Wrapf(err, "deprovisioning service instance %s", instance.Name)
+	}
+	if op.Async {
+		if err := m.wait(ctx, b, instance, op.Operation); err != nil {
+			return errors.Wrapf(err, "deprovisioning service instance %s", instance.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) broker(name string) (*broker.Client, error) {
+	for _, b := range m.brokers {
+		if b.Name() == name {
+			return b, nil
+		}
+	}
+	return nil, errors.Errorf("broker %s is not registered", name)
+}
+
+// parameters merges the values of the secret parameters of an instance into its parameters
+func (m *Manager) parameters(ctx context.Context, instance *ServiceInstance) (map[string]interface{}, error) {
+	if len(instance.SecretParameters) == 0 {
+		return instance.Parameters, nil
+	}
+	parameters := make(map[string]interface{})
+	for k, v := range instance.Parameters {
+		parameters[k] = v
+	}
+	for _, name := range instance.SecretParameters {
+		secret, err := m.secrets.GetSecret(ctx, &dapi.Meta{Org: instance.OrganizationID, Project: instance.Project, Name: name})
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting secret parameters %s", name)
+		}
+		for k, v := range secret.Secrets {
+			parameters[k] = v
+		}
+	}
+	return parameters, nil
+}
+
+// wait polls an asynchronous operation on an instance until it completes
+func (m *Manager) wait(ctx context.Context, b *broker.Client, instance *ServiceInstance, operation string) error {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		state, err := b.LastOperation(ctx, instance.ID, instance.ServiceID, instance.PlanID, operation)
+		if err != nil {
+			return err
+		}
+		switch state.State {
+		case broker.StateSucceeded:
+			return nil
+		case broker.StateFailed:
+			return errors.Errorf("operation failed: %s", state.Description)
+		}
+	}
+}
+
+// bind creates a binding of an instance and stores its credentials in the binding secret
+func (m *Manager) bind(ctx context.Context, b *broker.Client, instance *ServiceInstance) error {
+	binding := &ServiceBinding{
+		ID:            uuid.NewV4().String(),
+		BindingSecret: knaming.BindingSecretName(instance.Name),
+		Status:        entitystore.StatusCREATING,
+	}
+	instance.Binding = binding
+	response, err := b.Bind(ctx, instance.ID, binding.ID, &broker.BindRequest{
+		ServiceID: instance.ServiceID,
+		PlanID:    instance.PlanID,
+	})
+	if err != nil {
+		binding.Status = entitystore.StatusERROR
+		binding.Reason = []string{err.Error()}
+		return errors.Wrapf(err, "binding service instance %s", instance.Name)
+	}
+	values, err := credentialValues(response.Credentials)
+	if err != nil {
+		binding.Status = entitystore.StatusERROR
+		binding.Reason = []string{err.Error()}
+		return err
+	}
+	err = m.storeCredentials(ctx, &dapi.Secret{
+		Meta:    dapi.Meta{Org: instance.OrganizationID, Project: instance.Project, Name: binding.BindingSecret},
+		Name:    swag.String(binding.BindingSecret),
+		Secrets: values,
+		Tags:    []*dapi.Tag{{Key: "serviceInstance", Value: instance.Name}},
+	})
+	if err != nil {
+		binding.Status = entitystore.StatusERROR
+		binding.Reason = []string{err.Error()}
+		return errors.Wrapf(err, "storing the credentials of service instance %s", instance.Name)
+	}
+	binding.Status = entitystore.StatusREADY
+	return nil
+}
+
+// storeCredentials adds the binding secret, or replaces it if an interrupted provisioning already stored it
+func (m *Manager) storeCredentials(ctx context.Context, secret *dapi.Secret) error {
+	_, err := m.secrets.GetSecret(ctx, &secret.Meta)
+	if _, notFound := err.(secretsservice.SecretNotFound); notFound {
+		_, err = m.secrets.AddSecret(ctx, secret)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = m.secrets.UpdateSecret(ctx, secret)
+	return err
+}
+
+// unbind deletes the binding secret of an instance and the binding
+func (m *Manager) unbind(ctx context.Context, b *broker.Client, instance *ServiceInstance) error {
+	err := m.secrets.DeleteSecret(ctx, &dapi.Meta{Org: instance.OrganizationID, Project: instance.Project, Name: instance.Binding.BindingSecret})
+	if _, notFound := err.(secretsservice.SecretNotFound); err != nil && !notFound {
+		return errors.Wrapf(err, "deleting the credentials of service instance %s", instance.Name)
+	}
+	if err := b.Unbind(ctx, instance.ID, instance.Binding.ID, instance.ServiceID, instance.PlanID); err != nil {
+		return errors.Wrapf(err, "unbinding service instance %s", instance.Name)
+	}
+	instance.Binding = nil
+	return nil
+}
+
+// credentialValues converts binding credentials into secret values, values which aren't strings are JSON encoded
+func credentialValues(credentials map[string]interface{}) (dapi.SecretValue, error) {
+	values := make(dapi.SecretValue)
+	for k, v := range credentials {
+		if s, ok := v.(string); ok {
+			values[k] = s
+			continue
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding credential %s", k)
+		}
+		values[k] = string(encoded)
+	}
+	return values, nil
+}
+
+func toServiceClass(o offering) *dapi.ServiceClass {
+	var tags []*dapi.Tag
+	for _, tag := range o.service.Tags {
+		tags = append(tags, &dapi.Tag{Key: "tag", Value: tag})
+	}
+	class := &dapi.ServiceClass{
+		ID:          strfmt.UUID(o.service.ID),
+		Kind:        dapi.ServiceClassKind,
+		Name:        swag.String(o.service.Name),
+		Broker:      swag.String(o.broker.Name()),
+		Description: o.service.Description,
+		Bindable:    o.service.Bindable,
+		Status:      dapi.StatusREADY,
+		Tags:        tags,
+	}
+	for i := range o.service.Plans {
+		plan := &o.service.Plans[i]
+		p := &dapi.ServicePlan{
+			ID:          strfmt.UUID(plan.ID),
+			Kind:        dapi.ServicePlanKind,
+			Name:        plan.Name,
+			Description: plan.Description,
+			Free:        plan.Free == nil || *plan.Free,
+			Bindable:    plan.IsBindable(o.service),
+		}
+		if plan.Metadata != nil {
+			p.Metadata = plan.Metadata
+		}
+		if s := plan.Schemas; s != nil {
+			p.Schema = new(dapi.ServicePlanSchema)
+			if s.ServiceInstance != nil && s.ServiceInstance.Create != nil {
+				p.Schema.Create = s.ServiceInstance.Create.Parameters
+			}
+			if s.ServiceInstance != nil && s.ServiceInstance.Update != nil {
+				p.Schema.Update = s.ServiceInstance.Update.Parameters
+			}
+			if s.ServiceBinding != nil && s.ServiceBinding.Create != nil {
+				p.Schema.Bind = s.ServiceBinding.Create.Parameters
+			}
+		}
+		class.Plans = append(class.Plans, p)
+	}
+	return class
+}
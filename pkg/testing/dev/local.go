@@ -9,6 +9,7 @@ package dev
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -17,6 +18,13 @@ var isLocal = func() bool {
 	return v == "1"
 }()
 
+var functionsBackend = func() string {
+	if v, ok := os.LookupEnv("DEVFUNCTIONSBACKEND"); ok && v != "" {
+		return v
+	}
+	return "knative"
+}()
+
 // Local returns whether running as a local test
 func Local() bool {
 	return isLocal
@@ -28,3 +36,28 @@ func EnsureLocal(t *testing.T) {
 		t.Skip("run with DEVLOCALTEST=1")
 	}
 }
+
+// FunctionsBackend returns the functions backend local tests run against, the local backend runs functions as
+// processes and needs no cluster
+func FunctionsBackend() string {
+	return functionsBackend
+}
+
+// LocalCommands returns the commands serving functions on the local backend, as the [LANGUAGE=]COMMAND lines of
+// DEVLOCALCOMMANDS
+func LocalCommands() []string {
+	var commands []string
+	for _, line := range strings.Split(os.Getenv("DEVLOCALCOMMANDS"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	return commands
+}
+
+// EnsureCluster helps skip local tests which need a Kubernetes cluster when functions run on the local backend
+func EnsureCluster(t *testing.T) {
+	if FunctionsBackend() == "local" {
+		t.Skip("needs a cluster, run with DEVFUNCTIONSBACKEND=knative")
+	}
+}
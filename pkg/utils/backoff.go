@@ -23,11 +23,21 @@ func init() {
 
 // Backoff runs a function with a random backoff timeout
 func Backoff(timeout time.Duration, f func() error) error {
-	maxTimer := time.NewTimer(timeout)
+	_, err := BackoffAttempts(0, time.Duration(minSleep+rand.Int63n(minSleep)), timeout, f)
+	return err
+}
+
+// BackoffAttempts runs a function until it succeeds, maxAttempts is reached (0 for no limit) or timeout expires (0 for
+// no timeout). The time to sleep between attempts starts at sleepTime and grows randomly. It returns the number of
+// attempts made.
+func BackoffAttempts(maxAttempts int, sleepTime, timeout time.Duration, f func() error) (int, error) {
+	var maxTimer <-chan time.Time
+	if timeout > 0 {
+		maxTimer = time.NewTimer(timeout).C
+	}
 	var err error
 
 	attempt := 0
-	sleepTime := time.Duration(minSleep + rand.Int63n(minSleep))
 
 	for ; ; sleepTime = sleepTime + time.Duration(rand.Int63n(int64(sleepTime+1))) {
 		attempt++
@@ -36,20 +46,25 @@ func Backoff(timeout time.Duration, f func() error) error {
 
 		err = f()
 		if err == nil {
-			return nil
+			return attempt, nil
 		}
 
 		log.Debugf("backoff: error on attempt # %v: %v", attempt, err)
 
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			log.Debugf("backoff: out of attempts")
+			return attempt, err
+		}
+
 		sleepTimer := time.NewTimer(sleepTime)
 
 		select {
 		case <-sleepTimer.C:
 			log.Debugf("backoff: retrying")
 			continue
-		case <-maxTimer.C:
+		case <-maxTimer:
 			log.Debugf("backoff: retries timed out")
-			return err
+			return attempt, err
 		}
 	}
 }
@@ -30,3 +30,24 @@ func TestBackoff(t *testing.T) {
 		return errors.Errorf("n = %v, r = %v", n, r)
 	}))
 }
+
+func TestBackoffAttempts(t *testing.T) {
+	calls := 0
+	attempts, err := BackoffAttempts(3, time.Millisecond, 0, func() error {
+		calls++
+		return errors.New("failed")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	attempts, err = BackoffAttempts(0, time.Millisecond, 0, func() error {
+		if calls++; calls < 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
@@ -27,6 +27,8 @@ const (
 	TheSecretKey = "secret"
 
 	InitialObjectAnnotation = "dispatchframework.io/initialObject"
+	RolledAnnotation        = "dispatchframework.io/rolled"
+	SourceURLAnnotation     = "dispatchframework.io/sourceURL"
 )
 
 //ToJSONString JSON-encodes a Dispatch API object
@@ -70,6 +72,10 @@ func ToObjectMeta(meta dapi.Meta, initialObject interface{}) v1.ObjectMeta {
 		name = SecretName(meta)
 		typedObject.Secrets = nil // omit secret data
 		initialObject = typedObject
+	case dapi.Configuration:
+		name = ConfigName(meta)
+		typedObject.Values = nil // values are the data of the config map
+		initialObject = typedObject
 	case dapi.BaseImage:
 		name = BaseImageName(meta)
 		initialObject = typedObject
@@ -119,6 +125,19 @@ func SecretName(meta dapi.Meta) string {
 	return "d-secret-" + meta.Project + "-" + meta.Name
 }
 
+//BindingSecretPrefix starts the names of the Dispatch secrets holding binding credentials, it is reserved to them
+const BindingSecretPrefix = "dispatch-binding-"
+
+//BindingSecretName returns the name of the Dispatch secret holding the binding credentials of a service instance
+func BindingSecretName(instance string) string {
+	return BindingSecretPrefix + instance
+}
+
+//ConfigName returns k8s API name of the Dispatch configuration
+func ConfigName(meta dapi.Meta) string {
+	return "d-config-" + meta.Project + "-" + meta.Name
+}
+
 //BaseImageName returns k8s API name of the Dispatch baseimage
 func BaseImageName(meta dapi.Meta) string {
 	return "d-base-image-" + meta.Project + "-" + meta.Name
@@ -111,6 +111,10 @@ func Untar(dst, prefix string, r io.Reader) error {
 			continue
 		}
 
+		// Directories are not part of archives written by Tar
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
 		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
 		if err != nil {
 			return err
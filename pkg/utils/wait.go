@@ -0,0 +1,59 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+const (
+	// DefaultWaitTimeout is how long a GET waits for the status given with waitFor when no waitTimeout is set
+	DefaultWaitTimeout = 60 * time.Second
+	// MaxWaitTimeout bounds waitTimeout, so a request is not held longer than the proxies in front of the API allow
+	MaxWaitTimeout = 5 * time.Minute
+)
+
+// WaitInterval is the time between two reads of a resource while waiting for its status
+var WaitInterval = time.Second
+
+// WaitForStatus implements the waitFor and waitTimeout (in seconds) parameters of the GET operations: it calls get
+// until the status it returns is waitFor or ERROR, the timeout expires or ctx is done. get is called once when waitFor
+// is not set. Running out of time is not an error, the caller returns the resource as get last read it.
+func WaitForStatus(ctx context.Context, waitFor *string, waitTimeout *int64, get func() (dapi.Status, error)) error {
+	status, err := get()
+	if err != nil || waitFor == nil || *waitFor == "" {
+		return err
+	}
+
+	timeout := DefaultWaitTimeout
+	if waitTimeout != nil && *waitTimeout >= 0 {
+		timeout = time.Duration(*waitTimeout) * time.Second
+	}
+	if timeout > MaxWaitTimeout {
+		timeout = MaxWaitTimeout
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(WaitInterval)
+	defer ticker.Stop()
+
+	for string(status) != *waitFor && status != dapi.StatusERROR {
+		select {
+		case <-ticker.C:
+			if status, err = get(); err != nil {
+				return err
+			}
+		case <-deadline.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
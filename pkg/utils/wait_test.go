@@ -0,0 +1,63 @@
+///////////////////////////////////////////////////////////////////////
+// Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+///////////////////////////////////////////////////////////////////////
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	dapi "github.com/vmware/dispatch/pkg/api/v1"
+)
+
+// statuses returns a get function going through the statuses, staying on the last one
+func statuses(calls *int, s ...dapi.Status) func() (dapi.Status, error) {
+	return func() (dapi.Status, error) {
+		*calls++
+		if *calls > len(s) {
+			return s[len(s)-1], nil
+		}
+		return s[*calls-1], nil
+	}
+}
+
+func TestWaitForStatus(t *testing.T) {
+	WaitInterval = time.Millisecond
+	defer func() { WaitInterval = time.Second }()
+	ctx := context.Background()
+
+	calls := 0
+	assert.NoError(t, WaitForStatus(ctx, nil, nil, statuses(&calls, dapi.StatusCREATING)))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	get := statuses(&calls, dapi.StatusCREATING, dapi.StatusCREATING, dapi.StatusREADY)
+	assert.NoError(t, WaitForStatus(ctx, swag.String("READY"), nil, get))
+	assert.Equal(t, 3, calls)
+
+	// ERROR ends the wait for any other status
+	calls = 0
+	get = statuses(&calls, dapi.StatusCREATING, dapi.StatusERROR, dapi.StatusREADY)
+	assert.NoError(t, WaitForStatus(ctx, swag.String("READY"), nil, get))
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	start := time.Now()
+	assert.NoError(t, WaitForStatus(ctx, swag.String("READY"), swag.Int64(0), statuses(&calls, dapi.StatusCREATING)))
+	assert.True(t, time.Since(start) < time.Second)
+
+	calls = 0
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.NoError(t, WaitForStatus(cancelled, swag.String("READY"), nil, statuses(&calls, dapi.StatusCREATING)))
+
+	failing := func() (dapi.Status, error) { return "", errors.New("not found") }
+	assert.EqualError(t, WaitForStatus(ctx, swag.String("READY"), nil, failing), "not found")
+}